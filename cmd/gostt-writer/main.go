@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -16,37 +19,85 @@ import (
 
 	"github.com/chaz8081/gostt-writer/internal/audio"
 	"github.com/chaz8081/gostt-writer/internal/ble"
+	blecrypto "github.com/chaz8081/gostt-writer/internal/ble/crypto"
 	"github.com/chaz8081/gostt-writer/internal/config"
 	"github.com/chaz8081/gostt-writer/internal/hotkey"
 	"github.com/chaz8081/gostt-writer/internal/inject"
+	"github.com/chaz8081/gostt-writer/internal/journal"
+	"github.com/chaz8081/gostt-writer/internal/metrics"
 	"github.com/chaz8081/gostt-writer/internal/models"
+	"github.com/chaz8081/gostt-writer/internal/notify"
 	"github.com/chaz8081/gostt-writer/internal/rewrite"
+	"github.com/chaz8081/gostt-writer/internal/selftest"
+	"github.com/chaz8081/gostt-writer/internal/stats"
 	"github.com/chaz8081/gostt-writer/internal/transcribe"
 )
 
 // version is set at build time via -ldflags.
 var version = "dev"
 
-const (
-	minRecordingDuration = 0.5  // seconds
-	maxRecordingDuration = 120.0 // seconds
-)
+const minRecordingDuration = 0.5 // seconds
 
 func main() {
 	// CLI flags
 	configPath := flag.String("config", "", "path to config file (default: ~/.config/gostt-writer/config.yaml)")
 	showVersion := flag.Bool("version", false, "print version and exit")
+	printConfig := flag.Bool("print-config", false, "print the effective config (defaults applied, secrets redacted) and exit")
+	assumeYes := flag.Bool("yes", false, "don't ask before downloading a missing model (transcribe.auto_download)")
+	noWriteConfig := flag.Bool("no-write-config", false, "don't create a default config file when none exists (or set GOSTT_NO_WRITE_CONFIG)")
+	selfTest := flag.Bool("selftest", false, "check config, models, microphone, transcriber and BLE, then exit")
+	bench := flag.Bool("bench", false, "time each installed backend on a built-in sample and recommend the faster one")
 	blePair := flag.Bool("ble-pair", false, "scan and pair with an ESP32-S3 BLE device")
+	pairStatus := flag.Bool("pair-status", false, "send a test message to each paired BLE device and report whether it arrived")
 	downloadModels := flag.Bool("download-models", false, "download transcription models from HuggingFace")
+	transcribeFile := flag.String("transcribe-file", "", "transcribe a PCM WAV file (any rate or channel count; converted to 16kHz mono) to stdout and exit")
+	outputFormat := flag.String("output", "txt", "output format for --transcribe-file: txt, srt, or vtt")
+	srtPath := flag.String("srt", "", "with --transcribe-file, write SRT subtitles to this file")
+	vttPath := flag.String("vtt", "", "with --transcribe-file, write WebVTT subtitles to this file")
+	exportJournal := flag.String("export-journal", "", "write the transcript journal (transcribe.journal_path) to this file as JSON (\"-\" = stdout) and exit")
+	// Hidden: replaces the microphone with a recorded file for pipeline testing.
+	noAudio := flag.Bool("no-audio", false, "run without a microphone (hotkey presses are ignored)")
+	audioSource := flag.String("audio-source", "", "")
+	flag.Usage = printUsage
 	flag.Parse()
 
+	// Without a config file, the built-in defaults are written out for the
+	// user to edit unless that's turned off (e.g. for scripts and containers).
+	writeConfig := !*noWriteConfig && os.Getenv("GOSTT_NO_WRITE_CONFIG") == ""
+
 	if *showVersion {
 		fmt.Printf("gostt-writer %s\n", version)
 		return
 	}
 
+	if *printConfig {
+		runPrintConfig(*configPath, writeConfig)
+		return
+	}
+
+	if *selfTest {
+		if !runSelfTest(*configPath, *audioSource, writeConfig) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *bench {
+		if !runBench(*configPath, writeConfig) {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if *blePair {
-		runBLEPairing()
+		runBLEPairing(*configPath, writeConfig)
+		return
+	}
+
+	if *pairStatus {
+		if !runPairStatus(*configPath, writeConfig) {
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -55,8 +106,28 @@ func main() {
 		return
 	}
 
+	if *exportJournal != "" {
+		runExportJournal(*configPath, *exportJournal, writeConfig)
+		return
+	}
+
+	if *transcribeFile != "" {
+		format, outPath := *outputFormat, ""
+		switch {
+		case *srtPath != "" && *vttPath != "":
+			fmt.Fprintln(os.Stderr, "--srt and --vtt cannot be used together")
+			os.Exit(1)
+		case *srtPath != "":
+			format, outPath = "srt", *srtPath
+		case *vttPath != "":
+			format, outPath = "vtt", *vttPath
+		}
+		runTranscribeFile(*configPath, *transcribeFile, format, outPath, writeConfig)
+		return
+	}
+
 	// Load configuration
-	cfg, err := loadConfig(*configPath)
+	cfg, err := loadConfig(*configPath, writeConfig)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "config: %v\n", err)
 		os.Exit(1)
@@ -67,9 +138,28 @@ func main() {
 		os.Exit(1)
 	}
 
+	confirm := confirmDownload
+	if *assumeYes || !isTerminal(os.Stdin) {
+		confirm = nil
+	}
+	if err := models.EnsureModels(cfg, models.HubDownloader, confirm); err != nil {
+		if cfg.Transcribe.FallbackBackend == "" {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "%v\nwill try fallback backend %q\n", err, cfg.Transcribe.FallbackBackend)
+	}
+
 	// Set up structured logging
 	logLevel := config.ParseLogLevel(cfg.LogLevel)
-	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})
+	handlerOpts := &slog.HandlerOptions{Level: logLevel}
+	var handler slog.Handler
+	switch cfg.LogFormat {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	default:
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	}
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
@@ -80,13 +170,17 @@ func main() {
 	modelStart := time.Now()
 	transcriber, err := transcribe.New(&cfg.Transcribe)
 	if err != nil {
+		hint := "Check transcribe settings in the config"
+		if errors.Is(err, transcribe.ErrBackendUnavailable) {
+			hint = "Run 'gostt-writer --download-models' to download models"
+		}
 		slog.Error("Failed to load transcription model",
 			"error", err,
 			"backend", cfg.Transcribe.Backend,
-			"hint", "Run 'gostt-writer --download-models' to download models")
+			"hint", hint)
 		os.Exit(1)
 	}
-	slog.Info("Model loaded", "backend", cfg.Transcribe.Backend, "elapsed", time.Since(modelStart).Round(time.Millisecond))
+	slog.Info("Model loaded", "backend", transcribe.BackendName(transcriber), "elapsed", time.Since(modelStart).Round(time.Millisecond))
 
 	// Initialize streaming transcriber if enabled (whisper only)
 	var streamer *transcribe.StreamingTranscriber
@@ -98,6 +192,7 @@ func main() {
 		}
 		sc := cfg.Transcribe.Streaming
 		streamer = transcribe.NewStreamingTranscriber(wt.Model(), sc.StepMs, sc.LengthMs, sc.KeepMs)
+		streamer.SetTranslate(wt.Translate())
 		slog.Info("Streaming transcription enabled",
 			"step_ms", sc.StepMs,
 			"length_ms", sc.LengthMs,
@@ -105,48 +200,59 @@ func main() {
 	}
 
 	// Initialize audio recorder
-	recorder, err := audio.NewRecorder(cfg.Audio.SampleRate, cfg.Audio.Channels)
+	var recorder audio.Recorder = audio.DisabledRecorder{}
+	if !*noAudio {
+		recorder, err = newRecorder(*audioSource, cfg)
+	}
 	if err != nil {
 		if err := transcriber.Close(); err != nil {
 			slog.Error("failed to close transcriber", "error", err)
 		}
 		slog.Error("Failed to initialize audio recorder",
 			"error", err,
-			"hint", "Ensure microphone access is granted in System Settings > Privacy & Security > Microphone")
+			"hint", recorderHint(err))
 		os.Exit(1)
 	}
-	slog.Info("Audio recorder ready")
+	if *noAudio {
+		slog.Info("Audio disabled (--no-audio); recording hotkeys are ignored")
+	} else {
+		slog.Info("Audio recorder ready")
+	}
 
 	// Initialize text injector
-	var injector inject.TextInjector
-	switch cfg.Inject.Method {
-	case "ble":
-		key, err := hex.DecodeString(cfg.Inject.BLE.SharedSecret)
-		if err != nil {
-			slog.Error("Invalid BLE shared secret", "error", err)
-			os.Exit(1)
-		}
-		bleAdapter := ble.NewCoreBluetoothAdapter()
-		bleClient, err := ble.NewClient(bleAdapter, cfg.Inject.BLE.DeviceMAC, key, ble.ClientOptions{
-			QueueSize:    cfg.Inject.BLE.QueueSize,
-			ReconnectMax: cfg.Inject.BLE.ReconnectMax,
-		})
-		if err != nil {
-			slog.Error("Invalid BLE configuration", "error", err)
-			os.Exit(1)
-		}
-		if err := bleClient.Connect(); err != nil {
+	injector, err := inject.Build(cfg.Inject)
+	if err != nil {
+		if cfg.Inject.Method == "ble" {
 			slog.Error("BLE connection failed", "error", err,
 				"hint", "Ensure ESP32-S3 is powered on and in range. Re-pair with: task ble-pair")
-			os.Exit(1)
+		} else {
+			slog.Error("Failed to initialize text injector", "error", err)
 		}
-		injector = inject.NewBLEInjector(bleClient)
-		slog.Info("Text injector ready", "method", "ble", "device", cfg.Inject.BLE.DeviceMAC)
-	default:
-		injector = inject.NewInjector(cfg.Inject.Method)
+		os.Exit(1)
+	}
+	if cfg.Inject.Method == "ble" {
+		var macs []string
+		for _, dev := range cfg.Inject.BLE.DeviceList() {
+			macs = append(macs, dev.DeviceMAC)
+		}
+		slog.Info("Text injector ready", "method", "ble", "devices", strings.Join(macs, ", "))
+	} else {
 		slog.Info("Text injector ready", "method", cfg.Inject.Method)
 	}
 
+	// Streaming revises text already typed. Validate rules out the built-in
+	// methods that can't; this catches any other injector before recording.
+	var incInjector inject.IncrementalInjector
+	if streamer != nil {
+		inc, ok := injector.(inject.IncrementalInjector)
+		if !ok {
+			slog.Error("Streaming needs an injector that can revise typed text",
+				"method", cfg.Inject.Method, "hint", "Disable transcribe.streaming or use the type or paste method")
+			os.Exit(1)
+		}
+		incInjector = inc
+	}
+
 	// Initialize LLM rewriter (optional)
 	var rewriter *rewrite.Rewriter
 	var rewriting atomic.Bool
@@ -155,8 +261,82 @@ func main() {
 		slog.Info("LLM rewrite enabled", "model", cfg.Rewrite.Model)
 	}
 
+	// Confidence gate (optional): needs per-segment confidence, which only
+	// segment-capable backends report.
+	var gate transcribe.SegmentTranscriber
+	if cfg.Transcribe.MinConfidence > 0 {
+		if st, ok := transcriber.(transcribe.SegmentTranscriber); ok {
+			gate = st
+			slog.Info("Confidence gate enabled", "min_confidence", cfg.Transcribe.MinConfidence)
+		} else {
+			slog.Warn("transcribe.min_confidence is ignored: backend does not report confidence",
+				"backend", cfg.Transcribe.Backend)
+		}
+	}
+
+	// Language detection report (optional, whisper only).
+	var detector transcribe.LanguageDetector
+	if cfg.Transcribe.Whisper.DetectLanguage {
+		if ld, ok := transcriber.(transcribe.LanguageDetector); ok {
+			detector = ld
+		} else {
+			slog.Warn("transcribe.whisper.detect_language is ignored: backend does not detect language",
+				"backend", cfg.Transcribe.Backend)
+		}
+	}
+
+	// Text transforms applied to each batch transcript
+	pipeline := transcribe.PipelineSteps(&cfg.Transcribe)
+	if len(pipeline) > 0 {
+		slog.Info("Text pipeline", "steps", strings.Join(pipeline, ","))
+	}
+
+	// Async transcriptions in flight, optionally capped
+	inflight := transcribe.NewInFlight(cfg.Transcribe.MaxConcurrent)
+
+	// Session statistics, summarized on shutdown
+	tracker := stats.NewTracker()
+
+	var journalWriter *journal.Writer
+	if cfg.Transcribe.JournalPath != "" {
+		journalWriter, err = journal.Open(cfg.Transcribe.JournalPath)
+		if err != nil {
+			slog.Error("Failed to open transcript journal", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Journaling transcripts", "path", cfg.Transcribe.JournalPath)
+	}
+	appendJournal := func(text string) {
+		if journalWriter == nil {
+			return
+		}
+		if err := journalWriter.Append(text); err != nil {
+			slog.Warn("Could not write transcript to journal", "error", err)
+		}
+	}
+
+	// Prometheus metrics endpoint (optional). The registry is always
+	// updated; it is only served when enabled.
+	registry := metrics.NewRegistry()
+	var metricsServer *metrics.Server
+	if cfg.Metrics.Enabled {
+		metricsServer, err = metrics.Serve(cfg.Metrics.Addr, registry)
+		if err != nil {
+			slog.Error("Failed to start metrics server", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Metrics endpoint ready", "url", "http://"+metricsServer.Addr()+"/metrics")
+	}
+
+	// Desktop notifications for failures in the dictation loop (optional)
+	var notifier notify.Notifier = notify.Nop{}
+	if cfg.Notifications.Enabled {
+		notifier = notify.System{}
+	}
+
 	// Initialize hotkey listener
 	listener := hotkey.NewListener(cfg.Hotkey.Keys, cfg.Hotkey.Mode)
+	listener.SetHoldThreshold(time.Duration(cfg.Hotkey.HoldThresholdMs) * time.Millisecond)
 	slog.Info("Hotkey listener ready",
 		"keys", strings.Join(cfg.Hotkey.Keys, "+"),
 		"mode", cfg.Hotkey.Mode)
@@ -176,8 +356,15 @@ func main() {
 	// this deadlocks because Go's main goroutine never pumps the GCD main queue.
 	// Running the hook on the main OS thread makes event_loop == CFRunLoopGetMain()
 	// inside hook_run(), which skips the dispatch_sync_f path entirely.
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		events := listener.Events()
+		if n, ok := recorder.(audio.SilenceNotifier); ok && cfg.Audio.SilenceAutoStopSecs > 0 {
+			events = withSilenceStops(events, n.SilenceStop(), listener.NotifyStopped)
+		}
+		debouncer := hotkey.NewDebouncer(time.Duration(cfg.Hotkey.DebounceMs) * time.Millisecond)
+		streamSuppressed := false // focused app was denied when streaming started
 		for {
 			select {
 			case ev, ok := <-events:
@@ -193,44 +380,70 @@ func main() {
 					return
 				}
 
+				if !debouncer.Allow(ev, time.Now()) {
+					slog.Debug("Hotkey bounce suppressed", "event", ev.Type)
+					continue
+				}
+
 				switch ev.Type {
 				case hotkey.EventStart:
 					if rewriting.Load() {
 						slog.Warn("LLM rewrite in progress, ignoring hotkey")
 						continue
 					}
-					if err := recorder.Start(); err != nil {
-						slog.Error("Failed to start recording", "error", err)
+					if err := recorder.Start(); errors.Is(err, audio.ErrAudioDisabled) {
+						slog.Info("Audio disabled, ignoring hotkey")
+						continue
+					} else if err != nil {
+						slog.Error("Failed to start recording", "error", err, "hint", recorderHint(err))
+						notifyError(notifier, "Recording failed", err)
 						continue
 					}
 					slog.Info("Recording...")
 
 					// Start streaming transcription if enabled
 					if streamer != nil {
-						localInjector := injector.(*inject.Injector)
+						streamSuppressed = !injectionAllowed(&cfg.Inject)
+						suppressed := streamSuppressed
 						streamer.Start(
 							recorder.Snapshot,
-							func(backspaces int, newText string) {
-								if err := localInjector.InjectDelta(backspaces, newText); err != nil {
+							func(prev, curr string) {
+								if suppressed {
+									return
+								}
+								if err := incInjector.InjectIncremental(prev, curr); err != nil {
 									slog.Error("Streaming injection failed", "error", err)
+									notifyError(notifier, "Text injection failed", err)
 								}
 							},
 						)
 					}
 
 				case hotkey.EventStop:
+					if !recorder.IsRecording() {
+						// Already stopped, e.g. by a silence auto-stop before the
+						// key was released. A second stop would journal and
+						// rewrite the streamed text again.
+						slog.Debug("Not recording, ignoring stop")
+						continue
+					}
 					if streamer != nil {
 						// Streaming mode: stop streamer first (does final transcription),
 						// then stop recording
+						start := time.Now()
 						streamer.Stop()
-						recorder.Stop()
-						slog.Info("Streaming transcription complete")
+						elapsed := time.Since(start).Round(time.Millisecond)
+						samples := recorder.Stop()
+						audioDur := time.Duration(float64(len(samples)) / float64(cfg.Audio.SampleRate) * float64(time.Second))
+						tracker.RecordTranscription(audioDur, elapsed)
+						registry.ObserveTranscription(audioDur, elapsed)
+						slog.Info("Streaming transcription complete", "elapsed", elapsed)
+						appendJournal(streamer.FinalText())
 
 						// LLM rewrite: backspace raw text and replace with rewritten
-						if rewriter != nil {
+						if rewriter != nil && !streamSuppressed {
 							finalText := streamer.FinalText()
 							if finalText != "" {
-								localInjector := injector.(*inject.Injector)
 								go func() {
 									rewriting.Store(true)
 									defer rewriting.Store(false)
@@ -239,9 +452,10 @@ func main() {
 										slog.Warn("LLM rewrite failed, keeping raw text", "error", rwErr)
 										return
 									}
-									// Backspace all raw text and type rewritten version
-									if err := localInjector.InjectDelta(len([]rune(finalText)), rewritten); err != nil {
+									// Replace the raw text with the rewritten version
+									if err := incInjector.InjectIncremental(finalText, rewritten); err != nil {
 										slog.Error("Rewrite injection failed", "error", err)
+										notifyError(notifier, "Text injection failed", err)
 									}
 								}()
 							}
@@ -262,35 +476,100 @@ func main() {
 							continue
 						}
 
-						if duration > maxRecordingDuration {
+						if maxDuration := float64(cfg.Audio.MaxDurationSecs); duration > maxDuration {
 							slog.Warn("Recording exceeds max duration, truncating",
 								"duration_s", fmt.Sprintf("%.1f", duration),
-								"max_s", maxRecordingDuration)
-							maxSamples := int(maxRecordingDuration * float64(cfg.Audio.SampleRate))
-							samples = samples[:maxSamples]
-							duration = maxRecordingDuration
+								"max_s", maxDuration)
+							maxSamples := cfg.Audio.MaxDurationSecs * int(cfg.Audio.SampleRate)
+							samples = clampDuration(samples, maxSamples)
+							duration = float64(len(samples)) / float64(cfg.Audio.SampleRate)
+						}
+
+						if cfg.Audio.TrimSilence {
+							before := len(samples)
+							samples = audio.TrimSilence(samples, cfg.Audio.SampleRate, audio.VADOptions{})
+							slog.Debug("Trimmed silence",
+								"removed_s", fmt.Sprintf("%.2f", float64(before-len(samples))/float64(cfg.Audio.SampleRate)))
+						}
+
+						if cfg.Audio.Normalize {
+							samples = audio.Normalize(samples, audio.DefaultTargetPeak)
 						}
 
 						slog.Info("Captured audio, transcribing...",
-							"duration_s", fmt.Sprintf("%.1f", duration))
+							"duration_s", fmt.Sprintf("%.1f", duration),
+							"inflight", inflight.Add())
 
 						// Async transcription and injection
 						go func(samples []float32) {
-							start := time.Now()
-							text, err := transcriber.Process(samples)
+							inflight.Acquire()
+							defer inflight.Done()
+
+							var text string
+							var segments []transcribe.Segment
+							var elapsed time.Duration
+							var rtf float64
+							var err error
+							// Both convert audio recorded at audio.sample_rate to the
+							// 16kHz the backends expect.
+							if gate != nil {
+								segments, elapsed, rtf, err = transcribe.ProcessSegmentsTimed(gate, samples,
+									int(cfg.Audio.SampleRate), cfg.Transcribe.RTFWarn)
+								text = transcribe.SegmentsText(segments)
+							} else {
+								text, elapsed, rtf, err = transcribe.ProcessTimed(transcriber, samples,
+									int(cfg.Audio.SampleRate), cfg.Transcribe.RTFWarn)
+							}
 							if err != nil {
-								slog.Error("Transcription failed", "error", err)
+								registry.ObserveError()
+								reportTranscribeError(notifier, err)
 								return
 							}
 
-							elapsed := time.Since(start).Round(time.Millisecond)
+							elapsed = elapsed.Round(time.Millisecond)
+							audioDur := time.Duration(duration * float64(time.Second))
+							tracker.RecordTranscription(audioDur, elapsed)
+							registry.ObserveTranscription(audioDur, elapsed)
 
 							if text == "" {
 								slog.Info("No speech detected", "elapsed", elapsed)
 								return
 							}
 
-							slog.Info("Transcribed", "elapsed", elapsed, "text", text)
+							if detector != nil {
+								// Detection takes another whisper pass and is only
+								// logged, so it runs once this goroutine is done,
+								// after the text is injected, rather than delaying it.
+								defer func() {
+									// The recorder downmixes to mono; detection needs 16kHz.
+									if lang, prob, err := detector.DetectLanguage(audio.Resample(samples, cfg.Audio.SampleRate, 16000)); err != nil {
+										slog.Warn("Language detection failed", "error", err)
+									} else {
+										slog.Info("Detected language", "lang", lang, "prob", fmt.Sprintf("%.2f", prob))
+									}
+								}()
+							}
+
+							if gate != nil && !transcribe.ShouldInject(segments, cfg.Transcribe.MinConfidence) {
+								slog.Warn("Low-confidence transcription, not injecting",
+									"confidence", fmt.Sprintf("%.2f", transcribe.TranscriptConfidence(segments)),
+									"min", cfg.Transcribe.MinConfidence,
+									"text", text)
+								if cfg.Transcribe.ConfidenceBeep {
+									audio.Beep()
+								}
+								return
+							}
+
+							text, err = transcribe.RunPipeline(text, pipeline, &cfg.Transcribe)
+							if err != nil {
+								slog.Error("Text pipeline failed", "error", err)
+								notifyError(notifier, "Transcription failed", err)
+								return
+							}
+
+							slog.Info("Transcribed", "elapsed", elapsed, "rtf", fmt.Sprintf("%.2f", rtf),
+								"inflight", inflight.Count(), "text", text)
 
 							if rewriter != nil {
 								rewriting.Store(true)
@@ -303,8 +582,16 @@ func main() {
 								}
 							}
 
+							appendJournal(text)
+
+							if !injectionAllowed(&cfg.Inject) {
+								return
+							}
+
+							text = inject.AppendTrailing(text, cfg.Inject.Trailing)
 							if err := injector.Inject(text); err != nil {
 								slog.Error("Text injection failed", "error", err)
+								notifyError(notifier, "Text injection failed", err)
 								return
 							}
 
@@ -315,32 +602,36 @@ func main() {
 
 			case sig := <-sigCh:
 				slog.Info("Shutting down...", "signal", sig)
-				// Stop streaming if active
-				if streamer != nil && recorder.IsRecording() {
-					streamer.Stop()
-				}
-				// Stop recording if active
-				if recorder.IsRecording() {
-					recorder.Stop()
-				}
-				if err := recorder.Close(); err != nil {
-					slog.Error("failed to close recorder", "error", err)
+				parts := shutdownParts{
+					stopListener: listener.Stop,
+					stopRecording: func() {
+						if streamer != nil && recorder.IsRecording() {
+							streamer.Stop()
+						}
+						if recorder.IsRecording() {
+							recorder.Stop()
+						}
+					},
+					drain: func() error {
+						if !inflight.Wait(shutdownDrainTimeout) {
+							return fmt.Errorf("%d still running after %s", inflight.Count(), shutdownDrainTimeout)
+						}
+						return nil
+					},
+					injector:    injector,
+					recorder:    recorder.Close,
+					transcriber: transcriber.Close,
 				}
-				if err := transcriber.Close(); err != nil {
-					slog.Error("failed to close transcriber", "error", err)
+				if metricsServer != nil {
+					parts.metrics = metricsServer.Close
 				}
-				if closer, ok := injector.(interface{ Close() error }); ok {
-					if err := closer.Close(); err != nil {
-						slog.Error("failed to close injector", "error", err)
-					}
+				if journalWriter != nil {
+					parts.journal = journalWriter.Close
 				}
+				shutdown(shutdownSteps(parts))
+				slog.Info("Session summary", "session", tracker)
 				slog.Info("Goodbye!")
-				// Stop the hotkey listener, which unblocks listener.Start() on
-				// the main goroutine and allows main() to return cleanly.
-				// We call listener.Stop() instead of os.Exit(0) because the
-				// CFRunLoop on the main OS thread needs to exit naturally to
-				// avoid the gohook C cleanup crash.
-				listener.Stop()
+				return
 			}
 		}
 	}()
@@ -353,12 +644,244 @@ func main() {
 	// instead of deadlocking on the GCD main queue.
 	runtime.LockOSThread()
 	listener.Start() // blocks until listener.Stop() is called
+	<-shutdownDone
+}
+
+// shutdownDrainTimeout bounds how long shutdown waits for in-flight
+// transcriptions to be transcribed and injected.
+const shutdownDrainTimeout = 10 * time.Second
+
+// shutdownStep is one stage of the ordered shutdown.
+type shutdownStep struct {
+	name string
+	stop func() error
+}
+
+// shutdownParts holds what the ordered shutdown stops and closes. metrics
+// and journal are nil when those features are off.
+type shutdownParts struct {
+	stopListener  func()
+	stopRecording func()
+	drain         func() error // waits for in-flight transcriptions
+	injector      inject.TextInjector
+	recorder      func() error
+	transcriber   func() error
+	metrics       func() error
+	journal       func() error
+}
+
+// shutdownSteps returns the order main shuts down in. Stopping the hotkey
+// listener first means no new dictation starts; it also unblocks
+// listener.Start() on the main goroutine, letting gohook's CFRunLoop exit
+// naturally while main() waits for the rest of the shutdown. In-flight
+// transcriptions are then drained before the injector, recorder and
+// transcriber they use are closed.
+func shutdownSteps(p shutdownParts) []shutdownStep {
+	steps := []shutdownStep{
+		{"hotkey listener", func() error { p.stopListener(); return nil }},
+		{"recording", func() error { p.stopRecording(); return nil }},
+		{"in-flight transcriptions", p.drain},
+	}
+	if closer, ok := p.injector.(interface{ Close() error }); ok {
+		// Closing the BLE injector sends anything still queued.
+		steps = append(steps, shutdownStep{"injector", closer.Close})
+	}
+	steps = append(steps,
+		shutdownStep{"recorder", p.recorder},
+		shutdownStep{"transcriber", p.transcriber})
+	if p.metrics != nil {
+		steps = append(steps, shutdownStep{"metrics server", p.metrics})
+	}
+	if p.journal != nil {
+		steps = append(steps, shutdownStep{"transcript journal", p.journal})
+	}
+	return steps
+}
+
+// shutdown runs steps in order. A step that fails or panics is logged and
+// the rest still run, so an error closing one component can't leave the BLE
+// link or the audio device open. Only Go panics are recovered: a crash in
+// gohook's or another library's C code still takes the process down.
+func shutdown(steps []shutdownStep) {
+	for _, s := range steps {
+		if err := runShutdownStep(s); err != nil {
+			slog.Error("Shutdown step failed", "step", s.name, "error", err)
+		}
+	}
+}
+
+func runShutdownStep(s shutdownStep) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.stop()
+}
+
+// withSilenceStops returns a channel carrying events plus an EventStop for
+// each silence auto-stop, calling onSilence first so the hotkey listener
+// knows the recording ended. It closes when events closes.
+func withSilenceStops(events <-chan hotkey.Event, silence <-chan struct{}, onSilence func()) <-chan hotkey.Event {
+	out := make(chan hotkey.Event)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				out <- ev
+			case <-silence:
+				slog.Info("Silence detected, stopping recording")
+				onSilence()
+				out <- hotkey.Event{Type: hotkey.EventStop}
+			}
+		}
+	}()
+	return out
+}
+
+// newRecorder creates the audio source: the default microphone, or with
+// source "file:<path>" a recorder that plays back a WAV or raw float32 file.
+func newRecorder(source string, cfg *config.Config) (audio.Recorder, error) {
+	switch {
+	case source == "":
+		return audio.NewRecorder(cfg.Audio.SampleRate, cfg.Audio.Channels, audio.RecorderOptions{
+			Persistent:       cfg.Audio.Persistent,
+			Format:           cfg.Audio.Format,
+			MaxSamples:       cfg.Audio.MaxDurationSecs * int(cfg.Audio.SampleRate),
+			SilenceAutoStop:  time.Duration(cfg.Audio.SilenceAutoStopSecs * float64(time.Second)),
+			PeriodSizeFrames: uint32(cfg.Audio.PeriodSizeFrames),
+			Periods:          uint32(cfg.Audio.Periods),
+		})
+	case strings.HasPrefix(source, "file:"):
+		return audio.NewFileRecorder(strings.TrimPrefix(source, "file:"), cfg.Audio.SampleRate)
+	default:
+		return nil, fmt.Errorf("unknown audio source %q (expected file:<path>)", source)
+	}
+}
+
+// printUsage prints command-line usage, omitting hidden flags (those with
+// an empty usage string).
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Usage == "" {
+			return
+		}
+		name, usage := flag.UnquoteUsage(f)
+		if name != "" {
+			fmt.Fprintf(out, "  -%s %s\n", f.Name, name)
+		} else {
+			fmt.Fprintf(out, "  -%s\n", f.Name)
+		}
+		fmt.Fprintf(out, "    \t%s", usage)
+		if f.DefValue != "" && f.DefValue != "false" {
+			fmt.Fprintf(out, " (default %q)", f.DefValue)
+		}
+		fmt.Fprintln(out)
+	})
+}
+
+// clampDuration returns at most the first maxSamples samples. maxSamples is
+// computed from a duration in seconds, so it is bounded by len(samples)
+// rather than trusted to line up with it.
+func clampDuration(samples []float32, maxSamples int) []float32 {
+	maxSamples = max(0, min(maxSamples, len(samples)))
+	return samples[:maxSamples]
+}
+
+// injectionAllowed reports whether the focused application may receive text
+// under inject.app_allowlist and inject.app_denylist, logging when it may not.
+func injectionAllowed(cfg *config.InjectConfig) bool {
+	return appAllowed(cfg, inject.FrontmostApp)
+}
+
+// appAllowed is injectionAllowed with the focused-app lookup passed in. If
+// the lookup fails, the text is not injected: the focused app could be one
+// the lists exist to protect.
+func appAllowed(cfg *config.InjectConfig, frontmostApp func() (string, error)) bool {
+	if len(cfg.AppAllowlist) == 0 && len(cfg.AppDenylist) == 0 {
+		return true
+	}
+	app, err := frontmostApp()
+	if err != nil {
+		slog.Error("Could not determine the focused app, not injecting", "error", err)
+		return false
+	}
+	if inject.AllowedForApp(app, cfg.AppAllowlist, cfg.AppDenylist) {
+		return true
+	}
+	slog.Warn("Focused app is not allowed to receive dictation, not injecting", "app", app)
+	return false
+}
+
+// confirmDownload asks on the terminal whether to download the model for
+// backend. Anything but an explicit "n" counts as yes.
+func confirmDownload(backend string) bool {
+	fmt.Printf("The %s model is missing. Download it now? [Y/n] ", backend)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer != "n" && answer != "no"
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// reportTranscribeError logs a failed transcription with a hint for its
+// class of error and notifies the user. A recording too short to transcribe
+// is routine, so it is only logged.
+func reportTranscribeError(n notify.Notifier, err error) {
+	switch {
+	case errors.Is(err, transcribe.ErrPredictTimeout):
+		slog.Error("Transcription timed out", "error", err)
+	case errors.Is(err, transcribe.ErrAudioTooShort):
+		slog.Info("Recording too short to transcribe", "error", err)
+		return
+	case errors.Is(err, transcribe.ErrModelNotLoaded):
+		slog.Error("Transcription failed", "error", err, "hint", "The model was unloaded; restart gostt-writer")
+	default:
+		slog.Error("Transcription failed", "error", err)
+	}
+	notifyError(n, "Transcription failed", err)
+}
+
+// notifyError shows a notification for a failure in the dictation loop. It
+// sends from its own goroutine, since osascript can take a moment to start
+// and the hotkey loop must keep handling events meanwhile. A notification
+// that can't be shown is only logged, at debug level.
+func notifyError(n notify.Notifier, title string, err error) {
+	go func() {
+		if sendErr := n.Send(title, err.Error()); sendErr != nil {
+			slog.Debug("Could not show notification", "error", sendErr)
+		}
+	}()
+}
+
+// recorderHint returns a user-facing hint for an audio recorder error.
+func recorderHint(err error) string {
+	switch {
+	case errors.Is(err, audio.ErrDeviceLost):
+		return "Reconnect the microphone (or pick another input in System Settings > Sound > Input) and press the hotkey again"
+	case errors.Is(err, audio.ErrMicPermissionDenied):
+		return "Grant microphone access to your terminal in System Settings > Privacy & Security > Microphone, then restart"
+	case errors.Is(err, audio.ErrNoInputDevice):
+		return "Connect a microphone or select an input device in System Settings > Sound > Input, or run with --no-audio"
+	default:
+		return "Ensure microphone access is granted in System Settings > Privacy & Security > Microphone"
+	}
 }
 
 // loadConfig loads the config from the specified path, or falls back to
-// the default config path, or uses built-in defaults. On first run,
-// it writes a default config file.
-func loadConfig(path string) (*config.Config, error) {
+// the default config path, or uses built-in defaults. On first run, with
+// writeDefault, it writes a default config file.
+func loadConfig(path string, writeDefault bool) (*config.Config, error) {
 	if path != "" {
 		return config.Load(path)
 	}
@@ -374,7 +897,12 @@ func loadConfig(path string) (*config.Config, error) {
 		return cfg, nil
 	}
 
-	// No config file found; create default for next time
+	// No config file found; use the defaults, writing them out for next time
+	// unless asked not to
+	if !writeDefault {
+		slog.Info("No config file, using built-in defaults", "path", defaultPath)
+		return config.Default(), nil
+	}
 	if created, err := config.WriteDefault(); err != nil {
 		slog.Warn("Could not write default config", "error", err)
 	} else if created != "" {
@@ -390,11 +918,16 @@ func printBanner(cfg *config.Config) {
 	fmt.Printf("  Version: %s\n", version)
 	fmt.Printf("  Backend: %s\n", cfg.Transcribe.Backend)
 	switch cfg.Transcribe.Backend {
+	case "mock":
+		fmt.Printf("  Model:   none (mock_text)\n")
 	case "parakeet":
 		fmt.Printf("  Model:   %s\n", cfg.Transcribe.ParakeetModelDir)
 	default:
 		fmt.Printf("  Model:   %s\n", cfg.Transcribe.ModelPath)
 	}
+	if cfg.Transcribe.Backend == "whisper" && cfg.Transcribe.Whisper.Task == "translate" {
+		fmt.Printf("  Task:    translate to English\n")
+	}
 	fmt.Printf("  Hotkey:  %s (%s mode)\n", strings.Join(cfg.Hotkey.Keys, "+"), cfg.Hotkey.Mode)
 	fmt.Printf("  Audio:   %dHz, %dch\n", cfg.Audio.SampleRate, cfg.Audio.Channels)
 	fmt.Printf("  Inject:  %s\n", cfg.Inject.Method)
@@ -410,9 +943,19 @@ func printBanner(cfg *config.Config) {
 }
 
 // runBLEPairing scans for ESP32-S3 devices and performs ECDH key exchange.
-func runBLEPairing() {
+func runBLEPairing(configPath string, writeConfig bool) {
 	fmt.Println("=== BLE Pairing ===")
 
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	pairOpts := ble.DefaultPairOptions()
+	if cfg.Inject.BLE.HKDFInfo != "" {
+		pairOpts.HKDFInfo = cfg.Inject.BLE.HKDFInfo
+	}
+
 	adapter := ble.NewCoreBluetoothAdapter()
 
 	fmt.Println("Scanning for ESP32-S3 devices (5 seconds)...")
@@ -436,7 +979,7 @@ func runBLEPairing() {
 	target := devices[0]
 	fmt.Printf("\nPairing with %s (%s)...\n", target.Name, target.MAC)
 
-	result, err := ble.Pair(adapter, target.MAC, ble.DefaultPairOptions())
+	result, err := ble.Pair(adapter, target.MAC, pairOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Pairing failed: %v\n", err)
 		os.Exit(1)
@@ -445,13 +988,394 @@ func runBLEPairing() {
 	secretHex := hex.EncodeToString(result.SharedSecret)
 	fmt.Println("\nPairing successful!")
 	fmt.Printf("  Device MAC:    %s\n", result.DeviceMAC)
+	if result.HardwareMAC != "" && result.HardwareMAC != result.DeviceMAC {
+		fmt.Printf("  Hardware MAC:  %s\n", result.HardwareMAC)
+	}
 	fmt.Printf("  Shared Secret: %s\n", secretHex)
 	fmt.Println("\nAdd to your config (~/.config/gostt-writer/config.yaml):")
 	fmt.Println("  inject:")
 	fmt.Println("    method: ble")
 	fmt.Println("    ble:")
-	fmt.Printf("      device_mac: %q\n", result.DeviceMAC)
+	if result.HardwareMAC != "" && result.HardwareMAC != result.DeviceMAC {
+		fmt.Printf("      device_mac: %q # this Mac's CoreBluetooth ID; re-pair on other machines\n", result.DeviceMAC)
+	} else {
+		fmt.Printf("      device_mac: %q\n", result.DeviceMAC)
+	}
+	if result.HardwareMAC != "" {
+		fmt.Printf("      hardware_mac: %q\n", result.HardwareMAC)
+	}
 	fmt.Printf("      shared_secret: %q\n", secretHex)
+	if pairOpts.HKDFInfo != blecrypto.DefaultHKDFInfo {
+		fmt.Printf("      hkdf_info: %q\n", pairOpts.HKDFInfo)
+	}
+	fmt.Println("\nTo keep the secret out of the config file, store it in the keychain and")
+	fmt.Println("set shared_secret to \"keychain:gostt-writer\":")
+	fmt.Printf("  security add-generic-password -s gostt-writer -a \"$USER\" -w %s\n", secretHex)
+	if passphrase := os.Getenv(config.PassphraseEnv); passphrase != "" {
+		sealed, err := config.SealSharedSecret(passphrase, secretHex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Encrypting shared secret: %v\n", err)
+			return
+		}
+		fmt.Printf("\nOr use the secret encrypted with $%s (asked for at startup if unset):\n", config.PassphraseEnv)
+		fmt.Printf("      shared_secret: %q\n", sealed)
+	} else {
+		fmt.Printf("\nTo store it encrypted with a passphrase instead, re-pair with %s set.\n", config.PassphraseEnv)
+	}
+}
+
+// runPairStatus probes each paired BLE device (inject.ble.device_mac and
+// inject.ble.devices) with ble.Probe, printing the timings or the failure,
+// and reports whether every probe succeeded. It doesn't need inject.method
+// to be ble, the models, or a microphone.
+func runPairStatus(configPath string, writeConfig bool) bool {
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return false
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "config validation: %v\n", err)
+		return false
+	}
+	devices := cfg.Inject.BLE.DeviceList()
+	if len(devices) == 0 {
+		fmt.Fprintln(os.Stderr, "No paired BLE device in inject.ble; run gostt-writer --ble-pair first")
+		return false
+	}
+
+	fmt.Printf("Probing %d BLE device(s); each types a single space.\n", len(devices))
+	adapter := ble.NewCoreBluetoothAdapter()
+	ok := true
+	for _, dev := range devices {
+		// The probe is a real packet, so its number is persisted like any other.
+		client, err := inject.NewBLEClient(adapter, &cfg.Inject.BLE, dev, !cfg.Inject.BLE.DisablePacketPersist)
+		if err != nil {
+			fmt.Printf("FAIL  %s: %v\n", dev.DeviceMAC, err)
+			ok = false
+			continue
+		}
+		res, err := ble.Probe(client)
+		if err != nil {
+			fmt.Printf("FAIL  %s: %v\n", dev.DeviceMAC, err)
+			ok = false
+			continue
+		}
+		fmt.Printf("OK    %s: connect %s, round trip %s\n", dev.DeviceMAC,
+			res.Connect.Round(time.Millisecond), res.RoundTrip.Round(time.Millisecond))
+		if !res.Acked {
+			fmt.Println("      not acknowledged, so the shared secret is unverified (set inject.ble.ack_timeout_ms)")
+		}
+	}
+	return ok
+}
+
+// runSelfTest checks each subsystem in turn, printing a pass/fail line per
+// check, and reports whether all of them passed. Checks that depend on an
+// earlier failure are skipped.
+func runSelfTest(configPath, audioSource string, writeConfig bool) bool {
+	var cfg *config.Config
+	var transcriber transcribe.Transcriber
+	defer func() {
+		if transcriber != nil {
+			_ = transcriber.Close()
+		}
+	}()
+
+	checks := []selftest.Check{
+		func() (string, error) {
+			c, err := loadConfig(configPath, writeConfig)
+			if err != nil {
+				return "config", err
+			}
+			if err := c.Validate(); err != nil {
+				return "config", err
+			}
+			cfg = c
+			return "config", nil
+		},
+		func() (string, error) {
+			if cfg == nil {
+				return "models", selftest.Skip("config not loaded")
+			}
+			return "models (" + cfg.Transcribe.Backend + ")", config.CheckModelFiles(cfg)
+		},
+		func() (string, error) {
+			if cfg == nil {
+				return "audio", selftest.Skip("config not loaded")
+			}
+			name := "audio"
+			if audioSource == "" {
+				mic, err := audio.DefaultInputName()
+				if err != nil {
+					return name, err
+				}
+				name += " (" + mic + ")"
+			}
+			// A persistent recorder opens the device immediately.
+			audioCfg := *cfg
+			audioCfg.Audio.Persistent = true
+			recorder, err := newRecorder(audioSource, &audioCfg)
+			if err != nil {
+				return name, fmt.Errorf("%w (%s)", err, recorderHint(err))
+			}
+			return name, recorder.Close()
+		},
+		func() (string, error) {
+			if cfg == nil {
+				return "transcriber", selftest.Skip("config not loaded")
+			}
+			t, err := transcribe.New(&cfg.Transcribe)
+			if err != nil {
+				return "transcriber", err
+			}
+			transcriber = t
+			name := "transcriber (" + transcribe.BackendName(t) + ")"
+			start := time.Now()
+			if err := transcribe.Warmup(t); err != nil {
+				return name, err
+			}
+			return fmt.Sprintf("%s, silence in %s", name, time.Since(start).Round(time.Millisecond)), nil
+		},
+		func() (string, error) {
+			if cfg == nil {
+				return "ble", selftest.Skip("config not loaded")
+			}
+			if cfg.Inject.Method != "ble" {
+				return "ble", selftest.Skip("inject.method is not ble")
+			}
+			adapter := ble.NewCoreBluetoothAdapter()
+			var errs []error
+			for _, dev := range cfg.Inject.BLE.DeviceList() {
+				// Nothing is sent, so the saved packet number is only read; counter
+				// nonces need it configured.
+				client, err := inject.NewBLEClient(adapter, &cfg.Inject.BLE, dev, !cfg.Inject.BLE.DisablePacketPersist)
+				if err == nil {
+					err = client.Connect()
+					_ = client.Close()
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", dev.DeviceMAC, err))
+				}
+			}
+			return "ble", errors.Join(errs...)
+		},
+	}
+
+	fmt.Println("gostt-writer self-test")
+	return selftest.Run(os.Stdout, checks)
+}
+
+// benchRuns is how many timed runs --bench averages per backend.
+const benchRuns = 5
+
+// runBench times each backend whose model is installed on the built-in
+// sample and prints a recommendation. It reports whether any backend ran.
+func runBench(configPath string, writeConfig bool) bool {
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return false
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "config validation: %v\n", err)
+		return false
+	}
+
+	samples, err := audio.DecodeWAV(bytes.NewReader(transcribe.BenchSampleWAV), 16000)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Loading the benchmark sample failed: %v\n", err)
+		return false
+	}
+
+	fmt.Printf("gostt-writer bench (%d runs of a %s sample)\n", benchRuns,
+		(time.Duration(len(samples)) * time.Second / 16000).Round(100*time.Millisecond))
+	var results []transcribe.BenchResult
+	for _, backend := range []string{"whisper", "parakeet"} {
+		bcfg := *cfg
+		bcfg.Transcribe.Backend = backend
+		bcfg.Transcribe.FallbackBackend = ""
+		if err := config.CheckModelFiles(&bcfg); err != nil {
+			fmt.Printf("SKIP  %s: model not installed\n", backend)
+			continue
+		}
+		t, err := transcribe.New(&bcfg.Transcribe)
+		if err != nil {
+			fmt.Printf("FAIL  %s: %v\n", backend, err)
+			continue
+		}
+		res := transcribe.BenchBackend(t, samples, benchRuns)
+		_ = t.Close()
+		if res.Err != nil {
+			fmt.Printf("FAIL  %s: %v\n", backend, res.Err)
+			continue
+		}
+		fmt.Printf("%-8s RTF %.3f (%.0fx real time, %s per run)\n", backend, res.RTF, 1/res.RTF,
+			(res.Elapsed / time.Duration(res.Runs)).Round(time.Millisecond))
+		results = append(results, res)
+	}
+
+	switch {
+	case len(results) == 0:
+		fmt.Println("No backend could be benchmarked; download models with gostt-writer --download-models")
+		return false
+	case len(results) == 1:
+		fmt.Printf("Only %s is installed; download the other model to compare\n", results[0].Backend)
+	default:
+		fmt.Println(transcribe.BenchRecommendation(results))
+	}
+	return true
+}
+
+// runPrintConfig prints the fully resolved config as YAML to stdout.
+func runPrintConfig(configPath string, writeConfig bool) {
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "config validation: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := cfg.MarshalEffective()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	_, _ = os.Stdout.Write(data)
+}
+
+// runTranscribeFile transcribes a WAV file and writes the result as plain
+// text or SRT/VTT subtitles to outPath, or to stdout if outPath is empty.
+func runTranscribeFile(configPath, path, format, outPath string, writeConfig bool) {
+	switch format {
+	case "txt", "srt", "vtt":
+	default:
+		fmt.Fprintf(os.Stderr, "invalid --output %q (expected txt, srt, or vtt)\n", format)
+		os.Exit(1)
+	}
+
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "config validation: %v\n", err)
+		os.Exit(1)
+	}
+	if err := config.CheckModelFiles(cfg); err != nil && cfg.Transcribe.FallbackBackend == "" {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	// The transcribers take 16kHz mono whatever audio.sample_rate is; the
+	// file is converted on load.
+	samples, err := audio.LoadWAV(path, 16000)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Loading %s failed: %v\n", path, err)
+		os.Exit(1)
+	}
+
+	transcriber, err := transcribe.New(&cfg.Transcribe)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load transcription model: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = transcriber.Close() }()
+
+	var segments []transcribe.Segment
+	if st, ok := transcriber.(transcribe.SegmentTranscriber); ok {
+		segments, err = st.ProcessSegments(samples)
+	} else {
+		// Backend has no timestamps: emit the whole file as one segment.
+		var text string
+		if lt, ok := transcriber.(transcribe.LongTranscriber); ok {
+			text, err = lt.ProcessLong(samples)
+		} else {
+			text, err = transcriber.Process(samples)
+		}
+		duration := time.Duration(len(samples)) * time.Second / 16000
+		segments = []transcribe.Segment{{Start: 0, End: duration, Text: text}}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Transcription failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	var out string
+	switch format {
+	case "srt":
+		out = transcribe.FormatSRT(segments)
+	case "vtt":
+		out = transcribe.FormatVTT(segments)
+	default:
+		texts := make([]string, 0, len(segments))
+		for _, seg := range segments {
+			if text := strings.TrimSpace(seg.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		// The same text pipeline as live dictation.
+		text, err := transcribe.RunPipeline(strings.Join(texts, " "), transcribe.PipelineSteps(&cfg.Transcribe), &cfg.Transcribe)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Text pipeline failed: %v\n", err)
+			os.Exit(1)
+		}
+		out = text + "\n"
+	}
+
+	if outPath == "" {
+		fmt.Print(out)
+		return
+	}
+	if err := os.WriteFile(outPath, []byte(out), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Writing %s failed: %v\n", outPath, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d segments to %s\n", len(segments), outPath)
+}
+
+// runExportJournal converts the configured transcript journal to a JSON
+// array of {timestamp, text} objects, written to outPath or stdout for "-".
+func runExportJournal(configPath, outPath string, writeConfig bool) {
+	cfg, err := loadConfig(configPath, writeConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Transcribe.JournalPath == "" {
+		fmt.Fprintln(os.Stderr, "transcribe.journal_path is not set; there is no journal to export")
+		os.Exit(1)
+	}
+
+	in, err := os.Open(cfg.Transcribe.JournalPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Opening journal failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = in.Close() }()
+
+	if outPath == "-" {
+		if err := journal.Export(in, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	var buf bytes.Buffer
+	if err := journal.Export(in, &buf); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	// Transcripts are private, so the export gets the journal's permissions.
+	if err := os.WriteFile(outPath, buf.Bytes(), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Writing %s failed: %v\n", outPath, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %s to %s\n", cfg.Transcribe.JournalPath, outPath)
 }
 
 // runModelDownload downloads transcription models from HuggingFace.
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+	"github.com/chaz8081/gostt-writer/internal/hotkey"
+	"github.com/chaz8081/gostt-writer/internal/inject"
+	"github.com/chaz8081/gostt-writer/internal/notify"
+	"github.com/chaz8081/gostt-writer/internal/transcribe"
+)
+
+func TestLoadConfigWriteDefault(t *testing.T) {
+	for _, writeDefault := range []bool{false, true} {
+		t.Setenv("HOME", t.TempDir())
+
+		cfg, err := loadConfig("", writeDefault)
+		if err != nil {
+			t.Fatalf("loadConfig(writeDefault=%v) error = %v", writeDefault, err)
+		}
+		if cfg.Transcribe.Backend != config.Default().Transcribe.Backend {
+			t.Errorf("writeDefault=%v: Backend = %q, want default", writeDefault, cfg.Transcribe.Backend)
+		}
+
+		_, statErr := os.Stat(config.DefaultConfigPath())
+		if written := statErr == nil; written != writeDefault {
+			t.Errorf("writeDefault=%v: config file written = %v", writeDefault, written)
+		}
+	}
+}
+
+// closingInjector is an injector with a Close method, like BLEInjector.
+type closingInjector struct {
+	close func() error
+}
+
+func (closingInjector) Inject(string) error { return nil }
+func (c closingInjector) Close() error      { return c.close() }
+
+func TestShutdownOrder(t *testing.T) {
+	var closed []string
+	closer := func(name string, err error) func() error {
+		return func() error {
+			closed = append(closed, name)
+			return err
+		}
+	}
+	stopper := func(name string) func() {
+		return func() { closed = append(closed, name) }
+	}
+
+	shutdown(shutdownSteps(shutdownParts{
+		stopListener: func() {
+			closed = append(closed, "hotkey listener")
+			panic("gohook teardown")
+		},
+		stopRecording: stopper("recording"),
+		drain:         closer("in-flight transcriptions", nil),
+		injector:      closingInjector{closer("injector", errors.New("disconnect failed"))},
+		recorder:      closer("recorder", nil),
+		transcriber:   closer("transcriber", nil),
+		journal:       closer("transcript journal", nil),
+	}))
+
+	// A panicking or failing step doesn't stop the ones after it, and the
+	// metrics server is skipped when it isn't running.
+	want := []string{"hotkey listener", "recording", "in-flight transcriptions", "injector",
+		"recorder", "transcriber", "transcript journal"}
+	if !slices.Equal(closed, want) {
+		t.Errorf("shutdown order = %v, want %v", closed, want)
+	}
+}
+
+func TestShutdownStepsInjectorWithoutClose(t *testing.T) {
+	nop := func() error { return nil }
+	steps := shutdownSteps(shutdownParts{
+		stopListener:  func() {},
+		stopRecording: func() {},
+		drain:         nop,
+		injector:      inject.NewEchoInjector(io.Discard),
+		recorder:      nop,
+		transcriber:   nop,
+	})
+	for _, s := range steps {
+		if s.name == "injector" {
+			t.Error("shutdownSteps() closes an injector that has no Close method")
+		}
+	}
+}
+
+func TestClampDuration(t *testing.T) {
+	samples := make([]float32, 100)
+	tests := []struct {
+		name       string
+		maxSamples int
+		want       int
+	}{
+		{name: "shorter", maxSamples: 40, want: 40},
+		{name: "exact", maxSamples: 100, want: 100},
+		{name: "beyond_end", maxSamples: 101, want: 100},
+		{name: "zero", maxSamples: 0, want: 0},
+		{name: "negative", maxSamples: -5, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(clampDuration(samples, tt.maxSamples)); got != tt.want {
+				t.Errorf("len(clampDuration(100 samples, %d)) = %d, want %d", tt.maxSamples, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSilenceStops(t *testing.T) {
+	events := make(chan hotkey.Event)
+	silence := make(chan struct{})
+	var notified int
+	merged := withSilenceStops(events, silence, func() { notified++ })
+
+	events <- hotkey.Event{Type: hotkey.EventStart}
+	if ev := <-merged; ev.Type != hotkey.EventStart {
+		t.Errorf("forwarded event = %v, want EventStart", ev.Type)
+	}
+	silence <- struct{}{}
+	if ev := <-merged; ev.Type != hotkey.EventStop {
+		t.Errorf("silence event = %v, want EventStop", ev.Type)
+	}
+	if notified != 1 {
+		t.Errorf("onSilence called %d times, want 1", notified)
+	}
+
+	close(events)
+	if _, ok := <-merged; ok {
+		t.Error("merged channel still open after events closed")
+	}
+}
+
+// recordingNotifier records the notifications it is asked to send.
+type recordingNotifier struct {
+	sent chan string // "title: body"
+	err  error
+}
+
+var _ notify.Notifier = (*recordingNotifier)(nil)
+
+func newRecordingNotifier(err error) *recordingNotifier {
+	return &recordingNotifier{sent: make(chan string, 8), err: err}
+}
+
+func (r *recordingNotifier) Send(title, body string) error {
+	r.sent <- title + ": " + body
+	return r.err
+}
+
+// wait returns the notifications sent, which notifyError sends from its own
+// goroutine, waiting for want of them or giving up after a short while.
+func (r *recordingNotifier) wait(want int) []string {
+	var got []string
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		select {
+		case s := <-r.sent:
+			got = append(got, s)
+			if len(got) > want {
+				return got
+			}
+		case <-timeout:
+			return got
+		}
+	}
+}
+
+func TestReportTranscribeErrorNotifies(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"failure", errors.New("decoder crashed"), []string{"Transcription failed: decoder crashed"}},
+		{"timeout", transcribe.ErrPredictTimeout, []string{"Transcription failed: " + transcribe.ErrPredictTimeout.Error()}},
+		{"too short", transcribe.ErrAudioTooShort, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newRecordingNotifier(nil)
+			reportTranscribeError(n, tt.err)
+			if got := n.wait(len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Errorf("notifications = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyErrorIgnoresSendFailure(t *testing.T) {
+	n := newRecordingNotifier(errors.New("no notification center"))
+	notifyError(n, "Text injection failed", errors.New("clipboard busy"))
+	if got, want := n.wait(1), []string{"Text injection failed: clipboard busy"}; !slices.Equal(got, want) {
+		t.Errorf("notifications = %q, want %q", got, want)
+	}
+}
+
+func TestAppAllowed(t *testing.T) {
+	lookup := func(app string, err error) func() (string, error) {
+		return func() (string, error) { return app, err }
+	}
+	errLookup := errors.New("no accessibility access")
+	deny := &config.InjectConfig{AppDenylist: []string{"Terminal"}}
+
+	tests := []struct {
+		name   string
+		cfg    *config.InjectConfig
+		lookup func() (string, error)
+		want   bool
+	}{
+		{"no lists", &config.InjectConfig{}, lookup("", errLookup), true},
+		{"allowed app", deny, lookup("Notes", nil), true},
+		{"denied app", deny, lookup("Terminal", nil), false},
+		// A failed lookup must not let text into an app that may be denied.
+		{"lookup fails", deny, lookup("", errLookup), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appAllowed(tt.cfg, tt.lookup); got != tt.want {
+				t.Errorf("appAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -74,7 +74,7 @@ static void start_advertising(void);
 static int tx_char_write_cb(uint16_t conn_handle, uint16_t attr_handle,
                              struct ble_gatt_access_ctxt *ctxt, void *arg)
 {
-    (void)conn_handle; (void)attr_handle; (void)arg;
+    (void)attr_handle; (void)arg;
 
     struct os_mbuf *om = ctxt->om;
     uint16_t len = OS_MBUF_PKTLEN(om);
@@ -157,6 +157,16 @@ static int tx_char_write_cb(uint16_t conn_handle, uint16_t attr_handle,
                            enc_data.command_data, enc_data.command_data_len);
     }
 
+    // Acknowledge the packet so a host that waits for delivery can move on.
+    uint8_t ack_buf[16];
+    int ack_len = gostt_encode_ack_packet(ack_buf, sizeof(ack_buf), pkt.packet_num);
+    if (ack_len > 0) {
+        struct os_mbuf *om_ack = ble_hs_mbuf_from_flat(ack_buf, ack_len);
+        if (om_ack) {
+            ble_gatts_notify_custom(conn_handle, s_resp_attr_handle, om_ack);
+        }
+    }
+
     return 0;
 }
 
@@ -199,3 +199,24 @@ int gostt_encode_response_packet(uint8_t *buf, size_t buf_len,
 
     return (int)pos;
 }
+
+int gostt_encode_ack_packet(uint8_t *buf, size_t buf_len, uint32_t packet_num)
+{
+    size_t pos = 0;
+
+    // Field 1: type (varint), tag = 0x08
+    if (pos >= buf_len) return -1;
+    buf[pos++] = 0x08;
+    int n = write_varint(buf + pos, buf_len - pos, (uint64_t)GOSTT_RESP_ACK);
+    if (n < 0) return -1;
+    pos += n;
+
+    // Field 4: packet_num (varint), tag = (4 << 3) | 0 = 0x20
+    if (pos >= buf_len) return -1;
+    buf[pos++] = 0x20;
+    n = write_varint(buf + pos, buf_len - pos, (uint64_t)packet_num);
+    if (n < 0) return -1;
+    pos += n;
+
+    return (int)pos;
+}
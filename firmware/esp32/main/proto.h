@@ -35,6 +35,7 @@ typedef struct {
 typedef enum {
     GOSTT_RESP_KEEPALIVE   = 0,
     GOSTT_RESP_PEER_STATUS = 1,
+    GOSTT_RESP_ACK         = 2, // packet_num was received and decrypted
 } gostt_response_type_t;
 
 typedef enum {
@@ -64,4 +65,8 @@ int gostt_encode_response_packet(uint8_t *buf, size_t buf_len,
                                   gostt_peer_status_t peer_status,
                                   const uint8_t *data, size_t data_len);
 
+// Encode a ResponsePacket acknowledging a DataPacket: type ACK plus
+// packet_num (field 4, matching DataPacket). Returns bytes written, or -1.
+int gostt_encode_ack_packet(uint8_t *buf, size_t buf_len, uint32_t packet_num);
+
 #endif // GOSTT_KBD_PROTO_H
@@ -58,6 +58,19 @@ void test_encode_response_packet(void)
     PASS();
 }
 
+void test_encode_ack_packet(void)
+{
+    TEST(encode_ack_packet);
+    // Expected from Go test: type=2 (ACK), packet_num=300
+    uint8_t expected[] = {0x08, 0x02, 0x20, 0xAC, 0x02};
+
+    uint8_t buf[16];
+    int len = gostt_encode_ack_packet(buf, sizeof(buf), 300);
+    assert(len == (int)sizeof(expected));
+    assert(memcmp(buf, expected, len) == 0);
+    PASS();
+}
+
 void test_decode_data_packet(void)
 {
     TEST(decode_data_packet);
@@ -119,6 +132,7 @@ int main(void)
     test_decode_keyboard_packet_hello();
     test_decode_keyboard_packet_empty();
     test_encode_response_packet();
+    test_encode_ack_packet();
     test_decode_data_packet();
     test_decode_encrypted_data();
 
@@ -0,0 +1,20 @@
+//go:build darwin
+
+package audio
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit
+
+#import <AppKit/AppKit.h>
+
+static void systemBeep(void) {
+	NSBeep();
+}
+*/
+import "C"
+
+// Beep plays the system alert sound.
+func Beep() {
+	C.systemBeep()
+}
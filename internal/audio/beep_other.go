@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package audio
+
+import "os"
+
+// Beep rings the terminal bell. Only macOS has a system alert sound API
+// available without extra dependencies.
+func Beep() {
+	_, _ = os.Stderr.WriteString("\a")
+}
@@ -0,0 +1,31 @@
+package audio
+
+import "errors"
+
+// ErrAudioDisabled is returned by DisabledRecorder.Start.
+var ErrAudioDisabled = errors.New("audio capture is disabled")
+
+// Compile-time interface satisfaction check.
+var _ Recorder = DisabledRecorder{}
+
+// DisabledRecorder is a Recorder that never captures anything, for running
+// without a microphone (e.g. only relaying text to a BLE receiver).
+type DisabledRecorder struct{}
+
+// Start returns ErrAudioDisabled.
+func (DisabledRecorder) Start() error { return ErrAudioDisabled }
+
+// Stop returns nil.
+func (DisabledRecorder) Stop() []float32 { return nil }
+
+// Snapshot returns nil.
+func (DisabledRecorder) Snapshot() []float32 { return nil }
+
+// IsRecording returns false.
+func (DisabledRecorder) IsRecording() bool { return false }
+
+// Level returns 0.
+func (DisabledRecorder) Level() float32 { return 0 }
+
+// Close does nothing.
+func (DisabledRecorder) Close() error { return nil }
@@ -0,0 +1,21 @@
+package audio
+
+// DownmixToMono averages interleaved multi-channel samples into one channel,
+// since both transcription backends expect mono input. The result has
+// len(samples)/channels samples; a trailing partial frame is dropped. Mono
+// input (channels <= 1) is returned unchanged.
+func DownmixToMono(samples []float32, channels uint32) []float32 {
+	if channels <= 1 {
+		return samples
+	}
+	n := len(samples) / int(channels)
+	out := make([]float32, n)
+	for i := range out {
+		var sum float32
+		for _, s := range samples[i*int(channels) : (i+1)*int(channels)] {
+			sum += s
+		}
+		out[i] = sum / float32(channels)
+	}
+	return out
+}
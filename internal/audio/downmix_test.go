@@ -0,0 +1,39 @@
+package audio
+
+import "testing"
+
+func TestDownmixToMonoStereo(t *testing.T) {
+	// Interleaved L/R frames.
+	in := []float32{1, 0, 0.5, -0.5, -1, -0.5, 0.25, 0.75}
+	want := []float32{0.5, 0, -0.75, 0.5}
+
+	got := DownmixToMono(in, 2)
+	if len(got) != len(in)/2 {
+		t.Fatalf("DownmixToMono() returned %d samples, want %d", len(got), len(in)/2)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDownmixToMonoPartialFrame(t *testing.T) {
+	got := DownmixToMono([]float32{0.2, 0.4, 0.6}, 2)
+	if len(got) != 1 {
+		t.Fatalf("DownmixToMono() returned %d samples, want 1 (partial frame dropped)", len(got))
+	}
+	if diff := got[0] - 0.3; diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("sample 0 = %v, want 0.3", got[0])
+	}
+}
+
+func TestDownmixToMonoMono(t *testing.T) {
+	in := []float32{0.1, 0.2, 0.3}
+	for _, channels := range []uint32{0, 1} {
+		got := DownmixToMono(in, channels)
+		if len(got) != len(in) || &got[0] != &in[0] {
+			t.Errorf("DownmixToMono(channels=%d) should return the input unchanged", channels)
+		}
+	}
+}
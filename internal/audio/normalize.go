@@ -0,0 +1,36 @@
+package audio
+
+import (
+	"math"
+	"slices"
+)
+
+// DefaultTargetPeak is the peak level Normalize scales recordings to when
+// audio.normalize is enabled, leaving a little headroom below full scale.
+const DefaultTargetPeak = 0.95
+
+// silencePeak is the peak level below which a buffer is treated as silence
+// and left alone (about -40 dBFS). Scaling it up would only amplify noise.
+const silencePeak = 0.01
+
+// Normalize returns a copy of samples scaled so its peak absolute value is
+// targetPeak. Quiet buffers are scaled up and loud buffers scaled down.
+// Near-silent buffers are copied unchanged.
+func Normalize(samples []float32, targetPeak float32) []float32 {
+	var peak float32
+	for _, s := range samples {
+		if a := float32(math.Abs(float64(s))); a > peak {
+			peak = a
+		}
+	}
+	if peak < silencePeak {
+		return slices.Clone(samples)
+	}
+
+	gain := targetPeak / peak
+	out := make([]float32, len(samples))
+	for i, s := range samples {
+		out[i] = s * gain
+	}
+	return out
+}
@@ -0,0 +1,69 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// peakOf returns the largest absolute sample value.
+func peakOf(samples []float32) float32 {
+	var peak float32
+	for _, s := range samples {
+		if a := float32(math.Abs(float64(s))); a > peak {
+			peak = a
+		}
+	}
+	return peak
+}
+
+func approxEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-6
+}
+
+func TestNormalizeScalesQuietUp(t *testing.T) {
+	in := []float32{0.1, -0.2, 0.05}
+	out := Normalize(in, 0.95)
+
+	if got := peakOf(out); !approxEqual(got, 0.95) {
+		t.Errorf("peak = %v, want 0.95", got)
+	}
+	// Relative levels are preserved.
+	if !approxEqual(out[0]*2, -out[1]) {
+		t.Errorf("out = %v, relative levels not preserved", out)
+	}
+	// Input is not modified.
+	if in[1] != -0.2 {
+		t.Errorf("input modified: %v", in)
+	}
+}
+
+func TestNormalizeScalesLoudDown(t *testing.T) {
+	out := Normalize([]float32{1.0, -1.0, 0.5}, 0.95)
+
+	if got := peakOf(out); !approxEqual(got, 0.95) {
+		t.Errorf("peak = %v, want 0.95", got)
+	}
+	if !approxEqual(out[2], 0.475) {
+		t.Errorf("out[2] = %v, want 0.475", out[2])
+	}
+}
+
+func TestNormalizeSkipsSilence(t *testing.T) {
+	in := []float32{0.001, -0.002, 0}
+	out := Normalize(in, 0.95)
+	for i := range in {
+		if out[i] != in[i] {
+			t.Errorf("out[%d] = %v, want unchanged %v", i, out[i], in[i])
+		}
+	}
+	out[0] = 1
+	if in[0] != 0.001 {
+		t.Error("Normalize() of silence returned the input slice, want a copy")
+	}
+}
+
+func TestNormalizeEmpty(t *testing.T) {
+	if out := Normalize(nil, 0.95); len(out) != 0 {
+		t.Errorf("Normalize(nil) = %v, want empty", out)
+	}
+}
@@ -0,0 +1,34 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gen2brain/malgo"
+)
+
+var (
+	// ErrMicPermissionDenied is returned when the OS has denied this process
+	// access to the microphone.
+	ErrMicPermissionDenied = errors.New("microphone access denied — grant it in System Settings > Privacy & Security > Microphone")
+
+	// ErrNoInputDevice is returned when no audio capture device is available.
+	ErrNoInputDevice = errors.New("no audio input device found")
+)
+
+// translateDeviceError maps opaque miniaudio errors from device setup to
+// ErrMicPermissionDenied or ErrNoInputDevice where possible. Because
+// CoreAudio often reports a denied permission as a generic backend failure,
+// the permission preflight is re-checked before giving up.
+func translateDeviceError(err error) error {
+	switch {
+	case errors.Is(err, malgo.ErrAccessDenied):
+		return fmt.Errorf("%w: %v", ErrMicPermissionDenied, err)
+	case errors.Is(err, malgo.ErrNoDevice), errors.Is(err, malgo.ErrDoesNotExist):
+		return fmt.Errorf("%w: %v", ErrNoInputDevice, err)
+	}
+	if permErr := CheckMicPermission(); permErr != nil {
+		return fmt.Errorf("%w: %v", permErr, err)
+	}
+	return err
+}
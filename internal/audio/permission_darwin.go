@@ -0,0 +1,36 @@
+//go:build darwin
+
+package audio
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework AVFoundation
+
+#import <AVFoundation/AVFoundation.h>
+
+static int micAuthorizationStatus(void) {
+	return (int)[AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+}
+*/
+import "C"
+
+// AVAuthorizationStatus values.
+const (
+	avAuthNotDetermined = 0
+	avAuthRestricted    = 1
+	avAuthDenied        = 2
+	avAuthAuthorized    = 3
+)
+
+// CheckMicPermission reports whether this process may capture audio. It
+// returns ErrMicPermissionDenied if the user has denied (or policy restricts)
+// microphone access. If the user has not been asked yet it returns nil; macOS
+// prompts on first capture.
+func CheckMicPermission() error {
+	switch C.micAuthorizationStatus() {
+	case avAuthDenied, avAuthRestricted:
+		return ErrMicPermissionDenied
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package audio
+
+// CheckMicPermission reports whether this process may capture audio. Only
+// macOS gates microphone access per app, so this is a no-op elsewhere.
+func CheckMicPermission() error {
+	return nil
+}
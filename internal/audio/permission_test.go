@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gen2brain/malgo"
+)
+
+func TestTranslateDeviceError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"access_denied", malgo.ErrAccessDenied, ErrMicPermissionDenied},
+		{"no_device", malgo.ErrNoDevice, ErrNoInputDevice},
+		{"does_not_exist", malgo.ErrDoesNotExist, ErrNoInputDevice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translateDeviceError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("translateDeviceError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateDeviceErrorPassthrough(t *testing.T) {
+	if err := CheckMicPermission(); err != nil {
+		t.Skipf("microphone access denied on this machine: %v", err)
+	}
+	got := translateDeviceError(malgo.ErrFormatNotSupported)
+	if got != malgo.ErrFormatNotSupported {
+		t.Errorf("translateDeviceError() = %v, want unchanged error", got)
+	}
+}
+
+func TestRequireCaptureDevice(t *testing.T) {
+	none := func() ([]malgo.DeviceInfo, error) { return nil, nil }
+	if err := requireCaptureDevice(none); !errors.Is(err, ErrNoInputDevice) {
+		t.Errorf("requireCaptureDevice(no devices) = %v, want ErrNoInputDevice", err)
+	}
+
+	one := func() ([]malgo.DeviceInfo, error) { return make([]malgo.DeviceInfo, 1), nil }
+	if err := requireCaptureDevice(one); err != nil {
+		t.Errorf("requireCaptureDevice(one device) = %v, want nil", err)
+	}
+
+	failing := func() ([]malgo.DeviceInfo, error) { return nil, errors.New("backend unavailable") }
+	if err := requireCaptureDevice(failing); err != nil {
+		t.Errorf("requireCaptureDevice(list error) = %v, want nil (left to device open)", err)
+	}
+}
+
+func TestDisabledRecorder(t *testing.T) {
+	var r Recorder = DisabledRecorder{}
+	if err := r.Start(); !errors.Is(err, ErrAudioDisabled) {
+		t.Errorf("Start() = %v, want ErrAudioDisabled", err)
+	}
+	if r.IsRecording() || r.Stop() != nil || r.Snapshot() != nil {
+		t.Error("DisabledRecorder reported captured audio")
+	}
+}
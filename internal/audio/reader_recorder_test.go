@@ -0,0 +1,112 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReaderRecorder(t *testing.T) {
+	want := []float32{0.25, -0.5, 1.0}
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, want); err != nil {
+		t.Fatal(err)
+	}
+
+	var r Recorder = NewReaderRecorder(buf)
+	if r.IsRecording() {
+		t.Error("IsRecording() should be false before Start")
+	}
+	if got := r.Stop(); got != nil {
+		t.Errorf("Stop() before Start = %v, want nil", got)
+	}
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := r.Start(); err == nil {
+		t.Error("second Start() should return error")
+	}
+	if !r.IsRecording() {
+		t.Error("IsRecording() should be true after Start")
+	}
+
+	got := r.Stop()
+	if len(got) != len(want) {
+		t.Fatalf("Stop() returned %d samples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	// The source is drained; a second recording is empty.
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := r.Stop(); len(got) != 0 {
+		t.Errorf("second Stop() = %v, want empty", got)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestFileRecorderWAV(t *testing.T) {
+	path := writeTestWAV(t, 16000, 1, []int{0, 8192, -16384, 32767})
+
+	r, err := NewFileRecorder(path, 16000)
+	if err != nil {
+		t.Fatalf("NewFileRecorder() error = %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	want, err := LoadWAV(path, 16000)
+	if err != nil {
+		t.Fatalf("LoadWAV() error = %v", err)
+	}
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	got := r.Stop()
+	if len(got) != len(want) {
+		t.Fatalf("Stop() returned %d samples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFileRecorderRawPCM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audio.f32")
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, []float32{0.5, -0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewFileRecorder(path, 16000)
+	if err != nil {
+		t.Fatalf("NewFileRecorder() error = %v", err)
+	}
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := r.Stop(); len(got) != 2 || got[0] != 0.5 || got[1] != -0.5 {
+		t.Errorf("Stop() = %v, want [0.5 -0.5]", got)
+	}
+}
+
+func TestFileRecorderMissingFile(t *testing.T) {
+	if _, err := NewFileRecorder("/nonexistent/audio.wav", 16000); err == nil {
+		t.Error("NewFileRecorder() with missing file should return error")
+	}
+}
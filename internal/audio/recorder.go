@@ -2,76 +2,267 @@ package audio
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gen2brain/malgo"
 )
 
-// Recorder captures audio from the default microphone into a float32 buffer.
-type Recorder struct {
+// Recorder captures mono float32 audio samples between Start and Stop. The
+// main loop depends only on this interface, so the live microphone can be
+// swapped for a file source when testing the pipeline.
+type Recorder interface {
+	// Start begins capturing audio.
+	Start() error
+	// Stop ends capturing and returns the recorded samples, or nil if not
+	// recording.
+	Stop() []float32
+	// Snapshot returns the samples captured so far without stopping, or nil
+	// if not recording.
+	Snapshot() []float32
+	// IsRecording reports whether audio is being captured.
+	IsRecording() bool
+	// Level returns the current smoothed RMS input level (0 to 1), for a
+	// live meter. It is 0 when not recording.
+	Level() float32
+	// Close releases all resources.
+	Close() error
+}
+
+// Compile-time interface satisfaction checks.
+var (
+	_ Recorder        = (*MicRecorder)(nil)
+	_ SilenceNotifier = (*MicRecorder)(nil)
+)
+
+// RecorderOptions configures optional MicRecorder behavior.
+type RecorderOptions struct {
+	// Persistent opens the capture device once in NewRecorder and keeps it
+	// running between recordings, avoiding device-open latency (and the
+	// occasional click) at the start of every utterance. Audio captured
+	// while not recording is discarded.
+	Persistent bool
+
+	// Format is the sample format requested from the capture device: "f32"
+	// (default), "s16" or "u8". Integer samples are converted to float32
+	// in [-1, 1]. Some inexpensive USB microphones only deliver s16 reliably.
+	Format string
+
+	// MaxSamples caps the mono samples kept per recording; audio arriving
+	// after the cap is reached is discarded. 0 means no cap.
+	MaxSamples int
+
+	// SilenceAutoStop ends a recording after this much continuous silence,
+	// signalled on SilenceStop. 0 disables it.
+	SilenceAutoStop time.Duration
+
+	// PeriodSizeFrames and Periods set the capture buffer: the device
+	// delivers audio every PeriodSizeFrames frames, from a ring of Periods
+	// such buffers. Smaller periods lower latency; more periods tolerate
+	// scheduling hiccups. 0 leaves the backend's default.
+	PeriodSizeFrames uint32
+	Periods          uint32
+}
+
+// ErrDeviceLost is returned by Start when the capture device stopped
+// unexpectedly (e.g. a USB microphone was unplugged) and could not be
+// reopened.
+var ErrDeviceLost = errors.New("audio input device disconnected")
+
+// levelTimeConstant is how quickly Level follows the input: after a change
+// in loudness, the level covers about 63% of the difference within this time.
+const levelTimeConstant = 100 * time.Millisecond
+
+const (
+	// reopenAttempts bounds how many times Start tries to reopen a lost
+	// capture device before giving up.
+	reopenAttempts = 4
+	// reopenBackoff is the delay after the first failed reopen; it doubles
+	// after each further failure.
+	reopenBackoff = 250 * time.Millisecond
+)
+
+// captureDevice is the subset of *malgo.Device used by MicRecorder.
+type captureDevice interface {
+	Start() error
+	Uninit()
+}
+
+// MicRecorder captures audio from the default microphone into a float32
+// buffer. Multi-channel input is downmixed to mono as it arrives.
+type MicRecorder struct {
 	ctx        *malgo.AllocatedContext
-	device     *malgo.Device
+	device     captureDevice
 	sampleRate uint32
 	channels   uint32
+	persistent bool   // device stays open from NewRecorder until Close
+	format     string // capture sample format; "" means f32
+	maxSamples int    // cap on len(buf); 0 = unlimited
+	opts       RecorderOptions
+
+	// open initializes and starts a capture device that calls onStop when
+	// it stops; resetCtx re-creates the audio context after a device is
+	// lost. Both are swapped out in tests, as is sleep.
+	open     func(onStop func()) (captureDevice, error)
+	resetCtx func() error
+	sleep    func(time.Duration)
+
+	lost    atomic.Bool // device stopped without us asking
+	closing atomic.Bool // we are uninitializing the device ourselves
+
+	level atomic.Uint32 // smoothed RMS level as float32 bits, read by Level
 
 	mu        sync.Mutex
 	buf       []float32
 	recording bool
+	full      bool // buf reached maxSamples this recording
+
+	silence   silenceTimer
+	silenceCh chan struct{} // receives when silence reaches silence.limit; see SilenceStop
 }
 
-// NewRecorder creates a new audio recorder. Call Close() when done.
-func NewRecorder(sampleRate, channels uint32) (*Recorder, error) {
+// NewRecorder creates a microphone recorder. Call Close() when done.
+// It returns ErrMicPermissionDenied if microphone access has been denied,
+// and ErrNoInputDevice if the machine has no capture device.
+func NewRecorder(sampleRate, channels uint32, opts RecorderOptions) (*MicRecorder, error) {
+	if _, err := malgoFormat(opts.Format); err != nil {
+		return nil, err
+	}
+	if err := CheckMicPermission(); err != nil {
+		return nil, err
+	}
+
 	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("initializing audio context: %w", err)
 	}
+	if err := requireCaptureDevice(func() ([]malgo.DeviceInfo, error) { return ctx.Devices(malgo.Capture) }); err != nil {
+		_ = ctx.Uninit()
+		ctx.Free()
+		return nil, err
+	}
 
-	r := &Recorder{
+	r := &MicRecorder{
 		ctx:        ctx,
 		sampleRate: sampleRate,
 		channels:   channels,
+		persistent: opts.Persistent,
+		format:     opts.Format,
+		maxSamples: opts.MaxSamples,
+		opts:       opts,
+		sleep:      time.Sleep,
+		silence:    silenceTimer{limit: int(opts.SilenceAutoStop.Seconds() * float64(sampleRate))},
+		silenceCh:  make(chan struct{}, 1),
+	}
+	r.open = r.openMalgoDevice
+	r.resetCtx = r.resetContext
+
+	if r.persistent {
+		device, err := r.open(r.onStop)
+		if err != nil {
+			_ = ctx.Uninit()
+			ctx.Free()
+			return nil, err
+		}
+		r.device = device
 	}
 
 	return r, nil
 }
 
+// requireCaptureDevice returns ErrNoInputDevice if list reports no capture
+// devices. A listing failure is not fatal: opening the device reports the
+// underlying problem more precisely.
+func requireCaptureDevice(list func() ([]malgo.DeviceInfo, error)) error {
+	devices, err := list()
+	if err != nil {
+		slog.Debug("Could not list capture devices", "error", err)
+		return nil
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("opening microphone: %w", ErrNoInputDevice)
+	}
+	return nil
+}
+
+// DefaultInputName returns the name of the default audio capture device. It
+// returns ErrNoInputDevice if there are no capture devices.
+func DefaultInputName() (string, error) {
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return "", fmt.Errorf("initializing audio context: %w", err)
+	}
+	defer func() {
+		_ = ctx.Uninit()
+		ctx.Free()
+	}()
+
+	devices, err := ctx.Devices(malgo.Capture)
+	if err != nil {
+		return "", fmt.Errorf("listing capture devices: %w", err)
+	}
+	if len(devices) == 0 {
+		return "", ErrNoInputDevice
+	}
+	for i := range devices {
+		if devices[i].IsDefault != 0 {
+			return devices[i].Name(), nil
+		}
+	}
+	return devices[0].Name(), nil
+}
+
 // Start begins capturing audio from the default microphone.
 // Audio samples are accumulated in an internal buffer as float32 values.
-func (r *Recorder) Start() error {
+// In persistent mode the device is already running and Start only resets
+// the buffer and begins keeping samples.
+//
+// If the device stopped unexpectedly since the last Start, the audio context
+// is re-created and the device reopened, retrying with backoff. ErrDeviceLost
+// is returned if it does not come back.
+func (r *MicRecorder) Start() error {
 	r.mu.Lock()
 	if r.recording {
 		r.mu.Unlock()
 		return fmt.Errorf("already recording")
 	}
 	r.buf = r.buf[:0] // reset buffer but keep capacity
+	r.full = false
+	r.silence.reset()
+	select {
+	case <-r.silenceCh: // drop a signal left over from the last recording
+	default:
+	}
 	r.recording = true
+	r.level.Store(0)
+	persistent := r.persistent
 	r.mu.Unlock()
 
-	deviceCfg := malgo.DefaultDeviceConfig(malgo.Capture)
-	deviceCfg.Capture.Format = malgo.FormatF32
-	deviceCfg.Capture.Channels = r.channels
-	deviceCfg.SampleRate = r.sampleRate
-
-	callbacks := malgo.DeviceCallbacks{
-		Data: r.onData,
+	if r.lost.Load() {
+		if err := r.reopen(); err != nil {
+			r.mu.Lock()
+			r.recording = false
+			r.mu.Unlock()
+			return err
+		}
+		return nil
 	}
 
-	device, err := malgo.InitDevice(r.ctx.Context, deviceCfg, callbacks)
-	if err != nil {
-		r.mu.Lock()
-		r.recording = false
-		r.mu.Unlock()
-		return fmt.Errorf("initializing capture device: %w", err)
+	if persistent {
+		return nil
 	}
 
-	if err := device.Start(); err != nil {
-		device.Uninit()
+	device, err := r.open(r.onStop)
+	if err != nil {
 		r.mu.Lock()
 		r.recording = false
 		r.mu.Unlock()
-		return fmt.Errorf("starting capture device: %w", err)
+		return err
 	}
 
 	r.mu.Lock()
@@ -81,9 +272,116 @@ func (r *Recorder) Start() error {
 	return nil
 }
 
+// reopen recovers from a lost device: it releases the dead device, resets
+// the audio context and opens a new device, retrying up to reopenAttempts
+// times.
+func (r *MicRecorder) reopen() error {
+	slog.Warn("Audio input device was lost, reopening")
+
+	r.mu.Lock()
+	r.closeDevice()
+	r.mu.Unlock()
+
+	var lastErr error
+	backoff := reopenBackoff
+	for attempt := 1; attempt <= reopenAttempts; attempt++ {
+		if attempt > 1 {
+			r.sleep(backoff)
+			backoff *= 2
+		}
+
+		if err := r.resetCtx(); err != nil {
+			lastErr = err
+			slog.Debug("Audio context reset failed", "attempt", attempt, "error", err)
+			continue
+		}
+		device, err := r.open(r.onStop)
+		if err != nil {
+			lastErr = err
+			slog.Debug("Audio device reopen failed", "attempt", attempt, "error", err)
+			continue
+		}
+
+		r.mu.Lock()
+		r.device = device
+		r.mu.Unlock()
+		r.lost.Store(false)
+		slog.Info("Audio input device reopened", "attempts", attempt)
+		return nil
+	}
+	return fmt.Errorf("%w: reopening failed after %d attempts: %w", ErrDeviceLost, reopenAttempts, lastErr)
+}
+
+// resetContext replaces the audio context with a fresh one, so a device
+// plugged back in (or a new default device) is picked up.
+func (r *MicRecorder) resetContext() error {
+	if r.ctx != nil {
+		_ = r.ctx.Uninit()
+		r.ctx.Free()
+		r.ctx = nil
+	}
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return fmt.Errorf("initializing audio context: %w", err)
+	}
+	r.ctx = ctx
+	return nil
+}
+
+// closeDevice uninitializes the current device, if any, without flagging it
+// as lost. The caller must hold r.mu.
+func (r *MicRecorder) closeDevice() {
+	if r.device == nil {
+		return
+	}
+	r.closing.Store(true)
+	r.device.Uninit()
+	r.closing.Store(false)
+	r.device = nil
+}
+
+// onStop is the malgo callback invoked when the device stops. A stop we did
+// not cause means the device went away; the next Start reopens it.
+func (r *MicRecorder) onStop() {
+	if r.closing.Load() {
+		return
+	}
+	if !r.lost.Swap(true) {
+		slog.Warn("Audio input device stopped unexpectedly")
+	}
+}
+
+// openMalgoDevice initializes and starts the capture device.
+func (r *MicRecorder) openMalgoDevice(onStop func()) (captureDevice, error) {
+	deviceCfg, err := buildDeviceConfig(r.sampleRate, r.channels, r.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	callbacks := malgo.DeviceCallbacks{
+		Data: r.onData,
+		Stop: onStop,
+	}
+
+	device, err := malgo.InitDevice(r.ctx.Context, deviceCfg, callbacks)
+	if err != nil {
+		return nil, fmt.Errorf("initializing capture device: %w", translateDeviceError(err))
+	}
+
+	if err := device.Start(); err != nil {
+		r.closing.Store(true)
+		device.Uninit()
+		r.closing.Store(false)
+		return nil, fmt.Errorf("starting capture device: %w", translateDeviceError(err))
+	}
+
+	return device, nil
+}
+
 // Stop ends the audio capture and returns the recorded samples as float32.
 // The returned slice can be passed directly to whisper.cpp for transcription.
-func (r *Recorder) Stop() []float32 {
+// In persistent mode the device keeps running until Close.
+func (r *MicRecorder) Stop() []float32 {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -91,11 +389,11 @@ func (r *Recorder) Stop() []float32 {
 		return nil
 	}
 
-	if r.device != nil {
-		r.device.Uninit()
-		r.device = nil
+	if !r.persistent {
+		r.closeDevice()
 	}
 	r.recording = false
+	r.level.Store(0)
 
 	// Return a copy of the buffer
 	result := make([]float32, len(r.buf))
@@ -106,7 +404,7 @@ func (r *Recorder) Stop() []float32 {
 
 // Snapshot returns a copy of the accumulated audio buffer without stopping
 // recording. Returns nil if not recording or buffer is empty. Thread-safe.
-func (r *Recorder) Snapshot() []float32 {
+func (r *MicRecorder) Snapshot() []float32 {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if !r.recording || len(r.buf) == 0 {
@@ -118,19 +416,30 @@ func (r *Recorder) Snapshot() []float32 {
 }
 
 // IsRecording returns whether the recorder is currently capturing audio.
-func (r *Recorder) IsRecording() bool {
+func (r *MicRecorder) IsRecording() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	return r.recording
 }
 
+// Level returns the current input level: the RMS of recent samples smoothed
+// with an exponential moving average (time constant levelTimeConstant). It
+// is 0 when not recording. Safe to poll from any goroutine.
+func (r *MicRecorder) Level() float32 {
+	return math.Float32frombits(r.level.Load())
+}
+
+// SilenceStop returns a channel that receives when a recording has been
+// silent for RecorderOptions.SilenceAutoStop. The recording keeps running
+// until the caller stops it.
+func (r *MicRecorder) SilenceStop() <-chan struct{} {
+	return r.silenceCh
+}
+
 // Close releases all audio resources.
-func (r *Recorder) Close() error {
+func (r *MicRecorder) Close() error {
 	r.mu.Lock()
-	if r.device != nil {
-		r.device.Uninit()
-		r.device = nil
-	}
+	r.closeDevice()
 	r.recording = false
 	r.mu.Unlock()
 
@@ -145,14 +454,120 @@ func (r *Recorder) Close() error {
 }
 
 // onData is the malgo callback invoked when audio data is available.
-// pSample contains the captured audio frames as raw bytes (float32 format).
-func (r *Recorder) onData(_, pSample []byte, frameCount uint32) {
-	sampleCount := frameCount * r.channels
-	samples := bytesToFloat32(pSample, sampleCount)
-
+// pSample contains the captured audio frames as raw bytes in the recorder's
+// sample format, interleaved if there is more than one channel.
+// Data arriving while not recording (persistent mode) or after the buffer
+// reaches maxSamples is discarded.
+func (r *MicRecorder) onData(_, pSample []byte, frameCount uint32) {
 	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recording {
+		return
+	}
+	samples := DownmixToMono(decodeSamples(pSample, frameCount*r.channels, r.format), r.channels)
+	r.updateLevel(samples)
+	if r.silence.feed(samples) {
+		slog.Debug("Silence auto-stop triggered",
+			"silence_s", fmt.Sprintf("%.1f", float64(r.silence.limit)/float64(r.sampleRate)))
+		select {
+		case r.silenceCh <- struct{}{}:
+		default:
+		}
+	}
+	if r.maxSamples > 0 && len(r.buf)+len(samples) > r.maxSamples {
+		samples = samples[:r.maxSamples-len(r.buf)]
+		if !r.full {
+			r.full = true
+			slog.Warn("Recording reached max duration, discarding further audio",
+				"max_s", fmt.Sprintf("%.1f", float64(r.maxSamples)/float64(r.sampleRate)))
+		}
+	}
 	r.buf = append(r.buf, samples...)
-	r.mu.Unlock()
+}
+
+// updateLevel folds the RMS of one callback's mono samples into the smoothed
+// level. The smoothing factor scales with the callback's duration, so the
+// meter responds the same whatever buffer size the device uses.
+func (r *MicRecorder) updateLevel(samples []float32) {
+	if len(samples) == 0 || r.sampleRate == 0 {
+		return
+	}
+	rms := rms(samples)
+
+	frames := float64(len(samples))
+	alpha := 1 - math.Exp(-frames/(float64(r.sampleRate)*levelTimeConstant.Seconds()))
+	prev := float64(math.Float32frombits(r.level.Load()))
+	r.level.Store(math.Float32bits(float32(prev + alpha*(rms-prev))))
+}
+
+// buildDeviceConfig returns the malgo capture configuration for the given
+// stream and options. Unset period settings keep malgo's defaults.
+func buildDeviceConfig(sampleRate, channels uint32, opts RecorderOptions) (malgo.DeviceConfig, error) {
+	deviceCfg := malgo.DefaultDeviceConfig(malgo.Capture)
+	format, err := malgoFormat(opts.Format)
+	if err != nil {
+		return malgo.DeviceConfig{}, err
+	}
+	deviceCfg.Capture.Format = format
+	deviceCfg.Capture.Channels = channels
+	deviceCfg.SampleRate = sampleRate
+	if opts.PeriodSizeFrames > 0 {
+		deviceCfg.PeriodSizeInFrames = opts.PeriodSizeFrames
+	}
+	if opts.Periods > 0 {
+		deviceCfg.Periods = opts.Periods
+	}
+	return deviceCfg, nil
+}
+
+// malgoFormat maps a RecorderOptions.Format name to the malgo sample format.
+func malgoFormat(name string) (malgo.FormatType, error) {
+	switch name {
+	case "", "f32":
+		return malgo.FormatF32, nil
+	case "s16":
+		return malgo.FormatS16, nil
+	case "u8":
+		return malgo.FormatU8, nil
+	}
+	return malgo.FormatUnknown, fmt.Errorf("unsupported audio format %q (want f32, s16, or u8)", name)
+}
+
+// decodeSamples converts raw captured bytes in the named format to float32.
+func decodeSamples(data []byte, sampleCount uint32, format string) []float32 {
+	switch format {
+	case "s16":
+		return s16ToFloat32(data, sampleCount)
+	case "u8":
+		return u8ToFloat32(data, sampleCount)
+	}
+	return bytesToFloat32(data, sampleCount)
+}
+
+// s16ToFloat32 converts raw bytes (little-endian signed 16-bit) to float32
+// samples in [-1, 1).
+func s16ToFloat32(data []byte, sampleCount uint32) []float32 {
+	samples := make([]float32, 0, sampleCount)
+	for i := uint32(0); i < sampleCount; i++ {
+		offset := i * 2
+		if offset+2 > uint32(len(data)) {
+			break
+		}
+		v := int16(binary.LittleEndian.Uint16(data[offset : offset+2]))
+		samples = append(samples, float32(v)/32768)
+	}
+	return samples
+}
+
+// u8ToFloat32 converts raw bytes (unsigned 8-bit, 128 = silence) to float32
+// samples in [-1, 1).
+func u8ToFloat32(data []byte, sampleCount uint32) []float32 {
+	n := min(sampleCount, uint32(len(data)))
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = (float32(data[i]) - 128) / 128
+	}
+	return samples
 }
 
 // bytesToFloat32 converts raw bytes (little-endian float32) to a float32 slice.
@@ -1,11 +1,17 @@
 package audio
 
 import (
+	"encoding/binary"
+	"errors"
+	"math"
 	"testing"
+	"time"
+
+	"github.com/gen2brain/malgo"
 )
 
 func TestNewRecorderAndClose(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -24,7 +30,7 @@ func TestNewRecorderAndClose(t *testing.T) {
 }
 
 func TestRecorderNotRecordingByDefault(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -40,7 +46,7 @@ func TestRecorderNotRecordingByDefault(t *testing.T) {
 }
 
 func TestStopWithoutStart(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -57,7 +63,7 @@ func TestStopWithoutStart(t *testing.T) {
 }
 
 func TestSnapshotWithoutRecording(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -74,7 +80,7 @@ func TestSnapshotWithoutRecording(t *testing.T) {
 }
 
 func TestSnapshotReturnsCopy(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -111,7 +117,7 @@ func TestSnapshotReturnsCopy(t *testing.T) {
 }
 
 func TestSnapshotEmptyBuffer(t *testing.T) {
-	r, err := NewRecorder(16000, 1)
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
 	if err != nil {
 		t.Fatalf("NewRecorder() error = %v", err)
 	}
@@ -133,6 +139,282 @@ func TestSnapshotEmptyBuffer(t *testing.T) {
 	}
 }
 
+// float32Bytes encodes samples as little-endian float32, as malgo delivers them.
+func float32Bytes(samples ...float32) []byte {
+	data := make([]byte, 4*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(s))
+	}
+	return data
+}
+
+func TestPersistentRecorderToggle(t *testing.T) {
+	// A persistent recorder's device is opened by NewRecorder; construct one
+	// without a device so Start/Stop and onData can be driven directly.
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: true}
+
+	r.onData(nil, float32Bytes(9, 9), 2)
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(1, 2), 2)
+	r.onData(nil, float32Bytes(3), 1)
+	got := r.Stop()
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("first Stop() = %v, want [1 2 3] (data before Start discarded)", got)
+	}
+
+	r.onData(nil, float32Bytes(9), 1)
+	if err := r.Start(); err != nil {
+		t.Fatalf("second Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(4), 1)
+	got = r.Stop()
+	if len(got) != 1 || got[0] != 4 {
+		t.Errorf("second Stop() = %v, want [4] (buffer reset on Start)", got)
+	}
+}
+
+func TestRecorderMaxSamples(t *testing.T) {
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: true, maxSamples: 5}
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(1, 2, 3), 3)
+	r.onData(nil, float32Bytes(4, 5, 6), 3) // crosses the cap
+	for i := 0; i < 100; i++ {
+		r.onData(nil, float32Bytes(9, 9, 9, 9), 4)
+	}
+	if n := len(r.Snapshot()); n != 5 {
+		t.Errorf("buffer holds %d samples, want the cap of 5", n)
+	}
+	got := r.Stop()
+	if len(got) != 5 || got[0] != 1 || got[4] != 5 {
+		t.Errorf("Stop() = %v, want [1 2 3 4 5]", got)
+	}
+
+	// The cap applies per recording.
+	if err := r.Start(); err != nil {
+		t.Fatalf("second Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(7, 8), 2)
+	if got := r.Stop(); len(got) != 2 {
+		t.Errorf("second Stop() = %v, want [7 8]", got)
+	}
+}
+
+func TestRecorderLevel(t *testing.T) {
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: true}
+
+	// 100ms blocks, one levelTimeConstant each.
+	block := func(v float32) []byte {
+		samples := make([]float32, 1600)
+		for i := range samples {
+			samples[i] = v
+			if i%2 == 1 {
+				samples[i] = -v // RMS is still v
+			}
+		}
+		return float32Bytes(samples...)
+	}
+	near := func(got, want float32) bool { return math.Abs(float64(got-want)) < 1e-3 }
+
+	r.onData(nil, block(0.5), 1600)
+	if got := r.Level(); got != 0 {
+		t.Errorf("Level() before Start = %v, want 0", got)
+	}
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Rising: each block closes 1-1/e of the gap to 0.5.
+	want := float32(0)
+	for i := 0; i < 5; i++ {
+		r.onData(nil, block(0.5), 1600)
+		want += float32(1-math.Exp(-1)) * (0.5 - want)
+		if got := r.Level(); !near(got, want) {
+			t.Fatalf("Level() after %d loud blocks = %v, want %v", i+1, got, want)
+		}
+	}
+	if r.Level() < 0.49 {
+		t.Errorf("Level() = %v, want close to 0.5 after 500ms of steady input", r.Level())
+	}
+
+	// Decaying: silence shrinks the level by 1/e per block.
+	for i := 0; i < 3; i++ {
+		before := r.Level()
+		r.onData(nil, block(0), 1600)
+		if got, want := r.Level(), before*float32(math.Exp(-1)); !near(got, want) {
+			t.Fatalf("Level() after %d silent blocks = %v, want %v", i+1, got, want)
+		}
+	}
+
+	// A shorter callback moves the level proportionally less.
+	before := r.Level()
+	r.onData(nil, float32Bytes(make([]float32, 160)...), 160)
+	if got, want := r.Level(), before*float32(math.Exp(-0.1)); !near(got, want) {
+		t.Errorf("Level() after a 10ms silent block = %v, want %v", got, want)
+	}
+
+	r.Stop()
+	if got := r.Level(); got != 0 {
+		t.Errorf("Level() after Stop = %v, want 0", got)
+	}
+}
+
+func TestOnDataDiscardsWhenNotRecording(t *testing.T) {
+	r, err := NewRecorder(16000, 1, RecorderOptions{})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	r.onData(nil, float32Bytes(1, 2), 2)
+	r.mu.Lock()
+	n := len(r.buf)
+	r.mu.Unlock()
+	if n != 0 {
+		t.Errorf("buffer has %d samples after onData while not recording, want 0", n)
+	}
+}
+
+// fakeDevice is a captureDevice that records Uninit and, like malgo, calls
+// its stop callback when uninitialized.
+type fakeDevice struct {
+	onStop   func()
+	uninited bool
+}
+
+func (d *fakeDevice) Start() error { return nil }
+
+func (d *fakeDevice) Uninit() {
+	d.uninited = true
+	d.onStop()
+}
+
+// fakeOpener hands out fakeDevices, failing the first failures calls.
+type fakeOpener struct {
+	failures int
+	calls    int
+	resets   int
+	devices  []*fakeDevice
+}
+
+func (f *fakeOpener) open(onStop func()) (captureDevice, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, ErrNoInputDevice
+	}
+	d := &fakeDevice{onStop: onStop}
+	f.devices = append(f.devices, d)
+	return d, nil
+}
+
+func (f *fakeOpener) reset() error {
+	f.resets++
+	return nil
+}
+
+// newFakeRecorder returns a MicRecorder wired to f that records sleeps
+// instead of waiting.
+func newFakeRecorder(f *fakeOpener, persistent bool, sleeps *[]time.Duration) *MicRecorder {
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: persistent}
+	r.open = f.open
+	r.resetCtx = f.reset
+	r.sleep = func(d time.Duration) { *sleeps = append(*sleeps, d) }
+	return r
+}
+
+func TestStopDoesNotFlagDeviceLost(t *testing.T) {
+	f := &fakeOpener{}
+	var sleeps []time.Duration
+	r := newFakeRecorder(f, false, &sleeps)
+
+	for i := 0; i < 2; i++ {
+		if err := r.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		r.Stop()
+	}
+	if r.lost.Load() {
+		t.Error("device flagged lost after a normal Stop")
+	}
+	if f.resets != 0 {
+		t.Errorf("context reset %d times, want 0", f.resets)
+	}
+	if !f.devices[0].uninited || !f.devices[1].uninited {
+		t.Error("Stop() should uninit the device in non-persistent mode")
+	}
+}
+
+func TestStartReopensLostDevice(t *testing.T) {
+	for _, persistent := range []bool{false, true} {
+		f := &fakeOpener{}
+		var sleeps []time.Duration
+		r := newFakeRecorder(f, persistent, &sleeps)
+		if persistent {
+			d, _ := f.open(r.onStop)
+			r.device = d
+		}
+
+		if err := r.Start(); err != nil {
+			t.Fatalf("persistent=%v: Start() error = %v", persistent, err)
+		}
+		// Device unplugged mid-recording.
+		r.onStop()
+		r.Stop()
+
+		f.failures = f.calls + 2 // next two opens fail
+		if err := r.Start(); err != nil {
+			t.Fatalf("persistent=%v: Start() after device loss error = %v", persistent, err)
+		}
+		if r.lost.Load() {
+			t.Errorf("persistent=%v: lost flag still set after reopen", persistent)
+		}
+		if f.resets != 3 {
+			t.Errorf("persistent=%v: context reset %d times, want 3", persistent, f.resets)
+		}
+		want := []time.Duration{reopenBackoff, 2 * reopenBackoff}
+		if len(sleeps) != len(want) || sleeps[0] != want[0] || sleeps[1] != want[1] {
+			t.Errorf("persistent=%v: backoff = %v, want %v", persistent, sleeps, want)
+		}
+		if !f.devices[0].uninited {
+			t.Errorf("persistent=%v: lost device was not released", persistent)
+		}
+
+		r.onData(nil, float32Bytes(1, 2), 2)
+		if got := r.Stop(); len(got) != 2 {
+			t.Errorf("persistent=%v: Stop() after reopen = %v, want 2 samples", persistent, got)
+		}
+	}
+}
+
+func TestStartDeviceNeverReturns(t *testing.T) {
+	f := &fakeOpener{failures: 100}
+	var sleeps []time.Duration
+	r := newFakeRecorder(f, false, &sleeps)
+	r.lost.Store(true)
+
+	err := r.Start()
+	if !errors.Is(err, ErrDeviceLost) {
+		t.Fatalf("Start() error = %v, want ErrDeviceLost", err)
+	}
+	if !errors.Is(err, ErrNoInputDevice) {
+		t.Errorf("Start() error = %v, want it to wrap the last open error", err)
+	}
+	if f.calls != reopenAttempts {
+		t.Errorf("open called %d times, want %d", f.calls, reopenAttempts)
+	}
+	if r.IsRecording() {
+		t.Error("IsRecording() should be false after a failed Start")
+	}
+	if !r.lost.Load() {
+		t.Error("lost flag should stay set so the next Start retries")
+	}
+}
+
 func TestBytesToFloat32(t *testing.T) {
 	// Test with known float32 value: 1.0 = 0x3F800000
 	data := []byte{0x00, 0x00, 0x80, 0x3F} // 1.0 in little-endian float32
@@ -146,6 +428,93 @@ func TestBytesToFloat32(t *testing.T) {
 	}
 }
 
+func TestS16ToFloat32(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want float32
+	}{
+		{name: "zero", data: []byte{0x00, 0x00}, want: 0},
+		{name: "max", data: []byte{0xFF, 0x7F}, want: 32767.0 / 32768},
+		{name: "min", data: []byte{0x00, 0x80}, want: -1.0},
+		{name: "half", data: []byte{0x00, 0x40}, want: 0.5},
+		{name: "minus_one", data: []byte{0xFF, 0xFF}, want: -1.0 / 32768},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := s16ToFloat32(tt.data, 1)
+			if len(samples) != 1 {
+				t.Fatalf("s16ToFloat32() returned %d samples, want 1", len(samples))
+			}
+			if samples[0] != tt.want {
+				t.Errorf("s16ToFloat32() = %v, want %v", samples[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestS16ToFloat32Truncated(t *testing.T) {
+	// Three bytes hold one full sample; the trailing byte is ignored.
+	samples := s16ToFloat32([]byte{0x00, 0x40, 0xFF}, 2)
+	if len(samples) != 1 {
+		t.Fatalf("s16ToFloat32() returned %d samples, want 1", len(samples))
+	}
+}
+
+func TestU8ToFloat32(t *testing.T) {
+	samples := u8ToFloat32([]byte{128, 255, 0, 192}, 4)
+	want := []float32{0, 127.0 / 128, -1.0, 0.5}
+	if len(samples) != len(want) {
+		t.Fatalf("u8ToFloat32() returned %d samples, want %d", len(samples), len(want))
+	}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("samples[%d] = %v, want %v", i, samples[i], want[i])
+		}
+	}
+}
+
+func TestMicRecorderS16Format(t *testing.T) {
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: true, format: "s16"}
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	r.onData(nil, []byte{0x00, 0x40, 0x00, 0xC0}, 2)
+	got := r.Stop()
+	if len(got) != 2 || got[0] != 0.5 || got[1] != -0.5 {
+		t.Errorf("Stop() = %v, want [0.5 -0.5]", got)
+	}
+}
+
+func TestMicRecorderDownmixesStereo(t *testing.T) {
+	r := &MicRecorder{sampleRate: 16000, channels: 2, persistent: true}
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(1, 0, 0.5, -0.5, -1, -0.5), 3)
+	got := r.Stop()
+	want := []float32{0.5, 0, -0.75}
+	if len(got) != len(want) {
+		t.Fatalf("Stop() returned %d samples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sample %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMalgoFormat(t *testing.T) {
+	for _, name := range []string{"", "f32", "s16", "u8"} {
+		if _, err := malgoFormat(name); err != nil {
+			t.Errorf("malgoFormat(%q) error = %v", name, err)
+		}
+	}
+	if _, err := malgoFormat("s24"); err == nil {
+		t.Error("malgoFormat(\"s24\") should fail")
+	}
+}
+
 func TestBytesToFloat32Multiple(t *testing.T) {
 	// Two samples: 0.0 and -1.0
 	// 0.0 = 0x00000000, -1.0 = 0xBF800000
@@ -165,3 +534,75 @@ func TestBytesToFloat32Multiple(t *testing.T) {
 		t.Errorf("samples[1] = %f, want -1.0", samples[1])
 	}
 }
+
+func TestRecorderSilenceAutoStop(t *testing.T) {
+	r := &MicRecorder{sampleRate: 16000, channels: 1, persistent: true,
+		silence: silenceTimer{limit: 4}, silenceCh: make(chan struct{}, 1)}
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	r.onData(nil, float32Bytes(0.5, -0.5), 2)
+	r.onData(nil, float32Bytes(0, 0), 2)
+	select {
+	case <-r.SilenceStop():
+		t.Fatal("signalled after 2 silent samples, limit is 4")
+	default:
+	}
+	r.onData(nil, float32Bytes(0, 0), 2)
+	select {
+	case <-r.SilenceStop():
+	default:
+		t.Fatal("no signal after 4 silent samples")
+	}
+	if !r.IsRecording() {
+		t.Error("silence stopped the recording itself; the caller should")
+	}
+	r.onData(nil, float32Bytes(0, 0, 0, 0), 4)
+	r.Stop()
+
+	// A leftover signal doesn't carry into the next recording.
+	r.silenceCh <- struct{}{}
+	if err := r.Start(); err != nil {
+		t.Fatalf("second Start() error = %v", err)
+	}
+	select {
+	case <-r.SilenceStop():
+		t.Error("stale silence signal survived Start")
+	default:
+	}
+	r.Stop()
+}
+
+func TestBuildDeviceConfig(t *testing.T) {
+	defaults := malgo.DefaultDeviceConfig(malgo.Capture)
+
+	cfg, err := buildDeviceConfig(16000, 1, RecorderOptions{})
+	if err != nil {
+		t.Fatalf("buildDeviceConfig() error = %v", err)
+	}
+	if cfg.SampleRate != 16000 || cfg.Capture.Channels != 1 || cfg.Capture.Format != malgo.FormatF32 {
+		t.Errorf("stream = %d Hz, %d ch, format %v; want 16000 Hz, 1 ch, f32",
+			cfg.SampleRate, cfg.Capture.Channels, cfg.Capture.Format)
+	}
+	if cfg.PeriodSizeInFrames != defaults.PeriodSizeInFrames || cfg.Periods != defaults.Periods {
+		t.Errorf("unset periods = %d x %d frames, want malgo defaults %d x %d",
+			cfg.Periods, cfg.PeriodSizeInFrames, defaults.Periods, defaults.PeriodSizeInFrames)
+	}
+
+	cfg, err = buildDeviceConfig(48000, 2, RecorderOptions{Format: "s16", PeriodSizeFrames: 256, Periods: 4})
+	if err != nil {
+		t.Fatalf("buildDeviceConfig() error = %v", err)
+	}
+	if cfg.PeriodSizeInFrames != 256 || cfg.Periods != 4 {
+		t.Errorf("periods = %d x %d frames, want 4 x 256", cfg.Periods, cfg.PeriodSizeInFrames)
+	}
+	if cfg.Capture.Format != malgo.FormatS16 || cfg.Capture.Channels != 2 || cfg.SampleRate != 48000 {
+		t.Errorf("stream = %d Hz, %d ch, format %v; want 48000 Hz, 2 ch, s16",
+			cfg.SampleRate, cfg.Capture.Channels, cfg.Capture.Format)
+	}
+
+	if _, err := buildDeviceConfig(16000, 1, RecorderOptions{Format: "f64"}); err == nil {
+		t.Error("buildDeviceConfig() with unknown format should fail")
+	}
+}
@@ -0,0 +1,26 @@
+package audio
+
+// Resample converts mono samples from one sample rate to another by linear
+// interpolation. The result has len(samples)*to/from samples, rounded.
+// There is no anti-aliasing filter, which is fine for speech going to 16kHz
+// but not for general audio. Equal rates return samples unchanged.
+func Resample(samples []float32, from, to uint32) []float32 {
+	if from == to || from == 0 || to == 0 || len(samples) == 0 {
+		return samples
+	}
+	n := int((uint64(len(samples))*uint64(to) + uint64(from)/2) / uint64(from))
+	out := make([]float32, n)
+	step := float64(from) / float64(to)
+	last := len(samples) - 1
+	for i := range out {
+		pos := float64(i) * step
+		j := int(pos)
+		if j >= last {
+			out[i] = samples[last]
+			continue
+		}
+		frac := float32(pos - float64(j))
+		out[i] = samples[j] + (samples[j+1]-samples[j])*frac
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		from, to uint32
+		wantLen  int
+	}{
+		{"same_rate", 100, 16000, 16000, 100},
+		{"down_44k1", 44100, 44100, 16000, 16000},
+		{"down_48k", 4800, 48000, 16000, 1600},
+		{"up_8k", 800, 8000, 16000, 1600},
+		{"empty", 0, 48000, 16000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(Resample(make([]float32, tt.n), tt.from, tt.to)); got != tt.wantLen {
+				t.Errorf("len = %d, want %d", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestResampleInterpolates(t *testing.T) {
+	// A ramp stays a ramp: doubling the rate inserts midpoints.
+	got := Resample([]float32{0, 1, 2, 3}, 8000, 16000)
+	want := []float32{0, 0.5, 1, 1.5, 2, 2.5, 3, 3}
+	if len(got) != len(want) {
+		t.Fatalf("Resample() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if math.Abs(float64(got[i]-want[i])) > 1e-6 {
+			t.Errorf("Resample()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package audio
+
+import "math"
+
+// SilenceNotifier is implemented by recorders that can end a recording on
+// their own after a stretch of silence. The main loop treats a value on
+// SilenceStop as if the hotkey had stopped the recording.
+type SilenceNotifier interface {
+	SilenceStop() <-chan struct{}
+}
+
+// silenceTimer counts how long the input has stayed below silencePeak.
+// Not safe for concurrent use.
+type silenceTimer struct {
+	limit int  // samples of continuous silence that trigger a stop; 0 = off
+	quiet int  // samples of silence seen since the last sound
+	heard bool // sound has been heard since reset; the timer is armed
+}
+
+// feed adds one callback's mono samples and reports whether the silent run
+// has just reached the limit. Silence before the first sound doesn't count,
+// so a recording isn't ended before the user starts speaking. It fires once
+// per run: sound must be heard again before it can fire a second time.
+func (s *silenceTimer) feed(samples []float32) bool {
+	if s.limit <= 0 || len(samples) == 0 {
+		return false
+	}
+	if rms(samples) >= silencePeak {
+		s.heard = true
+		s.quiet = 0
+		return false
+	}
+	if !s.heard {
+		return false
+	}
+	before := s.quiet
+	s.quiet += len(samples)
+	return before < s.limit && s.quiet >= s.limit
+}
+
+// reset disarms the timer until sound is heard, e.g. at the start of a
+// recording.
+func (s *silenceTimer) reset() {
+	s.quiet = 0
+	s.heard = false
+}
+
+// rms returns the root mean square of samples.
+func rms(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
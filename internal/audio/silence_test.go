@@ -0,0 +1,87 @@
+package audio
+
+import "testing"
+
+// frame returns n samples of a square wave with the given amplitude.
+func frame(n int, amp float32) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		if i%2 == 0 {
+			out[i] = amp
+		} else {
+			out[i] = -amp
+		}
+	}
+	return out
+}
+
+func TestSilenceTimer(t *testing.T) {
+	s := silenceTimer{limit: 1600} // 100ms at 16kHz
+	quiet, loud := frame(160, 0.001), frame(160, 0.2)
+
+	// Speech, then 90ms of silence: not yet.
+	for range 3 {
+		if s.feed(loud) {
+			t.Fatal("feed(loud) triggered")
+		}
+	}
+	for i := range 9 {
+		if s.feed(quiet) {
+			t.Fatalf("triggered after %d quiet frames, want 10", i+1)
+		}
+	}
+	// Sound resets the run.
+	s.feed(loud)
+	fired := 0
+	for range 30 {
+		if s.feed(quiet) {
+			fired++
+		}
+	}
+	if fired != 1 {
+		t.Errorf("30 quiet frames fired %d times, want once", fired)
+	}
+
+	// After more sound it can fire again.
+	s.feed(loud)
+	for i := range 10 {
+		if s.feed(quiet) != (i == 9) {
+			t.Fatalf("quiet frame %d: fired = %v, want %v", i+1, !(i == 9), i == 9)
+		}
+	}
+}
+
+func TestSilenceTimerDisabled(t *testing.T) {
+	var s silenceTimer
+	for range 1000 {
+		if s.feed(frame(160, 0)) {
+			t.Fatal("disabled timer fired")
+		}
+	}
+}
+
+func TestSilenceTimerArmsOnSound(t *testing.T) {
+	s := silenceTimer{limit: 1600}
+	quiet, loud := frame(160, 0.001), frame(160, 0.2)
+
+	// Silence before any speech never fires.
+	for range 100 {
+		if s.feed(quiet) {
+			t.Fatal("fired before any sound was heard")
+		}
+	}
+	s.feed(loud)
+	for i := range 10 {
+		if s.feed(quiet) != (i == 9) {
+			t.Fatalf("quiet frame %d after speech: wrong fire state", i+1)
+		}
+	}
+
+	// reset disarms it again.
+	s.reset()
+	for range 100 {
+		if s.feed(quiet) {
+			t.Fatal("fired after reset before any sound was heard")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// Region is a span of detected speech, as sample indices [Start, End).
+type Region struct {
+	Start int
+	End   int
+}
+
+// VADOptions tunes DetectSpeechRegions. Zero fields take the defaults from
+// DefaultVADOptions.
+type VADOptions struct {
+	Frame          time.Duration // analysis frame length
+	Smoothing      time.Duration // time constant of the energy moving average
+	OpenThreshold  float32       // smoothed RMS at which a speech region opens
+	CloseThreshold float32       // smoothed RMS below which an open region starts closing
+	MinSilence     time.Duration // energy must stay below CloseThreshold this long to close a region
+	Hangover       time.Duration // audio kept around each region so soft onsets and trailing consonants survive
+}
+
+// DefaultVADOptions returns thresholds that suit a close-talking microphone
+// at normal dictation levels. The close threshold matches the level
+// Normalize treats as silence (about -40 dBFS); the open threshold sits
+// 6 dB above it so background noise near the floor can't toggle a region.
+func DefaultVADOptions() VADOptions {
+	return VADOptions{
+		Frame:          10 * time.Millisecond,
+		Smoothing:      50 * time.Millisecond,
+		OpenThreshold:  2 * silencePeak,
+		CloseThreshold: silencePeak,
+		MinSilence:     400 * time.Millisecond,
+		Hangover:       150 * time.Millisecond,
+	}
+}
+
+// withDefaults fills zero fields from DefaultVADOptions.
+func (o VADOptions) withDefaults() VADOptions {
+	d := DefaultVADOptions()
+	if o.Frame <= 0 {
+		o.Frame = d.Frame
+	}
+	if o.Smoothing <= 0 {
+		o.Smoothing = d.Smoothing
+	}
+	if o.OpenThreshold <= 0 {
+		o.OpenThreshold = d.OpenThreshold
+	}
+	if o.CloseThreshold <= 0 {
+		o.CloseThreshold = d.CloseThreshold
+	}
+	if o.MinSilence <= 0 {
+		o.MinSilence = d.MinSilence
+	}
+	if o.Hangover <= 0 {
+		o.Hangover = d.Hangover
+	}
+	return o
+}
+
+// DetectSpeechRegions finds the spans of samples that contain speech.
+//
+// Frame energy (RMS) is smoothed with an exponential moving average, and a
+// region opens when it reaches OpenThreshold. Once open, a region only
+// closes after the smoothed energy has stayed below the lower
+// CloseThreshold for MinSilence, so natural pauses between words don't split
+// an utterance. Region edges come from the raw frame energy rather than the
+// smoothed value, so the moving average's lag doesn't shift them. Each region
+// is then widened by Hangover on both sides and overlapping regions are
+// merged.
+func DetectSpeechRegions(samples []float32, sampleRate uint32, opts VADOptions) []Region {
+	if len(samples) == 0 || sampleRate == 0 {
+		return nil
+	}
+	opts = opts.withDefaults()
+
+	toSamples := func(d time.Duration) int {
+		return int(d.Seconds() * float64(sampleRate))
+	}
+	frameLen := max(toSamples(opts.Frame), 1)
+	minSilence := toSamples(opts.MinSilence)
+	hangover := toSamples(opts.Hangover)
+	alpha := 1 - math.Exp(-opts.Frame.Seconds()/opts.Smoothing.Seconds())
+
+	var regions []Region
+	var smoothed float64
+	open := false
+	start := 0
+	runStart := 0      // first sample of the current run of frames above CloseThreshold
+	lastLoud := 0      // end of the most recent frame above CloseThreshold
+	silenceStart := -1 // first sample of the current smoothed-quiet run, or -1
+
+	for pos := 0; pos < len(samples); pos += frameLen {
+		end := min(pos+frameLen, len(samples))
+		var sum float64
+		for _, s := range samples[pos:end] {
+			sum += float64(s) * float64(s)
+		}
+		rms := math.Sqrt(sum / float64(end-pos))
+		smoothed += alpha * (rms - smoothed)
+
+		if rms >= float64(opts.CloseThreshold) {
+			if lastLoud < pos {
+				runStart = pos
+			}
+			lastLoud = end
+		}
+
+		if !open {
+			if smoothed >= float64(opts.OpenThreshold) {
+				open = true
+				start = runStart
+				silenceStart = -1
+			}
+			continue
+		}
+
+		if smoothed >= float64(opts.CloseThreshold) {
+			silenceStart = -1
+			continue
+		}
+		if silenceStart < 0 {
+			silenceStart = pos
+		}
+		if end-silenceStart >= minSilence {
+			regions = append(regions, Region{Start: start, End: lastLoud})
+			open = false
+		}
+	}
+	if open {
+		regions = append(regions, Region{Start: start, End: lastLoud})
+	}
+
+	// Widen by the hangover and merge regions that now touch.
+	merged := regions[:0]
+	for _, r := range regions {
+		r.Start = max(r.Start-hangover, 0)
+		r.End = min(r.End+hangover, len(samples))
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = r.End
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// TrimSilence returns samples from the start of the first speech region to
+// the end of the last, dropping leading and trailing silence. Pauses between
+// regions are kept. If no speech is detected the samples are returned
+// unchanged, so a threshold set too high never discards a quiet recording.
+func TrimSilence(samples []float32, sampleRate uint32, opts VADOptions) []float32 {
+	regions := DetectSpeechRegions(samples, sampleRate, opts)
+	if len(regions) == 0 {
+		return samples
+	}
+	return samples[regions[0].Start:regions[len(regions)-1].End]
+}
@@ -0,0 +1,102 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// vadSignal builds a 16 kHz test signal from alternating spans: a positive
+// duration is a 220 Hz tone at amp, a negative one is silence.
+func vadSignal(amp float32, spans ...time.Duration) []float32 {
+	const rate = 16000
+	var out []float32
+	for _, d := range spans {
+		n := int(math.Abs(d.Seconds()) * rate)
+		for i := 0; i < n; i++ {
+			if d > 0 {
+				out = append(out, amp*float32(math.Sin(2*math.Pi*220*float64(i)/rate)))
+			} else {
+				out = append(out, 0)
+			}
+		}
+	}
+	return out
+}
+
+func TestDetectSpeechRegionsBridgesPause(t *testing.T) {
+	ms := time.Millisecond
+	// 300ms silence, 500ms speech, 200ms pause, 500ms speech, 300ms silence.
+	samples := vadSignal(0.3, -300*ms, 500*ms, -200*ms, 500*ms, -300*ms)
+
+	regions := DetectSpeechRegions(samples, 16000, VADOptions{})
+	if len(regions) != 1 {
+		t.Fatalf("regions = %v, want one region spanning the pause", regions)
+	}
+
+	// Speech runs from 0.3s to 1.5s; allow for hangover and smoothing lag.
+	r := regions[0]
+	if r.Start < int(0.1*16000) || r.Start > int(0.3*16000) {
+		t.Errorf("Start = %d, want between 1600 and 4800", r.Start)
+	}
+	if r.End < int(1.5*16000) || r.End > int(1.7*16000) {
+		t.Errorf("End = %d, want between 24000 and 27200", r.End)
+	}
+}
+
+func TestDetectSpeechRegionsSplitsLongPause(t *testing.T) {
+	ms := time.Millisecond
+	samples := vadSignal(0.3, -300*ms, 500*ms, -1000*ms, 500*ms, -300*ms)
+
+	regions := DetectSpeechRegions(samples, 16000, VADOptions{})
+	if len(regions) != 2 {
+		t.Fatalf("regions = %v, want two regions", regions)
+	}
+	if regions[0].End >= regions[1].Start {
+		t.Errorf("regions overlap: %v", regions)
+	}
+}
+
+func TestDetectSpeechRegionsHysteresis(t *testing.T) {
+	ms := time.Millisecond
+	// A level between the close and open thresholds (RMS ~0.014) neither
+	// opens a region on its own nor closes one already open.
+	quiet := vadSignal(0.02, 1000*ms)
+	if regions := DetectSpeechRegions(quiet, 16000, VADOptions{}); len(regions) != 0 {
+		t.Errorf("quiet-only regions = %v, want none", regions)
+	}
+
+	samples := append(vadSignal(0.3, 300*ms), quiet...)
+	regions := DetectSpeechRegions(samples, 16000, VADOptions{})
+	if len(regions) != 1 || regions[0].End != len(samples) {
+		t.Errorf("regions = %v, want one region to the end (%d)", regions, len(samples))
+	}
+}
+
+func TestDetectSpeechRegionsSilence(t *testing.T) {
+	if regions := DetectSpeechRegions(make([]float32, 16000), 16000, VADOptions{}); len(regions) != 0 {
+		t.Errorf("regions = %v, want none for silence", regions)
+	}
+	if regions := DetectSpeechRegions(nil, 16000, VADOptions{}); regions != nil {
+		t.Errorf("regions = %v, want nil for empty input", regions)
+	}
+}
+
+func TestTrimSilence(t *testing.T) {
+	ms := time.Millisecond
+	samples := vadSignal(0.3, -500*ms, 500*ms, -200*ms, 500*ms, -500*ms)
+
+	got := TrimSilence(samples, 16000, VADOptions{})
+	if len(got) >= len(samples) {
+		t.Fatalf("len = %d, want fewer than %d samples", len(got), len(samples))
+	}
+	// Both bursts and the pause between them (1.2s) must survive.
+	if len(got) < int(1.2*16000) {
+		t.Errorf("len = %d, want at least %d samples", len(got), int(1.2*16000))
+	}
+
+	silence := make([]float32, 8000)
+	if got := TrimSilence(silence, 16000, VADOptions{}); len(got) != len(silence) {
+		t.Errorf("silence len = %d, want unchanged %d", len(got), len(silence))
+	}
+}
@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-audio/wav"
+)
+
+// LoadWAV decodes a PCM WAV file into mono float32 samples normalized to
+// [-1.0, 1.0] at sampleRate. Multi-channel audio is downmixed and other
+// sample rates are resampled.
+func LoadWAV(path string, sampleRate uint32) ([]float32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening WAV file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	samples, err := DecodeWAV(f, sampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return samples, nil
+}
+
+// DecodeWAV is LoadWAV for WAV data already in memory or open, such as an
+// embedded sample.
+func DecodeWAV(r io.ReadSeeker, sampleRate uint32) ([]float32, error) {
+	dec := wav.NewDecoder(r)
+	if !dec.IsValidFile() {
+		return nil, errors.New("not a valid WAV file")
+	}
+
+	buf, err := dec.FullPCMBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("decoding WAV file: %w", err)
+	}
+	if buf.Format.NumChannels < 1 || buf.Format.SampleRate <= 0 {
+		return nil, fmt.Errorf("WAV file has %d channels at %dHz", buf.Format.NumChannels, buf.Format.SampleRate)
+	}
+
+	if buf.SourceBitDepth < 8 || buf.SourceBitDepth > 32 {
+		return nil, fmt.Errorf("unsupported WAV bit depth %d", buf.SourceBitDepth)
+	}
+
+	scale := float32(int64(1) << (buf.SourceBitDepth - 1))
+	// 8-bit WAV samples are unsigned, centered on 128; wider ones are signed.
+	offset := 0
+	if buf.SourceBitDepth == 8 {
+		offset = 128
+	}
+	samples := make([]float32, len(buf.Data))
+	for i, s := range buf.Data {
+		samples[i] = float32(s-offset) / scale
+	}
+	samples = DownmixToMono(samples, uint32(buf.Format.NumChannels))
+	return Resample(samples, uint32(buf.Format.SampleRate), sampleRate), nil
+}
@@ -0,0 +1,127 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// writeTestWAV writes 16-bit PCM samples to a WAV file in a temp dir.
+func writeTestWAV(t *testing.T, sampleRate, channels int, data []int) string {
+	t.Helper()
+	return writeTestWAVDepth(t, sampleRate, channels, 16, data)
+}
+
+// writeTestWAVDepth writes PCM samples of the given bit depth to a WAV file
+// in a temp dir. 8-bit samples are unsigned, as the format stores them.
+func writeTestWAVDepth(t *testing.T, sampleRate, channels, bitDepth int, data []int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc := wav.NewEncoder(f, sampleRate, bitDepth, channels, 1)
+	buf := &goaudio.IntBuffer{
+		Format:         &goaudio.Format{NumChannels: channels, SampleRate: sampleRate},
+		Data:           data,
+		SourceBitDepth: bitDepth,
+	}
+	if err := enc.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadWAV(t *testing.T) {
+	path := writeTestWAV(t, 16000, 1, []int{0, 16384, -32768, 32767})
+
+	samples, err := LoadWAV(path, 16000)
+	if err != nil {
+		t.Fatalf("LoadWAV() error = %v", err)
+	}
+
+	want := []float32{0, 0.5, -1.0, 32767.0 / 32768.0}
+	if len(samples) != len(want) {
+		t.Fatalf("len(samples) = %d, want %d", len(samples), len(want))
+	}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("samples[%d] = %v, want %v", i, samples[i], want[i])
+		}
+	}
+}
+
+func TestLoadWAV8Bit(t *testing.T) {
+	// 8-bit PCM is unsigned: 128 is silence, 0 and 255 the extremes.
+	path := writeTestWAVDepth(t, 16000, 1, 8, []int{128, 192, 0, 255})
+
+	samples, err := LoadWAV(path, 16000)
+	if err != nil {
+		t.Fatalf("LoadWAV() error = %v", err)
+	}
+
+	want := []float32{0, 0.5, -1.0, 127.0 / 128.0}
+	if len(samples) != len(want) {
+		t.Fatalf("len(samples) = %d, want %d", len(samples), len(want))
+	}
+	for i := range want {
+		if samples[i] != want[i] {
+			t.Errorf("samples[%d] = %v, want %v", i, samples[i], want[i])
+		}
+	}
+}
+
+func TestLoadWAVConvertsToMono16k(t *testing.T) {
+	tests := []struct {
+		name       string
+		sampleRate int
+		channels   int
+		seconds    float64
+	}{
+		{"stereo_16k", 16000, 2, 0.5},
+		{"mono_44k1", 44100, 1, 1},
+		{"stereo_48k", 48000, 2, 0.25},
+		{"mono_8k", 8000, 1, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Left channel at half scale, right silent: the mono mix is a quarter.
+			frames := int(tt.seconds * float64(tt.sampleRate))
+			data := make([]int, frames*tt.channels)
+			for i := 0; i < len(data); i += tt.channels {
+				data[i] = 16384
+			}
+			path := writeTestWAV(t, tt.sampleRate, tt.channels, data)
+
+			samples, err := LoadWAV(path, 16000)
+			if err != nil {
+				t.Fatalf("LoadWAV() error = %v", err)
+			}
+			if want := int(tt.seconds * 16000); len(samples) != want {
+				t.Errorf("len(samples) = %d, want %d (%.2fs at 16kHz)", len(samples), want, tt.seconds)
+			}
+			want := float32(0.5) / float32(tt.channels)
+			for i, s := range samples {
+				if s != want {
+					t.Fatalf("samples[%d] = %v, want %v", i, s, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadWAVMissingFile(t *testing.T) {
+	if _, err := LoadWAV("/nonexistent/audio.wav", 16000); err == nil {
+		t.Error("LoadWAV() with missing file should return error")
+	}
+}
@@ -0,0 +1,88 @@
+package ble
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/chaz8081/gostt-writer/internal/ble/protocol"
+)
+
+// subscribeAcks subscribes to the response characteristic of conn so sends
+// can wait for the device to acknowledge each packet. If the characteristic
+// is missing or can't be subscribed, sends fall back to fire-and-forget for
+// this connection. The caller must hold c.mu.
+func (c *Client) subscribeAcks(conn Connection) {
+	c.acksEnabled = false
+	if c.opts.AckTimeout <= 0 {
+		return
+	}
+	char, err := conn.DiscoverCharacteristic(ServiceUUID, ResponseCharUUID)
+	if err == nil {
+		err = char.Subscribe(c.handleResponse)
+	}
+	if err != nil {
+		slog.Warn("[BLE] delivery acks unavailable, sending without waiting", "error", err)
+		return
+	}
+	c.subs = append(c.subs, char)
+	c.acksEnabled = true
+}
+
+// handleResponse wakes the Send waiting for the packet a response acks.
+// Other responses, and acks nobody is waiting for, are ignored.
+func (c *Client) handleResponse(data []byte) {
+	resp, err := protocol.UnmarshalResponsePacket(data)
+	if err != nil {
+		slog.Debug("[BLE] ignoring malformed response", "error", err)
+		return
+	}
+	if resp.Type != protocol.ResponseTypeAck {
+		return
+	}
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+	if ch, ok := c.acks[resp.PacketNum]; ok {
+		close(ch)
+		delete(c.acks, resp.PacketNum)
+	}
+}
+
+// expectAck registers interest in the ack for pktNum, returning the channel
+// closed when it arrives, or nil if acks aren't in use on this connection.
+// Register before writing the packet so a fast ack isn't missed.
+func (c *Client) expectAck(pktNum uint32) chan struct{} {
+	c.mu.Lock()
+	enabled := c.acksEnabled
+	c.mu.Unlock()
+	if !enabled {
+		return nil
+	}
+	ch := make(chan struct{})
+	c.ackMu.Lock()
+	c.acks[pktNum] = ch
+	c.ackMu.Unlock()
+	return ch
+}
+
+// waitAck waits up to opts.AckTimeout for ch, returned by expectAck for
+// pktNum, to be closed. A nil ch returns immediately.
+func (c *Client) waitAck(ch chan struct{}, pktNum uint32) error {
+	if ch == nil {
+		return nil
+	}
+	select {
+	case <-ch:
+		return nil
+	case <-time.After(c.opts.AckTimeout):
+		c.cancelAck(pktNum)
+		return fmt.Errorf("ble: no ack for packet %d within %s", pktNum, c.opts.AckTimeout)
+	}
+}
+
+// cancelAck stops waiting for the ack for pktNum.
+func (c *Client) cancelAck(pktNum uint32) {
+	c.ackMu.Lock()
+	delete(c.acks, pktNum)
+	c.ackMu.Unlock()
+}
@@ -17,8 +17,13 @@ const (
 type Characteristic interface {
 	// Write sends data to the characteristic.
 	Write(data []byte) error
+	// Read returns the current value of the characteristic.
+	Read() ([]byte, error)
 	// Subscribe registers a callback for notifications on this characteristic.
 	Subscribe(callback func(data []byte)) error
+	// Unsubscribe stops notifications registered by Subscribe. It is safe
+	// to call when not subscribed.
+	Unsubscribe() error
 }
 
 // Device represents a discovered BLE peripheral.
@@ -36,15 +41,19 @@ type Connection interface {
 	Disconnect() error
 	// OnDisconnect registers a callback invoked when the connection drops.
 	OnDisconnect(callback func())
+	// RSSI returns the current signal strength of the connection in dBm,
+	// or ErrRSSIUnsupported if the platform can't read it.
+	RSSI() (int, error)
 }
 
 // Adapter abstracts the BLE hardware adapter for testing.
 type Adapter interface {
 	// Enable powers on the BLE adapter.
 	Enable() error
-	// Scan discovers BLE peripherals advertising the given service UUID.
-	// Returns discovered devices until ctx is cancelled or timeout.
-	Scan(ctx context.Context, serviceUUID string) ([]Device, error)
+	// Scan discovers BLE peripherals advertising the given service UUID,
+	// calling found for each advertisement until ctx is cancelled. A device
+	// may be reported more than once.
+	Scan(ctx context.Context, serviceUUID string, found func(Device)) error
 	// Connect establishes a connection to the device with the given MAC address.
 	Connect(ctx context.Context, mac string) (Connection, error)
 }
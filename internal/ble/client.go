@@ -2,8 +2,11 @@ package ble
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,6 +20,16 @@ type ClientOptions struct {
 	QueueSize       int           // max queued messages during disconnect
 	ReconnectMax    int           // max reconnect backoff in seconds (used by reconnection loop in Task 7)
 	InterChunkDelay time.Duration // delay between BLE write chunks (default 20ms)
+	VerifyMAC       string        // "", "warn", or "fail": check the device-reported MAC on connect
+	HardwareMAC     string        // MAC VerifyMAC expects the device to report ("" = the connect address)
+	RSSIInterval    time.Duration // how often to poll connection RSSI (0 = off)
+	RSSIWarn        int           // warn when RSSI falls below this many dBm (default DefaultRSSIWarn)
+	PacketNumPath   string        // file persisting the last packet number across restarts ("" = off)
+	FlushPolicy     string        // queued messages sent on reconnect: "all" (default), "latest", or "drop"
+	NonceMode       string        // AES-GCM nonce: "random" (default) or "counter" (derived from the packet number)
+	Jitter          bool          // randomize each reconnect delay within [delay/2, delay]
+	AckTimeout      time.Duration // wait this long for the device to ack each packet (0 = fire-and-forget)
+	ChunkMode       string        // how long messages are split: "word" (default, at spaces) or "fixed" (full packets)
 }
 
 // DefaultClientOptions returns sensible defaults.
@@ -37,10 +50,20 @@ type Client struct {
 	mu        sync.Mutex
 	conn      Connection
 	txChar    Characteristic
+	subs      []Characteristic // characteristics subscribed on conn
 	connected bool
 
+	sendMu sync.Mutex // held for all of a message's chunks so concurrent sends don't interleave
+
+	acksEnabled bool                     // conn's response characteristic is subscribed
+	ackMu       sync.Mutex               // guards acks
+	acks        map[uint32]chan struct{} // closed when the packet number is acked
+
 	packetNum    atomic.Uint32
+	pktMu        sync.Mutex  // serializes packet number saves
+	savedPktNum  uint32      // last packet number written to PacketNumPath
 	reconnecting atomic.Bool // guards against stacked reconnect goroutines
+	rssiWarnOnce sync.Once   // warns once that RSSIInterval has no effect
 
 	done  chan struct{} // closed by Close() to stop reconnectLoop
 	queue []string
@@ -62,18 +85,69 @@ func NewClient(adapter Adapter, deviceMAC string, key []byte, opts ClientOptions
 	if opts.InterChunkDelay <= 0 {
 		opts.InterChunkDelay = 20 * time.Millisecond
 	}
-	return &Client{
+	if opts.RSSIWarn == 0 {
+		opts.RSSIWarn = DefaultRSSIWarn
+	}
+	switch opts.VerifyMAC {
+	case "", "warn", "fail":
+	default:
+		return nil, fmt.Errorf("ble: VerifyMAC must be \"\", \"warn\", or \"fail\", got %q", opts.VerifyMAC)
+	}
+	switch opts.FlushPolicy {
+	case "", "all", "latest", "drop":
+	default:
+		return nil, fmt.Errorf("ble: FlushPolicy must be \"all\", \"latest\", or \"drop\", got %q", opts.FlushPolicy)
+	}
+	switch opts.NonceMode {
+	case "", "random":
+	case "counter":
+		if opts.PacketNumPath == "" {
+			// Packet numbers would restart at 1, repeating nonces under the same key.
+			return nil, errors.New("ble: NonceMode \"counter\" requires PacketNumPath")
+		}
+	default:
+		return nil, fmt.Errorf("ble: NonceMode must be \"random\" or \"counter\", got %q", opts.NonceMode)
+	}
+	switch opts.ChunkMode {
+	case "", "word", "fixed":
+	default:
+		return nil, fmt.Errorf("ble: ChunkMode must be \"word\" or \"fixed\", got %q", opts.ChunkMode)
+	}
+	c := &Client{
 		adapter:   adapter,
 		deviceMAC: deviceMAC,
 		key:       key,
 		done:      make(chan struct{}),
 		opts:      opts,
-	}, nil
+		acks:      make(map[uint32]chan struct{}),
+	}
+	if opts.PacketNumPath != "" {
+		// Continue the sequence from the last run so the firmware's replay
+		// protection doesn't reject numbers it has already seen.
+		n, err := loadPacketNum(opts.PacketNumPath)
+		if err != nil {
+			return nil, err
+		}
+		c.packetNum.Store(n)
+		c.savedPktNum = n
+	}
+	return c, nil
 }
 
 // Send encrypts and transmits text to the ESP32. If disconnected, the text
-// is queued for delivery on reconnect. Safe for concurrent use.
+// is queued for delivery on reconnect. With opts.AckTimeout set, Send
+// returns once the device has acked every packet, or an error if an ack
+// doesn't arrive in time. Safe for concurrent use.
 func (c *Client) Send(text string) error {
+	return c.SendWithProgress(text, nil)
+}
+
+// SendWithProgress is Send, calling onChunk after each chunk is written (and
+// acked, with opts.AckTimeout set) with the number of chunks sent so far and
+// the message's total. onChunk runs on the sending goroutine and should
+// return quickly. It is not called for text that is queued while
+// disconnected. A nil onChunk is allowed.
+func (c *Client) SendWithProgress(text string, onChunk func(sent, total int)) error {
 	if text == "" {
 		return nil
 	}
@@ -87,16 +161,28 @@ func (c *Client) Send(text string) error {
 	txChar := c.txChar
 	c.mu.Unlock()
 
-	return c.sendChunked(txChar, text)
+	return c.sendChunked(txChar, text, onChunk)
 }
 
-// sendChunked splits text into BLE-MTU-safe chunks, encrypts each, and writes.
-func (c *Client) sendChunked(txChar Characteristic, text string) error {
-	chunks := protocol.ChunkText(text, protocol.MaxPayloadBytes)
+// sendChunked splits text into BLE-MTU-safe chunks, encrypts each, and writes,
+// reporting progress to onChunk if it is non-nil. Concurrent calls are
+// serialized, so each message's chunks reach the device contiguously.
+func (c *Client) sendChunked(txChar Characteristic, text string, onChunk func(sent, total int)) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	chunk := protocol.ChunkText
+	if c.opts.ChunkMode == "fixed" {
+		chunk = protocol.ChunkTextFixed
+	}
+	chunks := chunk(text, protocol.MaxPayloadBytes)
 	for i, chunk := range chunks {
 		if err := c.sendOne(txChar, chunk); err != nil {
 			return err
 		}
+		if onChunk != nil {
+			onChunk(i+1, len(chunks))
+		}
 		// Small delay between chunks to avoid overwhelming the ESP32
 		if i < len(chunks)-1 {
 			time.Sleep(c.opts.InterChunkDelay)
@@ -111,20 +197,42 @@ func (c *Client) sendOne(txChar Characteristic, text string) error {
 	kbPacket := protocol.MarshalKeyboardPacket(text)
 	encData := protocol.MarshalEncryptedData(kbPacket)
 
+	pktNum, err := c.nextPacketNum()
+	if err != nil {
+		return err
+	}
+	if err := c.persistPacketNum(pktNum); err != nil {
+		if c.opts.NonceMode == "counter" {
+			// An unsaved number may be reused after a restart, and with it the nonce.
+			return err
+		}
+		slog.Warn("[BLE] could not persist packet number", "error", err)
+	}
+
 	// Encrypt
-	iv, ciphertext, tag, err := blecrypto.Encrypt(c.key, encData)
+	var iv, ciphertext, tag []byte
+	if c.opts.NonceMode == "counter" {
+		iv = blecrypto.CounterNonce(pktNum)
+		ciphertext, tag, err = blecrypto.EncryptWithNonce(c.key, encData, iv)
+	} else {
+		iv, ciphertext, tag, err = blecrypto.Encrypt(c.key, encData)
+	}
 	if err != nil {
 		return fmt.Errorf("ble: encrypt: %w", err)
 	}
 
 	// Build outer DataPacket
-	pktNum := c.packetNum.Add(1)
 	dataPacket, err := protocol.MarshalDataPacket(iv, tag, ciphertext, pktNum)
 	if err != nil {
 		return fmt.Errorf("ble: marshal data packet: %w", err)
 	}
 
-	return txChar.Write(dataPacket)
+	ack := c.expectAck(pktNum)
+	if err := txChar.Write(dataPacket); err != nil {
+		c.cancelAck(pktNum)
+		return err
+	}
+	return c.waitAck(ack, pktNum)
 }
 
 // enqueue adds text to the send queue (caller must hold mu).
@@ -156,21 +264,60 @@ func (c *Client) setConnected(conn Connection) error {
 	}
 	c.txChar = txChar
 	c.connected = true
+	c.subscribeAcks(conn)
 	return nil
 }
 
-// setDisconnected marks the client as disconnected.
+// Subscribe registers cb for notifications from the characteristic charUUID
+// on the current connection. Subscriptions end when the connection drops,
+// so a stale callback never fires for a later connection; callers that
+// need notifications after a reconnect must subscribe again.
+func (c *Client) Subscribe(charUUID string, cb func(data []byte)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.connected {
+		return fmt.Errorf("ble: subscribe %s: not connected", charUUID)
+	}
+	char, err := c.conn.DiscoverCharacteristic(ServiceUUID, charUUID)
+	if err != nil {
+		return fmt.Errorf("ble: discover %s: %w", charUUID, err)
+	}
+	if err := char.Subscribe(cb); err != nil {
+		return fmt.Errorf("ble: subscribe %s: %w", charUUID, err)
+	}
+	c.subs = append(c.subs, char)
+	return nil
+}
+
+// unsubscribeAll removes every subscription made with Subscribe. The
+// connection may already be gone, so failures are only logged. The caller
+// must hold c.mu.
+func (c *Client) unsubscribeAll() {
+	for _, char := range c.subs {
+		if err := char.Unsubscribe(); err != nil {
+			slog.Debug("[BLE] unsubscribe failed", "error", err)
+		}
+	}
+	c.subs = nil
+}
+
+// setDisconnected marks the client as disconnected and drops its
+// notification subscriptions.
 func (c *Client) setDisconnected() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.unsubscribeAll()
+	c.acksEnabled = false
 	c.connected = false
 	c.conn = nil
 	c.txChar = nil
 }
 
-// flushQueue sends all queued messages. Call after reconnection.
-// Messages that fail to send are logged and dropped — for a keyboard
-// input application, stale keystrokes are less useful than current ones.
+// flushQueue sends queued messages according to opts.FlushPolicy. Call after
+// reconnection. For a keyboard input application, stale keystrokes are less
+// useful than current ones: "latest" sends only the most recent message
+// (the user may have re-dictated while disconnected) and "drop" discards
+// them all. Messages that fail to send are logged and dropped.
 func (c *Client) flushQueue() {
 	c.mu.Lock()
 	if !c.connected || len(c.queue) == 0 {
@@ -183,15 +330,30 @@ func (c *Client) flushQueue() {
 	txChar := c.txChar
 	c.mu.Unlock()
 
+	switch c.opts.FlushPolicy {
+	case "latest":
+		if len(queued) > 1 {
+			slog.Info("[BLE] discarding older queued messages", "count", len(queued)-1)
+		}
+		queued = queued[len(queued)-1:]
+	case "drop":
+		slog.Info("[BLE] discarding queued messages", "count", len(queued))
+		return
+	}
+
 	for _, text := range queued {
-		if err := c.sendChunked(txChar, text); err != nil {
+		if err := c.sendChunked(txChar, text, nil); err != nil {
 			slog.Error("[BLE] failed to flush queued message", "error", err)
 		}
 	}
 }
 
 // Close gracefully disconnects the BLE client and stops any reconnect loop.
+// If connected, messages still queued (e.g. from a reconnect whose flush
+// hasn't run yet) are sent first, following the flush policy.
 func (c *Client) Close() error {
+	c.flushQueue()
+
 	// Signal reconnect loop to stop. safe to call multiple times since
 	// we use sync.Once semantics via select-default.
 	select {
@@ -208,6 +370,7 @@ func (c *Client) Close() error {
 		slog.Warn("[BLE] closing with unsent messages", "count", len(c.queue))
 	}
 
+	c.unsubscribeAll()
 	var disconnectErr error
 	if c.conn != nil {
 		disconnectErr = c.conn.Disconnect()
@@ -229,6 +392,14 @@ func backoffDelay(attempt int, maxSeconds int) time.Duration {
 	return delay
 }
 
+// jitterDelay returns a random duration in [d/2, d] ("equal jitter"), so
+// clients that lost their connections together don't all retry at once
+// while still backing off at least half as long as without jitter.
+func jitterDelay(d time.Duration) time.Duration {
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
 // registerDisconnectHandler sets up the auto-reconnect callback on a connection.
 func (c *Client) registerDisconnectHandler(conn Connection) {
 	conn.OnDisconnect(func() {
@@ -252,16 +423,86 @@ func (c *Client) Connect() error {
 		return fmt.Errorf("ble: connect to %s: %w", c.deviceMAC, err)
 	}
 
+	if err := c.verifyMAC(conn); err != nil {
+		_ = conn.Disconnect()
+		return err
+	}
+
 	if err := c.setConnected(conn); err != nil {
 		return fmt.Errorf("ble: set connected: %w", err)
 	}
 
 	c.registerDisconnectHandler(conn)
 
+	if c.opts.RSSIInterval > 0 && c.rssiSupported(conn) {
+		go c.monitorRSSI()
+	}
+
 	slog.Info("[BLE] connected", "mac", c.deviceMAC)
 	return nil
 }
 
+// verifyMAC reads the device's MAC characteristic and compares it with the
+// configured device address. With VerifyMAC "warn" a mismatch or read failure
+// is logged; with "fail" it is returned as an error. No-op when VerifyMAC is "".
+func (c *Client) verifyMAC(conn Connection) error {
+	if c.opts.VerifyMAC == "" {
+		return nil
+	}
+
+	mac, err := ReadMAC(conn)
+	if err != nil {
+		if c.opts.VerifyMAC == "fail" {
+			return fmt.Errorf("ble: verify MAC: %w", err)
+		}
+		slog.Warn("[BLE] could not read device MAC", "error", err)
+		return nil
+	}
+
+	// On macOS the connect address is a CoreBluetooth UUID, so the MAC
+	// recorded at pairing is what the device should report.
+	expected := c.opts.HardwareMAC
+	if expected == "" {
+		expected = c.deviceMAC
+	}
+	if strings.EqualFold(mac, expected) {
+		slog.Debug("[BLE] device MAC verified", "mac", mac)
+		return nil
+	}
+	if c.opts.VerifyMAC == "fail" {
+		return fmt.Errorf("ble: device MAC mismatch: expected %s, device reports %s", expected, mac)
+	}
+	slog.Warn("[BLE] device MAC mismatch", "expected", expected, "reported", mac)
+	return nil
+}
+
+// ReadMAC reads the MAC characteristic of a connected GOSTT-KBD device and
+// returns it as an uppercase colon-separated string (e.g. "AA:BB:CC:DD:EE:FF").
+func ReadMAC(conn Connection) (string, error) {
+	macChar, err := conn.DiscoverCharacteristic(ServiceUUID, MACCharUUID)
+	if err != nil {
+		return "", fmt.Errorf("ble: discover MAC characteristic: %w", err)
+	}
+	data, err := macChar.Read()
+	if err != nil {
+		return "", fmt.Errorf("ble: read MAC characteristic: %w", err)
+	}
+	return formatMAC(data)
+}
+
+// formatMAC converts a 6-byte address as sent by the ESP32 (NimBLE
+// little-endian byte order) to the conventional "AA:BB:CC:DD:EE:FF" form.
+func formatMAC(data []byte) (string, error) {
+	if len(data) != 6 {
+		return "", fmt.Errorf("ble: MAC must be 6 bytes, got %d", len(data))
+	}
+	parts := make([]string, 6)
+	for i, b := range data {
+		parts[5-i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
 // reconnectLoop attempts to reconnect with exponential backoff.
 func (c *Client) reconnectLoop() {
 	defer c.reconnecting.Store(false)
@@ -277,6 +518,9 @@ func (c *Client) reconnectLoop() {
 		// On the first attempt, try immediately; subsequent attempts use backoff.
 		if attempt > 0 {
 			delay := backoffDelay(attempt-1, c.opts.ReconnectMax)
+			if c.opts.Jitter {
+				delay = jitterDelay(delay)
+			}
 			slog.Info("[BLE] reconnect backoff", "attempt", attempt+1, "delay", delay)
 			select {
 			case <-c.done:
@@ -292,6 +536,12 @@ func (c *Client) reconnectLoop() {
 			continue
 		}
 
+		if err := c.verifyMAC(conn); err != nil {
+			_ = conn.Disconnect()
+			slog.Warn("[BLE] reconnect rejected", "error", err, "attempt", attempt+1)
+			continue
+		}
+
 		if err := c.setConnected(conn); err != nil {
 			slog.Warn("[BLE] reconnect set connected failed", "error", err, "attempt", attempt+1)
 			continue
@@ -1,9 +1,17 @@
 package ble
 
 import (
+	"bytes"
 	"encoding/binary"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	blecrypto "github.com/chaz8081/gostt-writer/internal/ble/crypto"
+	"github.com/chaz8081/gostt-writer/internal/ble/protocol"
 )
 
 func makeTestKey() []byte {
@@ -74,6 +82,92 @@ func TestClientSendChunksLongText(t *testing.T) {
 	}
 }
 
+func TestClientSendFixedChunks(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	key := makeTestKey()
+	opts := zeroDelayOpts()
+	opts.ChunkMode = "fixed"
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", key, opts)
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	longText := strings.Repeat("word ", 100) // 500 bytes
+	if err := client.Send(longText); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	writes := conn.txChar.writes
+	if len(writes) != 3 {
+		t.Fatalf("got %d writes, want 3", len(writes))
+	}
+	var got string
+	for i, w := range writes {
+		chunk := decryptChunk(t, key, w)
+		if i < len(writes)-1 && len(chunk) != protocol.MaxPayloadBytes {
+			t.Errorf("chunk[%d] len = %d, want a full %d-byte packet", i, len(chunk), protocol.MaxPayloadBytes)
+		}
+		got += chunk
+	}
+	if got != longText {
+		t.Errorf("reassembled text differs from sent text")
+	}
+}
+
+func TestClientSendWithProgress(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	longText := strings.Repeat("word ", 100)
+	wantTotal := len(protocol.ChunkText(longText, protocol.MaxPayloadBytes))
+	if wantTotal < 2 {
+		t.Fatalf("test text makes %d chunk(s), want several", wantTotal)
+	}
+
+	var sent []int
+	err := client.SendWithProgress(longText, func(n, total int) {
+		if total != wantTotal {
+			t.Errorf("progress total = %d, want %d", total, wantTotal)
+		}
+		if n != len(conn.txChar.writes) {
+			t.Errorf("progress reported %d sent after %d writes", n, len(conn.txChar.writes))
+		}
+		sent = append(sent, n)
+	})
+	if err != nil {
+		t.Fatalf("SendWithProgress() error = %v", err)
+	}
+	for i, n := range sent {
+		if n != i+1 {
+			t.Fatalf("progress counts = %v, want 1..%d", sent, wantTotal)
+		}
+	}
+	if len(sent) != wantTotal {
+		t.Errorf("progress called %d times, want %d", len(sent), wantTotal)
+	}
+}
+
+func TestClientSendWithProgressQueued(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+
+	called := false
+	if err := client.SendWithProgress("hello", func(int, int) { called = true }); err != nil {
+		t.Fatalf("SendWithProgress() error = %v", err)
+	}
+	if called {
+		t.Error("progress called for a message queued while disconnected")
+	}
+	if client.QueueLen() != 1 {
+		t.Errorf("QueueLen() = %d, want 1", client.QueueLen())
+	}
+}
+
 func TestClientSendEmptyString(t *testing.T) {
 	adapter := newMockAdapter(nil)
 	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
@@ -160,6 +254,181 @@ func extractPacketNum(t *testing.T, data []byte) uint32 {
 	return 0
 }
 
+// extractBytesField returns the length-delimited DataPacket field fieldNum
+// (1 = iv, 2 = tag, 3 = encrypted).
+func extractBytesField(t *testing.T, data []byte, fieldNum byte) []byte {
+	t.Helper()
+	pos := 0
+	for pos < len(data) {
+		tagByte := data[pos]
+		pos++
+		switch tagByte & 0x07 {
+		case 0:
+			_, n := binary.Uvarint(data[pos:])
+			if n <= 0 {
+				t.Fatalf("failed to read varint at offset %d", pos)
+			}
+			pos += n
+		case 2:
+			length, n := binary.Uvarint(data[pos:])
+			if n <= 0 {
+				t.Fatalf("failed to read length varint at offset %d", pos)
+			}
+			pos += n
+			if tagByte>>3 == fieldNum {
+				return data[pos : pos+int(length)]
+			}
+			pos += int(length)
+		default:
+			t.Fatalf("unexpected wire type %d at offset %d", tagByte&0x07, pos-1)
+		}
+	}
+	t.Fatalf("field %d not found in DataPacket", fieldNum)
+	return nil
+}
+
+func TestClientCounterNonce(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.NonceMode = "counter"
+	opts.PacketNumPath = filepath.Join(t.TempDir(), "packet.num")
+	key := makeTestKey()
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", key, opts)
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	_ = client.Send("first")
+	_ = client.Send("second")
+
+	writes := conn.txChar.writes
+	if len(writes) != 2 {
+		t.Fatalf("expected 2 writes, got %d", len(writes))
+	}
+	for i, w := range writes {
+		pktNum := extractPacketNum(t, w)
+		iv := extractBytesField(t, w, 1)
+		if want := blecrypto.CounterNonce(pktNum); !bytes.Equal(iv, want) {
+			t.Errorf("write %d iv = %x, want %x", i, iv, want)
+		}
+		tag := extractBytesField(t, w, 2)
+		ct := extractBytesField(t, w, 3)
+		if _, err := blecrypto.Decrypt(key, iv, ct, tag); err != nil {
+			t.Errorf("write %d Decrypt() error = %v", i, err)
+		}
+	}
+}
+
+func TestClientRandomNonceByDefault(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	key := makeTestKey()
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", key, zeroDelayOpts())
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	_ = client.Send("hello")
+
+	w := conn.txChar.writes[0]
+	iv := extractBytesField(t, w, 1)
+	if len(iv) != blecrypto.NonceSize {
+		t.Fatalf("iv length = %d, want %d", len(iv), blecrypto.NonceSize)
+	}
+	if bytes.Equal(iv, blecrypto.CounterNonce(extractPacketNum(t, w))) {
+		t.Error("default nonce mode used the counter nonce")
+	}
+	if _, err := blecrypto.Decrypt(key, iv, extractBytesField(t, w, 3), extractBytesField(t, w, 2)); err != nil {
+		t.Errorf("Decrypt() error = %v", err)
+	}
+}
+
+// ackPacket encodes a ResponsePacket acking packet n, as the firmware sends it.
+func ackPacket(n uint32) []byte {
+	buf := []byte{0x08, byte(protocol.ResponseTypeAck), 0x20}
+	return binary.AppendUvarint(buf, uint64(n))
+}
+
+func TestClientSendWaitsForAck(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.AckTimeout = time.Second
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	conn := adapter.latestConnection()
+	const delay = 50 * time.Millisecond
+	var acked atomic.Bool
+	conn.txChar.onWrite = func(data []byte) {
+		n := extractPacketNum(t, data)
+		go func() {
+			// An ack for another packet must not release Send.
+			conn.respChar.SimulateNotification(ackPacket(n + 100))
+			time.Sleep(delay)
+			acked.Store(true)
+			conn.respChar.SimulateNotification(ackPacket(n))
+		}()
+	}
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+	if !conn.respChar.isSubscribed() {
+		t.Fatal("client did not subscribe to the response characteristic")
+	}
+
+	start := time.Now()
+	if err := client.Send("hello"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !acked.Load() {
+		t.Errorf("Send() returned after %v, before the matching ack", time.Since(start))
+	}
+}
+
+func TestClientSendAckTimeout(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.AckTimeout = 20 * time.Millisecond
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	err := client.Send("hello")
+	if err == nil || !strings.Contains(err.Error(), "no ack") {
+		t.Fatalf("Send() error = %v, want an ack timeout", err)
+	}
+}
+
+func TestClientSendWithoutAcks(t *testing.T) {
+	// Acks off, and acks on with a device that has no response
+	// characteristic: both send fire-and-forget.
+	for _, noRespChar := range []bool{false, true} {
+		adapter := newMockAdapter(nil)
+		opts := zeroDelayOpts()
+		if noRespChar {
+			opts.AckTimeout = time.Second
+		}
+		client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+		conn := adapter.latestConnection()
+		conn.noRespChar = noRespChar
+		if err := client.setConnected(conn); err != nil {
+			t.Fatalf("setConnected() error = %v", err)
+		}
+
+		// No ack ever arrives, so Send only succeeds if it doesn't wait.
+		if err := client.Send("hello"); err != nil {
+			t.Fatalf("noRespChar=%v: Send() error = %v", noRespChar, err)
+		}
+		if conn.respChar.isSubscribed() {
+			t.Errorf("noRespChar=%v: response characteristic subscribed without acks", noRespChar)
+		}
+	}
+}
+
 func TestClientQueuesDuringDisconnect(t *testing.T) {
 	adapter := newMockAdapter(nil)
 	opts := zeroDelayOpts()
@@ -220,6 +489,127 @@ func TestClientFlushQueueOnReconnect(t *testing.T) {
 	}
 }
 
+func TestClientCloseFlushesQueue(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	_ = client.Send("msg1")
+	_ = client.Send("msg2")
+
+	// Reconnected, but the reconnect flush hasn't run yet.
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if client.QueueLen() != 0 {
+		t.Errorf("QueueLen() after Close = %d, want 0", client.QueueLen())
+	}
+	if got := len(conn.txChar.writes); got != 2 {
+		t.Errorf("writes before Close = %d, want 2", got)
+	}
+}
+
+func TestClientFlushPolicy(t *testing.T) {
+	// The last message spans 3 chunks, so the write count shows which
+	// messages were sent: 1 + 1 + 3 for all of them, 3 for the latest only.
+	long := strings.Repeat("x", 2*protocol.MaxPayloadBytes+1)
+	tests := []struct {
+		policy     string
+		wantWrites int
+	}{
+		{"", 5},
+		{"all", 5},
+		{"latest", 3},
+		{"drop", 0},
+	}
+	for _, tt := range tests {
+		t.Run("policy_"+tt.policy, func(t *testing.T) {
+			adapter := newMockAdapter(nil)
+			opts := zeroDelayOpts()
+			opts.FlushPolicy = tt.policy
+			client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+			_ = client.Send("first")
+			_ = client.Send("second")
+			_ = client.Send(long)
+
+			conn := adapter.latestConnection()
+			if err := client.setConnected(conn); err != nil {
+				t.Fatalf("setConnected() error = %v", err)
+			}
+			client.flushQueue()
+
+			if client.QueueLen() != 0 {
+				t.Errorf("QueueLen() after flush = %d, want 0", client.QueueLen())
+			}
+			if got := len(conn.txChar.writes); got != tt.wantWrites {
+				t.Errorf("writes after flush = %d, want %d", got, tt.wantWrites)
+			}
+
+			// Messages sent after the flush go out normally.
+			if err := client.Send("after"); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if got := len(conn.txChar.writes); got != tt.wantWrites+1 {
+				t.Errorf("writes after Send = %d, want %d", got, tt.wantWrites+1)
+			}
+		})
+	}
+}
+
+func TestNewClientRejectsInvalidFlushPolicy(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := DefaultClientOptions()
+	opts.FlushPolicy = "newest"
+	if _, err := NewClient(adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() should reject an unknown FlushPolicy")
+	}
+}
+
+func TestNewClientRejectsInvalidNonceMode(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := DefaultClientOptions()
+	opts.NonceMode = "sequential"
+	if _, err := NewClient(adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() should reject an unknown NonceMode")
+	}
+}
+
+func TestNewClientRejectsInvalidChunkMode(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := DefaultClientOptions()
+	opts.ChunkMode = "bytes"
+	if _, err := NewClient(adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() should reject an unknown ChunkMode")
+	}
+}
+
+func TestNewClientInterChunkDelay(t *testing.T) {
+	tests := []struct {
+		name  string
+		delay time.Duration
+		want  time.Duration
+	}{
+		{name: "zero_uses_default", delay: 0, want: 20 * time.Millisecond},
+		{name: "configured", delay: 50 * time.Millisecond, want: 50 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultClientOptions()
+			opts.InterChunkDelay = tt.delay
+			client := mustNewClient(t, newMockAdapter(nil), "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+			if client.opts.InterChunkDelay != tt.want {
+				t.Errorf("InterChunkDelay = %v, want %v", client.opts.InterChunkDelay, tt.want)
+			}
+		})
+	}
+}
+
 func TestNewClientRejectsInvalidKeyLength(t *testing.T) {
 	adapter := newMockAdapter(nil)
 	_, err := NewClient(adapter, "AA:BB:CC:DD:EE:FF", make([]byte, 16), DefaultClientOptions())
@@ -227,3 +617,65 @@ func TestNewClientRejectsInvalidKeyLength(t *testing.T) {
 		t.Error("NewClient() should reject 16-byte key")
 	}
 }
+
+// decryptChunk returns the keyboard text carried by a DataPacket write.
+func decryptChunk(t *testing.T, key, w []byte) string {
+	t.Helper()
+	plain, err := blecrypto.Decrypt(key, extractBytesField(t, w, 1), extractBytesField(t, w, 3), extractBytesField(t, w, 2))
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	kbPacket := extractBytesField(t, plain, 1)
+	return string(extractBytesField(t, kbPacket, 1))
+}
+
+func TestClientConcurrentSendsDoNotInterleave(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := DefaultClientOptions()
+	opts.InterChunkDelay = 100 * time.Microsecond
+	key := makeTestKey()
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", key, opts)
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	// Each message is one letter repeated over several chunks, so every
+	// chunk identifies the message it came from.
+	const senders = 16
+	var wg sync.WaitGroup
+	for i := range senders {
+		text := strings.Repeat(string(rune('a'+i))+" ", 3*protocol.MaxPayloadBytes/2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.Send(text); err != nil {
+				t.Errorf("Send() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	conn.txChar.mu.Lock()
+	writes := conn.txChar.writes
+	conn.txChar.mu.Unlock()
+
+	seen := map[byte]bool{}
+	var current byte
+	for i, w := range writes {
+		chunk := strings.TrimSpace(decryptChunk(t, key, w))
+		if chunk == "" {
+			t.Fatalf("write %d carries no text", i)
+		}
+		if letter := chunk[0]; letter != current {
+			if seen[letter] {
+				t.Fatalf("write %d: message %q resumed after another message's chunks", i, letter)
+			}
+			seen[letter] = true
+			current = letter
+		}
+	}
+	if len(seen) != senders {
+		t.Errorf("saw %d messages, want %d", len(seen), senders)
+	}
+}
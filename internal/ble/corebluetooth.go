@@ -66,16 +66,12 @@ func (a *CoreBluetoothAdapter) Enable() error {
 	return nil
 }
 
-func (a *CoreBluetoothAdapter) Scan(ctx context.Context, serviceUUID string) ([]Device, error) {
+func (a *CoreBluetoothAdapter) Scan(ctx context.Context, serviceUUID string, found func(Device)) error {
 	uuid, err := bluetooth.ParseUUID(serviceUUID)
 	if err != nil {
-		return nil, fmt.Errorf("ble: parse service UUID: %w", err)
+		return fmt.Errorf("ble: parse service UUID: %w", err)
 	}
 
-	var mu sync.Mutex
-	var devices []Device
-	seen := make(map[string]bool)
-
 	done := make(chan struct{})
 	go func() {
 		select {
@@ -89,25 +85,18 @@ func (a *CoreBluetoothAdapter) Scan(ctx context.Context, serviceUUID string) ([]
 		if !result.HasServiceUUID(uuid) {
 			return
 		}
-		mac := result.Address.String()
-		mu.Lock()
-		defer mu.Unlock()
-		if seen[mac] {
-			return
-		}
-		seen[mac] = true
-		devices = append(devices, Device{
+		found(Device{
 			Name: result.LocalName(),
-			MAC:  mac,
+			MAC:  result.Address.String(),
 			RSSI: int(result.RSSI),
 		})
 	})
 	close(done)
 
 	if err != nil && ctx.Err() == nil {
-		return nil, fmt.Errorf("ble: scan: %w", err)
+		return fmt.Errorf("ble: scan: %w", err)
 	}
-	return devices, nil
+	return nil
 }
 
 func (a *CoreBluetoothAdapter) Connect(ctx context.Context, mac string) (Connection, error) {
@@ -220,6 +209,12 @@ func (c *coreBluetoothConnection) OnDisconnect(cb func()) {
 	c.disconnectCb = cb
 }
 
+// RSSI is not available: tinygo/bluetooth doesn't expose CBPeripheral's
+// readRSSI for connected devices, only the advertisement RSSI seen in Scan.
+func (c *coreBluetoothConnection) RSSI() (int, error) {
+	return 0, ErrRSSIUnsupported
+}
+
 type coreBluetoothCharacteristic struct {
 	char *bluetooth.DeviceCharacteristic
 }
@@ -229,8 +224,22 @@ func (c *coreBluetoothCharacteristic) Write(data []byte) error {
 	return err
 }
 
+func (c *coreBluetoothCharacteristic) Read() ([]byte, error) {
+	buf := make([]byte, 512) // max ATT attribute value length
+	n, err := c.char.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
 func (c *coreBluetoothCharacteristic) Subscribe(cb func([]byte)) error {
 	return c.char.EnableNotifications(func(buf []byte) {
 		cb(buf)
 	})
 }
+
+// Unsubscribe disables notifications; a nil callback turns them off.
+func (c *coreBluetoothCharacteristic) Unsubscribe() error {
+	return c.char.EnableNotifications(nil)
+}
@@ -10,6 +10,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -124,10 +125,19 @@ func DeriveSharedSecret(priv *ecdh.PrivateKey, peerPub *ecdh.PublicKey) ([]byte,
 	return secret, nil
 }
 
+// DefaultHKDFInfo is the HKDF info string used by stock GOSTT-KBD firmware.
+const DefaultHKDFInfo = "toothpaste"
+
 // DeriveEncryptionKey uses HKDF-SHA256 to derive a 32-byte AES key from the shared secret.
 // Matches GOSTT-KBD: HKDF(secret, salt=nil, info="toothpaste", length=32).
 func DeriveEncryptionKey(sharedSecret []byte) ([]byte, error) {
-	hkdfReader := hkdf.New(sha256.New, sharedSecret, nil, []byte("toothpaste"))
+	return DeriveEncryptionKeyWithInfo(sharedSecret, nil, DefaultHKDFInfo)
+}
+
+// DeriveEncryptionKeyWithInfo is like DeriveEncryptionKey but with an explicit
+// HKDF salt and info string, for firmware builds that don't use the defaults.
+func DeriveEncryptionKeyWithInfo(sharedSecret, salt []byte, info string) ([]byte, error) {
+	hkdfReader := hkdf.New(sha256.New, sharedSecret, salt, []byte(info))
 	key := make([]byte, 32)
 	if _, err := io.ReadFull(hkdfReader, key); err != nil {
 		return nil, fmt.Errorf("ble/crypto: HKDF: %w", err)
@@ -135,26 +145,42 @@ func DeriveEncryptionKey(sharedSecret []byte) ([]byte, error) {
 	return key, nil
 }
 
-// Encrypt encrypts plaintext with AES-256-GCM, returning iv (12 bytes),
-// ciphertext, and tag (16 bytes) separately (as GOSTT-KBD expects them in
-// separate protobuf fields).
+// NonceSize is the AES-GCM nonce (IV) length used by GOSTT-KBD.
+const NonceSize = 12
+
+// Encrypt encrypts plaintext with AES-256-GCM under a random nonce, returning
+// iv (12 bytes), ciphertext, and tag (16 bytes) separately (as GOSTT-KBD
+// expects them in separate protobuf fields).
 func Encrypt(key, plaintext []byte) (iv, ciphertext, tag []byte, err error) {
+	iv = make([]byte, NonceSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, nil, nil, fmt.Errorf("ble/crypto: random IV: %w", err)
+	}
+	ciphertext, tag, err = EncryptWithNonce(key, plaintext, iv)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return iv, ciphertext, tag, nil
+}
+
+// EncryptWithNonce is like Encrypt but uses the given 12-byte nonce. The
+// caller must never reuse a nonce with the same key: doing so breaks GCM's
+// confidentiality and authenticity.
+func EncryptWithNonce(key, plaintext, nonce []byte) (ciphertext, tag []byte, err error) {
+	if len(nonce) != NonceSize {
+		return nil, nil, fmt.Errorf("ble/crypto: nonce must be %d bytes, got %d", NonceSize, len(nonce))
+	}
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("ble/crypto: new cipher: %w", err)
+		return nil, nil, fmt.Errorf("ble/crypto: new cipher: %w", err)
 	}
 	aead, err := cipher.NewGCM(block)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("ble/crypto: new GCM: %w", err)
-	}
-
-	iv = make([]byte, aead.NonceSize()) // 12 bytes
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return nil, nil, nil, fmt.Errorf("ble/crypto: random IV: %w", err)
+		return nil, nil, fmt.Errorf("ble/crypto: new GCM: %w", err)
 	}
 
 	// Go's GCM Seal appends the tag to the ciphertext
-	sealed := aead.Seal(nil, iv, plaintext, nil)
+	sealed := aead.Seal(nil, nonce, plaintext, nil)
 
 	// Split: ciphertext is sealed[:len-tagSize], tag is sealed[len-tagSize:]
 	tagSize := aead.Overhead() // 16
@@ -163,13 +189,22 @@ func Encrypt(key, plaintext []byte) (iv, ciphertext, tag []byte, err error) {
 	t := make([]byte, tagSize)
 	copy(t, sealed[len(sealed)-tagSize:])
 
-	return iv, ct, t, nil
+	return ct, t, nil
+}
+
+// CounterNonce returns the deterministic nonce for packet number n, for
+// firmware that ties the nonce to the packet number: four zero bytes
+// followed by n as a big-endian uint64.
+func CounterNonce(n uint32) []byte {
+	nonce := make([]byte, NonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], uint64(n))
+	return nonce
 }
 
 // Decrypt decrypts ciphertext with AES-256-GCM using separate iv, ciphertext, and tag.
 func Decrypt(key, iv, ciphertext, tag []byte) ([]byte, error) {
-	if len(iv) != 12 {
-		return nil, fmt.Errorf("ble/crypto: IV must be 12 bytes, got %d", len(iv))
+	if len(iv) != NonceSize {
+		return nil, fmt.Errorf("ble/crypto: IV must be %d bytes, got %d", NonceSize, len(iv))
 	}
 	if len(tag) != 16 {
 		return nil, fmt.Errorf("ble/crypto: tag must be 16 bytes, got %d", len(tag))
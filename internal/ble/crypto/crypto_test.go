@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
 )
 
@@ -67,6 +68,58 @@ func TestDeriveEncryptionKey(t *testing.T) {
 	}
 }
 
+func TestDeriveEncryptionKeyKnownVector(t *testing.T) {
+	sharedSecret := make([]byte, 32)
+	sharedSecret[0] = 0x42
+
+	// HKDF-SHA256(secret, salt=nil, info="toothpaste"), as derived by GOSTT-KBD.
+	want, _ := hex.DecodeString("151df81de459db1744f72c34b09b00a7e97e11f3fe4bc581e74d17ce5abab978")
+
+	key, err := DeriveEncryptionKey(sharedSecret)
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKey() error = %v", err)
+	}
+	if !bytes.Equal(key, want) {
+		t.Errorf("DeriveEncryptionKey() = %x, want %x", key, want)
+	}
+
+	key, err = DeriveEncryptionKeyWithInfo(sharedSecret, nil, DefaultHKDFInfo)
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+	}
+	if !bytes.Equal(key, want) {
+		t.Errorf("DeriveEncryptionKeyWithInfo(default) = %x, want %x", key, want)
+	}
+}
+
+func TestDeriveEncryptionKeyWithInfo(t *testing.T) {
+	sharedSecret := make([]byte, 32)
+	sharedSecret[0] = 0x42
+
+	def, err := DeriveEncryptionKeyWithInfo(sharedSecret, nil, DefaultHKDFInfo)
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+	}
+	other, err := DeriveEncryptionKeyWithInfo(sharedSecret, nil, "custom-fw")
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+	}
+	salted, err := DeriveEncryptionKeyWithInfo(sharedSecret, []byte("salt"), DefaultHKDFInfo)
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+	}
+
+	if len(other) != 32 || len(salted) != 32 {
+		t.Errorf("key lengths = %d, %d, want 32", len(other), len(salted))
+	}
+	if bytes.Equal(def, other) {
+		t.Error("different info strings produced the same key")
+	}
+	if bytes.Equal(def, salted) {
+		t.Error("different salts produced the same key")
+	}
+}
+
 func TestEncryptDecryptRoundTrip(t *testing.T) {
 	key := make([]byte, 32)
 	key[0] = 0x01
@@ -193,6 +246,72 @@ func TestDecryptInvalidTagLength(t *testing.T) {
 	}
 }
 
+func TestEncryptRandomNonce(t *testing.T) {
+	key := make([]byte, 32)
+	iv1, _, _, err := Encrypt(key, []byte("same"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	iv2, _, _, err := Encrypt(key, []byte("same"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if len(iv1) != NonceSize || len(iv2) != NonceSize {
+		t.Fatalf("IV lengths = %d, %d, want %d", len(iv1), len(iv2), NonceSize)
+	}
+	if bytes.Equal(iv1, iv2) {
+		t.Error("two Encrypt() calls produced the same IV")
+	}
+}
+
+func TestEncryptWithNonceRoundTrip(t *testing.T) {
+	key := make([]byte, 32)
+	key[0] = 0x01
+	plaintext := []byte("counter nonce")
+	nonce := CounterNonce(7)
+
+	ciphertext, tag, err := EncryptWithNonce(key, plaintext, nonce)
+	if err != nil {
+		t.Fatalf("EncryptWithNonce() error = %v", err)
+	}
+	decrypted, err := Decrypt(key, nonce, ciphertext, tag)
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Decrypt() = %q, want %q", decrypted, plaintext)
+	}
+
+	// Same key, nonce and plaintext must give the same output.
+	ciphertext2, tag2, err := EncryptWithNonce(key, plaintext, nonce)
+	if err != nil {
+		t.Fatalf("EncryptWithNonce() error = %v", err)
+	}
+	if !bytes.Equal(ciphertext, ciphertext2) || !bytes.Equal(tag, tag2) {
+		t.Error("EncryptWithNonce() is not deterministic for a fixed nonce")
+	}
+}
+
+func TestEncryptWithNonceInvalidLength(t *testing.T) {
+	key := make([]byte, 32)
+	for _, n := range []int{0, 8, 16} {
+		if _, _, err := EncryptWithNonce(key, []byte("x"), make([]byte, n)); err == nil {
+			t.Errorf("EncryptWithNonce() with %d-byte nonce should fail", n)
+		}
+	}
+}
+
+func TestCounterNonce(t *testing.T) {
+	got := CounterNonce(0x01020304)
+	want := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x02, 0x03, 0x04}
+	if !bytes.Equal(got, want) {
+		t.Errorf("CounterNonce() = %x, want %x", got, want)
+	}
+	if bytes.Equal(CounterNonce(1), CounterNonce(2)) {
+		t.Error("CounterNonce(1) == CounterNonce(2)")
+	}
+}
+
 func TestParseCompressedPublicKey(t *testing.T) {
 	_, pub, err := GenerateKeyPair()
 	if err != nil {
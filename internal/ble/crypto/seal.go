@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrWrongPassphrase is returned by OpenSecret when the passphrase doesn't
+// match (or the sealed data has been modified).
+var ErrWrongPassphrase = errors.New("ble/crypto: wrong passphrase or corrupted secret")
+
+// Sealed secret layout: salt || nonce || AES-256-GCM ciphertext and tag.
+const (
+	sealSaltSize = 16
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+)
+
+// SealSecret encrypts secret with AES-256-GCM under a key derived from
+// passphrase with scrypt and a random salt. The result is self-contained:
+// OpenSecret needs only it and the passphrase.
+func SealSecret(passphrase, secret []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("ble/crypto: empty passphrase")
+	}
+	salt := make([]byte, sealSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("ble/crypto: random salt: %w", err)
+	}
+	nonce := make([]byte, NonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("ble/crypto: random nonce: %w", err)
+	}
+	aead, err := passphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, sealSaltSize+NonceSize+len(secret)+aead.Overhead())
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, secret, nil), nil
+}
+
+// OpenSecret decrypts data produced by SealSecret. It returns
+// ErrWrongPassphrase if authentication fails.
+func OpenSecret(passphrase, sealed []byte) ([]byte, error) {
+	if len(sealed) < sealSaltSize+NonceSize+16 {
+		return nil, fmt.Errorf("ble/crypto: sealed secret too short (%d bytes)", len(sealed))
+	}
+	salt := sealed[:sealSaltSize]
+	nonce := sealed[sealSaltSize : sealSaltSize+NonceSize]
+	aead, err := passphraseAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := aead.Open(nil, nonce, sealed[sealSaltSize+NonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return secret, nil
+}
+
+// passphraseAEAD returns AES-256-GCM keyed with scrypt(passphrase, salt).
+func passphraseAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("ble/crypto: scrypt: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ble/crypto: new cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("ble/crypto: new GCM: %w", err)
+	}
+	return aead, nil
+}
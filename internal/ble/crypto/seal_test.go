@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpenSecretRoundTrip(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xab}, 32)
+	sealed, err := SealSecret([]byte("correct horse"), secret)
+	if err != nil {
+		t.Fatalf("SealSecret() error = %v", err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Error("sealed output contains the plaintext secret")
+	}
+
+	got, err := OpenSecret([]byte("correct horse"), sealed)
+	if err != nil {
+		t.Fatalf("OpenSecret() error = %v", err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Errorf("OpenSecret() = %x, want %x", got, secret)
+	}
+
+	again, err := SealSecret([]byte("correct horse"), secret)
+	if err != nil {
+		t.Fatalf("SealSecret() error = %v", err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Error("sealing twice produced identical output; salt and nonce should be random")
+	}
+}
+
+func TestOpenSecretWrongPassphrase(t *testing.T) {
+	sealed, err := SealSecret([]byte("correct horse"), []byte("secret"))
+	if err != nil {
+		t.Fatalf("SealSecret() error = %v", err)
+	}
+	if _, err := OpenSecret([]byte("battery staple"), sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("OpenSecret(wrong passphrase) error = %v, want ErrWrongPassphrase", err)
+	}
+
+	sealed[len(sealed)-1] ^= 0x01
+	if _, err := OpenSecret([]byte("correct horse"), sealed); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("OpenSecret(tampered) error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestSealOpenSecretInvalidInput(t *testing.T) {
+	if _, err := SealSecret(nil, []byte("secret")); err == nil {
+		t.Error("SealSecret(empty passphrase) should fail")
+	}
+	if _, err := OpenSecret([]byte("pw"), []byte("short")); err == nil {
+		t.Error("OpenSecret(short input) should fail")
+	}
+}
@@ -9,27 +9,62 @@ import (
 
 // mockCharacteristic records writes and allows subscribing.
 type mockCharacteristic struct {
-	mu       sync.Mutex
-	writes   [][]byte
-	callback func([]byte)
+	mu         sync.Mutex
+	writes     [][]byte
+	callback   func([]byte)
+	subscribed bool
+	value      []byte       // returned by Read
+	onWrite    func([]byte) // called after each write, e.g. to send an ack
 }
 
 func (c *mockCharacteristic) Write(data []byte) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	cp := make([]byte, len(data))
 	copy(cp, data)
 	c.writes = append(c.writes, cp)
+	onWrite := c.onWrite
+	c.mu.Unlock()
+	if onWrite != nil {
+		onWrite(cp)
+	}
 	return nil
 }
 
+func (c *mockCharacteristic) Read() ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.value == nil {
+		return nil, fmt.Errorf("mock: characteristic has no value")
+	}
+	cp := make([]byte, len(c.value))
+	copy(cp, c.value)
+	return cp, nil
+}
+
 func (c *mockCharacteristic) Subscribe(cb func([]byte)) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.callback = cb
+	c.subscribed = true
+	return nil
+}
+
+func (c *mockCharacteristic) Unsubscribe() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callback = nil
+	c.subscribed = false
 	return nil
 }
 
+// isSubscribed reports whether a notification callback is registered
+// (thread-safe).
+func (c *mockCharacteristic) isSubscribed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscribed
+}
+
 // SimulateNotification sends a notification to the subscriber.
 func (c *mockCharacteristic) SimulateNotification(data []byte) {
 	c.mu.Lock()
@@ -45,14 +80,22 @@ type mockConnection struct {
 	mu           sync.Mutex
 	txChar       *mockCharacteristic
 	respChar     *mockCharacteristic
+	macChar      *mockCharacteristic
 	disconnectCb func()
 	disconnected bool
+	noRespChar   bool // the device has no response characteristic
+	dropOnWatch  bool // OnDisconnect fires its callback at once, as if the link just dropped
+
+	rssi      []int // scripted RSSI readings; the last one repeats
+	rssiErr   error // returned by RSSI if set
+	rssiCalls int
 }
 
 func newMockConnection() *mockConnection {
 	return &mockConnection{
 		txChar:   &mockCharacteristic{},
 		respChar: &mockCharacteristic{},
+		macChar:  &mockCharacteristic{},
 	}
 }
 
@@ -61,7 +104,12 @@ func (c *mockConnection) DiscoverCharacteristic(serviceUUID, charUUID string) (C
 	case TXCharUUID:
 		return c.txChar, nil
 	case ResponseCharUUID:
+		if c.noRespChar {
+			return nil, fmt.Errorf("mock: no response characteristic")
+		}
 		return c.respChar, nil
+	case MACCharUUID:
+		return c.macChar, nil
 	default:
 		return nil, fmt.Errorf("mock: unknown characteristic UUID %q", charUUID)
 	}
@@ -76,8 +124,36 @@ func (c *mockConnection) Disconnect() error {
 
 func (c *mockConnection) OnDisconnect(cb func()) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.disconnectCb = cb
+	drop := c.dropOnWatch
+	c.mu.Unlock()
+	if drop {
+		cb()
+	}
+}
+
+func (c *mockConnection) RSSI() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rssiCalls++
+	if c.rssiErr != nil {
+		return 0, c.rssiErr
+	}
+	if len(c.rssi) == 0 {
+		return 0, fmt.Errorf("mock: no RSSI readings scripted")
+	}
+	rssi := c.rssi[0]
+	if len(c.rssi) > 1 {
+		c.rssi = c.rssi[1:]
+	}
+	return rssi, nil
+}
+
+// rssiReads returns how many times RSSI was called (thread-safe).
+func (c *mockConnection) rssiReads() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rssiCalls
 }
 
 // SimulateDisconnect triggers the disconnect callback.
@@ -94,7 +170,12 @@ func (c *mockConnection) SimulateDisconnect() {
 type mockAdapter struct {
 	mu         sync.Mutex
 	devices    []Device
-	connection *mockConnection // most recent connection for test assertions
+	connection *mockConnection       // most recent connection for test assertions
+	mac        []byte                // raw MAC characteristic value for new connections
+	connectErr error                 // returned by Connect instead of a connection
+	onConnect  func(*mockConnection) // called with each new connection, e.g. to set onWrite
+
+	scanUntilCancel bool // Scan blocks until its context is cancelled
 }
 
 func newMockAdapter(devices []Device) *mockAdapter {
@@ -106,15 +187,30 @@ func newMockAdapter(devices []Device) *mockAdapter {
 
 func (a *mockAdapter) Enable() error { return nil }
 
-func (a *mockAdapter) Scan(_ context.Context, _ string) ([]Device, error) {
-	return a.devices, nil
+// Scan reports each of a.devices, then keeps scanning until ctx is done if
+// a.scanUntilCancel is set, or returns immediately otherwise.
+func (a *mockAdapter) Scan(ctx context.Context, _ string, found func(Device)) error {
+	for _, d := range a.devices {
+		found(d)
+	}
+	if a.scanUntilCancel {
+		<-ctx.Done()
+	}
+	return nil
 }
 
 func (a *mockAdapter) Connect(_ context.Context, _ string) (Connection, error) {
+	if a.connectErr != nil {
+		return nil, a.connectErr
+	}
 	conn := newMockConnection()
 	a.mu.Lock()
+	conn.macChar.value = a.mac
 	a.connection = conn
 	a.mu.Unlock()
+	if a.onConnect != nil {
+		a.onConnect(conn)
+	}
 	return conn, nil
 }
 
@@ -0,0 +1,82 @@
+package ble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// loadPacketNum reads the last packet number saved at path. A missing file
+// means no packets have been sent yet and yields 0.
+func loadPacketNum(path string) (uint32, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ble: reading packet number: %w", err)
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("ble: parsing packet number in %s: %w", path, err)
+	}
+	return uint32(n), nil
+}
+
+// savePacketNum atomically writes n to path, creating its directory.
+func savePacketNum(path string, n uint32) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("ble: creating packet number dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(uint64(n), 10)+"\n"), 0600); err != nil {
+		return fmt.Errorf("ble: writing packet number: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("ble: writing packet number: %w", err)
+	}
+	return nil
+}
+
+// nextPacketNum returns the number for the next packet. In counter nonce
+// mode the number is the nonce, so it refuses to wrap around to numbers
+// already used; re-pair to get a new key. Otherwise it wraps.
+func (c *Client) nextPacketNum() (uint32, error) {
+	if c.opts.NonceMode != "counter" {
+		return c.packetNum.Add(1), nil
+	}
+	for {
+		n := c.packetNum.Load()
+		if n == math.MaxUint32 {
+			return 0, errors.New("ble: packet number exhausted; counter nonces would repeat (re-pair the device)")
+		}
+		if c.packetNum.CompareAndSwap(n, n+1) {
+			return n + 1, nil
+		}
+	}
+}
+
+// persistPacketNum records n as the last packet number used, if persistence
+// is enabled. It is called before the packet is written, so a crash can
+// skip a number but never reuse one. Concurrent sends may finish out of
+// order; only a higher number than the last saved one is written.
+func (c *Client) persistPacketNum(n uint32) error {
+	if c.opts.PacketNumPath == "" {
+		return nil
+	}
+	c.pktMu.Lock()
+	defer c.pktMu.Unlock()
+	if n <= c.savedPktNum {
+		return nil
+	}
+	if err := savePacketNum(c.opts.PacketNumPath, n); err != nil {
+		return err
+	}
+	c.savedPktNum = n
+	return nil
+}
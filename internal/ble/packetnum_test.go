@@ -0,0 +1,141 @@
+package ble
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPacketNumRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "packet.num")
+
+	n, err := loadPacketNum(path)
+	if err != nil {
+		t.Fatalf("loadPacketNum() on missing file error = %v", err)
+	}
+	if n != 0 {
+		t.Errorf("loadPacketNum() on missing file = %d, want 0", n)
+	}
+
+	if err := savePacketNum(path, 4294967295); err != nil {
+		t.Fatalf("savePacketNum() error = %v", err)
+	}
+	n, err = loadPacketNum(path)
+	if err != nil {
+		t.Fatalf("loadPacketNum() error = %v", err)
+	}
+	if n != 4294967295 {
+		t.Errorf("loadPacketNum() = %d, want 4294967295", n)
+	}
+}
+
+func TestLoadPacketNumCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packet.num")
+	if err := os.WriteFile(path, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadPacketNum(path); err == nil {
+		t.Error("loadPacketNum() on corrupt file should fail")
+	}
+
+	opts := zeroDelayOpts()
+	opts.PacketNumPath = path
+	if _, err := NewClient(newMockAdapter(nil), "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() with corrupt packet number file should fail")
+	}
+}
+
+func TestClientSendContinuesPersistedPacketNum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packet.num")
+	if err := savePacketNum(path, 41); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := zeroDelayOpts()
+	opts.PacketNumPath = path
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	if err := client.Send("hello"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := extractPacketNum(t, conn.txChar.writes[0]); got != 42 {
+		t.Errorf("packet_num = %d, want 42 (continuing from persisted 41)", got)
+	}
+
+	// A restarted client picks up where this one left off.
+	n, err := loadPacketNum(path)
+	if err != nil {
+		t.Fatalf("loadPacketNum() error = %v", err)
+	}
+	if n != 42 {
+		t.Errorf("persisted packet number = %d, want 42", n)
+	}
+}
+
+func TestNewClientCounterNonceRequiresPersistence(t *testing.T) {
+	opts := zeroDelayOpts()
+	opts.NonceMode = "counter"
+	if _, err := NewClient(newMockAdapter(nil), "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() should reject counter nonces without PacketNumPath")
+	}
+}
+
+func TestClientCounterNonceFailsWhenNotPersisted(t *testing.T) {
+	dir := t.TempDir()
+	for _, mode := range []string{"random", "counter"} {
+		opts := zeroDelayOpts()
+		opts.NonceMode = mode
+		opts.PacketNumPath = filepath.Join(dir, mode, "packet.num")
+		adapter := newMockAdapter(nil)
+		client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+		conn := adapter.latestConnection()
+		if err := client.setConnected(conn); err != nil {
+			t.Fatalf("setConnected() error = %v", err)
+		}
+		// A file where the directory should be makes every save fail.
+		if err := os.WriteFile(filepath.Join(dir, mode), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		err := client.Send("hello")
+		if mode == "counter" {
+			if err == nil {
+				t.Error("counter mode: Send() should fail when the packet number can't be saved")
+			}
+			if len(conn.txChar.writes) != 0 {
+				t.Error("counter mode: packet written with an unsaved packet number")
+			}
+		} else if err != nil {
+			t.Errorf("random mode: Send() error = %v, want only a warning", err)
+		}
+	}
+}
+
+func TestClientCounterNonceRefusesWrap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "packet.num")
+	if err := savePacketNum(path, math.MaxUint32); err != nil {
+		t.Fatal(err)
+	}
+	opts := zeroDelayOpts()
+	opts.NonceMode = "counter"
+	opts.PacketNumPath = path
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+	conn := adapter.latestConnection()
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	if err := client.Send("hello"); err == nil {
+		t.Fatal("Send() should fail instead of wrapping the packet number")
+	}
+	if len(conn.txChar.writes) != 0 {
+		t.Error("packet written after the packet number was exhausted")
+	}
+}
@@ -2,7 +2,10 @@ package ble
 
 import (
 	"context"
+	"crypto/ecdh"
 	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	blecrypto "github.com/chaz8081/gostt-writer/internal/ble/crypto"
@@ -11,36 +14,88 @@ import (
 
 // PairResult contains the data needed to save to config after pairing.
 type PairResult struct {
-	DeviceMAC    string
+	// DeviceMAC is the address used to connect. On macOS it is a
+	// CoreBluetooth UUID, which only identifies the device on this machine.
+	DeviceMAC string
+	// HardwareMAC is the MAC the ESP32 reports over its MAC characteristic
+	// ("AA:BB:CC:DD:EE:FF"), stable across machines. Empty if it couldn't
+	// be read.
+	HardwareMAC  string
 	SharedSecret []byte // 32-byte derived encryption key
 }
 
 // PairOptions configures pairing behavior.
 type PairOptions struct {
-	Timeout time.Duration // how long to wait for peer public key
+	Timeout  time.Duration // how long to wait for peer public key
+	HKDFInfo string        // HKDF info string for key derivation (default: blecrypto.DefaultHKDFInfo)
+
+	// Retries is how many more times our public key is written if no valid
+	// peer key arrives within RetryInterval, for when the ESP32 misses the
+	// first write. All attempts share Timeout.
+	Retries       int
+	RetryInterval time.Duration // wait per attempt (default: Timeout / (Retries+1))
 }
 
 // DefaultPairOptions returns sensible defaults for production use.
 func DefaultPairOptions() PairOptions {
 	return PairOptions{
-		Timeout: 10 * time.Second,
+		Timeout:  10 * time.Second,
+		HKDFInfo: blecrypto.DefaultHKDFInfo,
+		Retries:  2,
 	}
 }
 
-// ScanForDevices scans for ESP32 devices advertising the GOSTT-KBD service.
+// ScanForDevices scans for ESP32 devices advertising the GOSTT-KBD service
+// for the given duration and returns every device found.
 func ScanForDevices(adapter Adapter, timeout time.Duration) ([]Device, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	out := make(chan Device)
+	errCh := make(chan error, 1)
+	go func() { errCh <- ScanStream(ctx, adapter, out) }()
+
+	var devices []Device
+	for d := range out {
+		devices = append(devices, d)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// ScanStream scans for ESP32 devices advertising the GOSTT-KBD service and
+// sends each one to out as it is discovered, once per MAC address. It runs
+// until ctx is cancelled or the adapter stops scanning, then closes out.
+// Cancellation is not an error.
+func ScanStream(ctx context.Context, adapter Adapter, out chan<- Device) error {
+	defer close(out)
+
 	if err := adapter.Enable(); err != nil {
-		return nil, fmt.Errorf("ble: enable adapter: %w", err)
+		return fmt.Errorf("ble: enable adapter: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	err := adapter.Scan(ctx, ServiceUUID, func(d Device) {
+		mu.Lock()
+		if seen[d.MAC] {
+			mu.Unlock()
+			return
+		}
+		seen[d.MAC] = true
+		mu.Unlock()
 
-	devices, err := adapter.Scan(ctx, ServiceUUID)
+		select {
+		case out <- d:
+		case <-ctx.Done():
+		}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("ble: scan: %w", err)
+		return fmt.Errorf("ble: scan: %w", err)
 	}
-	return devices, nil
+	return nil
 }
 
 // Pair performs the ECDH key exchange with the specified device.
@@ -48,6 +103,15 @@ func Pair(adapter Adapter, deviceMAC string, opts PairOptions) (*PairResult, err
 	if opts.Timeout <= 0 {
 		opts.Timeout = 10 * time.Second
 	}
+	if opts.HKDFInfo == "" {
+		opts.HKDFInfo = blecrypto.DefaultHKDFInfo
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = opts.Timeout / time.Duration(opts.Retries+1)
+	}
 
 	if err := adapter.Enable(); err != nil {
 		return nil, fmt.Errorf("ble: enable adapter: %w", err)
@@ -62,6 +126,13 @@ func Pair(adapter Adapter, deviceMAC string, opts PairOptions) (*PairResult, err
 	}
 	defer func() { _ = conn.Disconnect() }()
 
+	// The hardware MAC is informational; firmware without the MAC
+	// characteristic can still pair.
+	hardwareMAC, err := ReadMAC(conn)
+	if err != nil {
+		slog.Warn("[BLE] could not read device hardware MAC", "error", err)
+	}
+
 	// Discover characteristics
 	txChar, err := conn.DiscoverCharacteristic(ServiceUUID, TXCharUUID)
 	if err != nil {
@@ -72,20 +143,36 @@ func Pair(adapter Adapter, deviceMAC string, opts PairOptions) (*PairResult, err
 		return nil, fmt.Errorf("ble: discover response char: %w", err)
 	}
 
-	// Subscribe to response notifications
-	peerPubKeyCh := make(chan []byte, 1)
+	// Subscribe to response notifications. Anything that isn't a valid peer
+	// public key (a keepalive sent before the key, a malformed packet) is
+	// ignored so it can't end pairing early. The key may arrive split across
+	// several notifications, so they're reassembled first.
+	peerPubKeyCh := make(chan *ecdh.PublicKey, 1)
+	var asm responseAssembler
 	if err := respChar.Subscribe(func(data []byte) {
-		resp, err := protocol.UnmarshalResponsePacket(data)
-		if err != nil {
+		resp := asm.add(data)
+		if resp == nil {
+			slog.Debug("[BLE] incomplete notification while pairing, waiting for more", "len", len(data))
 			return
 		}
 		// The ESP32 sends its public key as challenge data in a PEER_STATUS response
-		if resp.Type == protocol.ResponseTypePeerStatus && len(resp.Data) == 33 {
-			peerPubKeyCh <- resp.Data
+		if resp.Type != protocol.ResponseTypePeerStatus || len(resp.Data) != 33 {
+			slog.Debug("[BLE] ignoring notification while pairing", "type", resp.Type, "len", len(resp.Data))
+			return
+		}
+		peerPubKey, err := blecrypto.ParseCompressedPublicKey(resp.Data)
+		if err != nil {
+			slog.Debug("[BLE] ignoring invalid peer public key", "error", err)
+			return
+		}
+		select {
+		case peerPubKeyCh <- peerPubKey:
+		default: // already have one
 		}
 	}); err != nil {
 		return nil, fmt.Errorf("ble: subscribe to responses: %w", err)
 	}
+	defer func() { _ = respChar.Unsubscribe() }()
 
 	// Generate our ECDH keypair
 	privKey, pubKey, err := blecrypto.GenerateKeyPair()
@@ -99,32 +186,97 @@ func Pair(adapter Adapter, deviceMAC string, opts PairOptions) (*PairResult, err
 		return nil, fmt.Errorf("ble: write public key: %w", err)
 	}
 
-	// Wait for peer's public key (with timeout)
-	select {
-	case peerPubKeyBytes := <-peerPubKeyCh:
-		peerPubKey, err := blecrypto.ParseCompressedPublicKey(peerPubKeyBytes)
-		if err != nil {
-			return nil, fmt.Errorf("ble: parse peer public key: %w", err)
+	// Wait for peer's public key (with timeout), rewriting ours if the
+	// ESP32 stays silent for a retry interval.
+	deadline := time.After(opts.Timeout)
+	retry := time.NewTicker(opts.RetryInterval)
+	defer retry.Stop()
+	var peerPubKey *ecdh.PublicKey
+	for attempt := 0; peerPubKey == nil; {
+		select {
+		case peerPubKey = <-peerPubKeyCh:
+		case <-retry.C:
+			if attempt >= opts.Retries {
+				continue
+			}
+			attempt++
+			slog.Info("[BLE] no peer public key yet, resending ours", "attempt", attempt+1)
+			if err := txChar.Write(compressed); err != nil {
+				return nil, fmt.Errorf("ble: write public key: %w", err)
+			}
+		case <-deadline:
+			return nil, fmt.Errorf("ble: pairing timed out waiting for peer public key")
 		}
+	}
 
-		// Derive shared secret
-		sharedSecret, err := blecrypto.DeriveSharedSecret(privKey, peerPubKey)
-		if err != nil {
-			return nil, err
-		}
+	// Derive shared secret
+	sharedSecret, err := blecrypto.DeriveSharedSecret(privKey, peerPubKey)
+	if err != nil {
+		return nil, err
+	}
 
-		// Derive encryption key
-		encKey, err := blecrypto.DeriveEncryptionKey(sharedSecret)
+	// Derive encryption key
+	encKey, err := blecrypto.DeriveEncryptionKeyWithInfo(sharedSecret, nil, opts.HKDFInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PairResult{
+		DeviceMAC:    deviceMAC,
+		HardwareMAC:  hardwareMAC,
+		SharedSecret: encKey,
+	}, nil
+}
+
+// maxPairResponseBytes bounds how many notification bytes responseAssembler
+// holds while waiting for a packet to complete.
+const maxPairResponseBytes = 256
+
+// responseAssembler rebuilds ResponsePackets the ESP32 splits across several
+// notifications. Protobuf messages carry no length prefix, so notifications
+// are accumulated until the bytes parse. Parsing is tried from each
+// notification boundary, earliest first, so junk before a packet can't wedge
+// the buffer. Parsed bytes are kept: concatenated protobuf messages merge, so
+// a fragment that happens to parse on its own (e.g. just the type fields)
+// still combines with the data that follows it.
+type responseAssembler struct {
+	mu     sync.Mutex
+	buf    []byte
+	starts []int // offset in buf where each notification begins
+}
+
+// add appends one notification and returns the packet it completes, or nil
+// if the buffered bytes don't parse yet.
+func (a *responseAssembler) add(data []byte) *protocol.ResponsePacket {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.starts = append(a.starts, len(a.buf))
+	a.buf = append(a.buf, data...)
+	for len(a.buf) > maxPairResponseBytes && len(a.starts) > 1 {
+		a.drop(1)
+	}
+
+	for i, off := range a.starts {
+		resp, err := protocol.UnmarshalResponsePacket(a.buf[off:])
 		if err != nil {
-			return nil, err
+			continue
 		}
+		a.drop(i)
+		return resp
+	}
+	return nil
+}
 
-		return &PairResult{
-			DeviceMAC:    deviceMAC,
-			SharedSecret: encKey,
-		}, nil
-
-	case <-time.After(opts.Timeout):
-		return nil, fmt.Errorf("ble: pairing timed out waiting for peer public key")
+// drop discards the first n buffered notifications.
+func (a *responseAssembler) drop(n int) {
+	if n == 0 {
+		return
+	}
+	off := a.starts[n]
+	a.buf = append(a.buf[:0], a.buf[off:]...)
+	a.starts = a.starts[n:]
+	for i := range a.starts {
+		a.starts[i] -= off
 	}
 }
@@ -1,7 +1,9 @@
 package ble
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdh"
 	"sync"
 	"testing"
 	"time"
@@ -41,6 +43,94 @@ func TestScanForDevicesEmpty(t *testing.T) {
 	}
 }
 
+func TestScanForDevicesDedupes(t *testing.T) {
+	adapter := newMockAdapter([]Device{
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:01", RSSI: -45},
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:01", RSSI: -50},
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:02", RSSI: -60},
+	})
+	result, err := ScanForDevices(adapter, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ScanForDevices() error = %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d devices, want 2: %v", len(result), result)
+	}
+	if result[0].RSSI != -45 {
+		t.Errorf("first report should win, RSSI = %d, want -45", result[0].RSSI)
+	}
+}
+
+func TestScanStreamCancel(t *testing.T) {
+	adapter := newMockAdapter([]Device{
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:01"},
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:02"},
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:01"},
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:03"},
+	})
+	adapter.scanUntilCancel = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan Device)
+	errCh := make(chan error, 1)
+	go func() { errCh <- ScanStream(ctx, adapter, out) }()
+
+	var got []string
+	for d := range out {
+		got = append(got, d.MAC)
+		if len(got) == 3 {
+			// The user picked a device; stop scanning.
+			cancel()
+		}
+	}
+
+	want := []string{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02", "AA:BB:CC:DD:EE:03"}
+	if len(got) != len(want) {
+		t.Fatalf("streamed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("device %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("ScanStream() error = %v, want nil after cancel", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ScanStream() did not return after cancel")
+	}
+}
+
+func TestScanStreamCancelWhileBlocked(t *testing.T) {
+	adapter := newMockAdapter([]Device{
+		{MAC: "AA:BB:CC:DD:EE:01"},
+		{MAC: "AA:BB:CC:DD:EE:02"},
+	})
+	adapter.scanUntilCancel = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan Device)
+	errCh := make(chan error, 1)
+	go func() { errCh <- ScanStream(ctx, adapter, out) }()
+
+	// Take one device and cancel without draining the rest.
+	<-out
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("ScanStream() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ScanStream() blocked sending to an unread channel after cancel")
+	}
+}
+
 func TestPairExchangeKeys(t *testing.T) {
 	adapter := newMockPairingAdapter()
 
@@ -51,11 +141,63 @@ func TestPairExchangeKeys(t *testing.T) {
 	if result.DeviceMAC != "AA:BB:CC:DD:EE:FF" {
 		t.Errorf("DeviceMAC = %q, want %q", result.DeviceMAC, "AA:BB:CC:DD:EE:FF")
 	}
+	if result.HardwareMAC != "" {
+		t.Errorf("HardwareMAC = %q, want empty when the MAC can't be read", result.HardwareMAC)
+	}
 	if len(result.SharedSecret) != 32 {
 		t.Errorf("SharedSecret length = %d, want 32", len(result.SharedSecret))
 	}
 }
 
+func TestPairReadsHardwareMAC(t *testing.T) {
+	adapter := newMockPairingAdapter()
+	adapter.mac = []byte{0xFF, 0xEE, 0xDD, 0xCC, 0xBB, 0xAA} // little-endian
+
+	const uuid = "5F2C1B7E-9A3D-4C8E-B1F0-2D6A8E4C3B19"
+	result, err := Pair(adapter, uuid, PairOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+	if result.DeviceMAC != uuid {
+		t.Errorf("DeviceMAC = %q, want the connect address %q", result.DeviceMAC, uuid)
+	}
+	if result.HardwareMAC != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("HardwareMAC = %q, want %q", result.HardwareMAC, "AA:BB:CC:DD:EE:FF")
+	}
+}
+
+func TestPairHKDFInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info string // passed in PairOptions
+		want string // info the ESP32 side uses
+	}{
+		{"default", "", blecrypto.DefaultHKDFInfo},
+		{"custom", "custom-fw", "custom-fw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newMockPairingAdapter()
+			result, err := Pair(adapter, "AA:BB:CC:DD:EE:FF", PairOptions{Timeout: 5 * time.Second, HKDFInfo: tt.info})
+			if err != nil {
+				t.Fatalf("Pair() error = %v", err)
+			}
+
+			secret := adapter.connection.txChar.peerSharedSecret()
+			if secret == nil {
+				t.Fatal("peer did not complete key exchange")
+			}
+			want, err := blecrypto.DeriveEncryptionKeyWithInfo(secret, nil, tt.want)
+			if err != nil {
+				t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+			}
+			if !bytes.Equal(result.SharedSecret, want) {
+				t.Errorf("SharedSecret = %x, want key derived with info %q", result.SharedSecret, tt.want)
+			}
+		})
+	}
+}
+
 func TestPairTimeout(t *testing.T) {
 	// Use regular mock adapter that doesn't respond with a public key
 	adapter := newMockAdapter(nil)
@@ -67,6 +209,110 @@ func TestPairTimeout(t *testing.T) {
 	}
 }
 
+func TestPairIgnoresJunkBeforeKey(t *testing.T) {
+	adapter := newMockPairingAdapter()
+	adapter.preamble = [][]byte{
+		{0xFF, 0xFF},             // malformed packet
+		{0x08, 0x01, 0x10, 0x00}, // PEER_STATUS keepalive without a key
+		append([]byte{0x08, 0x01, 0x10, 0x00, 0x1a, 0x21, 0x04}, make([]byte, 32)...), // 33 bytes, not a valid key
+	}
+
+	result, err := Pair(adapter, "AA:BB:CC:DD:EE:FF", PairOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+	secret := adapter.connection.txChar.peerSharedSecret()
+	if secret == nil {
+		t.Fatal("peer did not complete key exchange")
+	}
+	want, err := blecrypto.DeriveEncryptionKeyWithInfo(secret, nil, blecrypto.DefaultHKDFInfo)
+	if err != nil {
+		t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+	}
+	if !bytes.Equal(result.SharedSecret, want) {
+		t.Error("SharedSecret does not match the key derived by the peer")
+	}
+}
+
+func TestPairReassemblesFragmentedKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		split    []int
+		preamble [][]byte
+	}{
+		{"two fragments", []int{20}, nil},
+		{"split inside header", []int{5}, nil},
+		{"split after type fields", []int{4}, nil}, // first fragment parses on its own
+		{"many fragments", []int{3, 10, 20, 30}, nil},
+		{"after junk", []int{20}, [][]byte{{0xFF, 0xFF}, {0x08, 0x01, 0x10, 0x00}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := newMockPairingAdapter()
+			adapter.split = tt.split
+			adapter.preamble = tt.preamble
+
+			result, err := Pair(adapter, "AA:BB:CC:DD:EE:FF", PairOptions{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatalf("Pair() error = %v", err)
+			}
+			secret := adapter.connection.txChar.peerSharedSecret()
+			if secret == nil {
+				t.Fatal("peer did not complete key exchange")
+			}
+			want, err := blecrypto.DeriveEncryptionKeyWithInfo(secret, nil, blecrypto.DefaultHKDFInfo)
+			if err != nil {
+				t.Fatalf("DeriveEncryptionKeyWithInfo() error = %v", err)
+			}
+			if !bytes.Equal(result.SharedSecret, want) {
+				t.Error("SharedSecret does not match the key derived by the peer")
+			}
+		})
+	}
+}
+
+func TestPairRetriesKeyWrite(t *testing.T) {
+	adapter := newMockPairingAdapter()
+	adapter.ignoreWrites = 1 // the ESP32 misses our first public key
+
+	_, err := Pair(adapter, "AA:BB:CC:DD:EE:FF", PairOptions{
+		Timeout:       5 * time.Second,
+		Retries:       2,
+		RetryInterval: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Pair() error = %v", err)
+	}
+	tx := adapter.connection.txChar.inner
+	tx.mu.Lock()
+	writes := len(tx.writes)
+	tx.mu.Unlock()
+	if writes != 2 {
+		t.Errorf("public key written %d times, want 2", writes)
+	}
+}
+
+func TestPairRetriesBounded(t *testing.T) {
+	adapter := newMockPairingAdapter()
+	adapter.ignoreWrites = 10
+
+	_, err := Pair(adapter, "AA:BB:CC:DD:EE:FF", PairOptions{
+		Timeout:       300 * time.Millisecond,
+		Retries:       2,
+		RetryInterval: 20 * time.Millisecond,
+	})
+	if err == nil {
+		t.Fatal("Pair() should have timed out")
+	}
+	tx := adapter.connection.txChar.inner
+	tx.mu.Lock()
+	writes := len(tx.writes)
+	tx.mu.Unlock()
+	if writes != 3 {
+		t.Errorf("public key written %d times, want 3 (1 + 2 retries)", writes)
+	}
+}
+
 // mockPairingAdapter is a mock BLE adapter that simulates the ECDH pairing flow.
 // When a 33-byte compressed public key is written to the TX characteristic,
 // it generates its own keypair and sends back a ResponsePacket with its
@@ -74,6 +320,11 @@ func TestPairTimeout(t *testing.T) {
 type mockPairingAdapter struct {
 	mu         sync.Mutex
 	connection *mockPairingConnection
+
+	preamble     [][]byte // notifications sent before the public key
+	split        []int    // offsets at which the key packet is split across notifications
+	ignoreWrites int      // public key writes the simulated ESP32 misses
+	mac          []byte   // value of the MAC characteristic (nil = unreadable)
 }
 
 func newMockPairingAdapter() *mockPairingAdapter {
@@ -82,12 +333,16 @@ func newMockPairingAdapter() *mockPairingAdapter {
 
 func (a *mockPairingAdapter) Enable() error { return nil }
 
-func (a *mockPairingAdapter) Scan(_ context.Context, _ string) ([]Device, error) {
-	return nil, nil
+func (a *mockPairingAdapter) Scan(_ context.Context, _ string, _ func(Device)) error {
+	return nil
 }
 
 func (a *mockPairingAdapter) Connect(_ context.Context, _ string) (Connection, error) {
 	conn := newMockPairingConnection()
+	conn.txChar.preamble = a.preamble
+	conn.txChar.split = a.split
+	conn.txChar.ignoreWrites = a.ignoreWrites
+	conn.base.macChar.value = a.mac
 	a.mu.Lock()
 	a.connection = conn
 	a.mu.Unlock()
@@ -134,12 +389,43 @@ func (c *mockPairingConnection) OnDisconnect(cb func()) {
 	c.base.OnDisconnect(cb)
 }
 
+func (c *mockPairingConnection) RSSI() (int, error) {
+	return c.base.RSSI()
+}
+
 // mockPairingCharacteristic wraps mockCharacteristic and intercepts Write calls.
 // When a 33-byte compressed public key is written, it generates the ESP32 side
 // of the ECDH exchange and sends back a ResponsePacket notification.
 type mockPairingCharacteristic struct {
 	inner    *mockCharacteristic
 	respChar *mockCharacteristic
+
+	preamble     [][]byte // notifications sent before the public key
+	split        []int    // offsets at which the key packet is split across notifications
+	ignoreWrites int      // public key writes to drop before responding
+
+	mu       sync.Mutex
+	hostPub  []byte           // compressed public key written by the host
+	peerPriv *ecdh.PrivateKey // the simulated ESP32's private key
+}
+
+// peerSharedSecret returns the ECDH shared secret as computed by the
+// simulated ESP32, or nil if no exchange has happened.
+func (c *mockPairingCharacteristic) peerSharedSecret() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.peerPriv == nil {
+		return nil
+	}
+	hostPub, err := blecrypto.ParseCompressedPublicKey(c.hostPub)
+	if err != nil {
+		return nil
+	}
+	secret, err := blecrypto.DeriveSharedSecret(c.peerPriv, hostPub)
+	if err != nil {
+		return nil
+	}
+	return secret
 }
 
 func (c *mockPairingCharacteristic) Write(data []byte) error {
@@ -149,24 +435,44 @@ func (c *mockPairingCharacteristic) Write(data []byte) error {
 
 	// Detect 33-byte compressed public key write (pairing initiation)
 	if len(data) == 33 && (data[0] == 0x02 || data[0] == 0x03) {
-		go c.simulatePeerKeyExchange(data)
+		c.mu.Lock()
+		ignore := c.ignoreWrites > 0
+		if ignore {
+			c.ignoreWrites--
+		}
+		c.mu.Unlock()
+		if !ignore {
+			go c.simulatePeerKeyExchange(data)
+		}
 	}
 
 	return nil
 }
 
+func (c *mockPairingCharacteristic) Read() ([]byte, error) {
+	return c.inner.Read()
+}
+
 func (c *mockPairingCharacteristic) Subscribe(cb func([]byte)) error {
 	return c.inner.Subscribe(cb)
 }
 
+func (c *mockPairingCharacteristic) Unsubscribe() error {
+	return c.inner.Unsubscribe()
+}
+
 // simulatePeerKeyExchange generates the ESP32's ECDH keypair and sends
 // back a ResponsePacket with the compressed public key.
-func (c *mockPairingCharacteristic) simulatePeerKeyExchange(_ []byte) {
+func (c *mockPairingCharacteristic) simulatePeerKeyExchange(hostPub []byte) {
 	// Generate ESP32's keypair
-	_, peerPub, err := blecrypto.GenerateKeyPair()
+	peerPriv, peerPub, err := blecrypto.GenerateKeyPair()
 	if err != nil {
 		return
 	}
+	c.mu.Lock()
+	c.hostPub = hostPub
+	c.peerPriv = peerPriv
+	c.mu.Unlock()
 	compressed := blecrypto.CompressPublicKey(peerPub)
 
 	// Build protobuf ResponsePacket manually:
@@ -182,5 +488,13 @@ func (c *mockPairingCharacteristic) simulatePeerKeyExchange(_ []byte) {
 	// Small delay to simulate BLE latency and ensure subscriber is registered
 	time.Sleep(10 * time.Millisecond)
 
-	c.respChar.SimulateNotification(buf)
+	for _, n := range c.preamble {
+		c.respChar.SimulateNotification(n)
+	}
+	prev := 0
+	for _, off := range c.split {
+		c.respChar.SimulateNotification(buf[prev:off])
+		prev = off
+	}
+	c.respChar.SimulateNotification(buf[prev:])
 }
@@ -0,0 +1,62 @@
+package ble
+
+import (
+	"errors"
+	"time"
+)
+
+// errProbeNotSent is returned by Probe when the client lost its connection
+// before the probe went out, so Send queued it rather than writing it.
+var errProbeNotSent = errors.New("ble: probe not sent: device disconnected")
+
+// ProbeText is what Probe sends: a single space, the least intrusive text
+// the device can type.
+const ProbeText = " "
+
+// ProbeResult reports how a Probe went.
+type ProbeResult struct {
+	Connect   time.Duration // time to connect to the device
+	RoundTrip time.Duration // time to send ProbeText, including the ack wait when Acked
+	// Acked is true if the device acknowledged the probe, which means it
+	// decrypted it with the shared secret. Without acks (AckTimeout unset or
+	// firmware without a response characteristic) a successful probe only
+	// shows the device is reachable.
+	Acked bool
+}
+
+// Probe connects c, sends ProbeText, and closes c, checking that a paired
+// device is reachable and, with acks, that the shared secret works. It fails
+// if the probe was queued instead of written. The result holds the timings
+// reached before any error.
+func Probe(c *Client) (ProbeResult, error) {
+	var res ProbeResult
+	defer func() { _ = c.Close() }()
+
+	start := time.Now()
+	if err := c.Connect(); err != nil {
+		return res, err
+	}
+	res.Connect = time.Since(start)
+
+	c.mu.Lock()
+	acked := c.acksEnabled
+	connected := c.connected
+	c.mu.Unlock()
+	if !connected {
+		return res, errProbeNotSent
+	}
+
+	// Send queues text while disconnected and returns nil, so a probe only
+	// counts as sent once a chunk has been written.
+	sent := false
+	start = time.Now()
+	if err := c.SendWithProgress(ProbeText, func(int, int) { sent = true }); err != nil {
+		return res, err
+	}
+	if !sent {
+		return res, errProbeNotSent
+	}
+	res.RoundTrip = time.Since(start)
+	res.Acked = acked
+	return res, nil
+}
@@ -0,0 +1,105 @@
+package ble
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProbeAcked(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	key := makeTestKey()
+	adapter.onConnect = func(conn *mockConnection) {
+		conn.txChar.onWrite = func(data []byte) {
+			go conn.respChar.SimulateNotification(ackPacket(extractPacketNum(t, data)))
+		}
+	}
+	opts := zeroDelayOpts()
+	opts.AckTimeout = time.Second
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", key, opts)
+
+	res, err := Probe(client)
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if !res.Acked {
+		t.Error("Acked = false, want true")
+	}
+	conn := adapter.latestConnection()
+	conn.txChar.mu.Lock()
+	writes := conn.txChar.writes
+	conn.txChar.mu.Unlock()
+	if len(writes) != 1 {
+		t.Fatalf("got %d writes, want 1", len(writes))
+	}
+	if got := decryptChunk(t, key, writes[0]); got != ProbeText {
+		t.Errorf("probe sent %q, want %q", got, ProbeText)
+	}
+	if !conn.disconnected {
+		t.Error("Probe() did not disconnect")
+	}
+}
+
+func TestProbeWithoutAcks(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+
+	res, err := Probe(client)
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if res.Acked {
+		t.Error("Acked = true without acks enabled")
+	}
+}
+
+func TestProbeNoAck(t *testing.T) {
+	// A device that can't decrypt the probe (wrong secret) never acks it.
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.AckTimeout = 20 * time.Millisecond
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	res, err := Probe(client)
+	if err == nil {
+		t.Fatal("Probe() should fail when the probe isn't acked")
+	}
+	if res.Connect == 0 {
+		t.Error("Connect = 0, want the time taken to connect")
+	}
+	if !adapter.latestConnection().disconnected {
+		t.Error("Probe() did not disconnect after failing")
+	}
+}
+
+func TestProbeConnectError(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	adapter.connectErr = errors.New("device not found")
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+
+	if _, err := Probe(client); err == nil {
+		t.Fatal("Probe() should fail when the device can't be reached")
+	}
+}
+
+func TestProbeDisconnectedAfterConnect(t *testing.T) {
+	// The link drops as soon as it's up and can't come back, so Send would
+	// queue the probe rather than write it.
+	adapter := newMockAdapter(nil)
+	adapter.onConnect = func(conn *mockConnection) {
+		conn.dropOnWatch = true
+		adapter.connectErr = errors.New("device out of range")
+	}
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+
+	_, err := Probe(client)
+	if !errors.Is(err, errProbeNotSent) {
+		t.Fatalf("Probe() error = %v, want %v", err, errProbeNotSent)
+	}
+	conn := adapter.latestConnection()
+	conn.txChar.mu.Lock()
+	defer conn.txChar.mu.Unlock()
+	if len(conn.txChar.writes) != 0 {
+		t.Errorf("got %d writes, want none", len(conn.txChar.writes))
+	}
+}
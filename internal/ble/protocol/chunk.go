@@ -67,3 +67,27 @@ func ChunkText(text string, maxBytes int) []string {
 	}
 	return chunks
 }
+
+// ChunkTextFixed splits text into chunks of exactly maxBytes, except the
+// last, for firmware that expects full packets. Unlike ChunkText it ignores
+// word boundaries, backing off only as far as needed to avoid splitting a
+// UTF-8 character. Returns nil for empty text.
+func ChunkTextFixed(text string, maxBytes int) []string {
+	if maxBytes <= 0 || len(text) == 0 {
+		return nil
+	}
+
+	var chunks []string
+	for len(text) > 0 {
+		split := min(maxBytes, len(text))
+		for split < len(text) && split > 0 && !utf8.RuneStart(text[split]) {
+			split--
+		}
+		if split == 0 {
+			_, split = utf8.DecodeRuneInString(text) // rune wider than maxBytes
+		}
+		chunks = append(chunks, text[:split])
+		text = text[split:]
+	}
+	return chunks
+}
@@ -4,6 +4,7 @@ package protocol
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 const testMaxBytes = 50 // small limit for easy testing
@@ -117,3 +118,54 @@ func TestChunkTextMaxSmallerThanRune(t *testing.T) {
 		t.Errorf("chunk[0] = %q, want %q", chunks[0], text)
 	}
 }
+
+func TestChunkTextFixedPacksFully(t *testing.T) {
+	// Word mode would break at a space; fixed mode fills every chunk but the last.
+	text := "the quick brown fox jumps over the lazy dog sleeping today and tomorrow too"
+	chunks := ChunkTextFixed(text, testMaxBytes)
+	if len(chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(chunks))
+	}
+	if len(chunks[0]) != testMaxBytes {
+		t.Errorf("chunk[0] len=%d, want %d", len(chunks[0]), testMaxBytes)
+	}
+	if got := strings.Join(chunks, ""); got != text {
+		t.Errorf("reassembled = %q, want %q", got, text)
+	}
+}
+
+func TestChunkTextFixedUTF8NeverSplitsMidChar(t *testing.T) {
+	// "a" then 4-byte emojis: with max=10 the first chunk holds "a" plus two
+	// emojis (9 bytes) because a third would straddle the boundary.
+	text := "a" + strings.Repeat("\U0001F600", 5)
+	chunks := ChunkTextFixed(text, 10)
+	want := []string{"a\U0001F600\U0001F600", "\U0001F600\U0001F600", "\U0001F600"}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks %q, want %d", len(chunks), chunks, len(want))
+	}
+	for i, c := range chunks {
+		if !utf8.ValidString(c) {
+			t.Errorf("chunk[%d] = %q is not valid UTF-8 (split mid-rune)", i, c)
+		}
+		if c != want[i] {
+			t.Errorf("chunk[%d] = %q, want %q", i, c, want[i])
+		}
+	}
+}
+
+func TestChunkTextFixedEdgeCases(t *testing.T) {
+	if chunks := ChunkTextFixed("", testMaxBytes); chunks != nil {
+		t.Errorf("empty text: got %v, want nil", chunks)
+	}
+	if chunks := ChunkTextFixed("hello", 0); chunks != nil {
+		t.Errorf("maxBytes=0: got %v, want nil", chunks)
+	}
+	exact := strings.Repeat("a", testMaxBytes)
+	if chunks := ChunkTextFixed(exact, testMaxBytes); len(chunks) != 1 || chunks[0] != exact {
+		t.Errorf("exact fit: got %q, want one chunk", chunks)
+	}
+	// A rune wider than maxBytes is sent whole to make progress.
+	if chunks := ChunkTextFixed("\U0001F600", 1); len(chunks) != 1 || chunks[0] != "\U0001F600" {
+		t.Errorf("maxBytes smaller than rune: got %q, want one chunk", chunks)
+	}
+}
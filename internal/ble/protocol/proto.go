@@ -13,6 +13,7 @@ type ResponseType uint32
 const (
 	ResponseTypeKeepalive  ResponseType = 0
 	ResponseTypePeerStatus ResponseType = 1
+	ResponseTypeAck        ResponseType = 2 // PacketNum was received and decrypted
 )
 
 // PeerStatus indicates whether the ESP32 recognizes us.
@@ -28,6 +29,7 @@ type ResponsePacket struct {
 	Type       ResponseType
 	PeerStatus PeerStatus
 	Data       []byte // challenge data during pairing
+	PacketNum  uint32 // packet acknowledged, for ResponseTypeAck
 }
 
 // MarshalKeyboardPacket encodes a KeyboardPacket protobuf.
@@ -88,7 +90,18 @@ func MarshalDataPacket(iv, tag, encrypted []byte, packetNum uint32) ([]byte, err
 	return buf, nil
 }
 
+// maxFieldNum is the largest field number protobuf allows.
+const maxFieldNum = 1<<29 - 1
+
 // UnmarshalResponsePacket decodes a ResponsePacket from raw protobuf bytes.
+//
+//	field 1 (uint32): type
+//	field 2 (uint32): peer status
+//	field 3 (bytes): data
+//	field 4 (uint32): packet_num, matching the DataPacket being acked
+//
+// The data comes from the radio, so every length is checked; unknown fields
+// are skipped, and malformed input returns an error rather than panicking.
 func UnmarshalResponsePacket(data []byte) (*ResponsePacket, error) {
 	resp := &ResponsePacket{}
 	for len(data) > 0 {
@@ -97,8 +110,11 @@ func UnmarshalResponsePacket(data []byte) (*ResponsePacket, error) {
 			return nil, fmt.Errorf("protocol: reading tag: %w", err)
 		}
 		data = data[n:]
-		fieldNum := uint8(tag >> 3)
-		wireType := uint8(tag & 0x07)
+		fieldNum := tag >> 3
+		wireType := tag & 0x07
+		if fieldNum == 0 || fieldNum > maxFieldNum {
+			return nil, fmt.Errorf("protocol: invalid field number %d", fieldNum)
+		}
 
 		switch wireType {
 		case 0: // varint
@@ -112,6 +128,8 @@ func UnmarshalResponsePacket(data []byte) (*ResponsePacket, error) {
 				resp.Type = ResponseType(val)
 			case 2:
 				resp.PeerStatus = PeerStatus(val)
+			case 4:
+				resp.PacketNum = uint32(val)
 			}
 		case 2: // length-delimited
 			if len(data) < 1 {
@@ -131,6 +149,15 @@ func UnmarshalResponsePacket(data []byte) (*ResponsePacket, error) {
 				copy(resp.Data, data[:length])
 			}
 			data = data[length:]
+		case 1, 5: // fixed64, fixed32: not used by ResponsePacket, skip
+			size := 8
+			if wireType == 5 {
+				size = 4
+			}
+			if len(data) < size {
+				return nil, fmt.Errorf("protocol: truncated fixed%d field %d", size*8, fieldNum)
+			}
+			data = data[size:]
 		default:
 			return nil, fmt.Errorf("protocol: unsupported wire type %d for field %d", wireType, fieldNum)
 		}
@@ -112,6 +112,23 @@ func TestUnmarshalResponsePacket(t *testing.T) {
 	}
 }
 
+func TestUnmarshalResponsePacketAck(t *testing.T) {
+	raw := []byte{
+		0x08, 0x02, // field 1: varint 2 (ACK)
+		0x20, 0xAC, 0x02, // field 4: varint 300
+	}
+	resp, err := UnmarshalResponsePacket(raw)
+	if err != nil {
+		t.Fatalf("UnmarshalResponsePacket() error = %v", err)
+	}
+	if resp.Type != ResponseTypeAck {
+		t.Errorf("Type = %d, want %d", resp.Type, ResponseTypeAck)
+	}
+	if resp.PacketNum != 300 {
+		t.Errorf("PacketNum = %d, want 300", resp.PacketNum)
+	}
+}
+
 func TestUnmarshalResponsePacketInvalid(t *testing.T) {
 	_, err := UnmarshalResponsePacket([]byte{0xFF})
 	if err == nil {
@@ -119,6 +136,53 @@ func TestUnmarshalResponsePacketInvalid(t *testing.T) {
 	}
 }
 
+func TestUnmarshalResponsePacketMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated_varint", data: []byte{0x08}},
+		{name: "truncated_length", data: []byte{0x1a}},
+		{name: "length_past_end", data: []byte{0x1a, 0x05, 0x01}},
+		{name: "huge_length", data: []byte{0x1a, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}},
+		{name: "varint_overflow", data: []byte{0x08, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}},
+		{name: "field_zero", data: []byte{0x00, 0x01}},
+		{name: "field_too_large", data: []byte{0x80, 0x80, 0x80, 0x80, 0x10, 0x00}},
+		{name: "start_group", data: []byte{0x0b}},
+		{name: "end_group", data: []byte{0x0c}},
+		{name: "truncated_fixed32", data: []byte{0x0d, 0x01, 0x02}},
+		{name: "truncated_fixed64", data: []byte{0x09, 0x01, 0x02, 0x03, 0x04}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp, err := UnmarshalResponsePacket(tt.data); err == nil {
+				t.Errorf("UnmarshalResponsePacket(%x) = %+v, want error", tt.data, resp)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResponsePacketSkipsUnknownFields(t *testing.T) {
+	raw := []byte{
+		0x88, 0x10, 0x07, // field 257 varint: must not be mistaken for field 1
+		0x08, 0x01, // field 1: varint 1
+		0x25, 0x01, 0x02, 0x03, 0x04, // field 4: fixed32
+		0x29, 1, 2, 3, 4, 5, 6, 7, 8, // field 5: fixed64
+		0x32, 0x01, 0xAA, // field 6: bytes
+		0x1a, 0x01, 0xBE, // field 3: bytes
+	}
+	resp, err := UnmarshalResponsePacket(raw)
+	if err != nil {
+		t.Fatalf("UnmarshalResponsePacket() error = %v", err)
+	}
+	if resp.Type != ResponseTypePeerStatus {
+		t.Errorf("Type = %d, want %d", resp.Type, ResponseTypePeerStatus)
+	}
+	if !bytes.Equal(resp.Data, []byte{0xBE}) {
+		t.Errorf("Data = %x, want be", resp.Data)
+	}
+}
+
 func TestUnmarshalResponsePacketNilAndEmpty(t *testing.T) {
 	// nil input should return zero-valued packet with no error
 	resp, err := UnmarshalResponsePacket(nil)
@@ -138,3 +202,43 @@ func TestUnmarshalResponsePacketNilAndEmpty(t *testing.T) {
 		t.Errorf("UnmarshalResponsePacket([]byte{}) = %+v, want zero-valued", resp)
 	}
 }
+
+func FuzzUnmarshalResponsePacket(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x08, 0x01, 0x10, 0x00, 0x1a, 0x02, 0xDE, 0xAD})
+	f.Add([]byte{0x08, 0x00})                                     // keepalive
+	f.Add([]byte{0x1a, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}) // huge length
+	f.Add([]byte{0x1a})                                           // length missing
+	f.Add([]byte{0x08})                                           // varint missing
+	f.Add([]byte{0x0b, 0x0c})                                     // group wire types
+	f.Add([]byte{0x0d, 0x01, 0x02, 0x03, 0x04})                   // fixed32
+	f.Add([]byte{0x89, 0x02, 0x05})                               // field 33
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		input := bytes.Clone(data)
+		resp, err := UnmarshalResponsePacket(data)
+		if !bytes.Equal(data, input) {
+			t.Fatal("UnmarshalResponsePacket() modified its input")
+		}
+		if err != nil {
+			if resp != nil {
+				t.Errorf("UnmarshalResponsePacket() = %+v with error %v, want nil", resp, err)
+			}
+			return
+		}
+		if resp == nil {
+			t.Fatal("UnmarshalResponsePacket() = nil, nil")
+		}
+		if len(resp.Data) > len(data) {
+			t.Fatalf("Data length %d exceeds input length %d", len(resp.Data), len(data))
+		}
+		if len(resp.Data) > 0 && len(data) > 0 {
+			// Data must be a copy, not a view into the caller's buffer.
+			resp.Data[0] ^= 0xFF
+			if !bytes.Equal(data, input) {
+				t.Fatal("Data aliases the input buffer")
+			}
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package ble
 
 import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -106,6 +110,24 @@ func TestBackoffDelayOverflowProtection(t *testing.T) {
 	}
 }
 
+func TestJitterDelayWithinBounds(t *testing.T) {
+	for attempt := 0; attempt < 8; attempt++ {
+		base := backoffDelay(attempt, 30)
+		lo, hi := base, time.Duration(0)
+		for i := 0; i < 1000; i++ {
+			got := jitterDelay(base)
+			if got < base/2 || got > base {
+				t.Fatalf("jitterDelay(%v) = %v, want within [%v, %v]", base, got, base/2, base)
+			}
+			lo, hi = min(lo, got), max(hi, got)
+		}
+		// 1000 samples over the range should spread out, not collapse to one value.
+		if hi-lo < base/4 {
+			t.Errorf("jitterDelay(%v) samples span [%v, %v], want a wider spread", base, lo, hi)
+		}
+	}
+}
+
 func TestConcurrentDisconnectsDoNotStackReconnects(t *testing.T) {
 	adapter := newMockAdapter([]Device{
 		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:FF", RSSI: -45},
@@ -149,3 +171,151 @@ func TestConcurrentDisconnectsDoNotStackReconnects(t *testing.T) {
 		t.Error("reconnecting flag should be cleared after successful reconnect")
 	}
 }
+
+// deviceMACBytes is "AA:BB:CC:DD:EE:FF" in the little-endian byte order the
+// ESP32 firmware sends on the MAC characteristic.
+var deviceMACBytes = []byte{0xFF, 0xEE, 0xDD, 0xCC, 0xBB, 0xAA}
+
+func TestFormatMAC(t *testing.T) {
+	got, err := formatMAC(deviceMACBytes)
+	if err != nil {
+		t.Fatalf("formatMAC() error = %v", err)
+	}
+	if got != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("formatMAC() = %q, want %q", got, "AA:BB:CC:DD:EE:FF")
+	}
+
+	if _, err := formatMAC([]byte{0x01, 0x02}); err == nil {
+		t.Error("formatMAC() should reject a short address")
+	}
+}
+
+func TestConnectVerifyMACMatch(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	adapter.mac = deviceMACBytes
+	opts := zeroDelayOpts()
+	opts.VerifyMAC = "fail"
+	client := mustNewClient(t, adapter, "aa:bb:cc:dd:ee:ff", makeTestKey(), opts)
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+}
+
+func TestConnectVerifyMACUsesHardwareMAC(t *testing.T) {
+	// On macOS the connect address is a CoreBluetooth UUID; the MAC
+	// recorded at pairing is what gets checked.
+	const uuid = "5F2C1B7E-9A3D-4C8E-B1F0-2D6A8E4C3B19"
+	for _, tt := range []struct {
+		hardwareMAC string
+		wantErr     bool
+	}{{"AA:BB:CC:DD:EE:FF", false}, {"11:22:33:44:55:66", true}} {
+		adapter := newMockAdapter(nil)
+		adapter.mac = deviceMACBytes
+		opts := zeroDelayOpts()
+		opts.VerifyMAC = "fail"
+		opts.HardwareMAC = tt.hardwareMAC
+		client := mustNewClient(t, adapter, uuid, makeTestKey(), opts)
+
+		err := client.Connect()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("HardwareMAC %s: Connect() error = %v, wantErr %v", tt.hardwareMAC, err, tt.wantErr)
+		}
+		_ = client.Close()
+	}
+}
+
+func TestConnectVerifyMACMismatchFails(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	adapter.mac = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	opts := zeroDelayOpts()
+	opts.VerifyMAC = "fail"
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	err := client.Connect()
+	if err == nil {
+		t.Fatal("Connect() should fail on MAC mismatch")
+	}
+	if !strings.Contains(err.Error(), "06:05:04:03:02:01") {
+		t.Errorf("error %q should name the reported MAC", err)
+	}
+
+	client.mu.Lock()
+	connected := client.connected
+	client.mu.Unlock()
+	if connected {
+		t.Error("client should not be connected after MAC mismatch")
+	}
+	if !adapter.latestConnection().disconnected {
+		t.Error("mismatched connection should be disconnected")
+	}
+}
+
+func TestConnectVerifyMACMismatchWarns(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	adapter := newMockAdapter(nil)
+	adapter.mac = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}
+	opts := zeroDelayOpts()
+	opts.VerifyMAC = "warn"
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v, want warning only", err)
+	}
+	if !strings.Contains(logs.String(), "device MAC mismatch") {
+		t.Errorf("expected MAC mismatch warning, got logs: %s", logs.String())
+	}
+}
+
+func TestNewClientRejectsInvalidVerifyMAC(t *testing.T) {
+	opts := DefaultClientOptions()
+	opts.VerifyMAC = "sometimes"
+	if _, err := NewClient(newMockAdapter(nil), "AA:BB:CC:DD:EE:FF", makeTestKey(), opts); err == nil {
+		t.Error("NewClient() should reject unknown VerifyMAC mode")
+	}
+}
+
+func TestClientUnsubscribesOnDisconnect(t *testing.T) {
+	adapter := newMockAdapter([]Device{
+		{Name: "GOSTT-KBD", MAC: "AA:BB:CC:DD:EE:FF", RSSI: -45},
+	})
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+	defer func() { _ = client.Close() }()
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	conn := adapter.latestConnection()
+
+	var received atomic.Int32
+	if err := client.Subscribe(ResponseCharUUID, func([]byte) { received.Add(1) }); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	conn.respChar.SimulateNotification([]byte{1})
+	if got := received.Load(); got != 1 {
+		t.Fatalf("notifications received = %d, want 1", got)
+	}
+
+	conn.SimulateDisconnect()
+	if conn.respChar.isSubscribed() {
+		t.Error("characteristic still subscribed after disconnect")
+	}
+
+	// A late notification on the old connection must not reach the callback.
+	conn.respChar.SimulateNotification([]byte{2})
+	if got := received.Load(); got != 1 {
+		t.Errorf("notifications received = %d after unsubscribe, want 1", got)
+	}
+}
+
+func TestClientSubscribeNotConnected(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+	if err := client.Subscribe(ResponseCharUUID, func([]byte) {}); err == nil {
+		t.Error("Subscribe() error = nil, want error when not connected")
+	}
+}
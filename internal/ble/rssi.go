@@ -0,0 +1,112 @@
+package ble
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// DefaultRSSIWarn is the signal strength, in dBm, below which the link is
+// reported as weak. Writes start to drop around this level.
+const DefaultRSSIWarn = -80
+
+// ErrRSSIUnsupported is returned by Connection.RSSI when the platform can't
+// read the signal strength of an established connection.
+var ErrRSSIUnsupported = errors.New("ble: reading connection RSSI is not supported")
+
+// rssiEvent is a change in link quality reported by rssiMonitor.
+type rssiEvent int
+
+const (
+	rssiNoChange  rssiEvent = iota
+	rssiWeak                // signal dropped below the threshold
+	rssiRecovered           // signal back at or above the threshold
+)
+
+// rssiMonitor tracks whether the link is weak, so a warning is logged once
+// when the signal drops rather than on every reading.
+type rssiMonitor struct {
+	threshold int
+	weak      bool
+}
+
+// update records a reading and reports whether the link just became weak or
+// recovered.
+func (m *rssiMonitor) update(rssi int) rssiEvent {
+	switch {
+	case rssi < m.threshold && !m.weak:
+		m.weak = true
+		return rssiWeak
+	case rssi >= m.threshold && m.weak:
+		m.weak = false
+		return rssiRecovered
+	}
+	return rssiNoChange
+}
+
+// checkRSSI reads the signal strength of the current connection and feeds it
+// to m. It returns rssiNoChange without reading while disconnected.
+func (c *Client) checkRSSI(m *rssiMonitor) (rssiEvent, int, error) {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return rssiNoChange, 0, nil
+	}
+
+	rssi, err := conn.RSSI()
+	if err != nil {
+		return rssiNoChange, 0, err
+	}
+	return m.update(rssi), rssi, nil
+}
+
+// rssiSupported reports whether conn can read its signal strength. The
+// first time it can't, it warns that RSSIInterval has no effect, so a
+// configured rssi_interval doesn't silently do nothing.
+func (c *Client) rssiSupported(conn Connection) bool {
+	if _, err := conn.RSSI(); !errors.Is(err, ErrRSSIUnsupported) {
+		return true
+	}
+	c.rssiWarnOnce.Do(func() {
+		slog.Warn("[BLE] rssi_interval has no effect: this platform can't read the signal strength of a connected device")
+	})
+	return false
+}
+
+// monitorRSSI polls the connection's signal strength every RSSIInterval and
+// logs when it falls below RSSIWarn, until the client is closed. It returns
+// early if the platform can't read RSSI.
+func (c *Client) monitorRSSI() {
+	m := &rssiMonitor{threshold: c.opts.RSSIWarn}
+	ticker := time.NewTicker(c.opts.RSSIInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		ev, rssi, err := c.checkRSSI(m)
+		if errors.Is(err, ErrRSSIUnsupported) {
+			slog.Debug("[BLE] RSSI monitoring unavailable on this platform")
+			return
+		}
+		if err != nil {
+			slog.Debug("[BLE] read RSSI failed", "error", err)
+			continue
+		}
+
+		switch ev {
+		case rssiWeak:
+			slog.Warn("[BLE] weak signal, writes may drop; move the device closer",
+				"rssi", rssi, "threshold", m.threshold)
+		case rssiRecovered:
+			slog.Info("[BLE] signal recovered", "rssi", rssi)
+		default:
+			slog.Debug("[BLE] RSSI", "rssi", rssi)
+		}
+	}
+}
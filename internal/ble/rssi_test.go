@@ -0,0 +1,136 @@
+package ble
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRSSIMonitorThreshold(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+	conn := adapter.latestConnection()
+	conn.rssi = []int{-50, -79, -80, -81, -90, -85, -80, -60, -95}
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	m := &rssiMonitor{threshold: -80}
+	want := []rssiEvent{
+		rssiNoChange, rssiNoChange, rssiNoChange, // at or above the threshold
+		rssiWeak,                   // -81 drops below
+		rssiNoChange, rssiNoChange, // still weak: warn only once
+		rssiRecovered, // -80 is back at the threshold
+		rssiNoChange,
+		rssiWeak, // drops again
+	}
+	for i, w := range want {
+		ev, rssi, err := client.checkRSSI(m)
+		if err != nil {
+			t.Fatalf("reading %d: checkRSSI() error = %v", i, err)
+		}
+		if ev != w {
+			t.Errorf("reading %d (rssi %d): event = %d, want %d", i, rssi, ev, w)
+		}
+	}
+}
+
+func TestCheckRSSIDisconnected(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+
+	ev, _, err := client.checkRSSI(&rssiMonitor{threshold: DefaultRSSIWarn})
+	if err != nil || ev != rssiNoChange {
+		t.Errorf("checkRSSI() while disconnected = %d, %v; want no change, nil", ev, err)
+	}
+}
+
+func TestMonitorRSSIStopsOnClose(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.RSSIInterval = time.Millisecond
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+	conn := adapter.latestConnection()
+	conn.rssi = []int{-50}
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		client.monitorRSSI()
+		close(stopped)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	_ = client.Close()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("monitorRSSI did not stop after Close")
+	}
+	if conn.rssiReads() == 0 {
+		t.Error("monitorRSSI never read RSSI")
+	}
+}
+
+func TestMonitorRSSIStopsWhenUnsupported(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	opts := zeroDelayOpts()
+	opts.RSSIInterval = time.Millisecond
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+	defer func() { _ = client.Close() }()
+	conn := adapter.latestConnection()
+	conn.rssiErr = ErrRSSIUnsupported
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		client.monitorRSSI()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("monitorRSSI kept polling after ErrRSSIUnsupported")
+	}
+	if n := conn.rssiReads(); n != 1 {
+		t.Errorf("RSSI read %d times, want 1", n)
+	}
+}
+
+func TestCheckRSSIError(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), zeroDelayOpts())
+	conn := adapter.latestConnection()
+	errRead := errors.New("read failed")
+	conn.rssiErr = errRead
+	if err := client.setConnected(conn); err != nil {
+		t.Fatalf("setConnected() error = %v", err)
+	}
+
+	if _, _, err := client.checkRSSI(&rssiMonitor{threshold: DefaultRSSIWarn}); !errors.Is(err, errRead) {
+		t.Errorf("checkRSSI() error = %v, want %v", err, errRead)
+	}
+}
+
+func TestConnectWarnsWhenRSSIUnsupported(t *testing.T) {
+	adapter := newMockAdapter(nil)
+	adapter.onConnect = func(conn *mockConnection) { conn.rssiErr = ErrRSSIUnsupported }
+	opts := zeroDelayOpts()
+	opts.RSSIInterval = time.Millisecond
+	client := mustNewClient(t, adapter, "AA:BB:CC:DD:EE:FF", makeTestKey(), opts)
+	defer func() { _ = client.Close() }()
+
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := adapter.latestConnection().rssiReads(); n != 1 {
+		t.Errorf("RSSI read %d times, want 1: monitor should not start when unsupported", n)
+	}
+}
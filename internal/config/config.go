@@ -4,22 +4,31 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/chaz8081/gostt-writer/internal/hotkey/keyname"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	ModelPath  string           `yaml:"model_path,omitempty"` // deprecated: use Transcribe.ModelPath
-	Transcribe TranscribeConfig `yaml:"transcribe"`
-	Hotkey     HotkeyConfig     `yaml:"hotkey"`
-	Audio      AudioConfig      `yaml:"audio"`
-	Inject     InjectConfig     `yaml:"inject"`
-	Rewrite    RewriteConfig    `yaml:"rewrite"`
-	LogLevel   string           `yaml:"log_level"`
+	Version       int                 `yaml:"version"`              // schema version, see CurrentVersion
+	ModelPath     string              `yaml:"model_path,omitempty"` // deprecated: use Transcribe.ModelPath
+	Transcribe    TranscribeConfig    `yaml:"transcribe"`
+	Hotkey        HotkeyConfig        `yaml:"hotkey"`
+	Audio         AudioConfig         `yaml:"audio"`
+	Inject        InjectConfig        `yaml:"inject"`
+	Rewrite       RewriteConfig       `yaml:"rewrite"`
+	Metrics       MetricsConfig       `yaml:"metrics"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	LogLevel      string              `yaml:"log_level"`
+	LogFormat     string              `yaml:"log_format"` // "text" or "json"
 }
 
 // RewriteConfig holds LLM post-processing settings via Ollama.
@@ -31,12 +40,67 @@ type RewriteConfig struct {
 	TimeoutSecs int    `yaml:"timeout_secs"` // per-request timeout in seconds
 }
 
+// MetricsConfig holds settings for the optional Prometheus /metrics endpoint.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"` // serve /metrics over HTTP
+	Addr    string `yaml:"addr"`    // listen address (default "127.0.0.1:9464")
+}
+
+// NotificationsConfig holds desktop notification settings.
+type NotificationsConfig struct {
+	Enabled bool `yaml:"enabled"` // notify when transcription or injection fails (macOS)
+}
+
 // TranscribeConfig holds transcription backend settings.
 type TranscribeConfig struct {
-	Backend          string          `yaml:"backend"`            // "whisper" or "parakeet"
-	ModelPath        string          `yaml:"model_path"`         // whisper: path to ggml model file
-	ParakeetModelDir string          `yaml:"parakeet_model_dir"` // parakeet: dir with .mlmodelc files + vocab
-	Streaming        StreamingConfig `yaml:"streaming"`          // real-time streaming settings (whisper only)
+	Backend          string          `yaml:"backend"`              // "whisper", "parakeet", or "mock" (testing)
+	FallbackBackend  string          `yaml:"fallback_backend"`     // backend to try if Backend fails to load ("" = none)
+	ModelPath        string          `yaml:"model_path"`           // whisper: path to ggml model file
+	ParakeetModelDir string          `yaml:"parakeet_model_dir"`   // parakeet: dir with .mlmodelc files + vocab
+	ParakeetMaxSyms  int             `yaml:"parakeet_max_symbols"` // parakeet: max tokens per encoder frame (0 = default 10)
+	Streaming        StreamingConfig `yaml:"streaming"`            // real-time streaming settings (whisper only)
+	Whisper          WhisperConfig   `yaml:"whisper"`              // whisper decoding settings
+	Parakeet         ParakeetConfig  `yaml:"parakeet"`             // parakeet CoreML settings
+	NormalizeNumbers bool            `yaml:"normalize_numbers"`    // convert spoken numbers to digits (batch mode only)
+	SpokenControls   bool            `yaml:"spoken_controls"`      // type "new line"/"tab" as Enter/Tab (batch mode only)
+	Warmup           bool            `yaml:"warmup"`               // run one transcription on silence after model load
+	AutoDownload     bool            `yaml:"auto_download"`        // download the backend's model at startup if missing
+	JournalPath      string          `yaml:"journal_path"`         // append every transcript, timestamped, to this file ("" = off)
+	RTFWarn          float64         `yaml:"rtf_warn"`             // warn when real-time factor exceeds this (0 = off)
+	MaxConcurrent    int             `yaml:"max_concurrent"`       // max utterances transcribed at once; more wait their turn (0 = no limit)
+	MinConfidence    float64         `yaml:"min_confidence"`       // skip injecting transcripts below this confidence, 0-1 (0 = off, whisper only)
+	ConfidenceBeep   bool            `yaml:"confidence_beep"`      // beep when min_confidence suppresses a transcript
+	StripAnnotations bool            `yaml:"strip_annotations"`    // remove "[BLANK_AUDIO]", "(music)" etc. from whisper output
+
+	// ParakeetBlankID is the parakeet blank token index. Unset detects it
+	// from the model's vocabulary; 0 is a valid index, not "auto".
+	ParakeetBlankID *int `yaml:"parakeet_blank_id,omitempty"`
+
+	// Pipeline lists the text transforms applied to each batch transcript,
+	// in order (see PipelineStepNames). When set it replaces
+	// normalize_numbers and spoken_controls. Replacements are used by the
+	// "replacements" step.
+	Pipeline     []string      `yaml:"pipeline,omitempty"`
+	Replacements []Replacement `yaml:"replacements,omitempty"`
+
+	// ReplacementFuzziness lets replacements also match words within this
+	// many character edits of a rule's from (0 = exact matches only).
+	// Fuzzy matching can rewrite words that merely look alike.
+	ReplacementFuzziness int `yaml:"replacement_fuzziness,omitempty"`
+
+	// ModelSelection picks a whisper model per recording by its length:
+	// the first rule whose max_secs covers the recording, or model_path for
+	// anything longer. Models load on first use.
+	ModelSelection []ModelRule `yaml:"model_selection,omitempty"`
+
+	// AnnotationPatterns overrides the regular expressions strip_annotations
+	// matches against bracketed annotation text ("" = built-in list).
+	AnnotationPatterns []string `yaml:"annotation_patterns,omitempty"`
+
+	// MockText enables the model-free "mock" backend for end-to-end tests:
+	// every transcription returns this text, or with "file:<path>" the
+	// file's lines in turn. The mock backend is rejected without it.
+	MockText string `yaml:"mock_text,omitempty"`
 }
 
 // StreamingConfig holds streaming transcription settings.
@@ -47,30 +111,159 @@ type StreamingConfig struct {
 	KeepMs   int  `yaml:"keep_ms"`   // overlap between windows in ms (default: 200)
 }
 
+// WhisperConfig holds whisper-specific decoding settings.
+type WhisperConfig struct {
+	InitialPrompt string   `yaml:"initial_prompt"` // text that biases whisper toward its vocabulary ("" = none)
+	HotWords      []string `yaml:"hot_words"`      // terms appended to the initial prompt
+	Task          string   `yaml:"task"`           // "transcribe" (default) or "translate" (to English; needs a multilingual model)
+
+	// Temperature is the decoding temperature (0 = greedy, deterministic).
+	// When a decode looks degenerate (repetitive or low-probability),
+	// whisper retries at temperatures raised by TemperatureInc until one
+	// succeeds or 1.0 is passed; TemperatureInc 0 disables the retries.
+	Temperature    float64 `yaml:"temperature"`
+	TemperatureInc float64 `yaml:"temperature_inc"`
+	// Deterministic forces greedy decoding (temperature 0, no retries, beam
+	// size 1) so the same audio always gives the same text, overriding
+	// Temperature and TemperatureInc.
+	Deterministic bool `yaml:"deterministic,omitempty"`
+
+	// DetectLanguage logs the detected spoken language and its probability
+	// for each recording. It runs an extra whisper pass and needs a
+	// multilingual model.
+	DetectLanguage bool `yaml:"detect_language,omitempty"`
+}
+
+// ParakeetConfig holds parakeet-specific CoreML settings.
+type ParakeetConfig struct {
+	// ComputeUnits selects where CoreML runs the encoder, decoder and joint
+	// models: "all" (default), "cpu_only", "cpu_and_gpu" or "cpu_and_ane".
+	// The preprocessor always runs on the CPU.
+	ComputeUnits string `yaml:"compute_units"`
+	// PredictTimeoutMs abandons a transcription whose model run takes longer
+	// than this, so a wedged CoreML call can't hang dictation (0 = no limit).
+	PredictTimeoutMs int `yaml:"predict_timeout_ms"`
+	// MaxRepeats stops the decoder emitting one token more than this many
+	// times in a row, breaking "the the the the" loops on difficult audio
+	// (0 = no limit).
+	MaxRepeats int `yaml:"max_repeats,omitempty"`
+}
+
 // HotkeyConfig holds hotkey-related settings.
 type HotkeyConfig struct {
-	Keys []string `yaml:"keys"`
-	Mode string   `yaml:"mode"` // "hold" or "toggle"
+	Keys       []string `yaml:"keys"`
+	Mode       string   `yaml:"mode"`        // "hold", "toggle" or "hybrid"
+	DebounceMs int      `yaml:"debounce_ms"` // ignore a start within this many ms of the previous stop (0 = off)
+
+	// HoldThresholdMs is, in hybrid mode, how long a press must last to act
+	// as hold-to-talk; shorter presses toggle recording (default: 400).
+	HoldThresholdMs int `yaml:"hold_threshold_ms"`
 }
 
 // AudioConfig holds audio capture settings.
 type AudioConfig struct {
-	SampleRate uint32 `yaml:"sample_rate"`
-	Channels   uint32 `yaml:"channels"`
+	SampleRate  uint32 `yaml:"sample_rate"`
+	Channels    uint32 `yaml:"channels"`
+	Normalize   bool   `yaml:"normalize"`         // scale each recording to a fixed peak level before transcription
+	TrimSilence bool   `yaml:"trim_silence"`      // drop leading and trailing silence before transcription
+	Persistent  bool   `yaml:"persistent_device"` // keep the microphone open between recordings
+	Format      string `yaml:"format"`            // capture sample format: "f32" (default), "s16", or "u8"
+
+	// MaxDurationSecs caps a recording's length. The microphone buffer
+	// stops growing at the cap, so a recording left running can't exhaust
+	// memory; longer audio is dropped (default: 120).
+	MaxDurationSecs int `yaml:"max_duration_secs"`
+
+	// SilenceAutoStopSecs stops a recording, in any hotkey mode, after this
+	// many seconds of continuous silence following speech (0 = off).
+	SilenceAutoStopSecs float64 `yaml:"silence_auto_stop_s,omitempty"`
+
+	// PeriodSizeFrames and Periods size the capture buffer: audio arrives
+	// every PeriodSizeFrames frames from a ring of Periods buffers. Smaller
+	// periods lower latency, more periods avoid dropouts (0 = backend default).
+	PeriodSizeFrames int `yaml:"period_size_frames,omitempty"`
+	Periods          int `yaml:"periods,omitempty"`
 }
 
 // InjectConfig holds text injection settings.
 type InjectConfig struct {
-	Method string    `yaml:"method"` // "type", "paste", or "ble"
-	BLE    BLEConfig `yaml:"ble,omitempty"`
+	Method    string    `yaml:"method"`     // "type", "paste", "clipboard", "ble", or "echo"
+	IMESafe   bool      `yaml:"ime_safe"`   // type: pace keystrokes for an active input method editor
+	IMECommit bool      `yaml:"ime_commit"` // type: with ime_safe, press Return after each word to commit composition
+	Trailing  string    `yaml:"trailing"`   // appended after each transcript: "none" (default), "space", or "newline"
+	BLE       BLEConfig `yaml:"ble,omitempty"`
+
+	// AppAllowlist and AppDenylist restrict which focused applications
+	// receive text, by name as shown in the menu bar (type and paste only).
+	// The denylist wins; an empty allowlist allows every app.
+	AppAllowlist []string `yaml:"app_allowlist,omitempty"`
+	AppDenylist  []string `yaml:"app_denylist,omitempty"`
+
+	// NormalizeUnicode composes text to Unicode NFC before it is typed or
+	// sent, so "e" plus a combining accent arrives as a single "é".
+	NormalizeUnicode bool `yaml:"normalize_unicode,omitempty"`
 }
 
 // BLEConfig holds BLE output settings (used when inject.method is "ble").
+// A single receiver is configured with device_mac and shared_secret; more
+// receivers go in devices. Use DeviceList for the combined list.
 type BLEConfig struct {
-	DeviceMAC    string `yaml:"device_mac,omitempty"`    // paired ESP32 MAC address
-	SharedSecret string `yaml:"shared_secret,omitempty"` // hex-encoded 32-byte AES key
-	QueueSize    int    `yaml:"queue_size,omitempty"`    // max queued messages during disconnect (default 64)
-	ReconnectMax int    `yaml:"reconnect_max,omitempty"` // max reconnect backoff in seconds (default 30)
+	DeviceMAC            string      `yaml:"device_mac,omitempty"`             // paired ESP32 MAC address (a CoreBluetooth UUID on macOS)
+	HardwareMAC          string      `yaml:"hardware_mac,omitempty"`           // MAC the device reported when paired; checked by verify_mac
+	SharedSecret         string      `yaml:"shared_secret,omitempty"`          // hex-encoded 32-byte AES key, or env:NAME / keychain:SERVICE
+	Devices              []BLEDevice `yaml:"devices,omitempty"`                // additional receivers; every dictation goes to all
+	QueueSize            int         `yaml:"queue_size,omitempty"`             // max queued messages during disconnect (default 64)
+	FlushPolicy          string      `yaml:"flush_policy,omitempty"`           // queued messages sent on reconnect: "all" (default), "latest", or "drop"
+	ReconnectMax         int         `yaml:"reconnect_max,omitempty"`          // max reconnect backoff in seconds (default 30)
+	ReconnectJitter      bool        `yaml:"reconnect_jitter,omitempty"`       // randomize each reconnect delay within [delay/2, delay]
+	VerifyMAC            string      `yaml:"verify_mac,omitempty"`             // "warn" or "fail": check device-reported MAC on connect
+	HKDFInfo             string      `yaml:"hkdf_info,omitempty"`              // HKDF info string used when pairing (default "toothpaste")
+	RSSIInterval         int         `yaml:"rssi_interval,omitempty"`          // seconds between connection RSSI checks (0 = off; unsupported on macOS)
+	RSSIWarn             int         `yaml:"rssi_warn,omitempty"`              // warn when RSSI falls below this many dBm (default -80)
+	DisablePacketPersist bool        `yaml:"disable_packet_persist,omitempty"` // don't save the packet number across restarts
+	NonceMode            string      `yaml:"nonce_mode,omitempty"`             // AES-GCM nonce: "random" (default) or "counter" (from the packet number)
+	AckTimeoutMs         int         `yaml:"ack_timeout_ms,omitempty"`         // wait this long for the device to ack each packet (0 = don't wait)
+	InterChunkDelayMs    int         `yaml:"inter_chunk_delay_ms,omitempty"`   // pause between BLE write chunks (0 = default 20ms)
+	ChunkMode            string      `yaml:"chunk_mode,omitempty"`             // how long messages are split: "word" (default) or "fixed" (full packets)
+}
+
+// ModelRule selects the whisper model for recordings up to MaxSecs long.
+type ModelRule struct {
+	MaxSecs   float64 `yaml:"max_secs"`   // longest recording (seconds) this model handles
+	ModelPath string  `yaml:"model_path"` // path to the ggml model file
+}
+
+// Replacement rewrites From (case-insensitive, whole words) to To.
+type Replacement struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// PipelineStepNames lists the valid transcribe.pipeline step names.
+var PipelineStepNames = []string{"trim", "replacements", "numbers", "controls", "punctuate", "capitalize"}
+
+// BLEDevice is one paired ESP32 receiver.
+type BLEDevice struct {
+	DeviceMAC    string `yaml:"device_mac"`             // paired ESP32 MAC address (a CoreBluetooth UUID on macOS)
+	HardwareMAC  string `yaml:"hardware_mac,omitempty"` // MAC the device reported when paired; checked by verify_mac
+	SharedSecret string `yaml:"shared_secret"`          // hex-encoded 32-byte AES key, or env:NAME / keychain:SERVICE
+}
+
+// DeviceList returns every configured receiver: the top-level device_mac
+// (if set) first, followed by the devices list. An entry in devices with the
+// same MAC as the top-level device is dropped.
+func (b BLEConfig) DeviceList() []BLEDevice {
+	var list []BLEDevice
+	if b.DeviceMAC != "" || b.SharedSecret != "" {
+		list = append(list, BLEDevice{DeviceMAC: b.DeviceMAC, HardwareMAC: b.HardwareMAC, SharedSecret: b.SharedSecret})
+	}
+	for _, d := range b.Devices {
+		if b.DeviceMAC != "" && strings.EqualFold(d.DeviceMAC, b.DeviceMAC) {
+			continue
+		}
+		list = append(list, d)
+	}
+	return list
 }
 
 // DefaultConfigDir returns the default config directory path.
@@ -105,6 +298,7 @@ func DefaultModelsDir() string {
 func Default() *Config {
 	modelsDir := DefaultModelsDir()
 	return &Config{
+		Version:   CurrentVersion,
 		ModelPath: filepath.Join(modelsDir, "ggml-base.en.bin"),
 		Transcribe: TranscribeConfig{
 			Backend:          "whisper",
@@ -116,56 +310,103 @@ func Default() *Config {
 				LengthMs: 10000,
 				KeepMs:   200,
 			},
+			RTFWarn: 1.0,
+			Whisper: WhisperConfig{
+				TemperatureInc: 0.2,
+			},
 		},
 		Hotkey: HotkeyConfig{
-			Keys: []string{"ctrl", "shift", "r"},
-			Mode: "hold",
+			Keys:            []string{"ctrl", "shift", "r"},
+			Mode:            "hold",
+			HoldThresholdMs: 400,
 		},
 		Audio: AudioConfig{
-			SampleRate: 16000,
-			Channels:   1,
+			SampleRate:      16000,
+			Channels:        1,
+			Format:          "f32",
+			MaxDurationSecs: 120,
 		},
 		Inject: InjectConfig{
-			Method: "type",
+			Method:   "type",
+			Trailing: "none",
 		},
 		Rewrite: RewriteConfig{
 			Enabled:     false,
 			OllamaURL:   "http://localhost:11434",
 			TimeoutSecs: 10,
 		},
-		LogLevel: "info",
+		Metrics: MetricsConfig{
+			Addr: "127.0.0.1:9464",
+		},
+		LogLevel:  "info",
+		LogFormat: "text",
 	}
 }
 
 // Load reads and parses a YAML config file. Missing fields are filled
 // with defaults. Tilde (~) in paths is expanded to the user's home directory.
-// For backward compatibility, a top-level model_path is copied to
-// Transcribe.ModelPath if the latter is not explicitly set.
+// Files from an older schema version are migrated to CurrentVersion and
+// rewritten in place, with the original kept as a backup alongside.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading config file: %w", err)
 	}
 
-	cfg := Default()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
 
-	// Backward compat: old top-level model_path → Transcribe.ModelPath
+	from, err := migrate(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("migrating config file: %w", err)
+	}
+	switch {
+	case from > CurrentVersion:
+		slog.Warn("Config file is from a newer version of gostt-writer, unknown settings are ignored",
+			"version", from, "supported", CurrentVersion)
+	case from < CurrentVersion:
+		backup, err := writeMigrated(path, data, &doc, from)
+		if err != nil {
+			slog.Warn("Could not save migrated config, using it for this run only", "error", err)
+		} else {
+			slog.Info("Migrated config file", "from", from, "to", CurrentVersion, "backup", backup)
+		}
+	}
+
+	cfg := Default()
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parsing config file: %w", err)
+		}
+	}
+
+	// A top-level model_path can still appear in a current-version file
+	// edited by hand; it applies unless transcribe.model_path is set.
 	if cfg.ModelPath != "" && cfg.Transcribe.ModelPath == Default().Transcribe.ModelPath {
 		cfg.Transcribe.ModelPath = cfg.ModelPath
 	}
 
+	if err := resolveBLESecrets(cfg); err != nil {
+		return nil, err
+	}
+
 	// Default backend if not set
 	if cfg.Transcribe.Backend == "" {
 		cfg.Transcribe.Backend = "whisper"
 	}
 
 	// Expand tildes
-	cfg.ModelPath = expandTilde(cfg.ModelPath)
 	cfg.Transcribe.ModelPath = expandTilde(cfg.Transcribe.ModelPath)
 	cfg.Transcribe.ParakeetModelDir = expandTilde(cfg.Transcribe.ParakeetModelDir)
+	cfg.Transcribe.JournalPath = expandTilde(cfg.Transcribe.JournalPath)
+	for i := range cfg.Transcribe.ModelSelection {
+		cfg.Transcribe.ModelSelection[i].ModelPath = expandTilde(cfg.Transcribe.ModelSelection[i].ModelPath)
+	}
+
+	// Keep the deprecated field in step for code that still reads it.
+	cfg.ModelPath = cfg.Transcribe.ModelPath
 
 	// Fallback: if configured model path doesn't exist, check relative path in working dir
 	cfg.Transcribe.ModelPath = resolveModelPath(cfg.Transcribe.ModelPath, "models/ggml-base.en.bin")
@@ -198,8 +439,113 @@ func (c *Config) Validate() error {
 		if c.Transcribe.ParakeetModelDir == "" {
 			return fmt.Errorf("transcribe.parakeet_model_dir must not be empty for parakeet backend")
 		}
+	case "mock":
+		if c.Transcribe.MockText == "" {
+			return fmt.Errorf("transcribe.backend \"mock\" is for testing and requires transcribe.mock_text")
+		}
 	default:
-		return fmt.Errorf("transcribe.backend must be \"whisper\" or \"parakeet\", got %q", c.Transcribe.Backend)
+		return fmt.Errorf("transcribe.backend must be \"whisper\", \"parakeet\", or \"mock\", got %q", c.Transcribe.Backend)
+	}
+
+	switch c.Transcribe.FallbackBackend {
+	case "":
+	case "whisper":
+		if c.Transcribe.ModelPath == "" {
+			return fmt.Errorf("transcribe.model_path must not be empty for whisper fallback")
+		}
+	case "parakeet":
+		if c.Transcribe.ParakeetModelDir == "" {
+			return fmt.Errorf("transcribe.parakeet_model_dir must not be empty for parakeet fallback")
+		}
+		if c.Transcribe.Streaming.Enabled {
+			return fmt.Errorf("transcribe.fallback_backend cannot be parakeet when streaming is enabled")
+		}
+	default:
+		return fmt.Errorf("transcribe.fallback_backend must be \"whisper\" or \"parakeet\", got %q", c.Transcribe.FallbackBackend)
+	}
+
+	if id := c.Transcribe.ParakeetBlankID; id != nil && *id < 0 {
+		return fmt.Errorf("transcribe.parakeet_blank_id must be >= 0, got %d", *id)
+	}
+	if c.Transcribe.ParakeetMaxSyms < 0 {
+		return fmt.Errorf("transcribe.parakeet_max_symbols must be >= 0, got %d", c.Transcribe.ParakeetMaxSyms)
+	}
+
+	switch c.Transcribe.Whisper.Task {
+	case "", "transcribe", "translate":
+	default:
+		return fmt.Errorf("transcribe.whisper.task must be \"transcribe\" or \"translate\", got %q", c.Transcribe.Whisper.Task)
+	}
+	if t := c.Transcribe.Whisper.Temperature; t < 0 || t > 1 {
+		return fmt.Errorf("transcribe.whisper.temperature must be between 0 and 1, got %g", t)
+	}
+	if inc := c.Transcribe.Whisper.TemperatureInc; inc < 0 || inc > 1 {
+		return fmt.Errorf("transcribe.whisper.temperature_inc must be between 0 and 1, got %g", inc)
+	}
+
+	if c.Transcribe.Parakeet.PredictTimeoutMs < 0 {
+		return fmt.Errorf("transcribe.parakeet.predict_timeout_ms must be >= 0, got %d", c.Transcribe.Parakeet.PredictTimeoutMs)
+	}
+
+	if c.Transcribe.Parakeet.MaxRepeats < 0 {
+		return fmt.Errorf("transcribe.parakeet.max_repeats must be >= 0, got %d", c.Transcribe.Parakeet.MaxRepeats)
+	}
+
+	switch c.Transcribe.Parakeet.ComputeUnits {
+	case "", "all", "cpu_only", "cpu_and_gpu", "cpu_and_ane":
+	default:
+		return fmt.Errorf("transcribe.parakeet.compute_units must be \"all\", \"cpu_only\", \"cpu_and_gpu\" or \"cpu_and_ane\", got %q",
+			c.Transcribe.Parakeet.ComputeUnits)
+	}
+
+	if c.Transcribe.MinConfidence < 0 || c.Transcribe.MinConfidence > 1 {
+		return fmt.Errorf("transcribe.min_confidence must be between 0 and 1, got %g", c.Transcribe.MinConfidence)
+	}
+
+	for i, step := range c.Transcribe.Pipeline {
+		if !slices.Contains(PipelineStepNames, step) {
+			return fmt.Errorf("transcribe.pipeline[%d] must be one of %s, got %q", i, strings.Join(PipelineStepNames, ", "), step)
+		}
+	}
+	for i, p := range c.Transcribe.AnnotationPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("transcribe.annotation_patterns[%d]: %w", i, err)
+		}
+	}
+	for i, r := range c.Transcribe.Replacements {
+		if r.From == "" {
+			return fmt.Errorf("transcribe.replacements[%d].from must not be empty", i)
+		}
+	}
+	if len(c.Transcribe.Replacements) > 0 && len(c.Transcribe.Pipeline) > 0 &&
+		!slices.Contains(c.Transcribe.Pipeline, "replacements") {
+		return fmt.Errorf("transcribe.replacements is set but transcribe.pipeline has no \"replacements\" step, so the rules would never apply")
+	}
+	for i, r := range c.Transcribe.ModelSelection {
+		if r.MaxSecs <= 0 {
+			return fmt.Errorf("transcribe.model_selection[%d].max_secs must be > 0, got %g", i, r.MaxSecs)
+		}
+		if r.ModelPath == "" {
+			return fmt.Errorf("transcribe.model_selection[%d].model_path must not be empty", i)
+		}
+	}
+	if len(c.Transcribe.ModelSelection) > 0 {
+		if c.Transcribe.Backend != "whisper" {
+			return fmt.Errorf("transcribe.model_selection needs the whisper backend, got %q", c.Transcribe.Backend)
+		}
+		if c.Transcribe.Streaming.Enabled {
+			return fmt.Errorf("transcribe.model_selection is not supported with streaming")
+		}
+	}
+	if c.Transcribe.ReplacementFuzziness < 0 {
+		return fmt.Errorf("transcribe.replacement_fuzziness must be >= 0, got %d", c.Transcribe.ReplacementFuzziness)
+	}
+
+	if c.Transcribe.MaxConcurrent < 0 {
+		return fmt.Errorf("transcribe.max_concurrent must be >= 0, got %d", c.Transcribe.MaxConcurrent)
+	}
+	if c.Transcribe.RTFWarn < 0 {
+		return fmt.Errorf("transcribe.rtf_warn must be >= 0, got %g", c.Transcribe.RTFWarn)
 	}
 
 	// Validate streaming config
@@ -207,9 +553,18 @@ func (c *Config) Validate() error {
 		if c.Transcribe.Backend == "parakeet" {
 			return fmt.Errorf("streaming is not supported with the parakeet backend (fixed 15s CoreML input)")
 		}
+		if c.Transcribe.Backend == "mock" {
+			return fmt.Errorf("streaming is not supported with the mock backend")
+		}
 		if c.Inject.Method == "ble" {
 			return fmt.Errorf("streaming is not supported with BLE injection")
 		}
+		if c.Inject.Method == "echo" {
+			return fmt.Errorf("streaming is not supported with echo injection (echo cannot revise printed text)")
+		}
+		if c.Inject.Method == "clipboard" {
+			return fmt.Errorf("streaming is not supported with clipboard injection (nothing is typed to revise)")
+		}
 		if c.Transcribe.Streaming.StepMs > c.Transcribe.Streaming.LengthMs {
 			return fmt.Errorf("transcribe.streaming.step_ms (%d) must not exceed length_ms (%d)",
 				c.Transcribe.Streaming.StepMs, c.Transcribe.Streaming.LengthMs)
@@ -228,11 +583,22 @@ func (c *Config) Validate() error {
 	if len(c.Hotkey.Keys) == 0 {
 		return fmt.Errorf("hotkey.keys must not be empty")
 	}
+	if err := keyname.Validate(c.Hotkey.Keys); err != nil {
+		return fmt.Errorf("hotkey.keys: %w", err)
+	}
 
 	switch c.Hotkey.Mode {
 	case "hold", "toggle":
+	case "hybrid":
+		if c.Hotkey.HoldThresholdMs <= 0 {
+			return fmt.Errorf("hotkey.hold_threshold_ms must be > 0 in hybrid mode, got %d", c.Hotkey.HoldThresholdMs)
+		}
 	default:
-		return fmt.Errorf("hotkey.mode must be \"hold\" or \"toggle\", got %q", c.Hotkey.Mode)
+		return fmt.Errorf("hotkey.mode must be \"hold\", \"toggle\" or \"hybrid\", got %q", c.Hotkey.Mode)
+	}
+
+	if c.Hotkey.DebounceMs < 0 {
+		return fmt.Errorf("hotkey.debounce_ms must be >= 0, got %d", c.Hotkey.DebounceMs)
 	}
 
 	if c.Audio.SampleRate == 0 {
@@ -243,23 +609,116 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("audio.channels must be > 0")
 	}
 
+	if c.Audio.MaxDurationSecs <= 0 {
+		return fmt.Errorf("audio.max_duration_secs must be > 0, got %d", c.Audio.MaxDurationSecs)
+	}
+
+	if c.Audio.SilenceAutoStopSecs < 0 {
+		return fmt.Errorf("audio.silence_auto_stop_s must be >= 0, got %g", c.Audio.SilenceAutoStopSecs)
+	}
+
+	if n := c.Audio.PeriodSizeFrames; n != 0 && (n < 64 || n > 16384) {
+		return fmt.Errorf("audio.period_size_frames must be 0 (default) or 64-16384, got %d", n)
+	}
+
+	if n := c.Audio.Periods; n != 0 && (n < 2 || n > 16) {
+		return fmt.Errorf("audio.periods must be 0 (default) or 2-16, got %d", n)
+	}
+
+	switch c.Audio.Format {
+	case "", "f32", "s16", "u8":
+	default:
+		return fmt.Errorf("audio.format must be \"f32\", \"s16\", or \"u8\", got %q", c.Audio.Format)
+	}
+
 	switch c.Inject.Method {
-	case "type", "paste":
+	case "type", "paste", "clipboard", "echo":
 	case "ble":
-		if c.Inject.BLE.DeviceMAC == "" {
-			return fmt.Errorf("inject.ble.device_mac required when inject.method is \"ble\" (run: task ble-pair)")
+		if len(c.Inject.AppAllowlist) > 0 || len(c.Inject.AppDenylist) > 0 {
+			return fmt.Errorf("inject.app_allowlist and inject.app_denylist are not supported with BLE injection (the receiver types into another device)")
 		}
-		if c.Inject.BLE.SharedSecret == "" {
-			return fmt.Errorf("inject.ble.shared_secret required when inject.method is \"ble\" (run: task ble-pair)")
+		if len(c.Inject.BLE.Devices) == 0 || c.Inject.BLE.DeviceMAC != "" || c.Inject.BLE.SharedSecret != "" {
+			top := BLEDevice{DeviceMAC: c.Inject.BLE.DeviceMAC, HardwareMAC: c.Inject.BLE.HardwareMAC, SharedSecret: c.Inject.BLE.SharedSecret}
+			if err := validateBLEDevice("inject.ble", top); err != nil {
+				return err
+			}
 		}
-		if len(c.Inject.BLE.SharedSecret) != 64 {
-			return fmt.Errorf("inject.ble.shared_secret must be 64 hex characters (32 bytes), got %d", len(c.Inject.BLE.SharedSecret))
+		seen := make(map[string]bool)
+		for i, d := range c.Inject.BLE.Devices {
+			if err := validateBLEDevice(fmt.Sprintf("inject.ble.devices[%d]", i), d); err != nil {
+				return err
+			}
+			mac := strings.ToUpper(d.DeviceMAC)
+			if seen[mac] {
+				return fmt.Errorf("inject.ble.devices[%d].device_mac %q is listed more than once", i, d.DeviceMAC)
+			}
+			seen[mac] = true
 		}
-		if _, err := hex.DecodeString(c.Inject.BLE.SharedSecret); err != nil {
-			return fmt.Errorf("inject.ble.shared_secret must be valid hex: %w", err)
+		switch c.Inject.BLE.VerifyMAC {
+		case "", "warn", "fail":
+		default:
+			return fmt.Errorf("inject.ble.verify_mac must be \"warn\" or \"fail\", got %q", c.Inject.BLE.VerifyMAC)
 		}
+		if c.Inject.BLE.VerifyMAC != "" {
+			// On macOS device_mac is a CoreBluetooth UUID, which never matches
+			// the MAC the device reports.
+			for _, d := range c.Inject.BLE.DeviceList() {
+				if d.HardwareMAC == "" && !isMACAddress(d.DeviceMAC) {
+					return fmt.Errorf("inject.ble.verify_mac needs a MAC to compare against: device_mac %q is not a MAC address; set hardware_mac (printed by --ble-pair)", d.DeviceMAC)
+				}
+			}
+		}
+		switch c.Inject.BLE.FlushPolicy {
+		case "", "all", "latest", "drop":
+		default:
+			return fmt.Errorf("inject.ble.flush_policy must be \"all\", \"latest\", or \"drop\", got %q", c.Inject.BLE.FlushPolicy)
+		}
+		switch c.Inject.BLE.NonceMode {
+		case "", "random":
+		case "counter":
+			if c.Inject.BLE.DisablePacketPersist {
+				// Packet numbers would restart at 1 and repeat nonces under the same key.
+				return fmt.Errorf("inject.ble.nonce_mode \"counter\" requires packet number persistence (disable_packet_persist must be false)")
+			}
+		default:
+			return fmt.Errorf("inject.ble.nonce_mode must be \"random\" or \"counter\", got %q", c.Inject.BLE.NonceMode)
+		}
+		switch c.Inject.BLE.ChunkMode {
+		case "", "word", "fixed":
+		default:
+			return fmt.Errorf("inject.ble.chunk_mode must be \"word\" or \"fixed\", got %q", c.Inject.BLE.ChunkMode)
+		}
+		if c.Inject.BLE.AckTimeoutMs < 0 {
+			return fmt.Errorf("inject.ble.ack_timeout_ms must be >= 0, got %d", c.Inject.BLE.AckTimeoutMs)
+		}
+		if c.Inject.BLE.InterChunkDelayMs < 0 {
+			return fmt.Errorf("inject.ble.inter_chunk_delay_ms must be >= 0, got %d", c.Inject.BLE.InterChunkDelayMs)
+		}
+		if c.Inject.BLE.RSSIInterval < 0 {
+			return fmt.Errorf("inject.ble.rssi_interval must be >= 0, got %d", c.Inject.BLE.RSSIInterval)
+		}
+		if c.Inject.BLE.RSSIWarn > 0 {
+			return fmt.Errorf("inject.ble.rssi_warn must be a negative dBm value, got %d", c.Inject.BLE.RSSIWarn)
+		}
+	default:
+		return fmt.Errorf("inject.method must be \"type\", \"paste\", \"clipboard\", \"ble\", or \"echo\", got %q", c.Inject.Method)
+	}
+
+	switch c.Inject.Trailing {
+	case "none", "space", "newline":
 	default:
-		return fmt.Errorf("inject.method must be \"type\", \"paste\", or \"ble\", got %q", c.Inject.Method)
+		return fmt.Errorf("inject.trailing must be \"none\", \"space\", or \"newline\", got %q", c.Inject.Trailing)
+	}
+
+	for i, app := range c.Inject.AppAllowlist {
+		if strings.TrimSpace(app) == "" {
+			return fmt.Errorf("inject.app_allowlist[%d] must not be empty", i)
+		}
+	}
+	for i, app := range c.Inject.AppDenylist {
+		if strings.TrimSpace(app) == "" {
+			return fmt.Errorf("inject.app_denylist[%d] must not be empty", i)
+		}
 	}
 
 	if c.Rewrite.Enabled {
@@ -277,15 +736,111 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Metrics.Enabled {
+		if _, _, err := net.SplitHostPort(c.Metrics.Addr); err != nil {
+			return fmt.Errorf("metrics.addr must be host:port, got %q: %w", c.Metrics.Addr, err)
+		}
+	}
+
 	switch c.LogLevel {
 	case "debug", "info", "warn", "error":
 	default:
 		return fmt.Errorf("log_level must be debug, info, warn, or error, got %q", c.LogLevel)
 	}
 
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		return fmt.Errorf("log_format must be \"text\" or \"json\", got %q", c.LogFormat)
+	}
+
+	return nil
+}
+
+// validateBLEDevice checks one receiver's MAC and shared secret. prefix is
+// the config path used in error messages.
+func validateBLEDevice(prefix string, d BLEDevice) error {
+	if d.DeviceMAC == "" {
+		return fmt.Errorf("%s.device_mac required when inject.method is \"ble\" (run: task ble-pair)", prefix)
+	}
+	if d.HardwareMAC != "" && !isMACAddress(d.HardwareMAC) {
+		return fmt.Errorf("%s.hardware_mac must look like \"AA:BB:CC:DD:EE:FF\", got %q", prefix, d.HardwareMAC)
+	}
+	if d.SharedSecret == "" {
+		return fmt.Errorf("%s.shared_secret required when inject.method is \"ble\" (run: task ble-pair)", prefix)
+	}
+	if len(d.SharedSecret) != 64 {
+		return fmt.Errorf("%s.shared_secret must be 64 hex characters (32 bytes), got %d", prefix, len(d.SharedSecret))
+	}
+	if _, err := hex.DecodeString(d.SharedSecret); err != nil {
+		return fmt.Errorf("%s.shared_secret must be valid hex: %w", prefix, err)
+	}
 	return nil
 }
 
+// isMACAddress reports whether s is a colon-separated 6-byte MAC address
+// such as "AA:BB:CC:DD:EE:FF" (either case).
+func isMACAddress(s string) bool {
+	parts := strings.Split(s, ":")
+	if len(parts) != 6 {
+		return false
+	}
+	for _, p := range parts {
+		if len(p) != 2 {
+			return false
+		}
+		if _, err := hex.DecodeString(p); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// parakeetModelFiles are the entries that must exist in parakeet_model_dir.
+var parakeetModelFiles = []string{
+	"Preprocessor.mlmodelc",
+	"Encoder.mlmodelc",
+	"Decoder.mlmodelc",
+	"JointDecision.mlmodelc",
+	"parakeet_vocab.json",
+}
+
+// CheckModelFiles verifies that the model files for the configured backend
+// exist on disk. It returns a single error listing everything that is
+// missing and how to download it.
+func CheckModelFiles(cfg *Config) error {
+	var missing []string
+	var fix string
+
+	switch cfg.Transcribe.Backend {
+	case "mock":
+		return nil
+	case "parakeet":
+		for _, name := range parakeetModelFiles {
+			path := filepath.Join(cfg.Transcribe.ParakeetModelDir, name)
+			if _, err := os.Stat(path); err != nil {
+				missing = append(missing, path)
+			}
+		}
+		fix = "task parakeet-model"
+	default:
+		if _, err := os.Stat(cfg.Transcribe.ModelPath); err != nil {
+			missing = append(missing, cfg.Transcribe.ModelPath)
+		}
+		fix = "task whisper-model"
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	backend := cfg.Transcribe.Backend
+	if backend == "" {
+		backend = "whisper"
+	}
+	return fmt.Errorf("%s model files missing:\n  %s\ndownload with: %s (or gostt-writer --download-models)",
+		backend, strings.Join(missing, "\n  "), fix)
+}
+
 // expandTilde replaces a leading ~ with the user's home directory.
 func expandTilde(path string) string {
 	if !strings.HasPrefix(path, "~") {
@@ -314,19 +869,54 @@ func WriteDefault() (string, error) {
 
 	cfg := Default()
 	cfg.ModelPath = "" // omit deprecated field from generated config
-	data, err := yaml.Marshal(cfg)
+	data, err := marshalDocumented(cfg)
 	if err != nil {
 		return "", fmt.Errorf("marshaling default config: %w", err)
 	}
 
-	header := "# gostt-writer configuration\n# See config.example.yaml for documentation\n\n"
-	if err := os.WriteFile(path, []byte(header+string(data)), 0644); err != nil {
+	if err := os.WriteFile(path, []byte(defaultConfigHeader+string(data)), 0644); err != nil {
 		return "", fmt.Errorf("writing config file: %w", err)
 	}
 
 	return path, nil
 }
 
+// MarshalEffective returns c as YAML for display, e.g. by --print-config.
+// The deprecated top-level model_path is omitted and BLE shared secrets are
+// redacted so the output is safe to paste into a bug report.
+func (c *Config) MarshalEffective() ([]byte, error) {
+	out := *c
+	out.ModelPath = ""
+	out.Inject.BLE.SharedSecret = redactSecret(c.Inject.BLE.SharedSecret)
+	out.Inject.BLE.Devices = make([]BLEDevice, len(c.Inject.BLE.Devices))
+	for i, d := range c.Inject.BLE.Devices {
+		d.SharedSecret = redactSecret(d.SharedSecret)
+		out.Inject.BLE.Devices[i] = d
+	}
+	if len(out.Inject.BLE.Devices) == 0 {
+		out.Inject.BLE.Devices = nil
+	}
+
+	data, err := yaml.Marshal(&out)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	return data, nil
+}
+
+// redactSecret keeps the first 4 characters of a secret, enough to tell keys
+// apart, and hides the rest. Secrets too short to be valid are hidden
+// entirely.
+func redactSecret(s string) string {
+	switch {
+	case s == "":
+		return ""
+	case len(s) < 16:
+		return "(redacted)"
+	}
+	return s[:4] + "...(redacted)"
+}
+
 // ParseLogLevel converts a log level string to a slog.Level.
 func ParseLogLevel(level string) slog.Level {
 	switch level {
@@ -4,6 +4,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -127,11 +129,344 @@ func TestValidate(t *testing.T) {
 			modify:  func(c *Config) {},
 			wantErr: false,
 		},
+		{
+			name:    "whisper fallback",
+			modify:  func(c *Config) { c.Transcribe.Backend = "parakeet"; c.Transcribe.FallbackBackend = "whisper" },
+			wantErr: false,
+		},
+		{
+			name:    "invalid fallback backend",
+			modify:  func(c *Config) { c.Transcribe.FallbackBackend = "vosk" },
+			wantErr: true,
+		},
+		{
+			name: "parakeet fallback with streaming",
+			modify: func(c *Config) {
+				c.Transcribe.Streaming.Enabled = true
+				c.Transcribe.FallbackBackend = "parakeet"
+			},
+			wantErr: true,
+		},
+		{
+			name:    "mock backend without mock_text",
+			modify:  func(c *Config) { c.Transcribe.Backend = "mock" },
+			wantErr: true,
+		},
+		{
+			name: "mock backend with streaming",
+			modify: func(c *Config) {
+				c.Transcribe.Backend = "mock"
+				c.Transcribe.MockText = "hello"
+				c.Transcribe.Streaming.Enabled = true
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid annotation pattern",
+			modify:  func(c *Config) { c.Transcribe.AnnotationPatterns = []string{"music("} },
+			wantErr: true,
+		},
+		{
+			name:    "unknown pipeline step",
+			modify:  func(c *Config) { c.Transcribe.Pipeline = []string{"trim", "shout"} },
+			wantErr: true,
+		},
+		{
+			name:    "empty replacement from",
+			modify:  func(c *Config) { c.Transcribe.Replacements = []Replacement{{From: "", To: "x"}} },
+			wantErr: true,
+		},
+		{
+			name: "replacements without pipeline step",
+			modify: func(c *Config) {
+				c.Transcribe.Replacements = []Replacement{{From: "go lang", To: "Go"}}
+				c.Transcribe.Pipeline = []string{"trim", "numbers"}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "replacements with implied pipeline",
+			modify:  func(c *Config) { c.Transcribe.Replacements = []Replacement{{From: "go lang", To: "Go"}} },
+			wantErr: false,
+		},
+		{
+			name:    "zero max_duration_secs",
+			modify:  func(c *Config) { c.Audio.MaxDurationSecs = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "negative silence_auto_stop_s",
+			modify:  func(c *Config) { c.Audio.SilenceAutoStopSecs = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "period_size_frames too small",
+			modify:  func(c *Config) { c.Audio.PeriodSizeFrames = 16 },
+			wantErr: true,
+		},
+		{
+			name:    "period_size_frames too large",
+			modify:  func(c *Config) { c.Audio.PeriodSizeFrames = 32768 },
+			wantErr: true,
+		},
+		{
+			name:    "period_size_frames and periods set",
+			modify:  func(c *Config) { c.Audio.PeriodSizeFrames = 256; c.Audio.Periods = 3 },
+			wantErr: false,
+		},
+		{
+			name:    "single period",
+			modify:  func(c *Config) { c.Audio.Periods = 1 },
+			wantErr: true,
+		},
+		{
+			name:    "silence_auto_stop_s set",
+			modify:  func(c *Config) { c.Audio.SilenceAutoStopSecs = 2.5 },
+			wantErr: false,
+		},
+		{
+			name:    "trailing newline",
+			modify:  func(c *Config) { c.Inject.Trailing = "newline" },
+			wantErr: false,
+		},
+		{
+			name:    "invalid trailing",
+			modify:  func(c *Config) { c.Inject.Trailing = "tab" },
+			wantErr: true,
+		},
+		{
+			name: "model selection",
+			modify: func(c *Config) {
+				c.Transcribe.ModelSelection = []ModelRule{{MaxSecs: 3, ModelPath: "tiny.bin"}}
+			},
+			wantErr: false,
+		},
+		{
+			name: "model selection without max_secs",
+			modify: func(c *Config) {
+				c.Transcribe.ModelSelection = []ModelRule{{ModelPath: "tiny.bin"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "model selection without model_path",
+			modify: func(c *Config) {
+				c.Transcribe.ModelSelection = []ModelRule{{MaxSecs: 3}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "model selection with parakeet",
+			modify: func(c *Config) {
+				c.Transcribe.Backend = "parakeet"
+				c.Transcribe.ModelSelection = []ModelRule{{MaxSecs: 3, ModelPath: "tiny.bin"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "model selection with streaming",
+			modify: func(c *Config) {
+				c.Transcribe.Streaming.Enabled = true
+				c.Transcribe.ModelSelection = []ModelRule{{MaxSecs: 3, ModelPath: "tiny.bin"}}
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negative replacement_fuzziness",
+			modify:  func(c *Config) { c.Transcribe.ReplacementFuzziness = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative max_concurrent",
+			modify:  func(c *Config) { c.Transcribe.MaxConcurrent = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative rtf_warn",
+			modify:  func(c *Config) { c.Transcribe.RTFWarn = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative parakeet_blank_id",
+			modify:  func(c *Config) { id := -1; c.Transcribe.ParakeetBlankID = &id },
+			wantErr: true,
+		},
+		{
+			name:    "negative parakeet_max_symbols",
+			modify:  func(c *Config) { c.Transcribe.ParakeetMaxSyms = -1 },
+			wantErr: true,
+		},
+		{
+			name: "explicit parakeet decode settings",
+			modify: func(c *Config) {
+				id := 8192
+				c.Transcribe.ParakeetBlankID = &id
+				c.Transcribe.ParakeetMaxSyms = 5
+			},
+			wantErr: false,
+		},
+		{
+			name:    "metrics enabled with default addr",
+			modify:  func(c *Config) { c.Metrics.Enabled = true },
+			wantErr: false,
+		},
+		{
+			name:    "metrics enabled with bad addr",
+			modify:  func(c *Config) { c.Metrics.Enabled = true; c.Metrics.Addr = "9464" },
+			wantErr: true,
+		},
+		{
+			name:    "metrics disabled ignores addr",
+			modify:  func(c *Config) { c.Metrics.Addr = "" },
+			wantErr: false,
+		},
+		{
+			name:    "parakeet compute_units cpu_and_gpu",
+			modify:  func(c *Config) { c.Transcribe.Parakeet.ComputeUnits = "cpu_and_gpu" },
+			wantErr: false,
+		},
+		{
+			name:    "negative parakeet predict_timeout_ms",
+			modify:  func(c *Config) { c.Transcribe.Parakeet.PredictTimeoutMs = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "negative parakeet max_repeats",
+			modify:  func(c *Config) { c.Transcribe.Parakeet.MaxRepeats = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "invalid parakeet compute_units",
+			modify:  func(c *Config) { c.Transcribe.Parakeet.ComputeUnits = "ane" },
+			wantErr: true,
+		},
+		{
+			name: "app allowlist and denylist",
+			modify: func(c *Config) {
+				c.Inject.AppAllowlist = []string{"Notes"}
+				c.Inject.AppDenylist = []string{"Terminal"}
+			},
+			wantErr: false,
+		},
+		{
+			name:    "blank app_denylist entry",
+			modify:  func(c *Config) { c.Inject.AppDenylist = []string{"Terminal", " "} },
+			wantErr: true,
+		},
+		{
+			name:    "blank app_allowlist entry",
+			modify:  func(c *Config) { c.Inject.AppAllowlist = []string{""} },
+			wantErr: true,
+		},
+		{
+			name:    "min_confidence set",
+			modify:  func(c *Config) { c.Transcribe.MinConfidence = 0.6 },
+			wantErr: false,
+		},
+		{
+			name:    "negative min_confidence",
+			modify:  func(c *Config) { c.Transcribe.MinConfidence = -0.1 },
+			wantErr: true,
+		},
+		{
+			name:    "min_confidence above 1",
+			modify:  func(c *Config) { c.Transcribe.MinConfidence = 60 },
+			wantErr: true,
+		},
+		{
+			name: "mock backend",
+			modify: func(c *Config) {
+				c.Transcribe.Backend = "mock"
+				c.Transcribe.MockText = "hello"
+			},
+			wantErr: false,
+		},
+		{
+			name:    "rtf_warn disabled",
+			modify:  func(c *Config) { c.Transcribe.RTFWarn = 0 },
+			wantErr: false,
+		},
+		{
+			name:    "negative debounce_ms",
+			modify:  func(c *Config) { c.Hotkey.DebounceMs = -5 },
+			wantErr: true,
+		},
+		{
+			name:    "debounce_ms set",
+			modify:  func(c *Config) { c.Hotkey.DebounceMs = 150 },
+			wantErr: false,
+		},
 		{
 			name:    "invalid hotkey mode",
 			modify:  func(c *Config) { c.Hotkey.Mode = "invalid" },
 			wantErr: true,
 		},
+		{
+			name:    "hybrid hotkey mode",
+			modify:  func(c *Config) { c.Hotkey.Mode = "hybrid" },
+			wantErr: false,
+		},
+		{
+			name: "hybrid without hold threshold",
+			modify: func(c *Config) {
+				c.Hotkey.Mode = "hybrid"
+				c.Hotkey.HoldThresholdMs = 0
+			},
+			wantErr: true,
+		},
+		{
+			name:    "whisper task translate",
+			modify:  func(c *Config) { c.Transcribe.Whisper.Task = "translate" },
+			wantErr: false,
+		},
+		{
+			name:    "invalid whisper task",
+			modify:  func(c *Config) { c.Transcribe.Whisper.Task = "summarize" },
+			wantErr: true,
+		},
+		{
+			name:    "whisper temperature in range",
+			modify:  func(c *Config) { c.Transcribe.Whisper.Temperature, c.Transcribe.Whisper.TemperatureInc = 1, 0 },
+			wantErr: false,
+		},
+		{
+			name:    "negative whisper temperature",
+			modify:  func(c *Config) { c.Transcribe.Whisper.Temperature = -0.1 },
+			wantErr: true,
+		},
+		{
+			name:    "whisper temperature above 1",
+			modify:  func(c *Config) { c.Transcribe.Whisper.Temperature = 1.5 },
+			wantErr: true,
+		},
+		{
+			name:    "negative whisper temperature_inc",
+			modify:  func(c *Config) { c.Transcribe.Whisper.TemperatureInc = -0.2 },
+			wantErr: true,
+		},
+		{
+			name:    "whisper temperature_inc above 1",
+			modify:  func(c *Config) { c.Transcribe.Whisper.TemperatureInc = 2 },
+			wantErr: true,
+		},
+		{
+			name:    "echo inject method",
+			modify:  func(c *Config) { c.Inject.Method = "echo" },
+			wantErr: false,
+		},
+		{
+			name:    "clipboard inject method",
+			modify:  func(c *Config) { c.Inject.Method = "clipboard" },
+			wantErr: false,
+		},
+		{
+			name: "clipboard inject method with streaming",
+			modify: func(c *Config) {
+				c.Inject.Method = "clipboard"
+				c.Transcribe.Streaming.Enabled = true
+			},
+			wantErr: true,
+		},
 		{
 			name:    "invalid inject method",
 			modify:  func(c *Config) { c.Inject.Method = "invalid" },
@@ -142,6 +477,16 @@ func TestValidate(t *testing.T) {
 			modify:  func(c *Config) { c.Hotkey.Keys = nil },
 			wantErr: true,
 		},
+		{
+			name:    "valid hotkey keys",
+			modify:  func(c *Config) { c.Hotkey.Keys = []string{"cmd", "alt", "space"} },
+			wantErr: false,
+		},
+		{
+			name:    "unknown hotkey key",
+			modify:  func(c *Config) { c.Hotkey.Keys = []string{"ctrl", "shfit", "r"} },
+			wantErr: true,
+		},
 		{
 			name:    "zero sample rate",
 			modify:  func(c *Config) { c.Audio.SampleRate = 0 },
@@ -152,6 +497,21 @@ func TestValidate(t *testing.T) {
 			modify:  func(c *Config) { c.Audio.Channels = 0 },
 			wantErr: true,
 		},
+		{
+			name:    "invalid audio format",
+			modify:  func(c *Config) { c.Audio.Format = "s24" },
+			wantErr: true,
+		},
+		{
+			name:    "invalid log format",
+			modify:  func(c *Config) { c.LogFormat = "xml" },
+			wantErr: true,
+		},
+		{
+			name:    "json log format",
+			modify:  func(c *Config) { c.LogFormat = "json" },
+			wantErr: false,
+		},
 		{
 			name:    "invalid log level",
 			modify:  func(c *Config) { c.LogLevel = "invalid" },
@@ -176,6 +536,79 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestMarshalEffectiveRoundTrip(t *testing.T) {
+	secret := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+	yamlContent := `
+model_path: /old/model.bin
+transcribe:
+  backend: whisper
+  whisper:
+    hot_words: [goroutine]
+inject:
+  method: ble
+  ble:
+    device_mac: "AA:BB:CC:DD:EE:FF"
+    shared_secret: "` + secret + `"
+    devices:
+      - device_mac: "11:22:33:44:55:66"
+        shared_secret: "` + other + `"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	data, err := cfg.MarshalEffective()
+	if err != nil {
+		t.Fatalf("MarshalEffective() error = %v", err)
+	}
+	out := string(data)
+	if strings.Contains(out, secret) || strings.Contains(out, other) {
+		t.Errorf("output leaks a shared secret:\n%s", out)
+	}
+	if strings.HasPrefix(out, "model_path:") || strings.Contains(out, "\nmodel_path:") {
+		t.Errorf("output contains deprecated top-level model_path:\n%s", out)
+	}
+	if cfg.Inject.BLE.SharedSecret != secret || cfg.Inject.BLE.Devices[0].SharedSecret != other {
+		t.Error("MarshalEffective() modified the original config")
+	}
+
+	printedPath := filepath.Join(tmpDir, "printed.yaml")
+	if err := os.WriteFile(printedPath, data, 0644); err != nil {
+		t.Fatalf("failed to write printed config: %v", err)
+	}
+	got, err := Load(printedPath)
+	if err != nil {
+		t.Fatalf("Load(printed) error = %v", err)
+	}
+
+	want := *cfg
+	want.Inject.BLE.SharedSecret = redactSecret(secret)
+	want.Inject.BLE.Devices = []BLEDevice{{DeviceMAC: "11:22:33:44:55:66", SharedSecret: redactSecret(other)}}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("re-parsed config differs:\ngot  %+v\nwant %+v", *got, want)
+	}
+}
+
+func TestRedactSecret(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"abc", "(redacted)"},
+		{strings.Repeat("ab", 32), "abab...(redacted)"},
+	}
+	for _, tt := range tests {
+		if got := redactSecret(tt.in); got != tt.want {
+			t.Errorf("redactSecret(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestWriteDefault_CreatesFile(t *testing.T) {
 	// Use a temp dir as fake home to avoid touching real config
 	tmpHome := t.TempDir()
@@ -206,6 +639,17 @@ func TestWriteDefault_CreatesFile(t *testing.T) {
 		t.Error("written config should start with header comment")
 	}
 
+	// Key options should be documented inline
+	for _, want := range []string{
+		"# Backend: \"whisper\"",
+		"  # \"hold\" = record while held",
+		"# \"type\" = keystrokes",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("written config missing documentation %q", want)
+		}
+	}
+
 	// Should be valid YAML that parses into a Config
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
@@ -392,6 +836,10 @@ inject:
     shared_secret: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
     queue_size: 32
     reconnect_max: 15
+    reconnect_jitter: true
+    ack_timeout_ms: 500
+    inter_chunk_delay_ms: 50
+    chunk_mode: fixed
 `
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.yaml")
@@ -419,6 +867,18 @@ inject:
 	if cfg.Inject.BLE.ReconnectMax != 15 {
 		t.Errorf("Inject.BLE.ReconnectMax = %d, want 15", cfg.Inject.BLE.ReconnectMax)
 	}
+	if !cfg.Inject.BLE.ReconnectJitter {
+		t.Error("Inject.BLE.ReconnectJitter = false, want true")
+	}
+	if cfg.Inject.BLE.AckTimeoutMs != 500 {
+		t.Errorf("Inject.BLE.AckTimeoutMs = %d, want 500", cfg.Inject.BLE.AckTimeoutMs)
+	}
+	if cfg.Inject.BLE.InterChunkDelayMs != 50 {
+		t.Errorf("Inject.BLE.InterChunkDelayMs = %d, want 50", cfg.Inject.BLE.InterChunkDelayMs)
+	}
+	if cfg.Inject.BLE.ChunkMode != "fixed" {
+		t.Errorf("Inject.BLE.ChunkMode = %q, want %q", cfg.Inject.BLE.ChunkMode, "fixed")
+	}
 }
 
 func TestValidateBLEMethodRequiresPairing(t *testing.T) {
@@ -442,40 +902,1126 @@ func TestValidateBLEMethodWithPairing(t *testing.T) {
 	}
 }
 
-func TestValidateBLEBadSharedSecretTooShort(t *testing.T) {
+func TestValidateBLEAppListsUnsupported(t *testing.T) {
 	cfg := Default()
 	cfg.Inject.Method = "ble"
 	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
-	cfg.Inject.BLE.SharedSecret = "not-hex"
-	err := cfg.Validate()
-	if err == nil {
-		t.Error("Validate() should fail for too-short shared_secret")
+	cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	cfg.Inject.AppDenylist = []string{"Terminal"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail when app_denylist is set with method=ble")
 	}
 }
 
-func TestValidateBLEBadSharedSecretInvalidHex(t *testing.T) {
-	cfg := Default()
-	cfg.Inject.Method = "ble"
-	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
-	cfg.Inject.BLE.SharedSecret = strings.Repeat("zz", 32) // 64 chars, but 'z' is invalid hex
-	err := cfg.Validate()
-	if err == nil {
-		t.Error("Validate() should fail for invalid hex shared_secret")
+func TestLoadAppLists(t *testing.T) {
+	yamlContent := `
+inject:
+  method: type
+  app_allowlist: [Notes, Slack]
+  app_denylist:
+    - Terminal
+    - 1Password
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
 	}
-}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"Notes", "Slack"}; !reflect.DeepEqual(cfg.Inject.AppAllowlist, want) {
+		t.Errorf("Inject.AppAllowlist = %v, want %v", cfg.Inject.AppAllowlist, want)
+	}
+	if want := []string{"Terminal", "1Password"}; !reflect.DeepEqual(cfg.Inject.AppDenylist, want) {
+		t.Errorf("Inject.AppDenylist = %v, want %v", cfg.Inject.AppDenylist, want)
+	}
+}
+
+func TestLoadNormalizeUnicode(t *testing.T) {
+	yamlContent := `
+inject:
+  method: ble
+  normalize_unicode: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Inject.NormalizeUnicode {
+		t.Error("Inject.NormalizeUnicode = false, want true")
+	}
+	if Default().Inject.NormalizeUnicode {
+		t.Error("Default().Inject.NormalizeUnicode = true, want off by default")
+	}
+}
+
+func TestLoadParakeetBlankIDZero(t *testing.T) {
+	yamlContent := `
+transcribe:
+  parakeet_blank_id: 0
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// 0 is a real token index, distinct from leaving the setting unset.
+	if id := cfg.Transcribe.ParakeetBlankID; id == nil || *id != 0 {
+		t.Errorf("Transcribe.ParakeetBlankID = %v, want 0", id)
+	}
+	if Default().Transcribe.ParakeetBlankID != nil {
+		t.Error("Default().Transcribe.ParakeetBlankID is set, want unset (detect from vocab)")
+	}
+}
+
+func TestValidateBLEBadSharedSecretTooShort(t *testing.T) {
+	cfg := Default()
+	cfg.Inject.Method = "ble"
+	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+	cfg.Inject.BLE.SharedSecret = "not-hex"
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Validate() should fail for too-short shared_secret")
+	}
+}
+
+func TestValidateBLEBadSharedSecretInvalidHex(t *testing.T) {
+	cfg := Default()
+	cfg.Inject.Method = "ble"
+	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+	cfg.Inject.BLE.SharedSecret = strings.Repeat("zz", 32) // 64 chars, but 'z' is invalid hex
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Validate() should fail for invalid hex shared_secret")
+	}
+}
+
+func TestValidateBLEVerifyMAC(t *testing.T) {
+	for _, mode := range []string{"", "warn", "fail"} {
+		cfg := Default()
+		cfg.Inject.Method = "ble"
+		cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+		cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		cfg.Inject.BLE.VerifyMAC = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with verify_mac=%q unexpected error: %v", mode, err)
+		}
+	}
+
+	cfg := Default()
+	cfg.Inject.Method = "ble"
+	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+	cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	cfg.Inject.BLE.VerifyMAC = "strict"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail for unknown verify_mac mode")
+	}
+}
+
+func TestValidateBLEVerifyMACNeedsMAC(t *testing.T) {
+	const uuid = "5F2C1B7E-9A3D-4C8E-B1F0-2D6A8E4C3B19"
+	tests := []struct {
+		name        string
+		deviceMAC   string
+		hardwareMAC string
+		verify      string
+		wantErr     bool
+	}{
+		{name: "uuid without verify", deviceMAC: uuid},
+		{name: "uuid with verify", deviceMAC: uuid, verify: "fail", wantErr: true},
+		{name: "uuid with hardware MAC", deviceMAC: uuid, hardwareMAC: "AA:BB:CC:DD:EE:FF", verify: "fail"},
+		{name: "MAC device_mac", deviceMAC: "AA:BB:CC:DD:EE:FF", verify: "warn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Inject.Method = "ble"
+			cfg.Inject.BLE.DeviceMAC = tt.deviceMAC
+			cfg.Inject.BLE.HardwareMAC = tt.hardwareMAC
+			cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+			cfg.Inject.BLE.VerifyMAC = tt.verify
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBLEFlushPolicy(t *testing.T) {
+	for _, policy := range []string{"", "all", "latest", "drop"} {
+		cfg := Default()
+		cfg.Inject.Method = "ble"
+		cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+		cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		cfg.Inject.BLE.FlushPolicy = policy
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with flush_policy=%q unexpected error: %v", policy, err)
+		}
+	}
+
+	cfg := Default()
+	cfg.Inject.Method = "ble"
+	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+	cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	cfg.Inject.BLE.FlushPolicy = "newest"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail for unknown flush_policy")
+	}
+}
+
+func TestValidateBLENonceMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		noPersist bool
+		wantErr   bool
+	}{
+		{name: "default", mode: ""},
+		{name: "random", mode: "random"},
+		{name: "counter", mode: "counter"},
+		{name: "random_no_persist", mode: "random", noPersist: true},
+		{name: "counter_no_persist", mode: "counter", noPersist: true, wantErr: true},
+		{name: "unknown", mode: "sequential", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Inject.Method = "ble"
+			cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+			cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+			cfg.Inject.BLE.NonceMode = tt.mode
+			cfg.Inject.BLE.DisablePacketPersist = tt.noPersist
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBLEAckTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		ms      int
+		wantErr bool
+	}{{0, false}, {500, false}, {-1, true}} {
+		cfg := Default()
+		cfg.Inject.Method = "ble"
+		cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+		cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		cfg.Inject.BLE.AckTimeoutMs = tt.ms
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ack_timeout_ms=%d: Validate() error = %v, wantErr %v", tt.ms, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBLEInterChunkDelay(t *testing.T) {
+	for _, tt := range []struct {
+		ms      int
+		wantErr bool
+	}{{0, false}, {50, false}, {-1, true}} {
+		cfg := Default()
+		cfg.Inject.Method = "ble"
+		cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+		cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		cfg.Inject.BLE.InterChunkDelayMs = tt.ms
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("inter_chunk_delay_ms=%d: Validate() error = %v, wantErr %v", tt.ms, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBLEChunkMode(t *testing.T) {
+	for _, tt := range []struct {
+		mode    string
+		wantErr bool
+	}{{"", false}, {"word", false}, {"fixed", false}, {"bytes", true}} {
+		cfg := Default()
+		cfg.Inject.Method = "ble"
+		cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+		cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+		cfg.Inject.BLE.ChunkMode = tt.mode
+		err := cfg.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("chunk_mode=%q: Validate() error = %v, wantErr %v", tt.mode, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateBLERSSI(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		warn     int
+		wantErr  bool
+	}{
+		{name: "off", interval: 0, warn: 0},
+		{name: "enabled", interval: 30, warn: -75},
+		{name: "negative interval", interval: -1, wantErr: true},
+		{name: "positive threshold", interval: 30, warn: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Inject.Method = "ble"
+			cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+			cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+			cfg.Inject.BLE.RSSIInterval = tt.interval
+			cfg.Inject.BLE.RSSIWarn = tt.warn
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadBLEDevices(t *testing.T) {
+	yamlContent := `
+inject:
+  method: ble
+  ble:
+    devices:
+      - device_mac: "AA:BB:CC:DD:EE:01"
+        shared_secret: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+      - device_mac: "AA:BB:CC:DD:EE:02"
+        shared_secret: "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	devices := cfg.Inject.BLE.DeviceList()
+	if len(devices) != 2 {
+		t.Fatalf("DeviceList() len = %d, want 2", len(devices))
+	}
+	if devices[0].DeviceMAC != "AA:BB:CC:DD:EE:01" || devices[1].DeviceMAC != "AA:BB:CC:DD:EE:02" {
+		t.Errorf("DeviceList() MACs = %q, %q", devices[0].DeviceMAC, devices[1].DeviceMAC)
+	}
+}
+
+func TestBLEDeviceList(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name string
+		ble  BLEConfig
+		want []string // MACs
+	}{
+		{name: "none", ble: BLEConfig{}, want: nil},
+		{
+			name: "single device normalized",
+			ble:  BLEConfig{DeviceMAC: "AA:BB:CC:DD:EE:FF", SharedSecret: secret},
+			want: []string{"AA:BB:CC:DD:EE:FF"},
+		},
+		{
+			name: "list only",
+			ble: BLEConfig{Devices: []BLEDevice{
+				{DeviceMAC: "AA:BB:CC:DD:EE:01", SharedSecret: secret},
+				{DeviceMAC: "AA:BB:CC:DD:EE:02", SharedSecret: secret},
+			}},
+			want: []string{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"},
+		},
+		{
+			name: "single plus list, duplicate dropped",
+			ble: BLEConfig{
+				DeviceMAC:    "AA:BB:CC:DD:EE:01",
+				SharedSecret: secret,
+				Devices: []BLEDevice{
+					{DeviceMAC: "aa:bb:cc:dd:ee:01", SharedSecret: secret},
+					{DeviceMAC: "AA:BB:CC:DD:EE:02", SharedSecret: secret},
+				},
+			},
+			want: []string{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ble.DeviceList()
+			if len(got) != len(tt.want) {
+				t.Fatalf("DeviceList() = %v, want MACs %v", got, tt.want)
+			}
+			for i, d := range got {
+				if d.DeviceMAC != tt.want[i] {
+					t.Errorf("DeviceList()[%d].DeviceMAC = %q, want %q", i, d.DeviceMAC, tt.want[i])
+				}
+			}
+		})
+	}
+
+	top := BLEConfig{DeviceMAC: "5F2C1B7E-9A3D-4C8E-B1F0-2D6A8E4C3B19", HardwareMAC: "AA:BB:CC:DD:EE:FF", SharedSecret: secret}
+	if got := top.DeviceList()[0].HardwareMAC; got != "AA:BB:CC:DD:EE:FF" {
+		t.Errorf("DeviceList()[0].HardwareMAC = %q, want the top-level hardware_mac", got)
+	}
+}
+
+func TestValidateBLEDevices(t *testing.T) {
+	const secret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name    string
+		modify  func(*BLEConfig)
+		wantErr bool
+	}{
+		{
+			name: "two devices",
+			modify: func(b *BLEConfig) {
+				b.Devices = []BLEDevice{
+					{DeviceMAC: "AA:BB:CC:DD:EE:01", SharedSecret: secret},
+					{DeviceMAC: "AA:BB:CC:DD:EE:02", SharedSecret: secret},
+				}
+			},
+		},
+		{
+			name: "top-level device plus list",
+			modify: func(b *BLEConfig) {
+				b.DeviceMAC = "AA:BB:CC:DD:EE:01"
+				b.SharedSecret = secret
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:02", SharedSecret: secret}}
+			},
+		},
+		{
+			name: "list entry missing secret",
+			modify: func(b *BLEConfig) {
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:01"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "list entry bad hex",
+			modify: func(b *BLEConfig) {
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:01", SharedSecret: strings.Repeat("zz", 32)}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate MAC in list",
+			modify: func(b *BLEConfig) {
+				b.Devices = []BLEDevice{
+					{DeviceMAC: "AA:BB:CC:DD:EE:01", SharedSecret: secret},
+					{DeviceMAC: "aa:bb:cc:dd:ee:01", SharedSecret: secret},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "hardware MAC",
+			modify: func(b *BLEConfig) {
+				b.DeviceMAC = "5F2C1B7E-9A3D-4C8E-B1F0-2D6A8E4C3B19"
+				b.HardwareMAC = "AA:BB:CC:DD:EE:01"
+				b.SharedSecret = secret
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:02", HardwareMAC: "aa:bb:cc:dd:ee:02", SharedSecret: secret}}
+			},
+		},
+		{
+			name: "malformed hardware MAC",
+			modify: func(b *BLEConfig) {
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:01", HardwareMAC: "AA-BB-CC-DD-EE-01", SharedSecret: secret}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "top-level secret without MAC",
+			modify: func(b *BLEConfig) {
+				b.SharedSecret = secret
+				b.Devices = []BLEDevice{{DeviceMAC: "AA:BB:CC:DD:EE:02", SharedSecret: secret}}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			cfg.Inject.Method = "ble"
+			tt.modify(&cfg.Inject.BLE)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
 
 func TestBLEConfigDefaults(t *testing.T) {
 	cfg := Default()
 	if cfg.Inject.BLE.QueueSize != 0 {
 		t.Errorf("default BLE.QueueSize = %d, want 0 (will use runtime default)", cfg.Inject.BLE.QueueSize)
 	}
-}
+	if cfg.Inject.BLE.DisablePacketPersist {
+		t.Error("default BLE.DisablePacketPersist should be false (persistence on)")
+	}
+}
+
+func TestLoadConfigWithoutBLESection(t *testing.T) {
+	// Backward compat: configs without inject.ble should still load fine
+	yamlContent := `
+inject:
+  method: type
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Inject.Method != "type" {
+		t.Errorf("Inject.Method = %q, want %q", cfg.Inject.Method, "type")
+	}
+	if cfg.Inject.BLE.DeviceMAC != "" {
+		t.Errorf("Inject.BLE.DeviceMAC = %q, want empty", cfg.Inject.BLE.DeviceMAC)
+	}
+}
+
+func TestValidateStreamingWithParakeetFails(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Backend = "parakeet"
+	cfg.Transcribe.ParakeetModelDir = "/some/dir"
+	cfg.Transcribe.Streaming.Enabled = true
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Validate() should fail when streaming enabled with parakeet backend")
+	}
+}
+
+func TestValidateStreamingWithBLEFails(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Streaming.Enabled = true
+	cfg.Inject.Method = "ble"
+	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
+	cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Validate() should fail when streaming enabled with BLE injection")
+	}
+}
+
+func TestValidateStreamingWithEchoFails(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Streaming.Enabled = true
+	cfg.Inject.Method = "echo"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail when streaming enabled with echo injection")
+	}
+}
+
+func TestValidateStreamingStepExceedsLength(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Streaming.Enabled = true
+	cfg.Transcribe.Streaming.StepMs = 15000
+	cfg.Transcribe.Streaming.LengthMs = 10000
+	err := cfg.Validate()
+	if err == nil {
+		t.Error("Validate() should fail when step_ms > length_ms")
+	}
+}
+
+func TestValidateStreamingKeepClamped(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Streaming.Enabled = true
+	cfg.Transcribe.Streaming.KeepMs = 5000
+	cfg.Transcribe.Streaming.StepMs = 3000
+	err := cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	if cfg.Transcribe.Streaming.KeepMs != cfg.Transcribe.Streaming.StepMs {
+		t.Errorf("KeepMs = %d, want %d (clamped to StepMs)", cfg.Transcribe.Streaming.KeepMs, cfg.Transcribe.Streaming.StepMs)
+	}
+}
+
+func TestValidateStreamingValidConfig(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Streaming.Enabled = true
+	err := cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() unexpected error for valid streaming config: %v", err)
+	}
+}
+
+func TestDefaultStreamingConfig(t *testing.T) {
+	cfg := Default()
+	sc := cfg.Transcribe.Streaming
+	if sc.Enabled {
+		t.Error("default streaming should be disabled")
+	}
+	if sc.StepMs != 3000 {
+		t.Errorf("StepMs = %d, want 3000", sc.StepMs)
+	}
+	if sc.LengthMs != 10000 {
+		t.Errorf("LengthMs = %d, want 10000", sc.LengthMs)
+	}
+	if sc.KeepMs != 200 {
+		t.Errorf("KeepMs = %d, want 200", sc.KeepMs)
+	}
+}
+
+func TestLoadStreamingConfig(t *testing.T) {
+	yamlContent := `
+transcribe:
+  streaming:
+    enabled: true
+    step_ms: 2000
+    length_ms: 8000
+    keep_ms: 500
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !cfg.Transcribe.Streaming.Enabled {
+		t.Error("Streaming.Enabled should be true")
+	}
+	if cfg.Transcribe.Streaming.StepMs != 2000 {
+		t.Errorf("StepMs = %d, want 2000", cfg.Transcribe.Streaming.StepMs)
+	}
+	if cfg.Transcribe.Streaming.LengthMs != 8000 {
+		t.Errorf("LengthMs = %d, want 8000", cfg.Transcribe.Streaming.LengthMs)
+	}
+	if cfg.Transcribe.Streaming.KeepMs != 500 {
+		t.Errorf("KeepMs = %d, want 500", cfg.Transcribe.Streaming.KeepMs)
+	}
+}
+
+func TestLoadNormalizeNumbers(t *testing.T) {
+	if Default().Transcribe.NormalizeNumbers {
+		t.Error("default normalize_numbers should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  normalize_numbers: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.NormalizeNumbers {
+		t.Error("Transcribe.NormalizeNumbers should be true")
+	}
+}
+
+func TestLoadSpokenControls(t *testing.T) {
+	if Default().Transcribe.SpokenControls {
+		t.Error("default spoken_controls should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  spoken_controls: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.SpokenControls {
+		t.Error("Transcribe.SpokenControls should be true")
+	}
+}
+
+func TestLoadParakeetComputeUnits(t *testing.T) {
+	yamlContent := `
+transcribe:
+  backend: parakeet
+  parakeet:
+    compute_units: cpu_only
+    predict_timeout_ms: 30000
+    max_repeats: 4
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.Parakeet.ComputeUnits != "cpu_only" {
+		t.Errorf("Transcribe.Parakeet.ComputeUnits = %q, want %q", cfg.Transcribe.Parakeet.ComputeUnits, "cpu_only")
+	}
+	if cfg.Transcribe.Parakeet.PredictTimeoutMs != 30000 {
+		t.Errorf("Transcribe.Parakeet.PredictTimeoutMs = %d, want 30000", cfg.Transcribe.Parakeet.PredictTimeoutMs)
+	}
+	if cfg.Transcribe.Parakeet.MaxRepeats != 4 {
+		t.Errorf("Transcribe.Parakeet.MaxRepeats = %d, want 4", cfg.Transcribe.Parakeet.MaxRepeats)
+	}
+}
+
+func TestLoadMinConfidence(t *testing.T) {
+	if Default().Transcribe.MinConfidence != 0 {
+		t.Error("default min_confidence should be 0 (off)")
+	}
+
+	yamlContent := `
+transcribe:
+  min_confidence: 0.6
+  confidence_beep: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.MinConfidence != 0.6 {
+		t.Errorf("Transcribe.MinConfidence = %v, want 0.6", cfg.Transcribe.MinConfidence)
+	}
+	if !cfg.Transcribe.ConfidenceBeep {
+		t.Error("Transcribe.ConfidenceBeep should be true")
+	}
+}
+
+func TestLoadWarmup(t *testing.T) {
+	if Default().Transcribe.Warmup {
+		t.Error("default warmup should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  warmup: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.Warmup {
+		t.Error("Transcribe.Warmup should be true")
+	}
+}
+
+func TestLoadJournalPath(t *testing.T) {
+	yamlContent := `
+transcribe:
+  journal_path: ~/notes/dictation.log
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	home, _ := os.UserHomeDir()
+	want := filepath.Join(home, "notes", "dictation.log")
+	if cfg.Transcribe.JournalPath != want {
+		t.Errorf("JournalPath = %q, want %q", cfg.Transcribe.JournalPath, want)
+	}
+}
+
+func TestLoadAutoDownload(t *testing.T) {
+	if Default().Transcribe.AutoDownload {
+		t.Error("default auto_download should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  auto_download: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.AutoDownload {
+		t.Error("Transcribe.AutoDownload should be true")
+	}
+}
+
+func TestLoadWhisperPrompt(t *testing.T) {
+	d := Default()
+	if d.Transcribe.Whisper.InitialPrompt != "" || len(d.Transcribe.Whisper.HotWords) != 0 {
+		t.Errorf("default whisper prompt should be empty, got %+v", d.Transcribe.Whisper)
+	}
+
+	yamlContent := `
+transcribe:
+  whisper:
+    initial_prompt: "Go code review."
+    hot_words: ["goroutine", "gofmt"]
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.Whisper.InitialPrompt != "Go code review." {
+		t.Errorf("InitialPrompt = %q, want %q", cfg.Transcribe.Whisper.InitialPrompt, "Go code review.")
+	}
+	if len(cfg.Transcribe.Whisper.HotWords) != 2 || cfg.Transcribe.Whisper.HotWords[1] != "gofmt" {
+		t.Errorf("HotWords = %v, want [goroutine gofmt]", cfg.Transcribe.Whisper.HotWords)
+	}
+}
+
+func TestLoadWhisperTask(t *testing.T) {
+	yamlContent := `
+transcribe:
+  whisper:
+    task: translate
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.Whisper.Task != "translate" {
+		t.Errorf("Whisper.Task = %q, want %q", cfg.Transcribe.Whisper.Task, "translate")
+	}
+}
+
+func TestLoadWhisperTemperature(t *testing.T) {
+	def := Default()
+	if def.Transcribe.Whisper.Temperature != 0 || def.Transcribe.Whisper.TemperatureInc != 0.2 {
+		t.Errorf("default temperature = %g, temperature_inc = %g, want 0 and 0.2",
+			def.Transcribe.Whisper.Temperature, def.Transcribe.Whisper.TemperatureInc)
+	}
+
+	yamlContent := `
+transcribe:
+  whisper:
+    temperature: 0.3
+    temperature_inc: 0
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.Whisper.Temperature != 0.3 {
+		t.Errorf("Whisper.Temperature = %g, want 0.3", cfg.Transcribe.Whisper.Temperature)
+	}
+	if cfg.Transcribe.Whisper.TemperatureInc != 0 {
+		t.Errorf("Whisper.TemperatureInc = %g, want 0 (retries off)", cfg.Transcribe.Whisper.TemperatureInc)
+	}
+}
+
+func TestLoadWhisperDeterministic(t *testing.T) {
+	if Default().Transcribe.Whisper.Deterministic {
+		t.Error("default deterministic should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  whisper:
+    deterministic: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.Whisper.Deterministic {
+		t.Error("Whisper.Deterministic should be true")
+	}
+}
+
+func TestLoadIMESafe(t *testing.T) {
+	def := Default()
+	if def.Inject.IMESafe || def.Inject.IMECommit {
+		t.Error("default ime_safe and ime_commit should be false")
+	}
+
+	yamlContent := `
+inject:
+  method: type
+  ime_safe: true
+  ime_commit: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Inject.IMESafe {
+		t.Error("Inject.IMESafe should be true")
+	}
+	if !cfg.Inject.IMECommit {
+		t.Error("Inject.IMECommit should be true")
+	}
+}
+
+func TestLoadRTFWarn(t *testing.T) {
+	if got := Default().Transcribe.RTFWarn; got != 1.0 {
+		t.Errorf("default rtf_warn = %v, want 1.0", got)
+	}
+
+	yamlContent := `
+transcribe:
+  rtf_warn: 0.5
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.RTFWarn != 0.5 {
+		t.Errorf("Transcribe.RTFWarn = %v, want 0.5", cfg.Transcribe.RTFWarn)
+	}
+}
+
+func TestLoadStripAnnotations(t *testing.T) {
+	if Default().Transcribe.StripAnnotations {
+		t.Error("default transcribe.strip_annotations should be false")
+	}
+
+	yamlContent := `
+transcribe:
+  strip_annotations: true
+  annotation_patterns: ["crosstalk", ".*music.*"]
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.StripAnnotations {
+		t.Error("Transcribe.StripAnnotations should be true")
+	}
+	if want := []string{"crosstalk", ".*music.*"}; !slices.Equal(cfg.Transcribe.AnnotationPatterns, want) {
+		t.Errorf("Transcribe.AnnotationPatterns = %v, want %v", cfg.Transcribe.AnnotationPatterns, want)
+	}
+}
+
+func TestLoadPipeline(t *testing.T) {
+	yamlContent := `
+transcribe:
+  pipeline: [trim, replacements, numbers, punctuate, capitalize]
+  replacements:
+    - from: go lang
+      to: Go
+  replacement_fuzziness: 2
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{"trim", "replacements", "numbers", "punctuate", "capitalize"}
+	if !slices.Equal(cfg.Transcribe.Pipeline, want) {
+		t.Errorf("Transcribe.Pipeline = %v, want %v", cfg.Transcribe.Pipeline, want)
+	}
+	if len(cfg.Transcribe.Replacements) != 1 || cfg.Transcribe.Replacements[0] != (Replacement{From: "go lang", To: "Go"}) {
+		t.Errorf("Transcribe.Replacements = %v, want [{go lang Go}]", cfg.Transcribe.Replacements)
+	}
+	if cfg.Transcribe.ReplacementFuzziness != 2 {
+		t.Errorf("Transcribe.ReplacementFuzziness = %d, want 2", cfg.Transcribe.ReplacementFuzziness)
+	}
+}
+
+func TestLoadMaxConcurrent(t *testing.T) {
+	if got := Default().Transcribe.MaxConcurrent; got != 0 {
+		t.Errorf("default max_concurrent = %d, want 0", got)
+	}
+
+	yamlContent := `
+transcribe:
+  max_concurrent: 1
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Transcribe.MaxConcurrent != 1 {
+		t.Errorf("Transcribe.MaxConcurrent = %d, want 1", cfg.Transcribe.MaxConcurrent)
+	}
+}
+
+func TestLoadLogFormat(t *testing.T) {
+	if got := Default().LogFormat; got != "text" {
+		t.Errorf("default log_format = %q, want %q", got, "text")
+	}
+
+	yamlContent := `
+log_format: json
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogFormat != "json" {
+		t.Errorf("LogFormat = %q, want %q", cfg.LogFormat, "json")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadAudioTrimSilence(t *testing.T) {
+	if Default().Audio.TrimSilence {
+		t.Error("default audio.trim_silence should be false")
+	}
+
+	yamlContent := `
+audio:
+  trim_silence: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Audio.TrimSilence {
+		t.Error("Audio.TrimSilence should be true")
+	}
+}
+
+func TestLoadAudioNormalize(t *testing.T) {
+	if Default().Audio.Normalize {
+		t.Error("default audio.normalize should be false")
+	}
+
+	yamlContent := `
+audio:
+  normalize: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Audio.Normalize {
+		t.Error("Audio.Normalize should be true")
+	}
+	if cfg.Audio.SampleRate != 16000 {
+		t.Errorf("Audio.SampleRate = %d, want default 16000", cfg.Audio.SampleRate)
+	}
+}
+
+func TestLoadAudioFormat(t *testing.T) {
+	if got := Default().Audio.Format; got != "f32" {
+		t.Errorf("default audio.format = %q, want \"f32\"", got)
+	}
 
-func TestLoadConfigWithoutBLESection(t *testing.T) {
-	// Backward compat: configs without inject.ble should still load fine
 	yamlContent := `
-inject:
-  method: type
+audio:
+  format: s16
 `
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.yaml")
@@ -487,96 +2033,71 @@ inject:
 	if err != nil {
 		t.Fatalf("Load() error = %v", err)
 	}
-	if cfg.Inject.Method != "type" {
-		t.Errorf("Inject.Method = %q, want %q", cfg.Inject.Method, "type")
-	}
-	if cfg.Inject.BLE.DeviceMAC != "" {
-		t.Errorf("Inject.BLE.DeviceMAC = %q, want empty", cfg.Inject.BLE.DeviceMAC)
-	}
-}
-
-func TestValidateStreamingWithParakeetFails(t *testing.T) {
-	cfg := Default()
-	cfg.Transcribe.Backend = "parakeet"
-	cfg.Transcribe.ParakeetModelDir = "/some/dir"
-	cfg.Transcribe.Streaming.Enabled = true
-	err := cfg.Validate()
-	if err == nil {
-		t.Error("Validate() should fail when streaming enabled with parakeet backend")
+	if cfg.Audio.Format != "s16" {
+		t.Errorf("Audio.Format = %q, want \"s16\"", cfg.Audio.Format)
 	}
 }
 
-func TestValidateStreamingWithBLEFails(t *testing.T) {
-	cfg := Default()
-	cfg.Transcribe.Streaming.Enabled = true
-	cfg.Inject.Method = "ble"
-	cfg.Inject.BLE.DeviceMAC = "AA:BB:CC:DD:EE:FF"
-	cfg.Inject.BLE.SharedSecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
-	err := cfg.Validate()
-	if err == nil {
-		t.Error("Validate() should fail when streaming enabled with BLE injection")
+func TestLoadAudioPersistent(t *testing.T) {
+	if Default().Audio.Persistent {
+		t.Error("default audio.persistent_device should be false")
 	}
-}
 
-func TestValidateStreamingStepExceedsLength(t *testing.T) {
-	cfg := Default()
-	cfg.Transcribe.Streaming.Enabled = true
-	cfg.Transcribe.Streaming.StepMs = 15000
-	cfg.Transcribe.Streaming.LengthMs = 10000
-	err := cfg.Validate()
-	if err == nil {
-		t.Error("Validate() should fail when step_ms > length_ms")
+	yamlContent := `
+audio:
+  persistent_device: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
 	}
-}
 
-func TestValidateStreamingKeepClamped(t *testing.T) {
-	cfg := Default()
-	cfg.Transcribe.Streaming.Enabled = true
-	cfg.Transcribe.Streaming.KeepMs = 5000
-	cfg.Transcribe.Streaming.StepMs = 3000
-	err := cfg.Validate()
+	cfg, err := Load(cfgPath)
 	if err != nil {
-		t.Errorf("Validate() unexpected error: %v", err)
+		t.Fatalf("Load() error = %v", err)
 	}
-	if cfg.Transcribe.Streaming.KeepMs != cfg.Transcribe.Streaming.StepMs {
-		t.Errorf("KeepMs = %d, want %d (clamped to StepMs)", cfg.Transcribe.Streaming.KeepMs, cfg.Transcribe.Streaming.StepMs)
+	if !cfg.Audio.Persistent {
+		t.Error("Audio.Persistent should be true")
 	}
 }
 
-func TestValidateStreamingValidConfig(t *testing.T) {
-	cfg := Default()
-	cfg.Transcribe.Streaming.Enabled = true
-	err := cfg.Validate()
-	if err != nil {
-		t.Errorf("Validate() unexpected error for valid streaming config: %v", err)
+func TestLoadMetrics(t *testing.T) {
+	if Default().Metrics.Enabled {
+		t.Error("default metrics should be disabled")
 	}
-}
 
-func TestDefaultStreamingConfig(t *testing.T) {
-	cfg := Default()
-	sc := cfg.Transcribe.Streaming
-	if sc.Enabled {
-		t.Error("default streaming should be disabled")
+	yamlContent := `
+metrics:
+  enabled: true
+  addr: ":9100"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
 	}
-	if sc.StepMs != 3000 {
-		t.Errorf("StepMs = %d, want 3000", sc.StepMs)
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
 	}
-	if sc.LengthMs != 10000 {
-		t.Errorf("LengthMs = %d, want 10000", sc.LengthMs)
+	if !cfg.Metrics.Enabled {
+		t.Error("Metrics.Enabled should be true")
 	}
-	if sc.KeepMs != 200 {
-		t.Errorf("KeepMs = %d, want 200", sc.KeepMs)
+	if cfg.Metrics.Addr != ":9100" {
+		t.Errorf("Metrics.Addr = %q, want %q", cfg.Metrics.Addr, ":9100")
 	}
 }
 
-func TestLoadStreamingConfig(t *testing.T) {
+func TestLoadNotifications(t *testing.T) {
+	if Default().Notifications.Enabled {
+		t.Error("default notifications should be disabled")
+	}
+
 	yamlContent := `
-transcribe:
-  streaming:
-    enabled: true
-    step_ms: 2000
-    length_ms: 8000
-    keep_ms: 500
+notifications:
+  enabled: true
 `
 	tmpDir := t.TempDir()
 	cfgPath := filepath.Join(tmpDir, "config.yaml")
@@ -588,18 +2109,8 @@ transcribe:
 	if err != nil {
 		t.Fatalf("Load() error = %v", err)
 	}
-
-	if !cfg.Transcribe.Streaming.Enabled {
-		t.Error("Streaming.Enabled should be true")
-	}
-	if cfg.Transcribe.Streaming.StepMs != 2000 {
-		t.Errorf("StepMs = %d, want 2000", cfg.Transcribe.Streaming.StepMs)
-	}
-	if cfg.Transcribe.Streaming.LengthMs != 8000 {
-		t.Errorf("LengthMs = %d, want 8000", cfg.Transcribe.Streaming.LengthMs)
-	}
-	if cfg.Transcribe.Streaming.KeepMs != 500 {
-		t.Errorf("KeepMs = %d, want 500", cfg.Transcribe.Streaming.KeepMs)
+	if !cfg.Notifications.Enabled {
+		t.Error("Notifications.Enabled should be true")
 	}
 }
 
@@ -795,3 +2306,234 @@ func TestResolveModelPathFallback(t *testing.T) {
 		t.Errorf("resolveModelPath() = %q, want %q (configured fallthrough)", result, "/nonexistent/a.bin")
 	}
 }
+
+func TestCheckModelFilesWhisper(t *testing.T) {
+	tmpDir := t.TempDir()
+	modelPath := filepath.Join(tmpDir, "ggml-base.en.bin")
+
+	cfg := Default()
+	cfg.Transcribe.Backend = "whisper"
+	cfg.Transcribe.ModelPath = modelPath
+
+	err := CheckModelFiles(cfg)
+	if err == nil {
+		t.Fatal("CheckModelFiles() should fail when whisper model is missing")
+	}
+	for _, want := range []string{modelPath, "task whisper-model"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	if err := os.WriteFile(modelPath, []byte("model"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckModelFiles(cfg); err != nil {
+		t.Errorf("CheckModelFiles() error = %v, want nil", err)
+	}
+}
+
+func TestCheckModelFilesMock(t *testing.T) {
+	cfg := Default()
+	cfg.Transcribe.Backend = "mock"
+	cfg.Transcribe.MockText = "hello"
+	cfg.Transcribe.ModelPath = "/nonexistent/model.bin"
+	if err := CheckModelFiles(cfg); err != nil {
+		t.Errorf("CheckModelFiles() error = %v, want nil (mock needs no model)", err)
+	}
+}
+
+func TestCheckModelFilesParakeet(t *testing.T) {
+	modelDir := t.TempDir()
+	cfg := Default()
+	cfg.Transcribe.Backend = "parakeet"
+	cfg.Transcribe.ParakeetModelDir = modelDir
+
+	// Only the encoder and vocab are present.
+	if err := os.MkdirAll(filepath.Join(modelDir, "Encoder.mlmodelc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modelDir, "parakeet_vocab.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckModelFiles(cfg)
+	if err == nil {
+		t.Fatal("CheckModelFiles() should fail when parakeet models are missing")
+	}
+	msg := err.Error()
+	for _, want := range []string{"Preprocessor.mlmodelc", "Decoder.mlmodelc", "JointDecision.mlmodelc", "task parakeet-model"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error should mention %q, got:\n%s", want, msg)
+		}
+	}
+	for _, present := range []string{"Encoder.mlmodelc", "parakeet_vocab.json"} {
+		if strings.Contains(msg, present) {
+			t.Errorf("error should not mention present file %q, got:\n%s", present, msg)
+		}
+	}
+
+	for _, name := range []string{"Preprocessor.mlmodelc", "Decoder.mlmodelc", "JointDecision.mlmodelc"} {
+		if err := os.MkdirAll(filepath.Join(modelDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CheckModelFiles(cfg); err != nil {
+		t.Errorf("CheckModelFiles() error = %v, want nil", err)
+	}
+}
+
+func TestLoadWhisperDetectLanguage(t *testing.T) {
+	if Default().Transcribe.Whisper.DetectLanguage {
+		t.Error("default DetectLanguage = true, want false")
+	}
+
+	yamlContent := `
+transcribe:
+  whisper:
+    detect_language: true
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.Transcribe.Whisper.DetectLanguage {
+		t.Error("Whisper.DetectLanguage = false, want true")
+	}
+}
+
+func TestLoadInjectTrailing(t *testing.T) {
+	if got := Default().Inject.Trailing; got != "none" {
+		t.Errorf("default trailing = %q, want none", got)
+	}
+
+	yamlContent := `
+inject:
+  trailing: space
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Inject.Trailing != "space" {
+		t.Errorf("Inject.Trailing = %q, want space", cfg.Inject.Trailing)
+	}
+}
+
+func TestLoadAudioMaxDuration(t *testing.T) {
+	if got := Default().Audio.MaxDurationSecs; got != 120 {
+		t.Errorf("default max_duration_secs = %d, want 120", got)
+	}
+
+	yamlContent := `
+audio:
+  max_duration_secs: 600
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Audio.MaxDurationSecs != 600 {
+		t.Errorf("Audio.MaxDurationSecs = %d, want 600", cfg.Audio.MaxDurationSecs)
+	}
+}
+
+func TestLoadAudioSilenceAutoStop(t *testing.T) {
+	yamlContent := `
+audio:
+  silence_auto_stop_s: 2.5
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Audio.SilenceAutoStopSecs != 2.5 {
+		t.Errorf("Audio.SilenceAutoStopSecs = %g, want 2.5", cfg.Audio.SilenceAutoStopSecs)
+	}
+}
+
+func TestLoadAudioPeriods(t *testing.T) {
+	yamlContent := `
+audio:
+  period_size_frames: 320
+  periods: 4
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Audio.PeriodSizeFrames != 320 || cfg.Audio.Periods != 4 {
+		t.Errorf("Audio periods = %d x %d frames, want 4 x 320", cfg.Audio.Periods, cfg.Audio.PeriodSizeFrames)
+	}
+}
+
+func TestLoadModelSelection(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	yamlContent := `
+transcribe:
+  model_selection:
+    - max_secs: 4
+      model_path: ~/models/ggml-tiny.en.bin
+    - max_secs: 12.5
+      model_path: /opt/models/ggml-small.en.bin
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []ModelRule{
+		{MaxSecs: 4, ModelPath: filepath.Join(home, "models/ggml-tiny.en.bin")},
+		{MaxSecs: 12.5, ModelPath: "/opt/models/ggml-small.en.bin"},
+	}
+	if !slices.Equal(cfg.Transcribe.ModelSelection, want) {
+		t.Errorf("Transcribe.ModelSelection = %v, want %v", cfg.Transcribe.ModelSelection, want)
+	}
+}
+
+func TestValidateHotkeyKeySuggestion(t *testing.T) {
+	cfg := Default()
+	cfg.Hotkey.Keys = []string{"ctrl", "shfit", "r"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `did you mean "shift"`) {
+		t.Errorf("Validate() error = %v, want a suggestion of \"shift\"", err)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigHeader starts every config file written by WriteDefault.
+const defaultConfigHeader = "# gostt-writer configuration\n# See config.example.yaml for every option and its documentation.\n\n"
+
+// fieldDocs holds the comment written above each key of the default config,
+// by dotted path. Keys without an entry are written uncommented.
+var fieldDocs = map[string]string{
+	"version": "Config schema version. Older files are upgraded automatically on startup.",
+
+	"transcribe":                    "Transcription backend settings",
+	"transcribe.backend":            "Backend: \"whisper\" (whisper.cpp, CPU/GPU) or \"parakeet\" (CoreML, Apple Neural Engine)",
+	"transcribe.fallback_backend":   "Backend to try if the primary one fails to load (\"\" = no fallback)",
+	"transcribe.model_path":         "Path to the whisper.cpp model in ggml format (whisper backend only)",
+	"transcribe.parakeet_model_dir": "Directory with the Parakeet CoreML models and vocabulary (parakeet backend only)",
+	"transcribe.streaming":          "Real-time streaming: text appears while you speak (whisper only)",
+	"transcribe.whisper":            "Whisper decoding settings",
+	"transcribe.parakeet":           "Parakeet CoreML settings",
+	"transcribe.normalize_numbers":  "Convert spoken numbers to digits, e.g. \"twenty five\" -> \"25\"",
+	"transcribe.spoken_controls":    "Type \"new line\" / \"tab\" as Enter / Tab",
+	"transcribe.warmup":             "Run one transcription on silence after loading the model",
+	"transcribe.auto_download":      "Download a missing model at startup (--yes skips the prompt)",
+	"transcribe.journal_path":       "Append every transcript, timestamped, to this file (\"\" = off)",
+	"transcribe.min_confidence":     "Skip transcripts below this confidence, 0-1 (0 = off, whisper only)",
+
+	"hotkey":                   "Hotkey settings",
+	"hotkey.keys":              "Key combination; modifiers: ctrl, shift, alt/option, cmd",
+	"hotkey.mode":              "\"hold\" = record while held, \"toggle\" = press to start/stop,\n\"hybrid\" = quick tap toggles, long press records while held",
+	"hotkey.debounce_ms":       "Ignore a start within this many ms of the previous stop (0 = off)",
+	"hotkey.hold_threshold_ms": "hybrid mode: presses longer than this record while held",
+
+	"audio":                   "Audio capture settings (whisper and parakeet expect 16kHz mono)",
+	"audio.persistent_device": "Keep the microphone open between recordings for a faster start",
+	"audio.format":            "Capture sample format: \"f32\", \"s16\", or \"u8\"",
+	"audio.max_duration_secs": "Longest recording kept; audio beyond this is dropped",
+
+	"inject":          "Text injection settings",
+	"inject.method":   "\"type\" = keystrokes, \"paste\" = clipboard + Cmd+V, \"clipboard\" = clipboard only, \"ble\" = ESP32 over Bluetooth, \"echo\" = stdout",
+	"inject.trailing": "Appended after each transcript: \"none\", \"space\", or \"newline\"",
+
+	"rewrite":         "Optional LLM rewrite through a local Ollama instance",
+	"rewrite.enabled": "Send transcribed text to the LLM before injecting it",
+
+	"metrics":         "Prometheus metrics endpoint",
+	"metrics.enabled": "Serve /metrics on addr",
+
+	"notifications":         "Desktop notifications",
+	"notifications.enabled": "Show a macOS notification when transcription or injection fails",
+
+	"log_level":  "Log level: debug, info, warn, error",
+	"log_format": "Log format: \"text\" or \"json\"",
+}
+
+// marshalDocumented encodes c as YAML with the fieldDocs comments above each
+// documented key.
+func marshalDocumented(c *Config) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	annotate(&doc, "")
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// annotate sets HeadComment on the keys of mapping node n (and nested
+// mappings) from fieldDocs, with prefix as the dotted path of n.
+func annotate(n *yaml.Node, prefix string) {
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		if doc, ok := fieldDocs[path]; ok {
+			key.HeadComment = doc
+		}
+		annotate(val, path)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config schema version written by this build. Bump it
+// and append to migrations when a change needs existing files rewritten.
+const CurrentVersion = 1
+
+// migrations[v] upgrades a config document's top-level mapping from version
+// v to v+1. A file without a version field is version 0.
+var migrations = []func(root *yaml.Node){
+	migrateV0,
+}
+
+// migrateV0 moves the deprecated top-level model_path into the transcribe
+// section, unless transcribe.model_path is already set.
+func migrateV0(root *yaml.Node) {
+	modelPath := removeKey(root, "model_path")
+	if modelPath == nil {
+		return
+	}
+	transcribe := mappingValue(root, "transcribe")
+	if transcribe == nil || transcribe.Kind != yaml.MappingNode {
+		transcribe = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		removeKey(root, "transcribe")
+		root.Content = append(root.Content, scalarNode("transcribe"), transcribe)
+	}
+	if mappingValue(transcribe, "model_path") == nil {
+		transcribe.Content = append(transcribe.Content, scalarNode("model_path"), modelPath)
+	}
+}
+
+// migrate upgrades a parsed config document in place to CurrentVersion and
+// returns the version it started from. Documents from a newer version are
+// left untouched. Empty documents have nothing to migrate.
+func migrate(doc *yaml.Node) (int, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return CurrentVersion, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return 0, fmt.Errorf("config must be a YAML mapping")
+	}
+
+	from := 0
+	if v := mappingValue(root, "version"); v != nil {
+		if err := v.Decode(&from); err != nil {
+			return 0, fmt.Errorf("invalid version: %w", err)
+		}
+	}
+	if from >= CurrentVersion {
+		return from, nil
+	}
+
+	for v := from; v < CurrentVersion; v++ {
+		migrations[v](root)
+	}
+	removeKey(root, "version")
+	root.Content = append([]*yaml.Node{scalarNode("version"), scalarNode(fmt.Sprint(CurrentVersion))}, root.Content...)
+	return from, nil
+}
+
+// writeMigrated saves the original file contents to a backup next to path,
+// then overwrites path with the migrated document. It returns the backup path.
+func writeMigrated(path string, original []byte, doc *yaml.Node, from int) (string, error) {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm() // keep permissions: the file may hold the BLE key
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, original, perm); err != nil {
+		return "", fmt.Errorf("writing backup: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("encoding migrated config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encoding migrated config: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), perm); err != nil {
+		return "", fmt.Errorf("writing migrated config: %w", err)
+	}
+	return backup, nil
+}
+
+// mappingValue returns the value node for key in mapping m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeKey deletes key from mapping m and returns its value node, or nil if
+// the key wasn't present.
+func removeKey(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return v
+		}
+	}
+	return nil
+}
+
+// scalarNode returns a plain scalar node holding s.
+func scalarNode(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: s}
+}
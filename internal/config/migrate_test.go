@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadMigratesV0(t *testing.T) {
+	original := `# my settings
+model_path: /custom/whisper.bin
+hotkey:
+  mode: toggle # push to start, push to stop
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(original), 0600); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+	}
+	if cfg.Transcribe.ModelPath != "/custom/whisper.bin" {
+		t.Errorf("Transcribe.ModelPath = %q, want %q", cfg.Transcribe.ModelPath, "/custom/whisper.bin")
+	}
+	if cfg.Hotkey.Mode != "toggle" {
+		t.Errorf("Hotkey.Mode = %q, want %q", cfg.Hotkey.Mode, "toggle")
+	}
+
+	// The original is backed up unchanged.
+	backup, err := os.ReadFile(cfgPath + ".v0.bak")
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup = %q, want original %q", backup, original)
+	}
+
+	// The rewritten file is at the current version, keeps comments, and
+	// loads without migrating again.
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("reading migrated config: %v", err)
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("parsing migrated config: %v", err)
+	}
+	if raw["version"] != CurrentVersion {
+		t.Errorf("migrated version = %v, want %d", raw["version"], CurrentVersion)
+	}
+	if _, ok := raw["model_path"]; ok {
+		t.Error("migrated config still has top-level model_path")
+	}
+	transcribe, _ := raw["transcribe"].(map[string]any)
+	if transcribe["model_path"] != "/custom/whisper.bin" {
+		t.Errorf("migrated transcribe.model_path = %v, want /custom/whisper.bin", transcribe["model_path"])
+	}
+	if !strings.Contains(string(data), "# push to start, push to stop") {
+		t.Errorf("migrated config lost comments:\n%s", data)
+	}
+
+	info, err := os.Stat(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("migrated config mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := os.Remove(cfgPath + ".v0.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(cfgPath); err != nil {
+		t.Fatalf("Load() of migrated config error = %v", err)
+	}
+	if _, err := os.Stat(cfgPath + ".v0.bak"); !os.IsNotExist(err) {
+		t.Error("loading a current config should not write a backup")
+	}
+}
+
+func TestMigrateV0(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "moves model_path",
+			in:   "model_path: /a.bin\n",
+			want: "version: 1\ntranscribe:\n  model_path: /a.bin\n",
+		},
+		{
+			name: "into existing transcribe section",
+			in:   "model_path: /a.bin\ntranscribe:\n  backend: whisper\n",
+			want: "version: 1\ntranscribe:\n  backend: whisper\n  model_path: /a.bin\n",
+		},
+		{
+			name: "transcribe.model_path wins",
+			in:   "model_path: /a.bin\ntranscribe:\n  model_path: /b.bin\n",
+			want: "version: 1\ntranscribe:\n  model_path: /b.bin\n",
+		},
+		{
+			name: "no deprecated keys",
+			in:   "log_level: debug\n",
+			want: "version: 1\nlog_level: debug\n",
+		},
+		{
+			name: "explicit version 0",
+			in:   "version: 0\nlog_level: debug\n",
+			want: "version: 1\nlog_level: debug\n",
+		},
+		{
+			name: "current version untouched",
+			in:   "version: 1\nmodel_path: /a.bin\n",
+			want: "version: 1\nmodel_path: /a.bin\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.in), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := migrate(&doc); err != nil {
+				t.Fatalf("migrate() error = %v", err)
+			}
+			var sb strings.Builder
+			enc := yaml.NewEncoder(&sb)
+			enc.SetIndent(2)
+			if err := enc.Encode(&doc); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("migrated =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	for _, in := range []string{"version: two\n", "- a list\n"} {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(in), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := migrate(&doc); err == nil {
+			t.Errorf("migrate(%q) should fail", in)
+		}
+	}
+}
+
+func TestLoadNewerVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	content := "version: 99\nlog_level: debug\n"
+	if err := os.WriteFile(cfgPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("LogLevel = %q, want debug", cfg.LogLevel)
+	}
+	data, _ := os.ReadFile(cfgPath)
+	if string(data) != content {
+		t.Errorf("newer config was rewritten:\n%s", data)
+	}
+}
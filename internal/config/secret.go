@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+
+	blecrypto "github.com/chaz8081/gostt-writer/internal/ble/crypto"
+)
+
+// Prefixes for shared_secret values that refer to a secret stored elsewhere
+// instead of holding the hex key itself.
+const (
+	secretEnvPrefix      = "env:"      // env:GOSTT_BLE_SECRET
+	secretKeychainPrefix = "keychain:" // keychain:gostt-writer (macOS only)
+	secretSealedPrefix   = "enc:"      // enc:<base64 of blecrypto.SealSecret output>
+)
+
+// PassphraseEnv names the environment variable holding the passphrase for
+// enc: secrets. Without it, the passphrase is read from the terminal.
+const PassphraseEnv = "GOSTT_PASSPHRASE"
+
+// passphraseLookup returns the passphrase for enc: secrets. Replaced in tests.
+var passphraseLookup = readPassphrase
+
+// promptPassphrase asks for the passphrase on the terminal at most once per
+// process, so several enc: secrets need only one prompt.
+// The passphrase isn't echoed.
+var promptPassphrase = sync.OnceValues(func() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no terminal to prompt on; set %s", PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, "Passphrase for BLE shared secret: ")
+	pass, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr) // the user's Enter wasn't echoed either
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	return string(pass), nil
+})
+
+// readPassphrase returns $GOSTT_PASSPHRASE, or prompts for the passphrase.
+func readPassphrase() (string, error) {
+	if p, ok := os.LookupEnv(PassphraseEnv); ok && p != "" {
+		return p, nil
+	}
+	return promptPassphrase()
+}
+
+// SealSharedSecret encrypts a hex shared secret with passphrase and returns
+// the "enc:..." value to put in shared_secret.
+func SealSharedSecret(passphrase, secretHex string) (string, error) {
+	sealed, err := blecrypto.SealSecret([]byte(passphrase), []byte(secretHex))
+	if err != nil {
+		return "", err
+	}
+	return secretSealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSealedSecret decrypts the base64 payload of an enc: secret.
+func openSealedSecret(payload string) (string, error) {
+	if payload == "" {
+		return "", errors.New("missing sealed secret")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("decoding sealed secret: %w", err)
+	}
+	passphrase, err := passphraseLookup()
+	if err != nil {
+		return "", fmt.Errorf("passphrase: %w", err)
+	}
+	secret, err := blecrypto.OpenSecret([]byte(passphrase), sealed)
+	if err != nil {
+		return "", err
+	}
+	if _, err := hex.DecodeString(string(secret)); err != nil {
+		return "", errors.New("sealed secret is not a hex key")
+	}
+	return string(secret), nil
+}
+
+// keychainLookup reads a generic password from the login keychain by service
+// name. Replaced in tests.
+var keychainLookup = keychainSecret
+
+// resolveSecret returns the secret a shared_secret value refers to:
+// "env:NAME" reads environment variable NAME, "keychain:SERVICE" reads the
+// macOS keychain item for SERVICE, "enc:DATA" decrypts DATA with the
+// passphrase (see SealSharedSecret), and anything else is returned unchanged
+// as a literal key.
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		if name == "" {
+			return "", fmt.Errorf("%q: missing environment variable name", value)
+		}
+		secret, ok := os.LookupEnv(name)
+		if !ok || strings.TrimSpace(secret) == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return strings.TrimSpace(secret), nil
+	case strings.HasPrefix(value, secretKeychainPrefix):
+		service := strings.TrimPrefix(value, secretKeychainPrefix)
+		if service == "" {
+			return "", fmt.Errorf("%q: missing keychain service name", value)
+		}
+		secret, err := keychainLookup(service)
+		if err != nil {
+			return "", fmt.Errorf("keychain item %q: %w", service, err)
+		}
+		return strings.TrimSpace(secret), nil
+	case strings.HasPrefix(value, secretSealedPrefix):
+		return openSealedSecret(strings.TrimPrefix(value, secretSealedPrefix))
+	}
+	return value, nil
+}
+
+// resolveBLESecrets replaces secret references in the BLE settings with the
+// secrets themselves. It only runs for the ble method, so an unset variable
+// doesn't stop other methods from loading.
+func resolveBLESecrets(cfg *Config) error {
+	if cfg.Inject.Method != "ble" {
+		return nil
+	}
+
+	secret, err := resolveSecret(cfg.Inject.BLE.SharedSecret)
+	if err != nil {
+		return fmt.Errorf("inject.ble.shared_secret: %w", err)
+	}
+	cfg.Inject.BLE.SharedSecret = secret
+
+	for i := range cfg.Inject.BLE.Devices {
+		secret, err := resolveSecret(cfg.Inject.BLE.Devices[i].SharedSecret)
+		if err != nil {
+			return fmt.Errorf("inject.ble.devices[%d].shared_secret: %w", i, err)
+		}
+		cfg.Inject.BLE.Devices[i].SharedSecret = secret
+	}
+	return nil
+}
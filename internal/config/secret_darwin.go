@@ -0,0 +1,26 @@
+//go:build darwin
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainSecret reads the password of the generic keychain item for service
+// using the security CLI. The item can be created with:
+//
+//	security add-generic-password -s gostt-writer -a "$USER" -w <hex>
+func keychainSecret(service string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("security: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("security: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,11 @@
+//go:build !darwin
+
+package config
+
+import "errors"
+
+// keychainSecret reads a secret from the macOS keychain. Other platforms
+// have no keychain; use an env: reference instead.
+func keychainSecret(service string) (string, error) {
+	return "", errors.New("keychain secrets are only supported on macOS")
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	blecrypto "github.com/chaz8081/gostt-writer/internal/ble/crypto"
+)
+
+func TestResolveSecret(t *testing.T) {
+	hexKey := strings.Repeat("ab", 32)
+	t.Setenv("GOSTT_TEST_BLE_SECRET", " "+hexKey+"\n")
+	t.Setenv("GOSTT_TEST_EMPTY_SECRET", "")
+
+	origLookup := keychainLookup
+	t.Cleanup(func() { keychainLookup = origLookup })
+	keychainLookup = func(service string) (string, error) {
+		if service == "gostt-writer" {
+			return hexKey + "\n", nil
+		}
+		return "", errors.New("item not found")
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "literal", value: hexKey, want: hexKey},
+		{name: "empty", value: "", want: ""},
+		{name: "env", value: "env:GOSTT_TEST_BLE_SECRET", want: hexKey},
+		{name: "env_unset", value: "env:GOSTT_TEST_UNSET_SECRET", wantErr: true},
+		{name: "env_empty", value: "env:GOSTT_TEST_EMPTY_SECRET", wantErr: true},
+		{name: "env_no_name", value: "env:", wantErr: true},
+		{name: "keychain", value: "keychain:gostt-writer", want: hexKey},
+		{name: "keychain_missing", value: "keychain:other", wantErr: true},
+		{name: "keychain_no_service", value: "keychain:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSecret(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSecret(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSecret(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadBLESecretFromEnv(t *testing.T) {
+	secret := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+	t.Setenv("GOSTT_TEST_BLE_SECRET", secret)
+
+	yamlContent := `
+inject:
+  method: ble
+  ble:
+    device_mac: "AA:BB:CC:DD:EE:FF"
+    shared_secret: "env:GOSTT_TEST_BLE_SECRET"
+    devices:
+      - device_mac: "11:22:33:44:55:66"
+        shared_secret: "` + other + `"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Inject.BLE.SharedSecret != secret {
+		t.Errorf("Inject.BLE.SharedSecret = %q, want the value of GOSTT_TEST_BLE_SECRET", cfg.Inject.BLE.SharedSecret)
+	}
+	if cfg.Inject.BLE.Devices[0].SharedSecret != other {
+		t.Errorf("Inject.BLE.Devices[0].SharedSecret = %q, want literal %q", cfg.Inject.BLE.Devices[0].SharedSecret, other)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoadBLESecretEnvUnset(t *testing.T) {
+	yamlContent := `
+inject:
+  method: ble
+  ble:
+    device_mac: "AA:BB:CC:DD:EE:FF"
+    shared_secret: "env:GOSTT_TEST_UNSET_SECRET"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	_, err := Load(cfgPath)
+	if err == nil || !strings.Contains(err.Error(), "GOSTT_TEST_UNSET_SECRET") {
+		t.Errorf("Load() error = %v, want one naming the unset variable", err)
+	}
+}
+
+func TestLoadBLESecretIgnoredForOtherMethods(t *testing.T) {
+	yamlContent := `
+inject:
+  method: type
+  ble:
+    shared_secret: "env:GOSTT_TEST_UNSET_SECRET"
+`
+	tmpDir := t.TempDir()
+	cfgPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	if _, err := Load(cfgPath); err != nil {
+		t.Errorf("Load() error = %v, want nil when method is not ble", err)
+	}
+}
+
+func TestResolveSealedSecret(t *testing.T) {
+	hexKey := strings.Repeat("ab", 32)
+	sealed, err := SealSharedSecret("hunter2", hexKey)
+	if err != nil {
+		t.Fatalf("SealSharedSecret() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, "enc:") || strings.Contains(sealed, hexKey) {
+		t.Fatalf("SealSharedSecret() = %q, want an enc: value without the key", sealed)
+	}
+
+	passphrase := "hunter2"
+	origLookup := passphraseLookup
+	t.Cleanup(func() { passphraseLookup = origLookup })
+	passphraseLookup = func() (string, error) { return passphrase, nil }
+
+	got, err := resolveSecret(sealed)
+	if err != nil {
+		t.Fatalf("resolveSecret(sealed) error = %v", err)
+	}
+	if got != hexKey {
+		t.Errorf("resolveSecret(sealed) = %q, want %q", got, hexKey)
+	}
+
+	passphrase = "wrong"
+	if _, err := resolveSecret(sealed); !errors.Is(err, blecrypto.ErrWrongPassphrase) {
+		t.Errorf("resolveSecret(sealed, wrong passphrase) error = %v, want ErrWrongPassphrase", err)
+	}
+
+	for _, bad := range []string{"enc:", "enc:not base64!"} {
+		if _, err := resolveSecret(bad); err == nil {
+			t.Errorf("resolveSecret(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReadPassphraseFromEnv(t *testing.T) {
+	t.Setenv(PassphraseEnv, "from-env")
+	got, err := readPassphrase()
+	if err != nil || got != "from-env" {
+		t.Errorf("readPassphrase() = %q, %v; want %q", got, err, "from-env")
+	}
+}
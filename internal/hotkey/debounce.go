@@ -0,0 +1,41 @@
+package hotkey
+
+import "time"
+
+// Debouncer filters the rapid start/stop pairs produced by a bouncing key or
+// a nervous double-tap. An EventStart arriving within the window after the
+// previous EventStop is suppressed, along with its matching EventStop.
+// A Debouncer is not safe for concurrent use; call it from the event loop.
+type Debouncer struct {
+	window     time.Duration
+	lastStop   time.Time
+	suppressed bool // a start was dropped, so drop its stop too
+}
+
+// NewDebouncer creates a Debouncer with the given window. A window of zero
+// or less disables debouncing.
+func NewDebouncer(window time.Duration) *Debouncer {
+	return &Debouncer{window: window}
+}
+
+// Allow reports whether ev, received at now, should be acted on.
+func (d *Debouncer) Allow(ev Event, now time.Time) bool {
+	if d.window <= 0 {
+		return true
+	}
+
+	switch ev.Type {
+	case EventStart:
+		if !d.lastStop.IsZero() && now.Sub(d.lastStop) < d.window {
+			d.suppressed = true
+			return false
+		}
+	case EventStop:
+		d.lastStop = now
+		if d.suppressed {
+			d.suppressed = false
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package hotkey
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	type step struct {
+		at   time.Duration // offset from t0
+		typ  EventType
+		want bool
+	}
+	tests := []struct {
+		name   string
+		window time.Duration
+		steps  []step
+	}{
+		{
+			name:   "disabled",
+			window: 0,
+			steps: []step{
+				{0, EventStart, true},
+				{10 * time.Millisecond, EventStop, true},
+				{15 * time.Millisecond, EventStart, true},
+				{20 * time.Millisecond, EventStop, true},
+			},
+		},
+		{
+			name:   "first_start_allowed",
+			window: 100 * time.Millisecond,
+			steps: []step{
+				{0, EventStart, true},
+				{2 * time.Second, EventStop, true},
+			},
+		},
+		{
+			name:   "bounce_suppressed_with_its_stop",
+			window: 100 * time.Millisecond,
+			steps: []step{
+				{0, EventStart, true},
+				{time.Second, EventStop, true},
+				{time.Second + 30*time.Millisecond, EventStart, false},
+				{time.Second + 60*time.Millisecond, EventStop, false},
+				{2 * time.Second, EventStart, true},
+				{3 * time.Second, EventStop, true},
+			},
+		},
+		{
+			name:   "chatter_extends_window",
+			window: 100 * time.Millisecond,
+			steps: []step{
+				{0, EventStart, true},
+				{time.Second, EventStop, true},
+				{time.Second + 50*time.Millisecond, EventStart, false},
+				{time.Second + 90*time.Millisecond, EventStop, false},
+				{time.Second + 140*time.Millisecond, EventStart, false},
+				{time.Second + 150*time.Millisecond, EventStop, false},
+				{time.Second + 300*time.Millisecond, EventStart, true},
+			},
+		},
+		{
+			name:   "start_at_window_edge_allowed",
+			window: 100 * time.Millisecond,
+			steps: []step{
+				{0, EventStart, true},
+				{time.Second, EventStop, true},
+				{time.Second + 100*time.Millisecond, EventStart, true},
+			},
+		},
+	}
+
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDebouncer(tt.window)
+			for i, s := range tt.steps {
+				if got := d.Allow(Event{Type: s.typ}, t0.Add(s.at)); got != s.want {
+					t.Errorf("step %d (type %d at %v): Allow() = %v, want %v", i, s.typ, s.at, got, s.want)
+				}
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 // Package hotkey provides a global hotkey listener using gohook.
-// It supports "hold" mode (press to start, release to stop) and
-// "toggle" mode (press to start, press again to stop).
+// It supports "hold" mode (press to start, release to stop),
+// "toggle" mode (press to start, press again to stop) and "hybrid" mode
+// (a tap toggles, a longer press holds).
 package hotkey
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/chaz8081/gostt-writer/internal/hotkey/keyname"
 	hook "github.com/robotn/gohook"
 )
 
@@ -27,24 +31,50 @@ type Event struct {
 // Listener manages a global hotkey and emits start/stop events.
 type Listener struct {
 	keys []string
-	mode string // "hold" or "toggle"
-	ch   chan Event
-	done chan struct{}
-	once sync.Once
+	mode string // "hold", "toggle" or "hybrid"
+	// holdThreshold is the hybrid-mode press length from which a press
+	// holds rather than toggles.
+	holdThreshold time.Duration
+	ch            chan Event
+	done          chan struct{}
+	once          sync.Once
+	// stoppedElsewhere is set by NotifyStopped and consumed by the next
+	// toggle/hybrid key press.
+	stoppedElsewhere atomic.Bool
 }
 
 // NewListener creates a Listener for the given key combo and mode.
-// keys should be lowercase key names (e.g., ["ctrl", "shift", "r"]).
-// mode must be "hold" or "toggle".
+// keys should be lowercase key names (e.g., ["ctrl", "shift", "r"]);
+// shifted symbols such as "!" are registered as their unshifted key.
+// mode must be "hold", "toggle" or "hybrid".
 func NewListener(keys []string, mode string) *Listener {
+	canonical := make([]string, len(keys))
+	for i, k := range keys {
+		canonical[i] = keyname.Canonical(k)
+	}
 	return &Listener{
-		keys: keys,
-		mode: mode,
-		ch:   make(chan Event, 16),
-		done: make(chan struct{}),
+		keys:          canonical,
+		mode:          mode,
+		holdThreshold: DefaultHoldThreshold,
+		ch:            make(chan Event, 16),
+		done:          make(chan struct{}),
 	}
 }
 
+// SetHoldThreshold sets how long a press must last in hybrid mode to act as
+// hold-to-talk; shorter presses toggle. Call before Start.
+func (l *Listener) SetHoldThreshold(d time.Duration) {
+	l.holdThreshold = d
+}
+
+// NotifyStopped tells the listener that recording was stopped by something
+// other than the hotkey (e.g. a silence auto-stop), so the next press in
+// toggle or hybrid mode starts a new recording instead of emitting a stop
+// for the one that already ended. Safe to call from any goroutine.
+func (l *Listener) NotifyStopped() {
+	l.stoppedElsewhere.Store(true)
+}
+
 // Events returns the channel that receives hotkey events.
 // The channel is closed when Stop is called.
 func (l *Listener) Events() <-chan Event {
@@ -57,6 +87,8 @@ func (l *Listener) Start() {
 	switch l.mode {
 	case "toggle":
 		l.startToggle()
+	case "hybrid":
+		l.startHybrid()
 	default: // "hold"
 		l.startHold()
 	}
@@ -97,6 +129,9 @@ func (l *Listener) startToggle() {
 	hook.Register(hook.KeyDown, l.keys, func(e hook.Event) {
 		mu.Lock()
 		defer mu.Unlock()
+		if l.stoppedElsewhere.Swap(false) {
+			recording = false
+		}
 		if recording {
 			select {
 			case l.ch <- Event{Type: EventStop}:
@@ -121,6 +156,46 @@ func (l *Listener) startToggle() {
 	close(l.ch)
 }
 
+// startHybrid implements hybrid mode: a tap (released within the hold
+// threshold) toggles recording like toggle mode, a longer press records only
+// while held like hold mode.
+func (l *Listener) startHybrid() {
+	var mu sync.Mutex
+	press := &hybridPress{threshold: l.holdThreshold}
+	emit := func(ev Event, ok bool) {
+		if !ok {
+			return
+		}
+		select {
+		case l.ch <- ev:
+		default:
+		}
+	}
+
+	hook.Register(hook.KeyDown, l.keys, func(e hook.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if l.stoppedElsewhere.Swap(false) {
+			press.stoppedElsewhere()
+		}
+		emit(press.down(time.Now()))
+	})
+
+	hook.Register(hook.KeyUp, l.keys, func(e hook.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		emit(press.up(time.Now()))
+	})
+
+	evChan := hook.Start()
+	go func() {
+		<-l.done
+		hook.End()
+	}()
+	<-hook.Process(evChan)
+	close(l.ch)
+}
+
 // Stop terminates the hotkey listener.
 // It is safe to call multiple times.
 func (l *Listener) Stop() {
@@ -0,0 +1,60 @@
+package hotkey
+
+import "time"
+
+// DefaultHoldThreshold is how long the hotkey must be held in hybrid mode
+// before releasing it stops recording rather than leaving it running.
+const DefaultHoldThreshold = 400 * time.Millisecond
+
+// hybridPress turns KeyDown/KeyUp pairs into hybrid-mode events: a tap
+// (released before threshold) toggles recording, a longer press records
+// only while the key is held. Not safe for concurrent use.
+type hybridPress struct {
+	threshold time.Duration
+	recording bool
+	pressed   bool      // key is down; repeats are ignored
+	started   bool      // this press started recording
+	downAt    time.Time // when the current press began
+}
+
+// down handles a KeyDown at now and returns the event to emit, if any.
+func (h *hybridPress) down(now time.Time) (Event, bool) {
+	if h.pressed {
+		return Event{}, false // key repeat
+	}
+	h.pressed = true
+	h.downAt = now
+	if h.recording {
+		// A press while toggled on stops recording; its release is a no-op.
+		h.recording = false
+		h.started = false
+		return Event{Type: EventStop}, true
+	}
+	h.recording = true
+	h.started = true
+	return Event{Type: EventStart}, true
+}
+
+// stoppedElsewhere records that recording ended without the hotkey, so the
+// next press starts rather than stops.
+func (h *hybridPress) stoppedElsewhere() {
+	h.recording = false
+	h.started = false
+}
+
+// up handles a KeyUp at now and returns the event to emit, if any.
+func (h *hybridPress) up(now time.Time) (Event, bool) {
+	if !h.pressed {
+		return Event{}, false
+	}
+	h.pressed = false
+	if !h.started {
+		return Event{}, false
+	}
+	h.started = false
+	if now.Sub(h.downAt) < h.threshold {
+		return Event{}, false // tap: stay recording until the next press
+	}
+	h.recording = false
+	return Event{Type: EventStop}, true
+}
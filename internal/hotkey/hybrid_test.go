@@ -0,0 +1,98 @@
+package hotkey
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHybridPress(t *testing.T) {
+	const threshold = 400 * time.Millisecond
+	type step struct {
+		at    time.Duration // offset from t0
+		down  bool          // KeyDown, else KeyUp
+		want  EventType
+		emits bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "long_press_holds",
+			steps: []step{
+				{0, true, EventStart, true},
+				{2 * time.Second, false, EventStop, true},
+			},
+		},
+		{
+			name: "tap_toggles",
+			steps: []step{
+				{0, true, EventStart, true},
+				{100 * time.Millisecond, false, 0, false},
+				{3 * time.Second, true, EventStop, true},
+				{3*time.Second + 80*time.Millisecond, false, 0, false},
+			},
+		},
+		{
+			name: "press_just_at_threshold_holds",
+			steps: []step{
+				{0, true, EventStart, true},
+				{threshold, false, EventStop, true},
+			},
+		},
+		{
+			name: "long_press_stops_toggled_recording",
+			steps: []step{
+				{0, true, EventStart, true},
+				{50 * time.Millisecond, false, 0, false},
+				{time.Second, true, EventStop, true},
+				{3 * time.Second, false, 0, false},
+				{4 * time.Second, true, EventStart, true},
+				{4*time.Second + 100*time.Millisecond, false, 0, false},
+			},
+		},
+		{
+			name: "key_repeat_ignored",
+			steps: []step{
+				{0, true, EventStart, true},
+				{300 * time.Millisecond, true, 0, false},
+				{600 * time.Millisecond, true, 0, false},
+				{time.Second, false, EventStop, true},
+				{time.Second + 10*time.Millisecond, false, 0, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &hybridPress{threshold: threshold}
+			t0 := time.Now()
+			for i, s := range tt.steps {
+				var ev Event
+				var ok bool
+				if s.down {
+					ev, ok = h.down(t0.Add(s.at))
+				} else {
+					ev, ok = h.up(t0.Add(s.at))
+				}
+				if ok != s.emits || (ok && ev.Type != s.want) {
+					t.Errorf("step %d (down=%v at %v) = %v, %v; want %v, %v",
+						i, s.down, s.at, ev.Type, ok, s.want, s.emits)
+				}
+			}
+		})
+	}
+}
+
+func TestHybridPressStoppedElsewhere(t *testing.T) {
+	h := &hybridPress{threshold: 400 * time.Millisecond}
+	t0 := time.Now()
+
+	// Tap to toggle recording on, then something else stops it.
+	h.down(t0)
+	h.up(t0.Add(100 * time.Millisecond))
+	h.stoppedElsewhere()
+
+	if ev, ok := h.down(t0.Add(5 * time.Second)); !ok || ev.Type != EventStart {
+		t.Errorf("press after external stop = (%v, %v), want EventStart", ev.Type, ok)
+	}
+}
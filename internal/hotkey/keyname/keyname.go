@@ -0,0 +1,120 @@
+// Package keyname validates and resolves the key names used in hotkey
+// combinations. It uses gohook's keycode table without importing gohook, so
+// config validation doesn't need cgo.
+package keyname
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/vcaesar/keycode"
+)
+
+// validKeys are the key names gohook recognizes: its keycode table, plus
+// the shifted symbols in keycode.Special, which Canonical maps to their
+// unshifted key. gohook maps any other name to keycode 0, so a hotkey
+// containing one never fires.
+var validKeys = func() []string {
+	keys := slices.Collect(maps.Keys(keycode.Keycode))
+	for k := range keycode.Special {
+		if _, ok := keycode.Keycode[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}()
+
+// keyAliases maps names from other tools and keyboards to the gohook name
+// for the same key, for suggestions that edit distance wouldn't find.
+var keyAliases = map[string]string{
+	"option":    "alt",
+	"opt":       "alt",
+	"meta":      "cmd",
+	"super":     "cmd",
+	"win":       "cmd",
+	"escape":    "esc",
+	"return":    "enter",
+	"backspace": "delete",
+	"spacebar":  "space",
+}
+
+// ValidKeys returns the key names accepted in a hotkey combination.
+func ValidKeys() []string {
+	return slices.Clone(validKeys)
+}
+
+// Canonical returns the name gohook registers for key: shifted symbols
+// such as "!" become their unshifted key ("1"), since gohook's keycode table
+// has no entry for them. Other names are returned unchanged.
+func Canonical(key string) string {
+	if _, ok := keycode.Keycode[key]; ok {
+		return key
+	}
+	if base, ok := keycode.Special[key]; ok {
+		return base
+	}
+	return key
+}
+
+// Validate checks that every name in keys is one gohook recognizes. The
+// error for an unknown name suggests the closest valid one, if any is close.
+func Validate(keys []string) error {
+	for _, k := range keys {
+		if slices.Contains(validKeys, k) {
+			continue
+		}
+		if s := Suggest(k); s != "" {
+			return fmt.Errorf("unknown key %q (did you mean %q?)", k, s)
+		}
+		return fmt.Errorf("unknown key %q (valid keys: %s)", k, strings.Join(validKeys, " "))
+	}
+	return nil
+}
+
+// Suggest returns the valid key name closest to name, or "" if none is
+// within two edits. Aliases and different case are matched first.
+func Suggest(name string) string {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if s, ok := keyAliases[lower]; ok {
+		return s
+	}
+	if slices.Contains(validKeys, lower) {
+		return lower
+	}
+	// Single-character names are all valid or all too far apart to guess.
+	if len([]rune(lower)) < 2 {
+		return ""
+	}
+	best, bestDist := "", 3
+	for _, k := range validKeys {
+		if d := editDistance(lower, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j-1]+cost, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,78 @@
+package keyname
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/vcaesar/keycode"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		wantErr string // substring; "" = valid
+	}{
+		{"default combo", []string{"ctrl", "shift", "r"}, ""},
+		{"mac combo", []string{"cmd", "alt", "space"}, ""},
+		{"function key", []string{"f9"}, ""},
+		{"shifted symbol", []string{"ctrl", "!"}, ""},
+		{"all shifted symbols", []string{"~", "!", "@", "#", "$", "%", "^", "&", "*", "(", ")"}, ""},
+		{"typo", []string{"ctrl", "shfit", "r"}, `unknown key "shfit" (did you mean "shift"?)`},
+		{"alias", []string{"option", "d"}, `unknown key "option" (did you mean "alt"?)`},
+		{"uppercase", []string{"Ctrl", "r"}, `unknown key "Ctrl" (did you mean "ctrl"?)`},
+		{"no suggestion", []string{"ctrl", "hyperdrive"}, `unknown key "hyperdrive" (valid keys:`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.keys)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%v) error = %v, want nil", tt.keys, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%v) error = %v, want %q", tt.keys, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidKeysIsACopy(t *testing.T) {
+	keys := ValidKeys()
+	keys[0] = "changed"
+	if ValidKeys()[0] == "changed" {
+		t.Error("ValidKeys() returned the package's slice")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct{ key, want string }{
+		{"r", "r"},
+		{"ctrl", "ctrl"},
+		{"_", "_"}, // in gohook's table already
+		{"!", "1"},
+		{"~", "`"},
+		{")", "0"},
+		{"hyperdrive", "hyperdrive"},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.key); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestValidKeysMatchGohook(t *testing.T) {
+	keys := ValidKeys()
+	for name := range keycode.Keycode {
+		if !slices.Contains(keys, name) {
+			t.Errorf("ValidKeys() is missing gohook key %q", name)
+		}
+	}
+	if !slices.IsSorted(keys) {
+		t.Error("ValidKeys() is not sorted")
+	}
+}
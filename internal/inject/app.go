@@ -0,0 +1,43 @@
+package inject
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrFrontmostAppUnsupported is returned by FrontmostApp on platforms where
+// the focused application cannot be determined.
+var ErrFrontmostAppUnsupported = errors.New("inject: frontmost application lookup not supported on this platform")
+
+// AllowedForApp reports whether text may be injected into app, the name of
+// the focused application (e.g. "Terminal", "1Password"). Names are compared
+// case-insensitively, ignoring surrounding whitespace.
+//
+// Precedence:
+//   - an app on deny is never allowed, even if it is also on allow;
+//   - an empty allow list allows every app not denied;
+//   - otherwise only apps on allow are allowed.
+//
+// An unknown app ("") is never on either list, so it is allowed only when
+// allow is empty.
+func AllowedForApp(app string, allow, deny []string) bool {
+	app = strings.TrimSpace(app)
+	if app != "" && containsApp(deny, app) {
+		return false
+	}
+	if len(allow) == 0 {
+		return true
+	}
+	return app != "" && containsApp(allow, app)
+}
+
+// containsApp reports whether list holds app, ignoring case and surrounding
+// whitespace.
+func containsApp(list []string, app string) bool {
+	for _, name := range list {
+		if strings.EqualFold(strings.TrimSpace(name), app) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package inject
+
+import "testing"
+
+func TestAllowedForApp(t *testing.T) {
+	tests := []struct {
+		name  string
+		app   string
+		allow []string
+		deny  []string
+		want  bool
+	}{
+		{name: "no_lists", app: "Notes", want: true},
+		{name: "no_lists_unknown_app", app: "", want: true},
+
+		{name: "denied", app: "Terminal", deny: []string{"Terminal", "1Password"}, want: false},
+		{name: "not_denied", app: "Notes", deny: []string{"Terminal", "1Password"}, want: true},
+		{name: "deny_case_insensitive", app: "terminal", deny: []string{"Terminal"}, want: false},
+		{name: "deny_trims_spaces", app: "Terminal", deny: []string{" Terminal "}, want: false},
+		{name: "deny_exact_name_only", app: "Terminal Pro", deny: []string{"Terminal"}, want: true},
+		{name: "deny_only_unknown_app", app: "", deny: []string{"Terminal"}, want: true},
+
+		{name: "allowed", app: "Notes", allow: []string{"Notes", "Slack"}, want: true},
+		{name: "not_allowed", app: "Safari", allow: []string{"Notes", "Slack"}, want: false},
+		{name: "allow_case_insensitive", app: "SLACK", allow: []string{"Slack"}, want: true},
+		{name: "allow_unknown_app", app: "", allow: []string{"Notes"}, want: false},
+		{name: "allow_blank_entry_ignores_unknown_app", app: "", allow: []string{""}, want: false},
+
+		{name: "deny_wins_over_allow", app: "Terminal", allow: []string{"Terminal", "Notes"}, deny: []string{"Terminal"}, want: false},
+		{name: "allow_and_deny_other", app: "Notes", allow: []string{"Notes"}, deny: []string{"Terminal"}, want: true},
+		{name: "allow_and_deny_neither", app: "Safari", allow: []string{"Notes"}, deny: []string{"Terminal"}, want: false},
+		{name: "empty_slices", app: "Notes", allow: []string{}, deny: []string{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AllowedForApp(tt.app, tt.allow, tt.deny); got != tt.want {
+				t.Errorf("AllowedForApp(%q, %q, %q) = %v, want %v", tt.app, tt.allow, tt.deny, got, tt.want)
+			}
+		})
+	}
+}
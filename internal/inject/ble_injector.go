@@ -1,39 +1,151 @@
 package inject
 
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chaz8081/gostt-writer/internal/ble"
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func init() {
+	Register("ble", buildBLE)
+}
+
+// newBLEAdapter returns the adapter BLE clients connect through. Replaced in
+// tests.
+var newBLEAdapter = func() ble.Adapter { return ble.NewCoreBluetoothAdapter() }
+
 // BLESender is the interface the BLE client exposes for sending text.
 type BLESender interface {
 	Send(text string) error
 }
 
-// BLEInjector sends transcribed text over BLE to an ESP32-S3.
+// BLEInjector sends transcribed text over BLE to one or more ESP32-S3
+// receivers. With several receivers, every dictation goes to all of them.
 type BLEInjector struct {
-	sender BLESender
+	senders []BLESender
 }
 
 // Compile-time interface satisfaction check.
 var _ TextInjector = (*BLEInjector)(nil)
 
-// NewBLEInjector creates a BLEInjector backed by the given sender.
-// Panics if sender is nil (programmer error).
-func NewBLEInjector(sender BLESender) *BLEInjector {
-	if sender == nil {
-		panic("inject: NewBLEInjector called with nil sender")
+// NewBLEInjector creates a BLEInjector that broadcasts to the given senders.
+// Panics if senders is empty or contains nil (programmer error).
+func NewBLEInjector(senders []BLESender) *BLEInjector {
+	if len(senders) == 0 {
+		panic("inject: NewBLEInjector called with no senders")
+	}
+	for _, s := range senders {
+		if s == nil {
+			panic("inject: NewBLEInjector called with nil sender")
+		}
 	}
-	return &BLEInjector{sender: sender}
+	return &BLEInjector{senders: senders}
 }
 
-// Inject sends text to the ESP32 via BLE.
+// Inject sends text to every ESP32 via BLE. Sends run concurrently so the
+// receivers type at the same time; a failure on one receiver does not stop
+// the others. The returned error joins the errors of all failed sends.
 func (b *BLEInjector) Inject(text string) error {
 	if text == "" {
 		return nil
 	}
-	return b.sender.Send(text)
+	if len(b.senders) == 1 {
+		return b.senders[0].Send(text)
+	}
+
+	errs := make([]error, len(b.senders))
+	var wg sync.WaitGroup
+	for i, s := range b.senders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.Send(text)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// buildBLE connects to every configured receiver and returns a BLEInjector
+// that broadcasts to them. If any receiver cannot be reached, the clients
+// already connected are closed and the error is returned.
+func buildBLE(cfg config.InjectConfig) (TextInjector, error) {
+	adapter := newBLEAdapter()
+	var senders []BLESender
+	closeAll := func() {
+		for _, s := range senders {
+			_ = s.(*ble.Client).Close()
+		}
+	}
+	for _, dev := range cfg.BLE.DeviceList() {
+		client, err := NewBLEClient(adapter, &cfg.BLE, dev, !cfg.BLE.DisablePacketPersist)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("inject: ble device %s: %w", dev.DeviceMAC, err)
+		}
+		if err := client.Connect(); err != nil {
+			_ = client.Close()
+			closeAll()
+			return nil, fmt.Errorf("inject: ble device %s: %w", dev.DeviceMAC, err)
+		}
+		senders = append(senders, client)
+	}
+	if len(senders) == 0 {
+		return nil, errors.New("inject: no BLE devices configured")
+	}
+	return NewBLEInjector(senders), nil
+}
+
+// NewBLEClient creates an unconnected client for one paired receiver. With
+// persist, the packet number is saved across restarts.
+func NewBLEClient(adapter ble.Adapter, bleCfg *config.BLEConfig, dev config.BLEDevice, persist bool) (*ble.Client, error) {
+	key, err := hex.DecodeString(dev.SharedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shared secret: %w", err)
+	}
+	opts := ble.ClientOptions{
+		QueueSize:       bleCfg.QueueSize,
+		ReconnectMax:    bleCfg.ReconnectMax,
+		VerifyMAC:       bleCfg.VerifyMAC,
+		HardwareMAC:     dev.HardwareMAC,
+		RSSIInterval:    time.Duration(bleCfg.RSSIInterval) * time.Second,
+		RSSIWarn:        bleCfg.RSSIWarn,
+		FlushPolicy:     bleCfg.FlushPolicy,
+		NonceMode:       bleCfg.NonceMode,
+		Jitter:          bleCfg.ReconnectJitter,
+		AckTimeout:      time.Duration(bleCfg.AckTimeoutMs) * time.Millisecond,
+		InterChunkDelay: time.Duration(bleCfg.InterChunkDelayMs) * time.Millisecond,
+		ChunkMode:       bleCfg.ChunkMode,
+	}
+	if persist {
+		opts.PacketNumPath = blePacketNumPath(dev.DeviceMAC)
+	}
+	return ble.NewClient(adapter, dev.DeviceMAC, key, opts)
+}
+
+// blePacketNumPath returns the file that persists the last BLE packet
+// number sent to the device with the given MAC.
+func blePacketNumPath(mac string) string {
+	name := strings.ToLower(strings.ReplaceAll(mac, ":", ""))
+	return filepath.Join(config.DefaultDataDir(), "ble", name+".packetnum")
 }
 
-// Close disconnects the BLE client if the sender supports it.
+// Close disconnects every BLE client whose sender supports it.
 func (b *BLEInjector) Close() error {
-	if closer, ok := b.sender.(interface{ Close() error }); ok {
-		return closer.Close()
+	var errs []error
+	for _, s := range b.senders {
+		if closer, ok := s.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
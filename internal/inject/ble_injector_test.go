@@ -17,7 +17,7 @@ func (m *mockBLESender) Send(text string) error {
 
 func TestBLEInjectorInject(t *testing.T) {
 	mock := &mockBLESender{}
-	inj := NewBLEInjector(mock)
+	inj := NewBLEInjector([]BLESender{mock})
 
 	err := inj.Inject("hello world")
 	if err != nil {
@@ -30,7 +30,7 @@ func TestBLEInjectorInject(t *testing.T) {
 
 func TestBLEInjectorInjectEmpty(t *testing.T) {
 	mock := &mockBLESender{}
-	inj := NewBLEInjector(mock)
+	inj := NewBLEInjector([]BLESender{mock})
 
 	err := inj.Inject("")
 	if err != nil {
@@ -48,19 +48,88 @@ func (e *errBLESender) Send(string) error { return e.err }
 
 func TestBLEInjectorInjectError(t *testing.T) {
 	want := errors.New("ble: disconnected")
-	inj := NewBLEInjector(&errBLESender{err: want})
+	inj := NewBLEInjector([]BLESender{&errBLESender{err: want}})
 	got := inj.Inject("hello")
 	if got != want {
 		t.Errorf("Inject() error = %v, want %v", got, want)
 	}
 }
 
-func TestNewBLEInjectorNilSenderPanics(t *testing.T) {
-	defer func() {
-		r := recover()
-		if r == nil {
-			t.Error("NewBLEInjector(nil) did not panic")
+func TestBLEInjectorBroadcast(t *testing.T) {
+	mocks := []*mockBLESender{{}, {}, {}}
+	senders := make([]BLESender, len(mocks))
+	for i, m := range mocks {
+		senders[i] = m
+	}
+	inj := NewBLEInjector(senders)
+
+	if err := inj.Inject("hello world"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	for i, m := range mocks {
+		if len(m.sent) != 1 || m.sent[0] != "hello world" {
+			t.Errorf("sender %d sent = %v, want [\"hello world\"]", i, m.sent)
 		}
-	}()
-	NewBLEInjector(nil)
+	}
+}
+
+func TestBLEInjectorBroadcastPartialError(t *testing.T) {
+	errA := errors.New("ble: device A disconnected")
+	errB := errors.New("ble: device B write failed")
+	ok := &mockBLESender{}
+	inj := NewBLEInjector([]BLESender{&errBLESender{err: errA}, ok, &errBLESender{err: errB}})
+
+	err := inj.Inject("hello")
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Inject() error = %v, want both %v and %v", err, errA, errB)
+	}
+	if len(ok.sent) != 1 || ok.sent[0] != "hello" {
+		t.Errorf("healthy sender sent = %v, want [\"hello\"]", ok.sent)
+	}
+}
+
+// closingBLESender counts Close calls.
+type closingBLESender struct {
+	mockBLESender
+	closed int
+	err    error
+}
+
+func (c *closingBLESender) Close() error {
+	c.closed++
+	return c.err
+}
+
+func TestBLEInjectorCloseAll(t *testing.T) {
+	want := errors.New("close failed")
+	a := &closingBLESender{}
+	b := &closingBLESender{err: want}
+	inj := NewBLEInjector([]BLESender{a, &mockBLESender{}, b})
+
+	if err := inj.Close(); !errors.Is(err, want) {
+		t.Errorf("Close() error = %v, want %v", err, want)
+	}
+	if a.closed != 1 || b.closed != 1 {
+		t.Errorf("closed = %d, %d; want 1, 1", a.closed, b.closed)
+	}
+}
+
+func TestNewBLEInjectorNilSenderPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		senders []BLESender
+	}{
+		{name: "nil slice", senders: nil},
+		{name: "nil element", senders: []BLESender{&mockBLESender{}, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("NewBLEInjector() did not panic")
+				}
+			}()
+			NewBLEInjector(tt.senders)
+		})
+	}
 }
@@ -0,0 +1,36 @@
+package inject
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// EchoInjector writes each transcription as a line to a writer instead of
+// typing it, for piping dictation into other programs or checking the
+// pipeline without touching the focused app.
+type EchoInjector struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// Compile-time interface satisfaction check.
+var _ TextInjector = (*EchoInjector)(nil)
+
+// NewEchoInjector creates an EchoInjector that writes to w.
+func NewEchoInjector(w io.Writer) *EchoInjector {
+	return &EchoInjector{w: w}
+}
+
+// Inject writes text followed by a newline.
+func (e *EchoInjector) Inject(text string) error {
+	if text == "" {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := fmt.Fprintln(e.w, text); err != nil {
+		return fmt.Errorf("inject: echo: %w", err)
+	}
+	return nil
+}
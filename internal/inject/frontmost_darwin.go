@@ -0,0 +1,42 @@
+//go:build darwin
+
+package inject
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework AppKit
+
+#import <AppKit/AppKit.h>
+#include <stdlib.h>
+#include <string.h>
+
+// frontmostAppName returns the localized name of the frontmost application
+// as a malloc'd string, or NULL if there is none.
+static char *frontmostAppName(void) {
+	@autoreleasepool {
+		NSRunningApplication *app = [[NSWorkspace sharedWorkspace] frontmostApplication];
+		NSString *name = app.localizedName;
+		if (name == nil) {
+			return NULL;
+		}
+		return strdup(name.UTF8String);
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// FrontmostApp returns the name of the application that currently has
+// keyboard focus, as shown in the menu bar (e.g. "Terminal").
+func FrontmostApp() (string, error) {
+	name := C.frontmostAppName()
+	if name == nil {
+		return "", errors.New("inject: no frontmost application")
+	}
+	defer C.free(unsafe.Pointer(name))
+	return C.GoString(name), nil
+}
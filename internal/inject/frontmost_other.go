@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package inject
+
+// FrontmostApp returns the name of the application that currently has
+// keyboard focus. It is only implemented on macOS.
+func FrontmostApp() (string, error) {
+	return "", ErrFrontmostAppUnsupported
+}
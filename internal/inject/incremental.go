@@ -0,0 +1,47 @@
+package inject
+
+import "unicode"
+
+// IncrementalInjector is implemented by injectors that can edit text they
+// typed earlier, which streaming transcription needs when a later window
+// revises words already on screen.
+type IncrementalInjector interface {
+	// InjectIncremental updates text previously injected as prev so that
+	// it reads curr, typing only what changed.
+	InjectIncremental(prev, curr string) error
+}
+
+// Compile-time interface satisfaction check.
+var _ IncrementalInjector = (*Injector)(nil)
+
+// InjectIncremental turns prev into curr on screen. Appends are typed
+// directly; when curr revises earlier text, the changed tail is backspaced
+// and retyped. See incrementalEdit for how the edit is chosen.
+func (inj *Injector) InjectIncremental(prev, curr string) error {
+	backspaces, text := incrementalEdit(prev, curr)
+	return inj.InjectDelta(backspaces, text)
+}
+
+// incrementalEdit returns the backspaces and text that turn prev into curr.
+// For the common append-only case that is just the new suffix, and when curr
+// only drops a tail of prev it is just backspaces. When curr diverges from
+// prev inside a word, the edit backs up to the start of that word so whole
+// words are retyped: patching the middle of a word confuses autocorrect and
+// input methods that treat the word as a unit.
+func incrementalEdit(prev, curr string) (backspaces int, text string) {
+	p, c := []rune(prev), []rune(curr)
+
+	common := 0
+	for common < len(p) && common < len(c) && p[common] == c[common] {
+		common++
+	}
+	if common == len(p) || common == len(c) {
+		// Pure append or pure deletion: no word is patched mid-way.
+		return len(p) - common, string(c[common:])
+	}
+
+	for common > 0 && !unicode.IsSpace(p[common-1]) {
+		common--
+	}
+	return len(p) - common, string(c[common:])
+}
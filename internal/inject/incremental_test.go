@@ -0,0 +1,77 @@
+package inject
+
+import "testing"
+
+func TestIncrementalEdit(t *testing.T) {
+	tests := []struct {
+		name           string
+		prev, curr     string
+		wantBackspaces int
+		wantText       string
+	}{
+		{name: "first text", prev: "", curr: "hello", wantText: "hello"},
+		{name: "append", prev: "hello", curr: "hello world", wantText: " world"},
+		{name: "unchanged", prev: "hello", curr: "hello"},
+		{name: "revise last word", prev: "hello word", curr: "hello world", wantBackspaces: 4, wantText: "world"},
+		{name: "revise at word start", prev: "the cat sat", curr: "the bat sat", wantBackspaces: 7, wantText: "bat sat"},
+		{name: "shrink", prev: "hello world", curr: "hello", wantBackspaces: 6},
+		{name: "revise first word", prev: "abc def", curr: "abd def", wantBackspaces: 7, wantText: "abd def"},
+		{name: "unicode", prev: "café noir", curr: "café noire", wantText: "e"},
+		{name: "unicode revise", prev: "naïve", curr: "naïf", wantBackspaces: 5, wantText: "naïf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backspaces, text := incrementalEdit(tt.prev, tt.curr)
+			if backspaces != tt.wantBackspaces || text != tt.wantText {
+				t.Errorf("incrementalEdit(%q, %q) = %d, %q; want %d, %q",
+					tt.prev, tt.curr, backspaces, text, tt.wantBackspaces, tt.wantText)
+			}
+		})
+	}
+}
+
+// screenKeyboard applies typed text and backspaces to a simulated text
+// field, so a sequence of edits can be checked against the final result.
+type screenKeyboard struct {
+	mockKeyboard
+	screen []rune
+}
+
+func (k *screenKeyboard) Type(text string) {
+	k.screen = append(k.screen, []rune(text)...)
+}
+
+func (k *screenKeyboard) KeyTap(key string, modifiers ...interface{}) error {
+	if key == "backspace" && len(k.screen) > 0 {
+		k.screen = k.screen[:len(k.screen)-1]
+	}
+	return nil
+}
+
+func TestInjectIncrementalSequence(t *testing.T) {
+	kb := &screenKeyboard{}
+	inj := &Injector{method: "type", kb: kb}
+
+	// Partial transcripts as a streaming window would produce them,
+	// including revisions of words already typed.
+	partials := []string{
+		"so the",
+		"so the quick",
+		"so the quick brow",
+		"so the quick brown fox",
+		"so the quick brown fox jumps",
+		"so they quick brown fox jumped",
+		"so the quick brown fox jumped over",
+		"So the quick brown fox jumped over.",
+	}
+	prev := ""
+	for _, curr := range partials {
+		if err := inj.InjectIncremental(prev, curr); err != nil {
+			t.Fatalf("InjectIncremental(%q, %q) error = %v", prev, curr, err)
+		}
+		if got := string(kb.screen); got != curr {
+			t.Fatalf("screen after %q = %q", curr, got)
+		}
+		prev = curr
+	}
+}
@@ -3,11 +3,37 @@
 package inject
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+)
 
-	"github.com/go-vgo/robotgo"
+const (
+	clipboardAttempts   = 3                     // tries per clipboard operation
+	clipboardRetryDelay = 20 * time.Millisecond // pause between tries
+	imeCharDelay        = 30 * time.Millisecond // pause after each character in IME-safe mode
+	imeCommitKey        = "enter"               // commits IME composition
 )
 
+// controlKeys maps control characters in injected text to the key tapped in
+// their place. robotgo.Type's handling of them varies by platform and target
+// app, so they are never typed directly.
+var controlKeys = map[rune]string{
+	'\n': "enter",
+	'\t': "tab",
+}
+
+// ErrClipboardNotSet is returned by paste when the clipboard doesn't hold the
+// text after writing it, e.g. because the write was silently blocked.
+var ErrClipboardNotSet = errors.New("inject: clipboard does not hold the written text")
+
+// ErrNoInputAccess is returned by Inject when keystrokes can't be simulated
+// because there is no display to send them to (see CanSimulateInput).
+var ErrNoInputAccess = errors.New("inject: cannot simulate input: no display access (running over SSH or in a sandbox?)")
+
 // TextInjector is the interface for all injection methods.
 type TextInjector interface {
 	Inject(text string) error
@@ -16,15 +42,49 @@ type TextInjector interface {
 // Compile-time interface satisfaction check.
 var _ TextInjector = (*Injector)(nil)
 
+// InjectorOptions configures optional Injector behavior.
+type InjectorOptions struct {
+	IMESafe   bool // type one character at a time, paced for an active IME
+	IMECommit bool // with IMESafe, press Return after each word to commit composition
+}
+
 // Injector handles typing or pasting text into the active application.
 type Injector struct {
-	method string // "type" or "paste"
+	method string // "type", "paste", or "clipboard"
+	opts   InjectorOptions
+	kb     keyboard              // keystroke and clipboard backend
+	sleep  func(d time.Duration) // replaced in tests
+	// unavailable, if set, is returned by every injection instead of
+	// touching kb: input simulation was found not to work at startup.
+	unavailable error
 }
 
 // NewInjector creates an Injector with the given method.
-// method must be "type" (keystroke simulation) or "paste" (clipboard).
-func NewInjector(method string) *Injector {
-	return &Injector{method: method}
+// method must be "type" (keystroke simulation), "paste" (clipboard and
+// Cmd+V), or "clipboard" (clipboard only, for pasting by hand).
+// If CanSimulateInput reports no display access, the Injector is degraded:
+// each injection logs and returns ErrNoInputAccess instead of crashing.
+func NewInjector(method string, opts InjectorOptions) *Injector {
+	return newInjector(method, opts, robotgoKeyboard{}, CanSimulateInput())
+}
+
+// newInjector creates an Injector using kb, degraded when canSimulate is false.
+func newInjector(method string, opts InjectorOptions, kb keyboard, canSimulate bool) *Injector {
+	inj := &Injector{method: method, opts: opts, kb: kb, sleep: time.Sleep}
+	if !canSimulate {
+		slog.Error("inject: input simulation is unavailable, transcripts will not be injected",
+			"method", method, "error", ErrNoInputAccess)
+		inj.unavailable = ErrNoInputAccess
+	}
+	return inj
+}
+
+// checkAvailable logs and returns the error recorded for a degraded Injector.
+func (inj *Injector) checkAvailable() error {
+	if inj.unavailable != nil {
+		slog.Error("inject: dropping text", "method", inj.method, "error", inj.unavailable)
+	}
+	return inj.unavailable
 }
 
 // Inject sends text to the active application using the configured method.
@@ -32,10 +92,20 @@ func (inj *Injector) Inject(text string) error {
 	if text == "" {
 		return nil
 	}
+	if err := inj.checkAvailable(); err != nil {
+		return err
+	}
 
 	switch inj.method {
 	case "paste":
-		return inj.paste(text)
+		err := inj.paste(text)
+		if errors.Is(err, ErrClipboardNotSet) {
+			slog.Warn("inject: clipboard write was blocked, typing instead")
+			return inj.typeText(text)
+		}
+		return err
+	case "clipboard":
+		return inj.copyToClipboard(text)
 	default: // "type"
 		return inj.typeText(text)
 	}
@@ -45,42 +115,169 @@ func (inj *Injector) Inject(text string) error {
 // the divergent suffix, then type the new text. Used by streaming mode for
 // corrections when the sliding window revises earlier transcription.
 func (inj *Injector) InjectDelta(backspaces int, newText string) error {
+	if err := inj.checkAvailable(); err != nil {
+		return err
+	}
 	for i := 0; i < backspaces; i++ {
-		if err := robotgo.KeyTap("backspace"); err != nil {
+		if err := inj.kb.KeyTap("backspace"); err != nil {
 			return fmt.Errorf("inject: backspace: %w", err)
 		}
 	}
 	if newText != "" {
-		robotgo.Type(newText)
+		return inj.typeText(newText)
 	}
 	return nil
 }
 
 // typeText simulates individual keystrokes. Preserves clipboard contents
-// but is slower for long text.
+// but is slower for long text. Newlines and tabs are sent as Enter and Tab
+// key taps between typed runs of text.
 func (inj *Injector) typeText(text string) error {
-	robotgo.Type(text)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if inj.opts.IMESafe {
+		return inj.typeIMESafe(text)
+	}
+
+	for text != "" {
+		i := strings.IndexFunc(text, isControlKey)
+		if i < 0 {
+			inj.kb.Type(text)
+			return nil
+		}
+		if i > 0 {
+			inj.kb.Type(text[:i])
+		}
+		if err := inj.tapControl(rune(text[i])); err != nil {
+			return err
+		}
+		text = text[i+1:]
+	}
+	return nil
+}
+
+// isControlKey reports whether r is typed as a key tap; see controlKeys.
+func isControlKey(r rune) bool {
+	_, ok := controlKeys[r]
+	return ok
+}
+
+// tapControl taps the key for control character r.
+func (inj *Injector) tapControl(r rune) error {
+	key := controlKeys[r]
+	if err := inj.kb.KeyTap(key); err != nil {
+		return fmt.Errorf("inject: key tap %s: %w", key, err)
+	}
 	return nil
 }
 
+// typeIMESafe types one character at a time with a pause after each, so an
+// active input method editor can keep up with composition. With IMECommit,
+// the composition is committed at the end of each word.
+func (inj *Injector) typeIMESafe(text string) error {
+	composing := false
+	commit := func() error {
+		if !inj.opts.IMECommit || !composing {
+			return nil
+		}
+		composing = false
+		if err := inj.kb.KeyTap(imeCommitKey); err != nil {
+			return fmt.Errorf("inject: commit composition: %w", err)
+		}
+		inj.sleep(imeCharDelay)
+		return nil
+	}
+
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			if err := commit(); err != nil {
+				return err
+			}
+		} else {
+			composing = true
+		}
+		if isControlKey(r) {
+			if err := inj.tapControl(r); err != nil {
+				return err
+			}
+		} else {
+			inj.kb.Type(string(r))
+		}
+		inj.sleep(imeCharDelay)
+	}
+	return commit()
+}
+
 // paste copies text to clipboard and pastes it with Cmd+V.
-// Faster for long text but overwrites the clipboard.
+// Faster for long text but overwrites the clipboard. It returns
+// ErrClipboardNotSet, without pasting, if the clipboard doesn't hold text
+// after the write. A paste that the target app ignores (e.g. a secure text
+// field) can't be detected.
 func (inj *Injector) paste(text string) error {
-	// Save current clipboard
-	prev, _ := robotgo.ReadAll()
+	// Save current clipboard. If it can't be read, don't restore it later:
+	// writing back an empty string would clobber the user's clipboard.
+	var prev string
+	saved := retryClipboard(func() error {
+		var err error
+		prev, err = inj.kb.ReadAll()
+		return err
+	}) == nil
+	if !saved {
+		slog.Warn("inject: could not read clipboard, skipping restore")
+	}
 
 	// Write text to clipboard
-	if err := robotgo.WriteAll(text); err != nil {
+	if err := retryClipboard(func() error { return inj.kb.WriteAll(text) }); err != nil {
 		return fmt.Errorf("inject: write to clipboard: %w", err)
 	}
 
+	// Read it back: a write can report success yet leave the clipboard
+	// unchanged, and Cmd+V would then paste stale contents. If the read
+	// fails, paste anyway rather than lose the text.
+	var got string
+	if err := retryClipboard(func() error {
+		var err error
+		got, err = inj.kb.ReadAll()
+		return err
+	}); err != nil {
+		slog.Debug("inject: could not verify clipboard", "error", err)
+	} else if got != text {
+		return ErrClipboardNotSet
+	}
+
 	// Paste with Cmd+V
-	if err := robotgo.KeyTap("v", "cmd"); err != nil {
+	if err := inj.kb.KeyTap("v", "cmd"); err != nil {
 		return fmt.Errorf("inject: key tap cmd+v: %w", err)
 	}
 
 	// Restore previous clipboard (best effort)
-	_ = robotgo.WriteAll(prev)
+	if saved {
+		_ = retryClipboard(func() error { return inj.kb.WriteAll(prev) })
+	}
 
 	return nil
 }
+
+// copyToClipboard writes text to the clipboard and leaves it there for the
+// user to paste wherever they choose. No keys are pressed.
+func (inj *Injector) copyToClipboard(text string) error {
+	if err := retryClipboard(func() error { return inj.kb.WriteAll(text) }); err != nil {
+		return fmt.Errorf("inject: write to clipboard: %w", err)
+	}
+	return nil
+}
+
+// retryClipboard runs a clipboard operation, retrying briefly on failure.
+// Clipboard access fails transiently when another process (e.g. a clipboard
+// manager) holds it.
+func retryClipboard(op func() error) error {
+	var err error
+	for attempt := 1; attempt <= clipboardAttempts; attempt++ {
+		if err = op(); err == nil {
+			return nil
+		}
+		if attempt < clipboardAttempts {
+			time.Sleep(clipboardRetryDelay)
+		}
+	}
+	return err
+}
@@ -0,0 +1,337 @@
+package inject
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockKeyboard records keystrokes and simulates a clipboard. readErrs and
+// writeErrs are returned (in order) by ReadAll and WriteAll before they
+// start succeeding.
+type mockKeyboard struct {
+	clipboard string
+	typed     []string
+	taps      []string
+	writes    []string
+	pasted    []string
+	events    []string // ordered log of Type and KeyTap calls
+
+	readErrs  []error
+	writeErrs []error
+	blocked   bool // WriteAll reports success without changing the clipboard
+}
+
+func (m *mockKeyboard) Type(text string) {
+	m.typed = append(m.typed, text)
+	m.events = append(m.events, "type:"+text)
+}
+
+func (m *mockKeyboard) KeyTap(key string, modifiers ...interface{}) error {
+	m.taps = append(m.taps, key)
+	m.events = append(m.events, "tap:"+key)
+	if key == "v" {
+		m.pasted = append(m.pasted, m.clipboard)
+	}
+	return nil
+}
+
+func (m *mockKeyboard) ReadAll() (string, error) {
+	if len(m.readErrs) > 0 {
+		err := m.readErrs[0]
+		m.readErrs = m.readErrs[1:]
+		return "", err
+	}
+	return m.clipboard, nil
+}
+
+func (m *mockKeyboard) WriteAll(text string) error {
+	if len(m.writeErrs) > 0 {
+		err := m.writeErrs[0]
+		m.writeErrs = m.writeErrs[1:]
+		return err
+	}
+	m.writes = append(m.writes, text)
+	if !m.blocked {
+		m.clipboard = text
+	}
+	return nil
+}
+
+var errClipboardBusy = errors.New("clipboard busy")
+
+func TestInjectType(t *testing.T) {
+	kb := &mockKeyboard{}
+	inj := &Injector{method: "type", kb: kb}
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.typed) != 1 || kb.typed[0] != "hello" {
+		t.Errorf("typed = %v, want [hello]", kb.typed)
+	}
+}
+
+func TestInjectPasteRestoresClipboard(t *testing.T) {
+	kb := &mockKeyboard{clipboard: "previous"}
+	inj := &Injector{method: "paste", kb: kb}
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.pasted) != 1 || kb.pasted[0] != "hello" {
+		t.Errorf("pasted = %v, want [hello]", kb.pasted)
+	}
+	if kb.clipboard != "previous" {
+		t.Errorf("clipboard = %q, want %q", kb.clipboard, "previous")
+	}
+}
+
+func TestInjectPasteRetriesWrite(t *testing.T) {
+	kb := &mockKeyboard{clipboard: "previous", writeErrs: []error{errClipboardBusy}}
+	inj := &Injector{method: "paste", kb: kb}
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.pasted) != 1 || kb.pasted[0] != "hello" {
+		t.Errorf("pasted = %v, want [hello]", kb.pasted)
+	}
+	if kb.clipboard != "previous" {
+		t.Errorf("clipboard = %q, want %q", kb.clipboard, "previous")
+	}
+}
+
+func TestInjectPasteWriteFails(t *testing.T) {
+	errs := make([]error, clipboardAttempts)
+	for i := range errs {
+		errs[i] = errClipboardBusy
+	}
+	kb := &mockKeyboard{writeErrs: errs}
+	inj := &Injector{method: "paste", kb: kb}
+
+	err := inj.Inject("hello")
+	if !errors.Is(err, errClipboardBusy) {
+		t.Fatalf("Inject() error = %v, want %v", err, errClipboardBusy)
+	}
+	if len(kb.taps) != 0 {
+		t.Errorf("taps = %v, want none after failed write", kb.taps)
+	}
+}
+
+func TestInjectPasteSkipsRestoreWhenReadFails(t *testing.T) {
+	errs := make([]error, clipboardAttempts)
+	for i := range errs {
+		errs[i] = errClipboardBusy
+	}
+	kb := &mockKeyboard{clipboard: "previous", readErrs: errs}
+	inj := &Injector{method: "paste", kb: kb}
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.pasted) != 1 || kb.pasted[0] != "hello" {
+		t.Errorf("pasted = %v, want [hello]", kb.pasted)
+	}
+	// Only the pasted text is written; an empty restore would clobber the clipboard.
+	if len(kb.writes) != 1 || kb.writes[0] != "hello" {
+		t.Errorf("writes = %v, want [hello]", kb.writes)
+	}
+}
+
+func TestPasteBlockedWrite(t *testing.T) {
+	kb := &mockKeyboard{clipboard: "previous", blocked: true}
+	inj := &Injector{method: "paste", kb: kb}
+
+	if err := inj.paste("hello"); !errors.Is(err, ErrClipboardNotSet) {
+		t.Fatalf("paste() error = %v, want %v", err, ErrClipboardNotSet)
+	}
+	if len(kb.taps) != 0 {
+		t.Errorf("taps = %v, want none: pasting would insert %q", kb.taps, kb.clipboard)
+	}
+}
+
+func TestInjectPasteBlockedFallsBackToType(t *testing.T) {
+	kb := &mockKeyboard{clipboard: "previous", blocked: true}
+	inj := &Injector{method: "paste", kb: kb}
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.pasted) != 0 {
+		t.Errorf("pasted = %v, want none", kb.pasted)
+	}
+	if len(kb.typed) != 1 || kb.typed[0] != "hello" {
+		t.Errorf("typed = %v, want [hello]", kb.typed)
+	}
+	if kb.clipboard != "previous" {
+		t.Errorf("clipboard = %q, want %q", kb.clipboard, "previous")
+	}
+}
+
+func TestInjectClipboardOnly(t *testing.T) {
+	kb := &mockKeyboard{clipboard: "previous", writeErrs: []error{errClipboardBusy}}
+	inj := &Injector{method: "clipboard", kb: kb}
+
+	if err := inj.Inject("hello\nworld"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if kb.clipboard != "hello\nworld" {
+		t.Errorf("clipboard = %q, want %q", kb.clipboard, "hello\nworld")
+	}
+	if len(kb.events) != 0 {
+		t.Errorf("keyboard events = %v, want none", kb.events)
+	}
+}
+
+func TestInjectTypeControlKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "newline",
+			text: "first line\nsecond line",
+			want: []string{"type:first line", "tap:enter", "type:second line"},
+		},
+		{
+			name: "code",
+			text: "if ok {\n\treturn\n}",
+			want: []string{"type:if ok {", "tap:enter", "tap:tab", "type:return", "tap:enter", "type:}"},
+		},
+		{
+			name: "crlf",
+			text: "a\r\nb",
+			want: []string{"type:a", "tap:enter", "type:b"},
+		},
+		{
+			name: "leading_and_trailing",
+			text: "\tindented\n",
+			want: []string{"tap:tab", "type:indented", "tap:enter"},
+		},
+		{
+			name: "no_controls",
+			text: "plain text",
+			want: []string{"type:plain text"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := &mockKeyboard{}
+			inj := &Injector{method: "type", kb: kb}
+			if err := inj.Inject(tt.text); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+			if !reflect.DeepEqual(kb.events, tt.want) {
+				t.Errorf("events = %v\nwant %v", kb.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectIMESafeControlKeys(t *testing.T) {
+	kb := &mockKeyboard{}
+	inj := &Injector{method: "type", opts: InjectorOptions{IMESafe: true}, kb: kb}
+	inj.sleep = func(time.Duration) {}
+
+	if err := inj.Inject("a\n\tb"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	want := []string{"type:a", "tap:enter", "tap:tab", "type:b"}
+	if !reflect.DeepEqual(kb.events, want) {
+		t.Errorf("events = %v\nwant %v", kb.events, want)
+	}
+}
+
+func TestInjectDelta(t *testing.T) {
+	kb := &mockKeyboard{}
+	inj := &Injector{method: "type", kb: kb}
+
+	if err := inj.InjectDelta(2, "fix"); err != nil {
+		t.Fatalf("InjectDelta() error = %v", err)
+	}
+	if len(kb.taps) != 2 || kb.taps[0] != "backspace" || kb.taps[1] != "backspace" {
+		t.Errorf("taps = %v, want two backspaces", kb.taps)
+	}
+	if len(kb.typed) != 1 || kb.typed[0] != "fix" {
+		t.Errorf("typed = %v, want [fix]", kb.typed)
+	}
+}
+
+func TestInjectIMESafe(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit bool
+		want   []string
+	}{
+		{
+			name: "paced",
+			want: []string{"type:你", "sleep", "type:好", "sleep", "type: ", "sleep", "type:a", "sleep"},
+		},
+		{
+			name:   "commit_between_words",
+			commit: true,
+			want: []string{
+				"type:你", "sleep", "type:好", "sleep",
+				"tap:enter", "sleep", "type: ", "sleep",
+				"type:a", "sleep", "tap:enter", "sleep",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb := &mockKeyboard{}
+			inj := &Injector{
+				method: "type",
+				opts:   InjectorOptions{IMESafe: true, IMECommit: tt.commit},
+				kb:     kb,
+			}
+			inj.sleep = func(d time.Duration) {
+				if d != imeCharDelay {
+					t.Errorf("sleep(%v), want %v", d, imeCharDelay)
+				}
+				kb.events = append(kb.events, "sleep")
+			}
+
+			if err := inj.Inject("你好 a"); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+			if !reflect.DeepEqual(kb.events, tt.want) {
+				t.Errorf("events = %v\nwant %v", kb.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectorWithoutInputAccess(t *testing.T) {
+	for _, method := range []string{"type", "paste", "clipboard"} {
+		t.Run(method, func(t *testing.T) {
+			kb := &mockKeyboard{}
+			inj := newInjector(method, InjectorOptions{}, kb, false)
+
+			if err := inj.Inject("hello"); !errors.Is(err, ErrNoInputAccess) {
+				t.Errorf("Inject() error = %v, want ErrNoInputAccess", err)
+			}
+			if err := inj.InjectDelta(2, "lo"); !errors.Is(err, ErrNoInputAccess) {
+				t.Errorf("InjectDelta() error = %v, want ErrNoInputAccess", err)
+			}
+			if len(kb.events) != 0 || len(kb.writes) != 0 {
+				t.Errorf("keyboard used while degraded: events %v, writes %v", kb.events, kb.writes)
+			}
+		})
+	}
+}
+
+func TestInjectorWithInputAccess(t *testing.T) {
+	kb := &mockKeyboard{}
+	inj := newInjector("type", InjectorOptions{}, kb, true)
+
+	if err := inj.Inject("hello"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	if len(kb.typed) != 1 || kb.typed[0] != "hello" {
+		t.Errorf("typed = %v, want [hello]", kb.typed)
+	}
+}
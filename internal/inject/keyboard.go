@@ -0,0 +1,59 @@
+package inject
+
+import (
+	"log/slog"
+	"os"
+	"runtime"
+	"sync"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// keyboard abstracts the keystroke and clipboard operations used by Injector
+// so they can be replaced in tests.
+type keyboard interface {
+	Type(text string)
+	KeyTap(key string, modifiers ...interface{}) error
+	ReadAll() (string, error)
+	WriteAll(text string) error
+}
+
+// robotgoKeyboard implements keyboard using robotgo.
+type robotgoKeyboard struct{}
+
+func (robotgoKeyboard) Type(text string) { robotgo.Type(text) }
+
+func (robotgoKeyboard) KeyTap(key string, modifiers ...interface{}) error {
+	return robotgo.KeyTap(key, modifiers...)
+}
+
+func (robotgoKeyboard) ReadAll() (string, error) { return robotgo.ReadAll() }
+
+func (robotgoKeyboard) WriteAll(text string) error { return robotgo.WriteAll(text) }
+
+// canSimulateInput caches the result of probeInput.
+var canSimulateInput = sync.OnceValue(probeInput)
+
+// CanSimulateInput reports whether robotgo can send keystrokes, i.e. a
+// display is reachable. Over SSH or in some sandboxes it isn't, and robotgo
+// calls fail silently or panic. The check runs once and is cached.
+func CanSimulateInput() bool {
+	return canSimulateInput()
+}
+
+// probeInput makes a harmless robotgo call, recovering from a panic, and
+// reports whether it saw a screen. On Linux, where robotgo talks to X11, a
+// missing DISPLAY fails fast without calling into X.
+func probeInput() (ok bool) {
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Debug("inject: input probe panicked", "panic", r)
+			ok = false
+		}
+	}()
+	w, h := robotgo.GetScreenSize()
+	return w > 0 && h > 0
+}
@@ -0,0 +1,81 @@
+package inject
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// Factory builds a TextInjector from the inject settings.
+type Factory func(cfg config.InjectConfig) (TextInjector, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Built-in methods. "ble" registers itself in ble_injector.go.
+func init() {
+	keyboardFactory := func(cfg config.InjectConfig) (TextInjector, error) {
+		return NewInjector(cfg.Method, InjectorOptions{
+			IMESafe:   cfg.IMESafe,
+			IMECommit: cfg.IMECommit,
+		}), nil
+	}
+	Register("type", keyboardFactory)
+	Register("paste", keyboardFactory)
+	Register("clipboard", keyboardFactory)
+	Register("echo", func(config.InjectConfig) (TextInjector, error) {
+		return NewEchoInjector(os.Stdout), nil
+	})
+}
+
+// Register makes an injection method available to Build under name.
+// It panics if name is already registered or factory is nil, since both
+// are programmer errors.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("inject: Register factory is nil for " + name)
+	}
+	if _, dup := factories[name]; dup {
+		panic("inject: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Methods returns the names of the registered injection methods, sorted.
+func Methods() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build creates the injector for cfg.Method using its registered factory.
+// With cfg.NormalizeUnicode, the injector normalizes everything it sends.
+func Build(cfg config.InjectConfig) (TextInjector, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[cfg.Method]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("inject: unknown method %q (available: %s)", cfg.Method, strings.Join(Methods(), ", "))
+	}
+	inj, err := factory(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.NormalizeUnicode {
+		inj = normalizing(inj)
+	}
+	return inj, nil
+}
@@ -0,0 +1,152 @@
+package inject
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/ble"
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestRegisterDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of an existing method did not panic")
+		}
+	}()
+	Register("type", func(config.InjectConfig) (TextInjector, error) { return nil, nil })
+}
+
+func TestBuildUnknownMethod(t *testing.T) {
+	_, err := Build(config.InjectConfig{Method: "smoke-signals"})
+	if err == nil {
+		t.Fatal("Build() error = nil, want error for unknown method")
+	}
+	if !strings.Contains(err.Error(), "ble, clipboard, echo, paste, type") {
+		t.Errorf("Build() error = %v, want it to list available methods", err)
+	}
+}
+
+func TestBuildKeyboardMethods(t *testing.T) {
+	for _, method := range []string{"type", "paste", "clipboard"} {
+		inj, err := Build(config.InjectConfig{Method: method, IMESafe: true})
+		if err != nil {
+			t.Fatalf("Build(%q) error = %v", method, err)
+		}
+		ki, ok := inj.(*Injector)
+		if !ok {
+			t.Fatalf("Build(%q) = %T, want *Injector", method, inj)
+		}
+		if ki.method != method || !ki.opts.IMESafe {
+			t.Errorf("Build(%q) method = %q, IMESafe = %v", method, ki.method, ki.opts.IMESafe)
+		}
+	}
+}
+
+func TestEchoInjector(t *testing.T) {
+	var buf bytes.Buffer
+	inj := NewEchoInjector(&buf)
+	for _, text := range []string{"hello", "", "world"} {
+		if err := inj.Inject(text); err != nil {
+			t.Fatalf("Inject(%q) error = %v", text, err)
+		}
+	}
+	if got, want := buf.String(), "hello\nworld\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+// fakeChar accepts every write.
+type fakeChar struct{}
+
+func (fakeChar) Write([]byte) error                { return nil }
+func (fakeChar) Read() ([]byte, error)             { return nil, nil }
+func (fakeChar) Subscribe(func(data []byte)) error { return nil }
+func (fakeChar) Unsubscribe() error                { return nil }
+
+// fakeConn records whether it was disconnected.
+type fakeConn struct{ disconnected bool }
+
+func (c *fakeConn) DiscoverCharacteristic(string, string) (ble.Characteristic, error) {
+	return fakeChar{}, nil
+}
+func (c *fakeConn) Disconnect() error   { c.disconnected = true; return nil }
+func (c *fakeConn) OnDisconnect(func()) {}
+func (c *fakeConn) RSSI() (int, error)  { return -50, nil }
+
+// fakeAdapter connects to every MAC except those listed in fail.
+type fakeAdapter struct {
+	fail  map[string]bool
+	conns []*fakeConn
+}
+
+func (a *fakeAdapter) Enable() error { return nil }
+func (a *fakeAdapter) Scan(context.Context, string, func(ble.Device)) error {
+	return nil
+}
+func (a *fakeAdapter) Connect(_ context.Context, mac string) (ble.Connection, error) {
+	if a.fail[mac] {
+		return nil, errors.New("unreachable")
+	}
+	c := &fakeConn{}
+	a.conns = append(a.conns, c)
+	return c, nil
+}
+
+func useFakeAdapter(t *testing.T, a *fakeAdapter) {
+	t.Helper()
+	orig := newBLEAdapter
+	newBLEAdapter = func() ble.Adapter { return a }
+	t.Cleanup(func() { newBLEAdapter = orig })
+}
+
+func bleInjectConfig(macs ...string) config.InjectConfig {
+	cfg := config.InjectConfig{Method: "ble"}
+	cfg.BLE.DisablePacketPersist = true
+	for _, mac := range macs {
+		cfg.BLE.Devices = append(cfg.BLE.Devices, config.BLEDevice{
+			DeviceMAC:    mac,
+			SharedSecret: strings.Repeat("ab", 32),
+		})
+	}
+	return cfg
+}
+
+func TestBuildBLE(t *testing.T) {
+	adapter := &fakeAdapter{}
+	useFakeAdapter(t, adapter)
+
+	inj, err := Build(bleInjectConfig("AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"))
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	bi, ok := inj.(*BLEInjector)
+	if !ok {
+		t.Fatalf("Build() = %T, want *BLEInjector", inj)
+	}
+	if len(adapter.conns) != 2 {
+		t.Errorf("connections = %d, want 2", len(adapter.conns))
+	}
+	if err := bi.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestBuildBLEConnectFailureClosesClients(t *testing.T) {
+	adapter := &fakeAdapter{fail: map[string]bool{"AA:BB:CC:DD:EE:02": true}}
+	useFakeAdapter(t, adapter)
+
+	_, err := Build(bleInjectConfig("AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"))
+	if err == nil {
+		t.Fatal("Build() error = nil, want connect failure")
+	}
+	if !strings.Contains(err.Error(), "AA:BB:CC:DD:EE:02") {
+		t.Errorf("Build() error = %v, want it to name the failing device", err)
+	}
+	if len(adapter.conns) != 1 || !adapter.conns[0].disconnected {
+		t.Error("already-connected client was not closed after a later device failed")
+	}
+}
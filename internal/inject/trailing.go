@@ -0,0 +1,18 @@
+package inject
+
+// trailingSeparators maps inject.trailing values to the text appended after
+// each transcript.
+var trailingSeparators = map[string]string{
+	"space":   " ",
+	"newline": "\n",
+}
+
+// AppendTrailing appends the separator named by trailing ("space" or
+// "newline") to text, so successive dictations don't run together. Empty
+// text and any other trailing value ("none") leave text unchanged.
+func AppendTrailing(text, trailing string) string {
+	if text == "" {
+		return text
+	}
+	return text + trailingSeparators[trailing]
+}
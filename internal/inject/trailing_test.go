@@ -0,0 +1,68 @@
+package inject
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestAppendTrailing(t *testing.T) {
+	tests := []struct {
+		trailing string
+		text     string
+		want     string
+	}{
+		{trailing: "none", text: "hello", want: "hello"},
+		{trailing: "space", text: "hello", want: "hello "},
+		{trailing: "newline", text: "hello", want: "hello\n"},
+		{trailing: "space", text: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := AppendTrailing(tt.text, tt.trailing); got != tt.want {
+			t.Errorf("AppendTrailing(%q, %q) = %q, want %q", tt.text, tt.trailing, got, tt.want)
+		}
+	}
+}
+
+func TestAppendTrailingPerMethod(t *testing.T) {
+	tests := []struct {
+		trailing  string
+		wantTyped []string // type method: Type calls
+		wantTaps  []string // type method: control key taps
+		wantPaste string   // paste method: pasted clipboard
+		wantEcho  string   // echo method: output
+	}{
+		{trailing: "none", wantTyped: []string{"hi"}, wantPaste: "hi", wantEcho: "hi\n"},
+		{trailing: "space", wantTyped: []string{"hi "}, wantPaste: "hi ", wantEcho: "hi \n"},
+		{trailing: "newline", wantTyped: []string{"hi"}, wantTaps: []string{"enter"}, wantPaste: "hi\n", wantEcho: "hi\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.trailing, func(t *testing.T) {
+			text := AppendTrailing("hi", tt.trailing)
+
+			kb := &mockKeyboard{}
+			if err := (&Injector{method: "type", kb: kb}).Inject(text); err != nil {
+				t.Fatalf("type Inject() error = %v", err)
+			}
+			if !reflect.DeepEqual(kb.typed, tt.wantTyped) || !reflect.DeepEqual(kb.taps, tt.wantTaps) {
+				t.Errorf("type: typed %q taps %q, want %q and %q", kb.typed, kb.taps, tt.wantTyped, tt.wantTaps)
+			}
+
+			kb = &mockKeyboard{}
+			if err := (&Injector{method: "paste", kb: kb}).Inject(text); err != nil {
+				t.Fatalf("paste Inject() error = %v", err)
+			}
+			if len(kb.pasted) != 1 || kb.pasted[0] != tt.wantPaste {
+				t.Errorf("paste: pasted %q, want [%q]", kb.pasted, tt.wantPaste)
+			}
+
+			var buf bytes.Buffer
+			if err := NewEchoInjector(&buf).Inject(text); err != nil {
+				t.Fatalf("echo Inject() error = %v", err)
+			}
+			if buf.String() != tt.wantEcho {
+				t.Errorf("echo: output %q, want %q", buf.String(), tt.wantEcho)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package inject
+
+import "golang.org/x/text/unicode/norm"
+
+// Compile-time interface satisfaction checks.
+var (
+	_ TextInjector        = (*normalizingInjector)(nil)
+	_ IncrementalInjector = (*normalizingIncrementalInjector)(nil)
+)
+
+// NormalizeUnicode returns text in Unicode normalization form C, replacing
+// decomposed sequences such as "e" + U+0301 COMBINING ACUTE ACCENT with
+// their precomposed form ("é"). Keyboard firmware and layouts handle the
+// precomposed characters far more reliably than combining marks.
+func NormalizeUnicode(text string) string {
+	return norm.NFC.String(text)
+}
+
+// normalizing wraps next so all text sent through it is normalized with
+// NormalizeUnicode. The wrapper is an IncrementalInjector only if next is,
+// so callers can still tell whether streaming edits are possible. Build
+// applies it when inject.normalize_unicode is set.
+func normalizing(next TextInjector) TextInjector {
+	n := &normalizingInjector{next: next}
+	if inc, ok := next.(IncrementalInjector); ok {
+		return &normalizingIncrementalInjector{normalizingInjector: n, inc: inc}
+	}
+	return n
+}
+
+// normalizingInjector applies NormalizeUnicode to text injected by next.
+type normalizingInjector struct {
+	next TextInjector
+}
+
+func (n *normalizingInjector) Inject(text string) error {
+	return n.next.Inject(NormalizeUnicode(text))
+}
+
+// Close closes next if it has a Close method, e.g. to flush BLE queues.
+func (n *normalizingInjector) Close() error {
+	if closer, ok := n.next.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// normalizingIncrementalInjector is normalizingInjector for an injector
+// that can also revise text it typed.
+type normalizingIncrementalInjector struct {
+	*normalizingInjector
+	inc IncrementalInjector
+}
+
+// InjectIncremental normalizes both prev and curr, so the edit is computed
+// between the texts as they were typed.
+func (n *normalizingIncrementalInjector) InjectIncremental(prev, curr string) error {
+	return n.inc.InjectIncremental(NormalizeUnicode(prev), NormalizeUnicode(curr))
+}
@@ -0,0 +1,121 @@
+package inject
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/chaz8081/gostt-writer/internal/ble/protocol"
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestNormalizeUnicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "combining_acute", input: "cafe\u0301", want: "caf\u00e9"},
+		{name: "hangul_jamo", input: "\u1112\u1161\u11ab", want: "\ud55c"},
+		{name: "already_composed", input: "caf\u00e9", want: "caf\u00e9"},
+		{name: "ascii", input: "hello world", want: "hello world"},
+		{name: "smart_quotes_kept", input: "\u201cquoted\u201d", want: "\u201cquoted\u201d"},
+		{name: "empty", input: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeUnicode(tt.input); got != tt.want {
+				t.Errorf("NormalizeUnicode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeUnicodeChunksOnRuneBoundaries(t *testing.T) {
+	// Decomposed "crème brûlée": every accent is a separate combining mark.
+	text := NormalizeUnicode(strings.Repeat("cre\u0300me bru\u0302le\u0301e ", 40))
+	if strings.ContainsAny(text, "\u0300\u0301\u0302") {
+		t.Fatal("NormalizeUnicode() left a combining accent")
+	}
+	chunks := protocol.ChunkText(text, protocol.MaxPayloadBytes)
+	if strings.Join(chunks, "") != text {
+		t.Fatal("ChunkText() chunks don't reassemble to the input")
+	}
+	for i, c := range chunks {
+		if !utf8.ValidString(c) {
+			t.Errorf("chunk %d splits a rune: %q", i, c)
+		}
+		if len(c) > protocol.MaxPayloadBytes {
+			t.Errorf("chunk %d is %d bytes, max %d", i, len(c), protocol.MaxPayloadBytes)
+		}
+	}
+}
+
+// recordingInjector records what it is asked to inject.
+type recordingInjector struct {
+	calls  []string
+	closed bool
+}
+
+func (r *recordingInjector) Inject(text string) error {
+	r.calls = append(r.calls, text)
+	return nil
+}
+
+func (r *recordingInjector) InjectIncremental(prev, curr string) error {
+	r.calls = append(r.calls, prev+" -> "+curr)
+	return nil
+}
+
+func (r *recordingInjector) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNormalizingInjector(t *testing.T) {
+	rec := &recordingInjector{}
+	n, ok := normalizing(rec).(*normalizingIncrementalInjector)
+	if !ok {
+		t.Fatalf("normalizing() of an incremental injector = %T, want *normalizingIncrementalInjector", n)
+	}
+
+	if err := n.Inject("cafe\u0301"); err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	// Streaming edits are normalized too.
+	if err := n.InjectIncremental("cafe\u0301", "cafe\u0301 cre\u0300me"); err != nil {
+		t.Fatalf("InjectIncremental() error = %v", err)
+	}
+	want := []string{"caf\u00e9", "caf\u00e9 -> caf\u00e9 cr\u00e8me"}
+	if !slices.Equal(rec.calls, want) {
+		t.Errorf("injected %q, want %q", rec.calls, want)
+	}
+
+	if err := n.Close(); err != nil || !rec.closed {
+		t.Errorf("Close() = %v, closed = %v; want it forwarded", err, rec.closed)
+	}
+}
+
+func TestNormalizingInjectorNotIncremental(t *testing.T) {
+	// Streaming checks for IncrementalInjector at startup, so the wrapper
+	// must not claim an ability the wrapped injector lacks.
+	n := normalizing(NewEchoInjector(&strings.Builder{}))
+	if _, ok := n.(IncrementalInjector); ok {
+		t.Errorf("normalizing() of an echo injector = %T, which implements IncrementalInjector", n)
+	}
+}
+
+func TestBuildNormalizeUnicode(t *testing.T) {
+	inj, err := Build(config.InjectConfig{Method: "type", NormalizeUnicode: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	n, ok := inj.(*normalizingIncrementalInjector)
+	if !ok {
+		t.Fatalf("Build() = %T, want *normalizingIncrementalInjector", inj)
+	}
+	if _, ok := n.next.(*Injector); !ok {
+		t.Errorf("wrapped injector = %T, want *Injector", n.next)
+	}
+}
@@ -0,0 +1,67 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// maxLineBytes bounds a single journal line read by Export; a transcript is
+// one line, and long dictations exceed bufio.Scanner's 64KB default.
+const maxLineBytes = 1 << 20
+
+// Entry is one journal line.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Text      string    `json:"text"`
+}
+
+// Export reads a journal in the format Writer produces and writes its
+// entries to w as an indented JSON array. Blank lines are ignored; lines
+// that aren't "<RFC3339 time>\t<text>" are skipped with a warning.
+func Export(r io.Reader, w io.Writer) error {
+	entries := []Entry{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		e, err := parseLine(line)
+		if err != nil {
+			slog.Warn("journal: skipping malformed line", "line", n, "error", err)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("journal: read: %w", err)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("journal: encode: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("journal: write: %w", err)
+	}
+	return nil
+}
+
+// parseLine splits a journal line into its timestamp and text.
+func parseLine(line string) (Entry, error) {
+	stamp, text, ok := strings.Cut(line, "\t")
+	if !ok {
+		return Entry{}, fmt.Errorf("no tab separator")
+	}
+	ts, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		return Entry{}, fmt.Errorf("bad timestamp: %w", err)
+	}
+	return Entry{Timestamp: ts, Text: text}, nil
+}
@@ -0,0 +1,95 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExport(t *testing.T) {
+	in := "2026-03-01T09:30:00Z\thello world\n" +
+		"\n" +
+		"2026-03-01T10:15:30+01:00\tsecond entry\r\n"
+	var out bytes.Buffer
+	if err := Export(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `[
+  {
+    "timestamp": "2026-03-01T09:30:00Z",
+    "text": "hello world"
+  },
+  {
+    "timestamp": "2026-03-01T10:15:30+01:00",
+    "text": "second entry"
+  }
+]
+`
+	if out.String() != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestExportSkipsMalformedLines(t *testing.T) {
+	in := "no tab here\n" +
+		"yesterday\tbad timestamp\n" +
+		"2026-03-01T09:30:00Z\tkept\n" +
+		"2026-03-01T09:31:00Z\t\n"
+	var out bytes.Buffer
+	if err := Export(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	var got []Entry
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != 2 || got[0].Text != "kept" || got[1].Text != "" {
+		t.Errorf("entries = %+v, want [kept, \"\"]", got)
+	}
+}
+
+func TestExportEmpty(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(strings.NewReader(""), &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if out.String() != "[]\n" {
+		t.Errorf("Export() = %q, want %q", out.String(), "[]\n")
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.txt")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	stamp := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	w.now = func() time.Time { return stamp }
+	if err := w.Append("line one\nline two"); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	_ = w.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := Export(bytes.NewReader(data), &out); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	var got []Entry
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !got[0].Timestamp.Equal(stamp) || got[0].Text != "line one line two" {
+		t.Errorf("entries = %+v", got)
+	}
+}
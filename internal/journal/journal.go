@@ -0,0 +1,58 @@
+// Package journal appends transcripts to a plain-text dictation log.
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// lineBreaks turns characters that would split an entry into spaces, so each
+// transcript stays on one line.
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// Writer appends timestamped transcripts to a file, one per line as
+// "<RFC3339 time>\t<text>". It is safe for concurrent use.
+type Writer struct {
+	mu  sync.Mutex
+	f   *os.File
+	now func() time.Time // replaced in tests
+}
+
+// Open opens path for appending, creating it and its directory if needed.
+// The caller must call Close when done.
+func Open(path string) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("journal: create dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("journal: open %s: %w", path, err)
+	}
+	return &Writer{f: f, now: time.Now}, nil
+}
+
+// Append writes one entry for text. Line breaks and tabs in text are
+// replaced with spaces. Empty text is ignored.
+func (w *Writer) Append(text string) error {
+	text = strings.TrimSpace(lineBreaks.Replace(text))
+	if text == "" {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := fmt.Fprintf(w.f, "%s\t%s\n", w.now().Format(time.RFC3339), text); err != nil {
+		return fmt.Errorf("journal: append: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
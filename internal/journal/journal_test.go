@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "journal.txt")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	w.now = func() time.Time { return time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC) }
+
+	for _, text := range []string{"hello world", "", "line one\nline two\tend"} {
+		if err := w.Append(text); err != nil {
+			t.Fatalf("Append(%q) error = %v", text, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2026-03-01T09:30:00Z\thello world\n" +
+		"2026-03-01T09:30:00Z\tline one line two end\n"
+	if string(got) != want {
+		t.Errorf("journal = %q, want %q", got, want)
+	}
+}
+
+func TestAppendKeepsExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.txt")
+	if err := os.WriteFile(path, []byte("earlier\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := w.Append("later"); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	_ = w.Close()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "earlier\n") {
+		t.Errorf("journal = %q, want existing content kept", got)
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.txt")
+	w, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Append("entry"); err != nil {
+				t.Errorf("Append() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := 0
+	for _, b := range got {
+		if b == '\n' {
+			lines++
+		}
+	}
+	if lines != 20 {
+		t.Errorf("journal has %d lines, want 20", lines)
+	}
+}
+
+func TestAppendAfterCloseFails(t *testing.T) {
+	w, err := Open(filepath.Join(t.TempDir(), "journal.txt"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_ = w.Close()
+	if err := w.Append("late"); err == nil {
+		t.Error("Append() after Close error = nil, want error")
+	}
+}
@@ -0,0 +1,172 @@
+// Package metrics exposes transcription counters and latencies in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the transcription
+// latency histogram buckets.
+var LatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds the metrics for a running session. It is safe for
+// concurrent use.
+type Registry struct {
+	mu             sync.Mutex
+	transcriptions uint64
+	errors         uint64
+	audioSeconds   float64
+
+	latencyCounts []uint64 // per bucket in LatencyBuckets, non-cumulative
+	latencySum    float64
+	latencyCount  uint64
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{latencyCounts: make([]uint64, len(LatencyBuckets))}
+}
+
+// ObserveTranscription records a successful transcription of audioDur
+// length that took latency to process.
+func (r *Registry) ObserveTranscription(audioDur, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.transcriptions++
+	r.audioSeconds += audioDur.Seconds()
+
+	secs := latency.Seconds()
+	r.latencySum += secs
+	r.latencyCount++
+	for i, le := range LatencyBuckets {
+		if secs <= le {
+			r.latencyCounts[i]++
+			break
+		}
+	}
+}
+
+// ObserveError records a failed transcription.
+func (r *Registry) ObserveError() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors++
+}
+
+// WriteTo writes all metrics to w in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	fmt.Fprintln(cw, "# HELP gostt_transcriptions_total Completed transcriptions.")
+	fmt.Fprintln(cw, "# TYPE gostt_transcriptions_total counter")
+	fmt.Fprintf(cw, "gostt_transcriptions_total %d\n", r.transcriptions)
+
+	fmt.Fprintln(cw, "# HELP gostt_transcription_errors_total Failed transcriptions.")
+	fmt.Fprintln(cw, "# TYPE gostt_transcription_errors_total counter")
+	fmt.Fprintf(cw, "gostt_transcription_errors_total %d\n", r.errors)
+
+	fmt.Fprintln(cw, "# HELP gostt_audio_seconds_total Seconds of audio transcribed.")
+	fmt.Fprintln(cw, "# TYPE gostt_audio_seconds_total counter")
+	fmt.Fprintf(cw, "gostt_audio_seconds_total %s\n", formatFloat(r.audioSeconds))
+
+	fmt.Fprintln(cw, "# HELP gostt_transcription_latency_seconds Time taken to transcribe a recording.")
+	fmt.Fprintln(cw, "# TYPE gostt_transcription_latency_seconds histogram")
+	var cumulative uint64
+	for i, le := range LatencyBuckets {
+		cumulative += r.latencyCounts[i]
+		fmt.Fprintf(cw, "gostt_transcription_latency_seconds_bucket{le=\"%s\"} %d\n", formatFloat(le), cumulative)
+	}
+	fmt.Fprintf(cw, "gostt_transcription_latency_seconds_bucket{le=\"+Inf\"} %d\n", r.latencyCount)
+	fmt.Fprintf(cw, "gostt_transcription_latency_seconds_sum %s\n", formatFloat(r.latencySum))
+	fmt.Fprintf(cw, "gostt_transcription_latency_seconds_count %d\n", r.latencyCount)
+
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := r.WriteTo(w); err != nil {
+		slog.Debug("metrics: write failed", "error", err)
+	}
+}
+
+// Server serves a Registry on /metrics.
+type Server struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// Serve starts an HTTP server on addr exposing reg at /metrics. The listener
+// is bound before Serve returns, so an address in use is reported here.
+func Serve(addr string, reg *Registry) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics: listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reg)
+	s := &Server{
+		srv: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		ln:  ln,
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close shuts the server down, waiting briefly for in-flight scrapes.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.srv.Shutdown(ctx); err != nil {
+		return fmt.Errorf("metrics: shutdown: %w", err)
+	}
+	return nil
+}
+
+// formatFloat renders v in the shortest form that round-trips, as
+// Prometheus expects.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter tracks bytes written and the first error, so WriteTo can
+// use fmt.Fprintf without checking every call.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
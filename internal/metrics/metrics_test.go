@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerFormat(t *testing.T) {
+	reg := NewRegistry()
+	reg.ObserveTranscription(2*time.Second, 80*time.Millisecond)
+	reg.ObserveTranscription(1500*time.Millisecond, 700*time.Millisecond)
+	reg.ObserveTranscription(3*time.Second, 20*time.Second)
+	reg.ObserveError()
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want Prometheus text format", ct)
+	}
+
+	want := `# HELP gostt_transcriptions_total Completed transcriptions.
+# TYPE gostt_transcriptions_total counter
+gostt_transcriptions_total 3
+# HELP gostt_transcription_errors_total Failed transcriptions.
+# TYPE gostt_transcription_errors_total counter
+gostt_transcription_errors_total 1
+# HELP gostt_audio_seconds_total Seconds of audio transcribed.
+# TYPE gostt_audio_seconds_total counter
+gostt_audio_seconds_total 6.5
+# HELP gostt_transcription_latency_seconds Time taken to transcribe a recording.
+# TYPE gostt_transcription_latency_seconds histogram
+gostt_transcription_latency_seconds_bucket{le="0.1"} 1
+gostt_transcription_latency_seconds_bucket{le="0.25"} 1
+gostt_transcription_latency_seconds_bucket{le="0.5"} 1
+gostt_transcription_latency_seconds_bucket{le="1"} 2
+gostt_transcription_latency_seconds_bucket{le="2.5"} 2
+gostt_transcription_latency_seconds_bucket{le="5"} 2
+gostt_transcription_latency_seconds_bucket{le="10"} 2
+gostt_transcription_latency_seconds_bucket{le="+Inf"} 3
+gostt_transcription_latency_seconds_sum 20.78
+gostt_transcription_latency_seconds_count 3
+`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestServe(t *testing.T) {
+	reg := NewRegistry()
+	reg.ObserveTranscription(time.Second, 100*time.Millisecond)
+
+	srv, err := Serve("127.0.0.1:0", reg)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	resp, err := http.Get("http://" + srv.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(body), "gostt_transcriptions_total 1\n") {
+		t.Errorf("body missing transcription count:\n%s", body)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := http.Get("http://" + srv.Addr() + "/metrics"); err == nil {
+		t.Error("GET after Close() succeeded, want connection error")
+	}
+}
+
+func TestServeAddrInUse(t *testing.T) {
+	srv, err := Serve("127.0.0.1:0", NewRegistry())
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+
+	if _, err := Serve(srv.Addr(), NewRegistry()); err == nil {
+		t.Error("Serve() on an address in use succeeded, want error")
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// Downloader fetches the model files for each backend.
+type Downloader interface {
+	DownloadWhisper() error
+	DownloadParakeet() error
+}
+
+// hubDownloader downloads models from HuggingFace into the default models
+// directory.
+type hubDownloader struct{}
+
+func (hubDownloader) DownloadWhisper() error  { return DownloadWhisper() }
+func (hubDownloader) DownloadParakeet() error { return DownloadParakeet() }
+
+// HubDownloader is the Downloader used at startup.
+var HubDownloader Downloader = hubDownloader{}
+
+// EnsureModels downloads the active backend's model if its files are
+// missing and transcribe.auto_download is on. confirm is asked before
+// downloading and may be nil to skip the prompt. It returns the result of
+// config.CheckModelFiles afterwards, so a model configured outside the
+// default models directory, which a download can't provide, is still
+// reported missing.
+func EnsureModels(cfg *config.Config, d Downloader, confirm func(backend string) bool) error {
+	err := config.CheckModelFiles(cfg)
+	if err == nil || !cfg.Transcribe.AutoDownload {
+		return err
+	}
+
+	backend := cfg.Transcribe.Backend
+	if backend == "" {
+		backend = "whisper"
+	}
+	if confirm != nil && !confirm(backend) {
+		return err
+	}
+
+	fmt.Printf("Downloading %s model (transcribe.auto_download)...\n", backend)
+	switch backend {
+	case "parakeet":
+		err = d.DownloadParakeet()
+	default:
+		err = d.DownloadWhisper()
+	}
+	if err != nil {
+		return fmt.Errorf("auto-download %s model: %w", backend, err)
+	}
+	return config.CheckModelFiles(cfg)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// fakeDownloader records calls and creates the whisper model file.
+type fakeDownloader struct {
+	whisperPath string
+	calls       []string
+	err         error
+}
+
+func (f *fakeDownloader) DownloadWhisper() error {
+	f.calls = append(f.calls, "whisper")
+	if f.err != nil {
+		return f.err
+	}
+	return os.WriteFile(f.whisperPath, []byte("model"), 0644)
+}
+
+func (f *fakeDownloader) DownloadParakeet() error {
+	f.calls = append(f.calls, "parakeet")
+	return f.err
+}
+
+func TestEnsureModels(t *testing.T) {
+	tests := []struct {
+		name         string
+		present      bool
+		autoDownload bool
+		confirm      func(string) bool
+		wantCalls    int
+		wantErr      bool
+	}{
+		{name: "present", present: true, autoDownload: true, wantCalls: 0},
+		{name: "missing, off", autoDownload: false, wantCalls: 0, wantErr: true},
+		{name: "missing, on", autoDownload: true, wantCalls: 1},
+		{name: "missing, on, declined", autoDownload: true, confirm: func(string) bool { return false }, wantCalls: 0, wantErr: true},
+		{name: "missing, on, confirmed", autoDownload: true, confirm: func(string) bool { return true }, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "ggml-base.en.bin")
+			if tt.present {
+				if err := os.WriteFile(path, []byte("model"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := config.Default()
+			cfg.Transcribe.ModelPath = path
+			cfg.Transcribe.AutoDownload = tt.autoDownload
+			d := &fakeDownloader{whisperPath: path}
+
+			err := EnsureModels(cfg, d, tt.confirm)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EnsureModels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(d.calls) != tt.wantCalls {
+				t.Errorf("downloads = %v, want %d", d.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestEnsureModelsParakeet(t *testing.T) {
+	cfg := config.Default()
+	cfg.Transcribe.Backend = "parakeet"
+	cfg.Transcribe.ParakeetModelDir = t.TempDir()
+	cfg.Transcribe.AutoDownload = true
+	d := &fakeDownloader{}
+
+	// The fake writes nothing, so the files are still missing afterwards.
+	if err := EnsureModels(cfg, d, nil); err == nil {
+		t.Error("EnsureModels() error = nil, want missing files after download")
+	}
+	if len(d.calls) != 1 || d.calls[0] != "parakeet" {
+		t.Errorf("downloads = %v, want [parakeet]", d.calls)
+	}
+}
+
+func TestEnsureModelsDownloadError(t *testing.T) {
+	cfg := config.Default()
+	cfg.Transcribe.ModelPath = filepath.Join(t.TempDir(), "missing.bin")
+	cfg.Transcribe.AutoDownload = true
+	want := errors.New("offline")
+
+	err := EnsureModels(cfg, &fakeDownloader{err: want}, nil)
+	if !errors.Is(err, want) {
+		t.Errorf("EnsureModels() error = %v, want %v", err, want)
+	}
+}
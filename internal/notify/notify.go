@@ -0,0 +1,26 @@
+// Package notify shows desktop notifications, so errors in a background
+// session reach the user without them watching the logs.
+package notify
+
+// Notifier delivers a user-visible notification.
+type Notifier interface {
+	Send(title, body string) error
+}
+
+// Compile-time interface satisfaction checks.
+var (
+	_ Notifier = System{}
+	_ Notifier = Nop{}
+)
+
+// System sends notifications through the operating system (see Send).
+type System struct{}
+
+// Send implements Notifier.
+func (System) Send(title, body string) error { return Send(title, body) }
+
+// Nop discards notifications.
+type Nop struct{}
+
+// Send implements Notifier.
+func (Nop) Send(string, string) error { return nil }
@@ -0,0 +1,33 @@
+//go:build darwin
+
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Send shows a macOS notification. It uses terminal-notifier when it is
+// installed, since its notifications can be clicked away individually, and
+// falls back to osascript.
+func Send(title, body string) error {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("terminal-notifier"); err == nil {
+		cmd = exec.Command(path, "-title", title, "-message", body, "-group", "gostt-writer")
+	} else {
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("notify: %s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
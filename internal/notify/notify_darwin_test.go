@@ -0,0 +1,18 @@
+//go:build darwin
+
+package notify
+
+import "testing"
+
+func TestAppleScriptString(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\path`, `"C:\\path"`},
+	}
+	for _, tt := range tests {
+		if got := appleScriptString(tt.in); got != tt.want {
+			t.Errorf("appleScriptString(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !darwin
+
+package notify
+
+// Send does nothing: desktop notifications are only implemented on macOS.
+func Send(title, body string) error { return nil }
@@ -0,0 +1,47 @@
+// Package selftest runs a sequence of subsystem checks and reports a
+// pass/fail line for each, for diagnosing setups that don't work.
+package selftest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSkipped matches errors returned by Skip.
+var ErrSkipped = errors.New("skipped")
+
+// skipError is a Skip result; its message is just the reason.
+type skipError struct{ reason string }
+
+func (e skipError) Error() string      { return e.reason }
+func (skipError) Is(target error) bool { return target == ErrSkipped }
+
+// Skip returns an error marking a check that did not apply, e.g. BLE when
+// another injection method is configured.
+func Skip(reason string) error {
+	return skipError{reason: reason}
+}
+
+// Check exercises one subsystem. It returns the name to report, which may
+// include a detail such as the device used, and a non-nil error on failure.
+type Check func() (name string, err error)
+
+// Run runs checks in order, writing one line per check to w, and reports
+// whether none failed. Skipped checks do not count as failures.
+func Run(w io.Writer, checks []Check) bool {
+	ok := true
+	for _, check := range checks {
+		name, err := check()
+		switch {
+		case err == nil:
+			fmt.Fprintf(w, "PASS  %s\n", name)
+		case errors.Is(err, ErrSkipped):
+			fmt.Fprintf(w, "SKIP  %s: %v\n", name, err)
+		default:
+			fmt.Fprintf(w, "FAIL  %s: %v\n", name, err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,74 @@
+package selftest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		wantOK bool
+		want   string
+	}{
+		{
+			name:   "no_checks",
+			wantOK: true,
+			want:   "",
+		},
+		{
+			name: "all_pass",
+			checks: []Check{
+				func() (string, error) { return "config", nil },
+				func() (string, error) { return "audio (Built-in Microphone)", nil },
+			},
+			wantOK: true,
+			want:   "PASS  config\nPASS  audio (Built-in Microphone)\n",
+		},
+		{
+			name: "failure",
+			checks: []Check{
+				func() (string, error) { return "config", nil },
+				func() (string, error) { return "models", errors.New("missing ggml-base.en.bin") },
+				func() (string, error) { return "transcriber", nil },
+			},
+			wantOK: false,
+			want:   "PASS  config\nFAIL  models: missing ggml-base.en.bin\nPASS  transcriber\n",
+		},
+		{
+			name: "skip_is_not_failure",
+			checks: []Check{
+				func() (string, error) { return "ble", Skip("inject.method is not ble") },
+			},
+			wantOK: true,
+			want:   "SKIP  ble: inject.method is not ble\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if got := Run(&out, tt.checks); got != tt.wantOK {
+				t.Errorf("Run() = %v, want %v", got, tt.wantOK)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRunRunsChecksInOrder(t *testing.T) {
+	var order []string
+	check := func(name string) Check {
+		return func() (string, error) {
+			order = append(order, name)
+			return name, nil
+		}
+	}
+	Run(&strings.Builder{}, []Check{check("a"), check("b"), check("c")})
+	if got := strings.Join(order, ","); got != "a,b,c" {
+		t.Errorf("checks ran in order %q, want %q", got, "a,b,c")
+	}
+}
@@ -0,0 +1,69 @@
+// Package stats keeps simple in-process counters for a dictation session.
+package stats
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Tracker accumulates transcription counts and timings. It is safe for
+// concurrent use, since batch transcriptions run on their own goroutines.
+type Tracker struct {
+	mu        sync.Mutex
+	count     int
+	audio     time.Duration
+	processed time.Duration
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// RecordTranscription records one dictation of audioDur length that took
+// procDur to transcribe.
+func (t *Tracker) RecordTranscription(audioDur, procDur time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.count++
+	t.audio += audioDur
+	t.processed += procDur
+}
+
+// Summary returns a one-line, human-readable summary of the session, e.g.
+// "3 dictations, 12.5s of audio, avg latency 420ms".
+func (t *Tracker) Summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.count == 0 {
+		return "0 dictations"
+	}
+
+	noun := "dictations"
+	if t.count == 1 {
+		noun = "dictation"
+	}
+	avg := (t.processed / time.Duration(t.count)).Round(time.Millisecond)
+	return fmt.Sprintf("%d %s, %.1fs of audio, avg latency %s",
+		t.count, noun, t.audio.Seconds(), avg)
+}
+
+// LogValue implements slog.LogValuer, so the session can be logged as
+// attributes, e.g. slog.Info("Session summary", "session", t).
+func (t *Tracker) LogValue() slog.Value {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var avg time.Duration
+	if t.count > 0 {
+		avg = (t.processed / time.Duration(t.count)).Round(time.Millisecond)
+	}
+	return slog.GroupValue(
+		slog.Int("dictations", t.count),
+		slog.String("audio", fmt.Sprintf("%.1fs", t.audio.Seconds())),
+		slog.Duration("avg_latency", avg),
+	)
+}
@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTrackerSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		record [][2]time.Duration // {audio, processing}
+		want   string
+	}{
+		{
+			name: "empty",
+			want: "0 dictations",
+		},
+		{
+			name:   "single",
+			record: [][2]time.Duration{{2 * time.Second, 300 * time.Millisecond}},
+			want:   "1 dictation, 2.0s of audio, avg latency 300ms",
+		},
+		{
+			name: "multiple",
+			record: [][2]time.Duration{
+				{2 * time.Second, 200 * time.Millisecond},
+				{3500 * time.Millisecond, 400 * time.Millisecond},
+				{7 * time.Second, 900 * time.Millisecond},
+			},
+			want: "3 dictations, 12.5s of audio, avg latency 500ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTracker()
+			for _, r := range tt.record {
+				tr.RecordTranscription(r[0], r[1])
+			}
+			if got := tr.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerConcurrent(t *testing.T) {
+	tr := NewTracker()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr.RecordTranscription(time.Second, 100*time.Millisecond)
+		}()
+	}
+	wg.Wait()
+
+	want := "50 dictations, 50.0s of audio, avg latency 100ms"
+	if got := tr.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestTrackerLogValue(t *testing.T) {
+	tr := NewTracker()
+	tr.RecordTranscription(2*time.Second, 200*time.Millisecond)
+	tr.RecordTranscription(3500*time.Millisecond, 400*time.Millisecond)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("Session summary", "session", tr)
+
+	want := "level=INFO msg=\"Session summary\" session.dictations=2 session.audio=5.5s session.avg_latency=300ms\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log line = %q, want %q", got, want)
+	}
+}
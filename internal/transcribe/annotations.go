@@ -0,0 +1,153 @@
+package transcribe
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// DefaultAnnotationPatterns match the non-speech annotations whisper writes
+// in brackets or parentheses, such as "[BLANK_AUDIO]", "(music)" or
+// "[ Silence ]". Each is a case-insensitive regular expression matched
+// against the whole annotation text, without the brackets.
+var DefaultAnnotationPatterns = []string{
+	`blank_audio`,
+	`silence`,
+	`no speech`,
+	`inaudible`,
+	`.*music.*`,
+	`.*noise.*`,
+	`.*sounds?`,
+	`applause`,
+	`laugh(s|ing|ter)?`,
+	`cough(s|ing)?`,
+	`sigh(s|ing)?`,
+	`static`,
+	`beep(s|ing)?`,
+}
+
+// annotationPatterns returns the patterns to strip for cfg: nil unless
+// strip_annotations is on, then annotation_patterns or the defaults.
+func annotationPatterns(cfg *config.TranscribeConfig) []string {
+	if !cfg.StripAnnotations {
+		return nil
+	}
+	if len(cfg.AnnotationPatterns) > 0 {
+		return cfg.AnnotationPatterns
+	}
+	return DefaultAnnotationPatterns
+}
+
+// multiSpace matches the runs of spaces left behind when an annotation is
+// removed from between two words.
+var multiSpace = regexp.MustCompile(` {2,}`)
+
+// annotationMatcher removes bracketed annotations whose text matches one of
+// its patterns.
+type annotationMatcher struct {
+	patterns []*regexp.Regexp
+}
+
+// newAnnotationMatcher compiles patterns, skipping any that are invalid.
+// config.Validate rejects invalid patterns before they get here.
+func newAnnotationMatcher(patterns []string) *annotationMatcher {
+	m := &annotationMatcher{}
+	for _, p := range patterns {
+		re, err := regexp.Compile(`(?i)^(?:` + p + `)$`)
+		if err != nil {
+			continue
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m
+}
+
+// StripAnnotations removes bracketed or parenthesized non-speech annotations
+// matching patterns from text, e.g. "[BLANK_AUDIO]" or "(music)". Nested
+// groups are handled inside out: "(see [BLANK_AUDIO] above)" becomes "(see
+// above)", and a group left empty, like "[[Silence]]", is removed too. Other
+// bracketed text is kept. Spacing around a removed annotation is collapsed.
+func StripAnnotations(text string, patterns []string) string {
+	return newAnnotationMatcher(patterns).strip(text)
+}
+
+func (m *annotationMatcher) strip(text string) string {
+	out, removed := m.stripGroups(text)
+	if !removed {
+		return text
+	}
+	return strings.TrimSpace(multiSpace.ReplaceAllString(out, " "))
+}
+
+// stripGroups removes matching annotation groups from text and reports
+// whether anything was removed. Unbalanced brackets are left as they are.
+func (m *annotationMatcher) stripGroups(text string) (string, bool) {
+	var b strings.Builder
+	removed := false
+	for i := 0; i < len(text); {
+		end := matchingBracket(text, i)
+		if end < 0 {
+			b.WriteByte(text[i])
+			i++
+			continue
+		}
+		inner, innerRemoved := m.stripGroups(text[i+1 : end])
+		content := strings.TrimSpace(multiSpace.ReplaceAllString(inner, " "))
+		switch {
+		case innerRemoved && content == "", m.matches(content):
+			removed = true
+		case innerRemoved:
+			b.WriteByte(text[i])
+			b.WriteString(content)
+			b.WriteByte(text[end])
+			removed = true
+		default:
+			b.WriteString(text[i : end+1])
+		}
+		i = end + 1
+	}
+	return b.String(), removed
+}
+
+// matches reports whether annotation text matches one of the patterns.
+func (m *annotationMatcher) matches(content string) bool {
+	if content == "" {
+		return false
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingBracket returns the index of the bracket closing the "[" or "("
+// at text[start], or -1 if text[start] isn't an opening bracket or it is
+// never closed. Brackets must nest properly: "([)]" has no match.
+func matchingBracket(text string, start int) int {
+	if text[start] != '[' && text[start] != '(' {
+		return -1
+	}
+	var stack []byte
+	for i := start; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '[', '(':
+			stack = append(stack, c)
+		case ']', ')':
+			open := byte('[')
+			if c == ')' {
+				open = '('
+			}
+			if stack[len(stack)-1] != open {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,65 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+
+	whisper "github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
+)
+
+func TestStripAnnotations(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "blank_audio", input: "[BLANK_AUDIO]", want: ""},
+		{name: "music", input: "(music)", want: ""},
+		{name: "spaced_silence", input: " [ Silence ] ", want: ""},
+		{name: "music_playing", input: "[MUSIC PLAYING]", want: ""},
+		{name: "mixed", input: " Hello [BLANK_AUDIO] world.", want: "Hello world."},
+		{name: "leading", input: "(upbeat music) Let's begin.", want: "Let's begin."},
+		{name: "nested_empty", input: "[[BLANK_AUDIO]]", want: ""},
+		{name: "nested_mixed", input: "call me (later [ Silence ]) ok", want: "call me (later) ok"},
+		{name: "nested_kept", input: "f(a[i]) is fine", want: "f(a[i]) is fine"},
+		{name: "speech_in_parens", input: "see the docs (section two)", want: "see the docs (section two)"},
+		{name: "unbalanced", input: "oops (music", want: "oops (music"},
+		{name: "mismatched", input: "([music)]", want: "([music)]"},
+		{name: "no_annotations", input: " Hello world.", want: " Hello world."},
+		{name: "empty_brackets", input: "array []", want: "array []"},
+		{name: "multiple", input: "[Applause] thank you (laughs) all", want: "thank you all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripAnnotations(tt.input, DefaultAnnotationPatterns); got != tt.want {
+				t.Errorf("StripAnnotations(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripAnnotationsCustomPatterns(t *testing.T) {
+	got := StripAnnotations("hi [BLANK_AUDIO] (crosstalk)", []string{"crosstalk"})
+	if want := "hi [BLANK_AUDIO]"; got != want {
+		t.Errorf("StripAnnotations() = %q, want %q", got, want)
+	}
+}
+
+func TestWhisperStripsAnnotations(t *testing.T) {
+	model := &fakeWhisperModel{segments: []whisper.Segment{
+		{Start: 0, End: time.Second, Text: " [BLANK_AUDIO]"},
+		{Start: time.Second, End: 2 * time.Second, Text: " Hello (music) there."},
+	}}
+	tr := &WhisperTranscriber{model: model, annotations: newAnnotationMatcher(DefaultAnnotationPatterns)}
+
+	segments, err := tr.ProcessSegments(make([]float32, 32000))
+	if err != nil {
+		t.Fatalf("ProcessSegments() error = %v", err)
+	}
+	if len(segments) != 1 {
+		t.Fatalf("got %d segments, want 1 (annotation-only segment dropped)", len(segments))
+	}
+	if segments[0].Text != "Hello there." || segments[0].Start != time.Second {
+		t.Errorf("segment = %+v, want \"Hello there.\" at 1s", segments[0])
+	}
+}
@@ -0,0 +1,77 @@
+package transcribe
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BenchSampleWAV is the built-in sample --bench transcribes: about 2.8s of
+// speech as 16kHz mono 16-bit PCM.
+//
+//go:embed testdata/short.wav
+var BenchSampleWAV []byte
+
+// benchSampleRate is the rate BenchBackend assumes for its samples; both
+// backends take 16kHz audio.
+const benchSampleRate = 16000
+
+// BenchResult is the outcome of timing one backend with BenchBackend.
+type BenchResult struct {
+	Backend string        // backend name, as reported by BackendName
+	Runs    int           // timed runs completed
+	Audio   time.Duration // length of the sample
+	Elapsed time.Duration // total processing time across all runs
+	RTF     float64       // mean real-time factor per run (lower is faster)
+	Err     error         // set if a run failed; the other fields cover runs before it
+}
+
+// BenchBackend transcribes samples (16kHz mono) n times with tr and returns
+// the mean real-time factor. One untimed run first warms up the model, as
+// the go test benchmarks do, so load time doesn't skew the result.
+func BenchBackend(tr Transcriber, samples []float32, n int) BenchResult {
+	res := BenchResult{
+		Backend: BackendName(tr),
+		Audio:   time.Duration(len(samples)) * time.Second / benchSampleRate,
+	}
+	if _, err := tr.Process(samples); err != nil {
+		res.Err = fmt.Errorf("transcribe: bench warm-up: %w", err)
+		return res
+	}
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		_, err := tr.Process(samples)
+		res.Elapsed += time.Since(start)
+		if err != nil {
+			res.Err = fmt.Errorf("transcribe: bench run %d: %w", i+1, err)
+			break
+		}
+		res.Runs++
+	}
+	if res.Runs > 0 {
+		res.RTF = RealTimeFactor(res.Elapsed/time.Duration(res.Runs), res.Audio)
+	}
+	return res
+}
+
+// BenchRecommendation compares successful results and names the fastest
+// backend, e.g. "parakeet is 2.3x faster on this machine; set
+// transcribe.backend: parakeet". It returns "" if fewer than two backends
+// completed a run.
+func BenchRecommendation(results []BenchResult) string {
+	var ok []BenchResult
+	for _, r := range results {
+		if r.Err == nil && r.Runs > 0 && r.RTF > 0 {
+			ok = append(ok, r)
+		}
+	}
+	if len(ok) < 2 {
+		return ""
+	}
+	slices.SortFunc(ok, func(a, b BenchResult) int { return cmp.Compare(a.RTF, b.RTF) })
+	fastest, next := ok[0], ok[1]
+	return fmt.Sprintf("%s is %.1fx faster on this machine; set transcribe.backend: %s",
+		fastest.Backend, next.RTF/fastest.RTF, fastest.Backend)
+}
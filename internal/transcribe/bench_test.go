@@ -0,0 +1,83 @@
+package transcribe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingTranscriber succeeds okRuns times, then fails every call.
+type failingTranscriber struct {
+	okRuns int
+	calls  int
+}
+
+func (f *failingTranscriber) Process([]float32) (string, error) {
+	f.calls++
+	if f.calls > f.okRuns {
+		return "", errors.New("boom")
+	}
+	return "hello", nil
+}
+
+func (f *failingTranscriber) Close() error { return nil }
+
+func TestBenchBackend(t *testing.T) {
+	samples := make([]float32, 16000) // 1s
+	res := BenchBackend(&slowTranscriber{delay: 20 * time.Millisecond}, samples, 3)
+	if res.Err != nil {
+		t.Fatalf("BenchBackend() error = %v", res.Err)
+	}
+	if res.Runs != 3 {
+		t.Errorf("Runs = %d, want 3", res.Runs)
+	}
+	if res.Audio != time.Second {
+		t.Errorf("Audio = %v, want 1s", res.Audio)
+	}
+	if res.Elapsed < 60*time.Millisecond {
+		t.Errorf("Elapsed = %v, want at least 60ms (warm-up excluded)", res.Elapsed)
+	}
+	if res.RTF < 0.02 || res.RTF > 0.5 {
+		t.Errorf("RTF = %.3f, want about 0.02", res.RTF)
+	}
+}
+
+func TestBenchBackendErrors(t *testing.T) {
+	samples := make([]float32, 16000)
+
+	res := BenchBackend(&failingTranscriber{okRuns: 0}, samples, 3)
+	if res.Err == nil || res.Runs != 0 {
+		t.Errorf("failed warm-up: Runs = %d, Err = %v; want 0 runs and an error", res.Runs, res.Err)
+	}
+
+	// Warm-up plus two timed runs succeed, the third fails.
+	res = BenchBackend(&failingTranscriber{okRuns: 3}, samples, 5)
+	if res.Err == nil {
+		t.Fatal("BenchBackend() should report the failed run")
+	}
+	if res.Runs != 2 {
+		t.Errorf("Runs = %d, want 2", res.Runs)
+	}
+}
+
+func TestBenchRecommendation(t *testing.T) {
+	whisper := BenchResult{Backend: "whisper", Runs: 3, RTF: 0.23}
+	parakeet := BenchResult{Backend: "parakeet", Runs: 3, RTF: 0.1}
+
+	got := BenchRecommendation([]BenchResult{whisper, parakeet})
+	want := "parakeet is 2.3x faster on this machine; set transcribe.backend: parakeet"
+	if got != want {
+		t.Errorf("BenchRecommendation() = %q, want %q", got, want)
+	}
+
+	whisper.RTF = 0.05
+	if got := BenchRecommendation([]BenchResult{whisper, parakeet}); !strings.HasPrefix(got, "whisper is 2.0x faster") {
+		t.Errorf("BenchRecommendation() = %q, want whisper recommended", got)
+	}
+
+	parakeet.Err = errors.New("boom")
+	if got := BenchRecommendation([]BenchResult{whisper, parakeet}); got != "" {
+		t.Errorf("BenchRecommendation() with one usable result = %q, want empty", got)
+	}
+}
@@ -2,14 +2,24 @@ package transcribe
 
 import (
 	"encoding/json"
+	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/go-audio/wav"
+	"github.com/chaz8081/gostt-writer/internal/audio"
+	"github.com/chaz8081/gostt-writer/internal/coreml"
 )
 
+// benchComputeUnits lists the parakeet compute-unit settings that
+// BenchmarkParakeetProcess compares, e.g.
+//
+//	go test -bench=Parakeet -run='^$' ./internal/transcribe/ -parakeet-compute-units=all,cpu_only,cpu_and_gpu
+var benchComputeUnits = flag.String("parakeet-compute-units", "all",
+	"comma-separated parakeet compute units to benchmark (all, cpu_only, cpu_and_gpu, cpu_and_ane)")
+
 // benchSample holds a test audio sample and its reference transcript.
 type benchSample struct {
 	Label      string  `json:"label"`
@@ -69,18 +79,13 @@ func loadBenchSamples(b *testing.B) []benchSampleWithAudio {
 	return results
 }
 
-// wavDecode opens and decodes a WAV file from an os.File, returning float32
+// wavDecode decodes a WAV file from an os.File, returning 16kHz mono float32
 // samples normalized to [-1.0, 1.0]. Returns nil on error.
 func wavDecode(f *os.File) []float32 {
-	dec := wav.NewDecoder(f)
-	buf, err := dec.FullPCMBuffer()
+	samples, err := audio.DecodeWAV(f, benchSampleRate)
 	if err != nil {
 		return nil
 	}
-	samples := make([]float32, len(buf.Data))
-	for i, s := range buf.Data {
-		samples[i] = float32(s) / 32768.0
-	}
 	return samples
 }
 
@@ -92,7 +97,8 @@ func BenchmarkWhisperProcess(b *testing.B) {
 
 	samples := loadBenchSamples(b)
 
-	tr, err := NewWhisperTranscriber(modelPath)
+	// Greedy decoding keeps the reported WER comparable across runs.
+	tr, err := NewWhisperTranscriber(modelPath, WhisperOptions{Deterministic: true})
 	if err != nil {
 		b.Fatalf("NewWhisperTranscriber: %v", err)
 	}
@@ -129,6 +135,28 @@ func BenchmarkWhisperProcess(b *testing.B) {
 	}
 }
 
+// BenchmarkWhisperNewContext measures the per-call context creation that
+// ProcessSegments does, for comparison with BenchmarkWhisperProcess.
+func BenchmarkWhisperNewContext(b *testing.B) {
+	modelPath := filepath.Join("..", "..", "models", "ggml-base.en.bin")
+	if _, err := os.Stat(modelPath); err != nil {
+		b.Skipf("whisper model not found at %s (run 'task whisper-model')", modelPath)
+	}
+
+	tr, err := NewWhisperTranscriber(modelPath, WhisperOptions{})
+	if err != nil {
+		b.Fatalf("NewWhisperTranscriber: %v", err)
+	}
+	defer func() { _ = tr.Close() }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tr.Model().NewContext(); err != nil {
+			b.Fatalf("NewContext: %v", err)
+		}
+	}
+}
+
 func BenchmarkParakeetProcess(b *testing.B) {
 	modelDir := filepath.Join("..", "..", "models", "parakeet-tdt-v2")
 	if _, err := os.Stat(filepath.Join(modelDir, "Encoder.mlmodelc")); err != nil {
@@ -137,14 +165,28 @@ func BenchmarkParakeetProcess(b *testing.B) {
 
 	samples := loadBenchSamples(b)
 
-	tr, err := NewParakeetTranscriber(modelDir)
+	for _, name := range strings.Split(*benchComputeUnits, ",") {
+		name = strings.TrimSpace(name)
+		units, err := ParseComputeUnits(name)
+		if err != nil {
+			b.Fatalf("-parakeet-compute-units: %v", err)
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkParakeetUnits(b, modelDir, units, samples)
+		})
+	}
+}
+
+// benchmarkParakeetUnits runs every sample through a parakeet transcriber
+// loaded with the given compute units.
+func benchmarkParakeetUnits(b *testing.B, modelDir string, units coreml.ComputeUnits, samples []benchSampleWithAudio) {
+	tr, err := NewParakeetTranscriber(modelDir, ParakeetOptions{ComputeUnits: units})
 	if err != nil {
 		b.Fatalf("NewParakeetTranscriber: %v", err)
 	}
 	defer func() { _ = tr.Close() }()
 
 	for _, s := range samples {
-		s := s // capture
 		b.Run(s.Label, func(b *testing.B) {
 			// Report audio duration as a custom metric
 			b.ReportMetric(s.DurationS*1000, "audio-ms")
@@ -195,7 +237,7 @@ func BenchmarkWhisperLatency(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		tr, err := NewWhisperTranscriber(modelPath)
+		tr, err := NewWhisperTranscriber(modelPath, WhisperOptions{Deterministic: true})
 		if err != nil {
 			b.Fatalf("NewWhisperTranscriber: %v", err)
 		}
@@ -236,7 +278,7 @@ func BenchmarkParakeetLatency(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
-		tr, err := NewParakeetTranscriber(modelDir)
+		tr, err := NewParakeetTranscriber(modelDir, ParakeetOptions{})
 		if err != nil {
 			b.Fatalf("NewParakeetTranscriber: %v", err)
 		}
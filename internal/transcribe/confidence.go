@@ -0,0 +1,50 @@
+package transcribe
+
+import "strings"
+
+// SegmentsText joins the text of segments into a single transcript.
+func SegmentsText(segments []Segment) string {
+	texts := make([]string, len(segments))
+	for i, seg := range segments {
+		texts[i] = seg.Text
+	}
+	return joinSegments(texts)
+}
+
+// TranscriptConfidence returns the overall confidence of a transcript: the
+// mean of its segments' confidences, weighted by segment duration so a short
+// garbled fragment doesn't sink a long clear sentence. Segments without text
+// are ignored. If no segment has a duration, the plain mean is used.
+func TranscriptConfidence(segments []Segment) float64 {
+	var weighted, total, sum float64
+	n := 0
+	for _, seg := range segments {
+		if strings.TrimSpace(seg.Text) == "" {
+			continue
+		}
+		d := (seg.End - seg.Start).Seconds()
+		if d > 0 {
+			weighted += seg.Confidence * d
+			total += d
+		}
+		sum += seg.Confidence
+		n++
+	}
+	switch {
+	case total > 0:
+		return weighted / total
+	case n > 0:
+		return sum / float64(n)
+	}
+	return 0
+}
+
+// ShouldInject reports whether a transcript is confident enough to inject.
+// A minConf of 0 or less disables the gate, and a transcript with no text
+// always passes (there is nothing to suppress).
+func ShouldInject(result []Segment, minConf float64) bool {
+	if minConf <= 0 || SegmentsText(result) == "" {
+		return true
+	}
+	return TranscriptConfidence(result) >= minConf
+}
@@ -0,0 +1,90 @@
+package transcribe
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTranscriptConfidence(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     float64
+	}{
+		{name: "empty", want: 0},
+		{
+			name:     "single",
+			segments: []Segment{{Start: 0, End: time.Second, Text: "hi", Confidence: 0.8}},
+			want:     0.8,
+		},
+		{
+			name: "duration_weighted",
+			segments: []Segment{
+				{Start: 0, End: 3 * time.Second, Text: "a long clear sentence", Confidence: 0.9},
+				{Start: 3 * time.Second, End: 4 * time.Second, Text: "mumble", Confidence: 0.1},
+			},
+			want: 0.7,
+		},
+		{
+			name: "blank_segments_ignored",
+			segments: []Segment{
+				{Start: 0, End: time.Second, Text: "hello", Confidence: 0.6},
+				{Start: time.Second, End: 5 * time.Second, Text: "  ", Confidence: 0},
+			},
+			want: 0.6,
+		},
+		{
+			name: "zero_durations_use_plain_mean",
+			segments: []Segment{
+				{Text: "a", Confidence: 0.2},
+				{Text: "b", Confidence: 0.6},
+			},
+			want: 0.4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TranscriptConfidence(tt.segments)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TranscriptConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldInject(t *testing.T) {
+	confident := []Segment{{Start: 0, End: 2 * time.Second, Text: "send the report", Confidence: 0.85}}
+	unsure := []Segment{{Start: 0, End: 2 * time.Second, Text: "sand the rapport", Confidence: 0.35}}
+	silent := []Segment{{Start: 0, End: time.Second, Text: " ", Confidence: 0}}
+
+	tests := []struct {
+		name    string
+		result  []Segment
+		minConf float64
+		want    bool
+	}{
+		{name: "gate_off", result: unsure, minConf: 0, want: true},
+		{name: "gate_off_negative", result: unsure, minConf: -1, want: true},
+		{name: "above_threshold", result: confident, minConf: 0.6, want: true},
+		{name: "at_threshold", result: confident, minConf: 0.85, want: true},
+		{name: "below_threshold", result: unsure, minConf: 0.6, want: false},
+		{name: "no_text", result: silent, minConf: 0.6, want: true},
+		{name: "nil", result: nil, minConf: 0.6, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldInject(tt.result, tt.minConf); got != tt.want {
+				t.Errorf("ShouldInject(conf=%.2f, min=%.2f) = %v, want %v",
+					TranscriptConfidence(tt.result), tt.minConf, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentsText(t *testing.T) {
+	got := SegmentsText([]Segment{{Text: " Hello"}, {Text: "world. "}})
+	if got != "Hello world." {
+		t.Errorf("SegmentsText() = %q, want %q", got, "Hello world.")
+	}
+}
@@ -0,0 +1,35 @@
+package transcribe
+
+import "errors"
+
+// Error classes returned by New and Process, for use with errors.Is.
+var (
+	// ErrBackendUnavailable means a backend could not be constructed: the
+	// name is unknown, its model is missing or fails to load, or the
+	// platform lacks what it needs. errors.As with *BackendError gives the
+	// backend's name.
+	ErrBackendUnavailable = errors.New("transcribe: backend unavailable")
+
+	// ErrModelNotLoaded is returned by Process on a transcriber whose model
+	// has been released by Close.
+	ErrModelNotLoaded = errors.New("transcribe: model not loaded")
+
+	// ErrAudioTooShort is returned by Process for non-empty audio shorter
+	// than the backend can transcribe. Empty audio is not an error.
+	ErrAudioTooShort = errors.New("transcribe: audio too short")
+)
+
+// BackendError reports that the named backend could not be constructed.
+// It matches ErrBackendUnavailable; its message is that of Err, which
+// already names the backend's problem.
+type BackendError struct {
+	Backend string
+	Err     error
+}
+
+func (e *BackendError) Error() string { return e.Err.Error() }
+
+func (e *BackendError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrBackendUnavailable.
+func (e *BackendError) Is(target error) bool { return target == ErrBackendUnavailable }
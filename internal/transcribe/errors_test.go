@@ -0,0 +1,58 @@
+package transcribe
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestNewBackendUnavailable(t *testing.T) {
+	loadErr := errors.New("model missing")
+	stubBackends(t, map[string]func(*config.TranscribeConfig) (Transcriber, error){
+		"whisper":  func(*config.TranscribeConfig) (Transcriber, error) { return nil, loadErr },
+		"parakeet": func(*config.TranscribeConfig) (Transcriber, error) { return nil, loadErr },
+	})
+
+	_, err := New(&config.TranscribeConfig{Backend: "whisper"})
+	if !errors.Is(err, ErrBackendUnavailable) || !errors.Is(err, loadErr) {
+		t.Errorf("New() error = %v, want ErrBackendUnavailable wrapping the load error", err)
+	}
+	var be *BackendError
+	if !errors.As(err, &be) || be.Backend != "whisper" {
+		t.Errorf("errors.As(*BackendError) = %v, want backend whisper", be)
+	}
+	if err.Error() != loadErr.Error() {
+		t.Errorf("Error() = %q, want the underlying message %q", err, loadErr)
+	}
+
+	_, err = New(&config.TranscribeConfig{Backend: "parakeet", FallbackBackend: "whisper"})
+	if !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("New() with failing fallback error = %v, want ErrBackendUnavailable", err)
+	}
+
+	_, err = New(&config.TranscribeConfig{Backend: "bogus"})
+	if !errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("New(unknown backend) error = %v, want ErrBackendUnavailable", err)
+	}
+}
+
+func TestProcessAfterClose(t *testing.T) {
+	wt := &WhisperTranscriber{model: &fakeWhisperModel{}}
+	if err := wt.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := wt.Process(make([]float32, 16000)); !errors.Is(err, ErrModelNotLoaded) {
+		t.Errorf("whisper Process() after Close error = %v, want ErrModelNotLoaded", err)
+	}
+
+	pt := &ParakeetTranscriber{pipeline: func([]float32, int) (string, error) { return "ok", nil }}
+	if err := pt.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for name, process := range map[string]func([]float32) (string, error){"Process": pt.Process, "ProcessLong": pt.ProcessLong} {
+		if _, err := process(make([]float32, 16000)); !errors.Is(err, ErrModelNotLoaded) {
+			t.Errorf("parakeet %s() after Close error = %v, want ErrModelNotLoaded", name, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package transcribe
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// wordSpan matches a whitespace-delimited token.
+var wordSpan = regexp.MustCompile(`\S+`)
+
+// ApplyFuzzyReplacements applies rules like ApplyReplacements, then also
+// replaces runs of words that are within maxDistance character edits of a
+// rule's From, so a near miss such as "arrow funk shun" still becomes the
+// replacement for "arrow function". Letters and digits are compared
+// case-insensitively, ignoring spaces and punctuation; punctuation around a
+// matched run is kept. A match must also be closer than half the key's
+// length, so short keys don't swallow unrelated words. maxDistance <= 0
+// means exact matching only.
+func ApplyFuzzyReplacements(text string, rules []config.Replacement, maxDistance int) string {
+	text = ApplyReplacements(text, rules)
+	if maxDistance <= 0 {
+		return text
+	}
+	for _, r := range rules {
+		key := []rune(fuzzyKey(r.From))
+		if len(key) == 0 {
+			continue
+		}
+		limit := min(maxDistance, (len(key)-1)/2)
+		text = fuzzyReplace(text, key, len(strings.Fields(r.From)), r.To, limit)
+	}
+	return text
+}
+
+// fuzzyReplace replaces each run of 1 to keyWords+1 words in text whose
+// fuzzyKey is within limit edits of key. At each word the closest run wins,
+// the shortest on a tie.
+func fuzzyReplace(text string, key []rune, keyWords int, to string, limit int) string {
+	spans := wordSpan.FindAllStringIndex(text, -1)
+	toKey := fuzzyKey(to)
+
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(spans); {
+		bestN, bestDist := 0, limit+1
+		for n := max(1, keyWords-1); n <= keyWords+1 && i+n <= len(spans); n++ {
+			window := fuzzyKey(text[spans[i][0]:spans[i+n-1][1]])
+			if window == toKey {
+				continue // already replaced
+			}
+			if d := editDistance([]rune(window), key); d < bestDist {
+				bestN, bestDist = n, d
+			}
+		}
+		if bestN == 0 {
+			i++
+			continue
+		}
+
+		start, end := spans[i][0], spans[i+bestN-1][1]
+		run := text[start:end]
+		lead := run[:len(run)-len(strings.TrimLeftFunc(run, unicode.IsPunct))]
+		trail := run[len(strings.TrimRightFunc(run, unicode.IsPunct)):]
+		b.WriteString(text[last:start])
+		b.WriteString(lead)
+		b.WriteString(to)
+		b.WriteString(trail)
+		last = end
+		i += bestN
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// fuzzyKey reduces s to its lowercased letters and digits.
+func fuzzyKey(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// editDistance returns the Levenshtein distance between a and b: the
+// minimum number of single-rune substitutions, insertions and deletions
+// that turn a into b.
+func editDistance(a, b []rune) int {
+	n, m := len(a), len(b)
+
+	// DP table for minimum edit distance, as in ComputeWER.
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			if a[i-1] == b[j-1] {
+				d[i][j] = d[i-1][j-1]
+			} else {
+				sub := d[i-1][j-1] + 1
+				del := d[i-1][j] + 1
+				ins := d[i][j-1] + 1
+				d[i][j] = min(sub, min(del, ins))
+			}
+		}
+	}
+	return d[n][m]
+}
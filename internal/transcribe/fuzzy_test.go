@@ -0,0 +1,57 @@
+package transcribe
+
+import (
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestApplyFuzzyReplacements(t *testing.T) {
+	rules := []config.Replacement{
+		{From: "arrow function", To: "=>"},
+		{From: "kubernetes", To: "Kubernetes"},
+		{From: "ai", To: "AI"},
+	}
+	tests := []struct {
+		name        string
+		input       string
+		maxDistance int
+		want        string
+	}{
+		{name: "exact_still_matches", input: "an arrow function here", maxDistance: 2, want: "an => here"},
+		{name: "one_edit", input: "an arrow funktion here", maxDistance: 1, want: "an => here"},
+		{name: "split_word", input: "use arrow funk shun.", maxDistance: 4, want: "use =>."},
+		{name: "phrase_at_start", input: "Cuber Netties cluster", maxDistance: 4, want: "Kubernetes cluster"},
+		{name: "beyond_distance", input: "an arrow funk shun here", maxDistance: 3, want: "an arrow funk shun here"},
+		{name: "exact_only_when_zero", input: "an arrow funktion here", maxDistance: 0, want: "an arrow funktion here"},
+		{name: "short_key_not_fuzzy", input: "a said I", maxDistance: 3, want: "a said I"},
+		{name: "unrelated_text", input: "the narrow corridor", maxDistance: 2, want: "the narrow corridor"},
+		{name: "replacement_not_rematched", input: "kubernetes", maxDistance: 3, want: "Kubernetes"},
+		{name: "whitespace_kept", input: "one\narrow funktion\ttwo", maxDistance: 1, want: "one\n=>\ttwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ApplyFuzzyReplacements(tt.input, rules, tt.maxDistance); got != tt.want {
+				t.Errorf("ApplyFuzzyReplacements(%q, %d) = %q, want %q", tt.input, tt.maxDistance, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"arrowfunction", "arrowfunkshun", 4},
+		{"h\u00e9llo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
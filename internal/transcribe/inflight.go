@@ -0,0 +1,73 @@
+package transcribe
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InFlight counts transcriptions that have been started but not finished,
+// and optionally limits how many run at once. Utterances beyond the limit
+// wait their turn and still count as in flight, so Count is the backlog.
+type InFlight struct {
+	n   atomic.Int32
+	wg  sync.WaitGroup
+	sem chan struct{} // nil = unlimited
+}
+
+// NewInFlight returns an InFlight that runs at most max transcriptions at
+// once. max <= 0 means no limit.
+func NewInFlight(max int) *InFlight {
+	f := &InFlight{}
+	if max > 0 {
+		f.sem = make(chan struct{}, max)
+	}
+	return f
+}
+
+// Add registers a new transcription and returns the number now in flight,
+// including it. Each Add must be followed by Acquire and then Done.
+func (f *InFlight) Add() int {
+	f.wg.Add(1)
+	return int(f.n.Add(1))
+}
+
+// Acquire blocks until a transcription slot is free. Call it after Add and
+// before transcribing, from the goroutine that does the work.
+func (f *InFlight) Acquire() {
+	if f.sem != nil {
+		f.sem <- struct{}{}
+	}
+}
+
+// Done releases the slot taken by Acquire and returns the number still in
+// flight.
+func (f *InFlight) Done() int {
+	if f.sem != nil {
+		<-f.sem
+	}
+	n := int(f.n.Add(-1))
+	f.wg.Done()
+	return n
+}
+
+// Wait blocks until no transcriptions are in flight or timeout passes, and
+// reports whether they all finished.
+func (f *InFlight) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Count returns the number of transcriptions in flight, including waiting ones.
+func (f *InFlight) Count() int {
+	return int(f.n.Load())
+}
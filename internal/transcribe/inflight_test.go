@@ -0,0 +1,80 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInFlightCounts(t *testing.T) {
+	f := NewInFlight(0)
+	if got := f.Add(); got != 1 {
+		t.Errorf("Add() = %d, want 1", got)
+	}
+	if got := f.Add(); got != 2 {
+		t.Errorf("Add() = %d, want 2", got)
+	}
+	f.Acquire()
+	f.Acquire() // unlimited: must not block
+	if got := f.Done(); got != 1 {
+		t.Errorf("Done() = %d, want 1", got)
+	}
+	if got := f.Count(); got != 1 {
+		t.Errorf("Count() = %d, want 1", got)
+	}
+	if got := f.Done(); got != 0 {
+		t.Errorf("Done() = %d, want 0", got)
+	}
+}
+
+func TestInFlightLimit(t *testing.T) {
+	f := NewInFlight(1)
+	f.Add()
+	f.Acquire()
+
+	f.Add()
+	acquired := make(chan struct{})
+	go func() {
+		f.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire() did not wait for a free slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := f.Count(); got != 2 {
+		t.Errorf("Count() = %d, want 2 (waiting transcription counts)", got)
+	}
+
+	f.Done()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second Acquire() did not proceed after Done()")
+	}
+	if got := f.Done(); got != 0 {
+		t.Errorf("Done() = %d, want 0", got)
+	}
+}
+
+func TestInFlightWait(t *testing.T) {
+	f := NewInFlight(0)
+	if !f.Wait(time.Millisecond) {
+		t.Error("Wait() with nothing in flight = false, want true")
+	}
+
+	f.Add()
+	f.Acquire()
+	if f.Wait(10 * time.Millisecond) {
+		t.Error("Wait() with a transcription in flight = true, want false after timeout")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.Done()
+	}()
+	if !f.Wait(time.Second) {
+		t.Error("Wait() = false, want true once the transcription is done")
+	}
+}
@@ -0,0 +1,33 @@
+package transcribe
+
+import "strings"
+
+// joinSegments joins segment texts into one transcript. Each segment is
+// trimmed and runs of whitespace inside it collapse to a single space, so
+// segments that already carry leading or trailing spaces don't produce
+// double spaces. Segments are separated by one space, except before closing
+// punctuation (a segment starting with "," or ".") and after an opening
+// bracket, where whisper's split would otherwise leave a stray space.
+// Empty segments are dropped.
+func joinSegments(texts []string) string {
+	var b strings.Builder
+	for _, text := range texts {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			continue
+		}
+		if b.Len() > 0 && needsSpace(b.String(), text) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// needsSpace reports whether a space belongs between prev and next.
+func needsSpace(prev, next string) bool {
+	if strings.ContainsRune(".,!?;:)]}%", rune(next[0])) {
+		return false
+	}
+	return !strings.ContainsRune("([{", rune(prev[len(prev)-1]))
+}
@@ -0,0 +1,27 @@
+package transcribe
+
+import "testing"
+
+func TestJoinSegments(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{name: "nil", texts: nil, want: ""},
+		{name: "whisper_leading_spaces", texts: []string{" Hello world.", " How are you?"}, want: "Hello world. How are you?"},
+		{name: "trailing_spaces", texts: []string{"Hello ", "world "}, want: "Hello world"},
+		{name: "internal_double_spaces", texts: []string{"one  two", "three\tfour"}, want: "one two three four"},
+		{name: "empty_segments_dropped", texts: []string{"a", "  ", "", "b"}, want: "a b"},
+		{name: "split_before_punctuation", texts: []string{" I think", ", therefore", " I am", "."}, want: "I think, therefore I am."},
+		{name: "split_after_bracket", texts: []string{"call it (", "maybe)", " later"}, want: "call it (maybe) later"},
+		{name: "intentional_spacing_kept", texts: []string{"Wait - what?", " 100 %"}, want: "Wait - what? 100 %"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinSegments(tt.texts); got != tt.want {
+				t.Errorf("joinSegments(%q) = %q, want %q", tt.texts, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package transcribe
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// longOverlapSamples is how much consecutive windows overlap in
+	// transcribeWindows (1s at 16kHz), so a word cut at a window edge is
+	// heard whole in one of them.
+	longOverlapSamples = 16000
+
+	// maxOverlapWords bounds how many words mergeOverlap will treat as
+	// repeated across a window boundary.
+	maxOverlapWords = 8
+)
+
+// LongTranscriber is implemented by backends with a fixed input window that
+// can transcribe longer audio by splitting it into several windows.
+type LongTranscriber interface {
+	// ProcessLong transcribes mono 16kHz float32 audio of any length.
+	ProcessLong(samples []float32) (string, error)
+}
+
+// transcribeWindows splits samples into windows of at most window samples,
+// each overlapping the previous by overlap samples, transcribes them in order
+// with process, and joins the results, dropping words repeated in the
+// overlap. Audio that fits in one window is passed through unchanged.
+func transcribeWindows(samples []float32, window, overlap int, process func([]float32) (string, error)) (string, error) {
+	if len(samples) <= window {
+		return process(samples)
+	}
+
+	step := window - overlap
+	var text string
+	for i, start := 0, 0; ; i, start = i+1, start+step {
+		end := min(start+window, len(samples))
+		part, err := process(samples[start:end])
+		if err != nil {
+			return "", fmt.Errorf("window %d: %w", i, err)
+		}
+		text = mergeOverlap(text, part)
+		if end == len(samples) {
+			break
+		}
+	}
+	return text, nil
+}
+
+// mergeOverlap appends next to prev, dropping the longest run of leading
+// words in next that repeats the trailing words of prev. Words are compared
+// ignoring case and punctuation.
+func mergeOverlap(prev, next string) string {
+	prevWords := strings.Fields(prev)
+	nextWords := strings.Fields(next)
+
+	k := min(len(prevWords), len(nextWords), maxOverlapWords)
+	for ; k > 0; k-- {
+		if sameWords(prevWords[len(prevWords)-k:], nextWords[:k]) {
+			break
+		}
+	}
+
+	return strings.Join(append(prevWords, nextWords[k:]...), " ")
+}
+
+// sameWords reports whether a and b match word for word, ignoring case and
+// punctuation.
+func sameWords(a, b []string) bool {
+	for i := range a {
+		if overlapKey(a[i]) != overlapKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapKey lowercases w and strips punctuation for overlap comparison.
+func overlapKey(w string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, w)
+}
@@ -0,0 +1,112 @@
+package transcribe
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		prev string
+		next string
+		want string
+	}{
+		{name: "empty_prev", prev: "", next: "hello world", want: "hello world"},
+		{name: "empty_next", prev: "hello world", next: "", want: "hello world"},
+		{name: "no_overlap", prev: "the cat sat", next: "on the mat", want: "the cat sat on the mat"},
+		{name: "one_word", prev: "the cat sat", next: "sat on the mat", want: "the cat sat on the mat"},
+		{name: "several_words", prev: "ask not what your country", next: "what your country can do", want: "ask not what your country can do"},
+		{name: "case_and_punctuation", prev: "the quick brown fox.", next: "Fox jumps", want: "the quick brown fox. jumps"},
+		{name: "longest_match_wins", prev: "a b a b", next: "a b a b c", want: "a b a b c"},
+		{name: "only_at_boundary", prev: "the cat sat", next: "cat food", want: "the cat sat cat food"},
+		{name: "whitespace_collapsed", prev: "  one  two ", next: " two  three", want: "one two three"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeOverlap(tt.prev, tt.next); got != tt.want {
+				t.Errorf("mergeOverlap(%q, %q) = %q, want %q", tt.prev, tt.next, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribeWindows(t *testing.T) {
+	// Each sample's value is its index, so windows can be identified by
+	// their first and last samples.
+	samples := make([]float32, 25)
+	for i := range samples {
+		samples[i] = float32(i)
+	}
+
+	// Mock transcriber: each window yields one word per sample, so the
+	// overlapping samples produce repeated words that must be dropped.
+	var windows [][2]int
+	process := func(w []float32) (string, error) {
+		windows = append(windows, [2]int{int(w[0]), int(w[len(w)-1])})
+		words := make([]string, len(w))
+		for i, s := range w {
+			words[i] = "w" + string(rune('a'+int(s)))
+		}
+		return strings.Join(words, " "), nil
+	}
+
+	got, err := transcribeWindows(samples, 10, 2, process)
+	if err != nil {
+		t.Fatalf("transcribeWindows() error = %v", err)
+	}
+
+	wantWindows := [][2]int{{0, 9}, {8, 17}, {16, 24}}
+	if !reflect.DeepEqual(windows, wantWindows) {
+		t.Errorf("windows = %v, want %v", windows, wantWindows)
+	}
+
+	want := make([]string, len(samples))
+	for i := range samples {
+		want[i] = "w" + string(rune('a'+i))
+	}
+	if got != strings.Join(want, " ") {
+		t.Errorf("transcribeWindows() = %q, want %q", got, strings.Join(want, " "))
+	}
+}
+
+func TestTranscribeWindowsShortAudio(t *testing.T) {
+	calls := 0
+	process := func(w []float32) (string, error) {
+		calls++
+		if len(w) != 10 {
+			t.Errorf("window len = %d, want 10", len(w))
+		}
+		return "hello", nil
+	}
+
+	got, err := transcribeWindows(make([]float32, 10), 10, 2, process)
+	if err != nil {
+		t.Fatalf("transcribeWindows() error = %v", err)
+	}
+	if got != "hello" || calls != 1 {
+		t.Errorf("transcribeWindows() = %q after %d calls, want %q after 1", got, calls, "hello")
+	}
+}
+
+func TestTranscribeWindowsError(t *testing.T) {
+	errBoom := errors.New("boom")
+	calls := 0
+	process := func(w []float32) (string, error) {
+		calls++
+		if calls == 2 {
+			return "", errBoom
+		}
+		return "ok", nil
+	}
+
+	_, err := transcribeWindows(make([]float32, 30), 10, 2, process)
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("transcribeWindows() error = %v, want %v", err, errBoom)
+	}
+	if calls != 2 {
+		t.Errorf("process called %d times, want 2 (stop at first error)", calls)
+	}
+}
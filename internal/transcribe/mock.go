@@ -0,0 +1,62 @@
+package transcribe
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Compile-time interface satisfaction check.
+var _ Transcriber = (*MockTranscriber)(nil)
+
+// MockTranscriber is a model-free backend for end-to-end tests of the
+// record → transcribe → inject path. It ignores the audio and returns
+// scripted transcripts in turn, starting over after the last.
+type MockTranscriber struct {
+	mu    sync.Mutex
+	texts []string
+	next  int
+}
+
+// NewMockTranscriber returns a MockTranscriber for transcribe.mock_text:
+// either a fixed transcript, or "file:<path>" to return the file's
+// non-empty lines one per call.
+func NewMockTranscriber(mockText string) (*MockTranscriber, error) {
+	path, ok := strings.CutPrefix(mockText, "file:")
+	if !ok {
+		if mockText == "" {
+			return nil, fmt.Errorf("transcribe: mock backend needs transcribe.mock_text")
+		}
+		return &MockTranscriber{texts: []string{mockText}}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("transcribe: read mock transcripts: %w", err)
+	}
+	var texts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			texts = append(texts, line)
+		}
+	}
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("transcribe: mock transcript file %q is empty", path)
+	}
+	return &MockTranscriber{texts: texts}, nil
+}
+
+// Process returns the next scripted transcript, whatever the samples.
+func (t *MockTranscriber) Process([]float32) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text := t.texts[t.next]
+	t.next = (t.next + 1) % len(t.texts)
+	return text, nil
+}
+
+// Close is a no-op.
+func (t *MockTranscriber) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package transcribe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestNewMockBackend(t *testing.T) {
+	tr, err := New(&config.TranscribeConfig{Backend: "mock", MockText: "hello world", Warmup: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = tr.Close() }()
+	if got := BackendName(tr); got != "mock" {
+		t.Errorf("BackendName() = %q, want \"mock\"", got)
+	}
+
+	for _, samples := range [][]float32{nil, make([]float32, 16000), {0.5, -0.5}} {
+		text, err := tr.Process(samples)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if text != "hello world" {
+			t.Errorf("Process(%d samples) = %q, want \"hello world\"", len(samples), text)
+		}
+	}
+}
+
+func TestMockTranscriberFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcripts.txt")
+	if err := os.WriteFile(path, []byte("first\n\n  second  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewMockTranscriber("file:" + path)
+	if err != nil {
+		t.Fatalf("NewMockTranscriber() error = %v", err)
+	}
+	for _, want := range []string{"first", "second", "first"} {
+		if got, _ := tr.Process(nil); got != want {
+			t.Errorf("Process() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestNewMockTranscriberErrors(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, []byte("\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, mockText := range []string{"", "file:" + empty, "file:/nonexistent/transcripts.txt"} {
+		if _, err := NewMockTranscriber(mockText); err == nil {
+			t.Errorf("NewMockTranscriber(%q) should fail", mockText)
+		}
+	}
+}
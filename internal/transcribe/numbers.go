@@ -0,0 +1,355 @@
+package transcribe
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numberKind classifies how a spoken number word combines with its neighbours.
+type numberKind int
+
+const (
+	kindUnit    numberKind = iota // zero..nine
+	kindTeen                      // ten..nineteen
+	kindTens                      // twenty..ninety
+	kindHundred                   // hundred
+	kindScale                     // thousand, million, billion
+)
+
+// numberWord is the value and kind of a single spoken number word.
+type numberWord struct {
+	kind    numberKind
+	value   int64
+	ordinal bool
+}
+
+// numberWords maps lowercase English number words (cardinal and ordinal) to
+// their values.
+var numberWords = func() map[string]numberWord {
+	m := make(map[string]numberWord)
+	add := func(kind numberKind, value int64, cardinal, ordinal string) {
+		m[cardinal] = numberWord{kind: kind, value: value}
+		m[ordinal] = numberWord{kind: kind, value: value, ordinal: true}
+	}
+	add(kindUnit, 0, "zero", "zeroth")
+	add(kindUnit, 1, "one", "first")
+	add(kindUnit, 2, "two", "second")
+	add(kindUnit, 3, "three", "third")
+	add(kindUnit, 4, "four", "fourth")
+	add(kindUnit, 5, "five", "fifth")
+	add(kindUnit, 6, "six", "sixth")
+	add(kindUnit, 7, "seven", "seventh")
+	add(kindUnit, 8, "eight", "eighth")
+	add(kindUnit, 9, "nine", "ninth")
+	add(kindTeen, 10, "ten", "tenth")
+	add(kindTeen, 11, "eleven", "eleventh")
+	add(kindTeen, 12, "twelve", "twelfth")
+	add(kindTeen, 13, "thirteen", "thirteenth")
+	add(kindTeen, 14, "fourteen", "fourteenth")
+	add(kindTeen, 15, "fifteen", "fifteenth")
+	add(kindTeen, 16, "sixteen", "sixteenth")
+	add(kindTeen, 17, "seventeen", "seventeenth")
+	add(kindTeen, 18, "eighteen", "eighteenth")
+	add(kindTeen, 19, "nineteen", "nineteenth")
+	add(kindTens, 20, "twenty", "twentieth")
+	add(kindTens, 30, "thirty", "thirtieth")
+	add(kindTens, 40, "forty", "fortieth")
+	add(kindTens, 50, "fifty", "fiftieth")
+	add(kindTens, 60, "sixty", "sixtieth")
+	add(kindTens, 70, "seventy", "seventieth")
+	add(kindTens, 80, "eighty", "eightieth")
+	add(kindTens, 90, "ninety", "ninetieth")
+	add(kindHundred, 100, "hundred", "hundredth")
+	add(kindScale, 1_000, "thousand", "thousandth")
+	add(kindScale, 1_000_000, "million", "millionth")
+	add(kindScale, 1_000_000_000, "billion", "billionth")
+	return m
+}()
+
+// ambiguousNumberWords are left untouched when they appear on their own,
+// since they are more often used as ordinary words ("no one", "at first",
+// "wait a second").
+var ambiguousNumberWords = map[string]bool{
+	"one":    true,
+	"first":  true,
+	"second": true,
+}
+
+// wordPattern matches a word, including hyphenated compounds like "twenty-three".
+var wordPattern = regexp.MustCompile(`[A-Za-z]+(?:-[A-Za-z]+)*`)
+
+// NormalizeNumbers rewrites spoken English numbers as digits, e.g.
+// "twenty twenty three" → "2023", "one hundred and five" → "105",
+// "point five" → ".5" and "twenty third" → "23rd". Text that is not part of
+// a number phrase is preserved exactly. Ambiguous phrases, such as a lone
+// "one" or number sequences that don't read as a single value ("two thirty"),
+// are left alone.
+func NormalizeNumbers(text string) string {
+	locs := wordPattern.FindAllStringIndex(text, -1)
+	if len(locs) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	prev := 0
+	for i := 0; i < len(locs); {
+		// A number phrase never spans punctuation, so work on runs of words
+		// separated only by whitespace.
+		j := i + 1
+		for j < len(locs) && isWhitespace(text[locs[j-1][1]:locs[j][0]]) {
+			j++
+		}
+
+		for k := i; k < j; {
+			out, n, ok := matchNumberPhrase(text, locs[k:j])
+			if n == 0 {
+				k++
+				continue
+			}
+			if !ok {
+				// Ambiguous phrase: keep all of its words as spoken.
+				k += n
+				continue
+			}
+			b.WriteString(text[prev:locs[k][0]])
+			b.WriteString(out)
+			prev = locs[k+n-1][1]
+			k += n
+		}
+		i = j
+	}
+	b.WriteString(text[prev:])
+	return b.String()
+}
+
+// matchNumberPhrase tries to parse a number phrase starting at the first of
+// the given words. It returns the digit form and how many words the phrase
+// spans, or n == 0 if the words don't start a number phrase. ok is false if
+// the phrase is ambiguous and should be left as spoken.
+func matchNumberPhrase(text string, locs [][]int) (out string, n int, ok bool) {
+	// Expand words into atoms, splitting hyphenated number compounds.
+	// wordEnds[w] is the atom index just past word w.
+	var atoms []string
+	var wordEnds []int
+expand:
+	for _, loc := range locs {
+		word := strings.ToLower(text[loc[0]:loc[1]])
+		parts := strings.Split(word, "-")
+		if len(parts) > 1 {
+			for _, p := range parts {
+				if _, ok := numberWords[p]; !ok {
+					// Hyphenated non-number ("well-known", "five-year")
+					// ends the phrase.
+					break expand
+				}
+			}
+		}
+		atoms = append(atoms, parts...)
+		wordEnds = append(wordEnds, len(atoms))
+	}
+
+	for len(wordEnds) > 0 {
+		out, n, ok = parseNumberPhrase(atoms)
+		if n == 0 {
+			return "", 0, false
+		}
+
+		// Only accept phrases that end on a word boundary, so a hyphenated
+		// compound is never split.
+		words := 0
+		for words < len(wordEnds) && wordEnds[words] <= n {
+			words++
+		}
+		if words == 0 {
+			return "", 0, false
+		}
+		if wordEnds[words-1] != n {
+			atoms = atoms[:wordEnds[words-1]]
+			wordEnds = wordEnds[:words]
+			continue
+		}
+
+		if words == 1 && ambiguousNumberWords[strings.ToLower(text[locs[0][0]:locs[0][1]])] {
+			ok = false
+		}
+		return out, words, ok
+	}
+	return "", 0, false
+}
+
+// numberParser accumulates number words into one or more values. Each value
+// is a "segment"; consecutive segments arise from sequences like
+// "nineteen eighty four" (19, 84) or "five five five" (5, 5, 5).
+type numberParser struct {
+	segments []int64
+
+	started    bool       // current segment has at least one word
+	last       numberKind // kind of the most recent word
+	total      int64      // sum of completed thousand/million/billion groups
+	small      int64      // current group below one thousand
+	hasHundred bool       // current group already has a "hundred"
+	lastScale  int64      // most recent scale word, to enforce descending order
+}
+
+// accepts reports whether w can extend the current segment.
+func (p *numberParser) accepts(w numberWord) bool {
+	if !p.started {
+		return w.kind <= kindTens
+	}
+	switch w.kind {
+	case kindUnit:
+		return (p.last == kindTens && w.value > 0) || p.last == kindHundred || p.last == kindScale
+	case kindTeen, kindTens:
+		return p.last == kindHundred || p.last == kindScale
+	case kindHundred:
+		return p.last <= kindTens && !p.hasHundred && p.small > 0
+	case kindScale:
+		return p.last <= kindHundred && p.small > 0 && (p.lastScale == 0 || w.value < p.lastScale)
+	}
+	return false
+}
+
+// add extends the current segment with w. The caller must check accepts first.
+func (p *numberParser) add(w numberWord) {
+	switch w.kind {
+	case kindHundred:
+		p.small *= w.value
+		p.hasHundred = true
+	case kindScale:
+		p.total += p.small * w.value
+		p.small = 0
+		p.hasHundred = false
+		p.lastScale = w.value
+	default:
+		p.small += w.value
+	}
+	p.last = w.kind
+	p.started = true
+}
+
+// flush closes the current segment.
+func (p *numberParser) flush() {
+	if p.started {
+		p.segments = append(p.segments, p.total+p.small)
+	}
+	*p = numberParser{segments: p.segments}
+}
+
+// parseNumberPhrase consumes the longest number phrase at the start of atoms
+// and returns its digit form and the number of atoms consumed. ok is false if
+// the phrase doesn't read as a single number.
+func parseNumberPhrase(atoms []string) (string, int, bool) {
+	var p numberParser
+	var ordinal bool
+	var decimals string
+	i := 0
+
+loop:
+	for i < len(atoms) {
+		switch atoms[i] {
+		case "and":
+			// "one hundred and five": only joins a scale word to a smaller number.
+			if (p.last == kindHundred || p.last == kindScale) && p.started && i+1 < len(atoms) {
+				if next, ok := numberWords[atoms[i+1]]; ok && next.kind <= kindTens && p.accepts(next) {
+					i++
+					continue
+				}
+			}
+			break loop
+		case "point":
+			var digits strings.Builder
+			j := i + 1
+			for ; j < len(atoms); j++ {
+				w, ok := numberWords[atoms[j]]
+				if !ok || w.kind != kindUnit || w.ordinal {
+					break
+				}
+				digits.WriteByte(byte('0' + w.value))
+			}
+			if digits.Len() > 0 {
+				decimals = "." + digits.String()
+				i = j
+			}
+			break loop
+		}
+
+		w, ok := numberWords[atoms[i]]
+		if !ok {
+			break
+		}
+		if !p.accepts(w) {
+			// Only plain cardinals below one hundred may start a new
+			// segment; "one second" must not become "12nd".
+			if w.kind > kindTens || w.ordinal {
+				break
+			}
+			p.flush()
+		}
+		p.add(w)
+		i++
+		if w.ordinal {
+			ordinal = true
+			break
+		}
+	}
+	p.flush()
+
+	if i == 0 {
+		return "", 0, false
+	}
+
+	digits, ok := joinNumberSegments(p.segments)
+	if !ok {
+		return "", i, false
+	}
+	if ordinal {
+		digits += ordinalSuffix(p.segments[len(p.segments)-1])
+	}
+	return digits + decimals, i, true
+}
+
+// joinNumberSegments renders parsed segments as a single digit string. A single
+// segment is rendered as-is. Multiple segments are only joined when they
+// read unambiguously as one number: a digit sequence ("five five five") or a
+// year-style pair ("nineteen eighty four", "twenty twenty three"). A year
+// pair must start with thirteen to twenty, so clock times ("ten thirty",
+// "eleven fifteen") and ranges ("thirty forty") are left alone.
+func joinNumberSegments(segments []int64) (string, bool) {
+	switch {
+	case len(segments) == 1:
+		return strconv.FormatInt(segments[0], 10), true
+	case len(segments) == 2 && segments[0] >= 13 && segments[0] <= 20 && segments[1] >= 10 && segments[1] < 100:
+		return strconv.FormatInt(segments[0], 10) + strconv.FormatInt(segments[1], 10), true
+	}
+
+	var b strings.Builder
+	for _, s := range segments {
+		if s > 9 {
+			return "", false
+		}
+		b.WriteString(strconv.FormatInt(s, 10))
+	}
+	return b.String(), true
+}
+
+// ordinalSuffix returns the English ordinal suffix for n ("st", "nd", "rd", "th").
+func ordinalSuffix(n int64) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// isWhitespace reports whether s is non-empty and contains only spaces or tabs.
+func isWhitespace(s string) bool {
+	return s != "" && strings.Trim(s, " \t") == ""
+}
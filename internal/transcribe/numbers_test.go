@@ -0,0 +1,95 @@
+package transcribe
+
+import "testing"
+
+func TestNormalizeNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// Cardinals
+		{name: "single_digit", input: "I have two cats", want: "I have 2 cats"},
+		{name: "zero", input: "zero", want: "0"},
+		{name: "teen", input: "fifteen minutes", want: "15 minutes"},
+		{name: "tens_and_unit", input: "twenty five", want: "25"},
+		{name: "hyphenated", input: "twenty-five people", want: "25 people"},
+		{name: "capitalized", input: "Twenty five", want: "25"},
+		{name: "hundred_and", input: "one hundred and five", want: "105"},
+		{name: "hundred_no_and", input: "three hundred twelve", want: "312"},
+		{name: "tens_hundred", input: "twenty five hundred", want: "2500"},
+		{name: "thousand_and", input: "two thousand and twenty", want: "2020"},
+		{name: "thousand", input: "two thousand twenty three", want: "2023"},
+		{name: "hundred_thousand", input: "five hundred thousand", want: "500000"},
+		{name: "million", input: "one million two hundred thousand", want: "1200000"},
+
+		// Sequences
+		{name: "year_pair", input: "twenty twenty three", want: "2023"},
+		{name: "year_in_sentence", input: "I was born in nineteen eighty four.", want: "I was born in 1984."},
+		{name: "year_thirteen", input: "in thirteen fifty", want: "in 1350"},
+		{name: "digit_sequence", input: "call five five five one two three four", want: "call 5551234"},
+		{name: "short_digit_sequence", input: "one two three", want: "123"},
+
+		// Ordinals
+		{name: "ordinal", input: "the fourth of July", want: "the 4th of July"},
+		{name: "ordinal_teen", input: "eleventh", want: "11th"},
+		{name: "ordinal_compound", input: "the twenty third of May", want: "the 23rd of May"},
+		{name: "ordinal_first", input: "twenty first", want: "21st"},
+		{name: "ordinal_second", input: "twenty second", want: "22nd"},
+		{name: "ordinal_hundredth", input: "one hundredth", want: "100th"},
+		{name: "ordinal_twelfth", input: "twelfth", want: "12th"},
+
+		// Decimals
+		{name: "point_only", input: "point five", want: ".5"},
+		{name: "decimal", input: "three point one four", want: "3.14"},
+		{name: "point_not_number", input: "the point is", want: "the point is"},
+		{name: "point_trailing", input: "two point", want: "2 point"},
+
+		// Ambiguous or non-number text is left alone
+		{name: "one_on_one", input: "one on one", want: "one on one"},
+		{name: "lone_one", input: "one of them", want: "one of them"},
+		{name: "at_first", input: "at first", want: "at first"},
+		{name: "wait_a_second", input: "wait a second", want: "wait a second"},
+		{name: "one_second", input: "wait one second", want: "wait one second"},
+		{name: "time_like", input: "at two thirty", want: "at two thirty"},
+		{name: "clock_time_ten", input: "at ten thirty", want: "at ten thirty"},
+		{name: "clock_time_eleven", input: "eleven fifteen", want: "eleven fifteen"},
+		{name: "clock_time_twelve", input: "twelve forty five", want: "twelve forty five"},
+		{name: "range", input: "thirty forty people", want: "thirty forty people"},
+		{name: "lone_hundred", input: "hundred", want: "hundred"},
+		{name: "a_thousand", input: "a thousand", want: "a thousand"},
+		{name: "and_between_units", input: "two and three", want: "2 and 3"},
+		{name: "rock_and_roll", input: "rock and roll", want: "rock and roll"},
+		{name: "hyphenated_non_number", input: "forty-two-year-old", want: "forty-two-year-old"},
+		{name: "punctuation_breaks_phrase", input: "seven, eight", want: "7, 8"},
+		{name: "no_numbers", input: "no numbers here", want: "no numbers here"},
+		{name: "someone", input: "someone", want: "someone"},
+		{name: "empty", input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeNumbers(tt.input)
+			if got != tt.want {
+				t.Errorf("NormalizeNumbers(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrdinalSuffix(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{1, "st"}, {2, "nd"}, {3, "rd"}, {4, "th"},
+		{11, "th"}, {12, "th"}, {13, "th"},
+		{21, "st"}, {22, "nd"}, {23, "rd"},
+		{100, "th"}, {101, "st"}, {111, "th"},
+	}
+	for _, tt := range tests {
+		if got := ordinalSuffix(tt.n); got != tt.want {
+			t.Errorf("ordinalSuffix(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package transcribe
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/chaz8081/gostt-writer/internal/coreml"
@@ -11,8 +14,28 @@ import (
 
 const parakeetMaxSamples = 240000 // 15s at 16kHz
 
+const (
+	parakeetHopSamples  = 160 // preprocessor mel hop (10ms at 16kHz)
+	parakeetSubsampling = 8   // mel frames per encoder frame
+)
+
+// ErrPredictTimeout is returned when a model run exceeds
+// ParakeetOptions.PredictTimeout, or while a run that timed out earlier is
+// still holding the models.
+var ErrPredictTimeout = errors.New("parakeet: prediction timed out")
+
+// Compile-time interface satisfaction checks.
+var (
+	_ Transcriber     = (*ParakeetTranscriber)(nil)
+	_ LongTranscriber = (*ParakeetTranscriber)(nil)
+)
+
 // ParakeetTranscriber uses Parakeet TDT 0.6B v2 via CoreML for speech-to-text.
+// It is safe for concurrent use; calls to Process are serialized because the
+// CoreML model handles are shared across the decode loop.
 type ParakeetTranscriber struct {
+	mu sync.Mutex // guards the models below for the duration of a Process call
+
 	preprocessor *coreml.Model
 	encoder      *coreml.Model
 	decoder      *coreml.Model
@@ -24,16 +47,84 @@ type ParakeetTranscriber struct {
 	encInputNames   []string
 	decInputNames   []string
 	jointInputNames []string
+
+	decode tdtParams // blank ID and symbol limit for the decode loop
+
+	predictTimeout time.Duration // budget for one pipeline run (0 = unlimited)
+	stuck          chan struct{} // closed when a timed-out run finishes; nil if none
+	closed         bool          // Close released the models
+
+	// pipeline runs the full model pipeline on padded audio whose first
+	// valid samples are real. It defaults to runPipeline and is replaced in
+	// tests.
+	pipeline func(padded []float32, valid int) (string, error)
+
+	// encode runs the preprocessor and encoder on padded audio, returning
+	// the encoder output as [frames, hidden] flattened and the number of
+	// frames that cover the first valid samples; tdt runs the decode loop on
+	// it. They default to runEncode and the CoreML decoder and joint, and
+	// are replaced in tests.
+	encode func(padded []float32, valid int) (output []float32, frames int, err error)
+	tdt    func(output []float32, frames int, params tdtParams) ([]int32, error)
+}
+
+// ParakeetOptions configures a ParakeetTranscriber for a particular model
+// conversion. Zero values select defaults.
+type ParakeetOptions struct {
+	// BlankID is the token index of the TDT blank symbol. nil detects it
+	// from the vocabulary: its last entry if that is "<blank>", otherwise
+	// 1024.
+	BlankID *int
+	// MaxSymbolsPerStep caps the tokens emitted on a single encoder frame
+	// (default 10).
+	MaxSymbolsPerStep int
+	// MaxRepeats stops a token from being emitted more than this many times
+	// in a row; the decoder moves to the next frame instead (0 = no limit).
+	MaxRepeats int
+	// ComputeUnits selects the CoreML compute units for the encoder, decoder
+	// and joint models (default coreml.ComputeAll). The preprocessor always
+	// runs on the CPU.
+	ComputeUnits coreml.ComputeUnits
+	// PredictTimeout bounds how long one run of the model pipeline may take
+	// before Process gives up with ErrPredictTimeout (0 = no limit). CoreML
+	// calls can't be cancelled, so a run that times out keeps going in the
+	// background; it only unblocks the caller. Until that run finishes,
+	// further calls fail fast instead of queueing behind it.
+	PredictTimeout time.Duration
+}
+
+// computeUnitNames maps transcribe.parakeet.compute_units values to CoreML
+// compute units.
+var computeUnitNames = map[string]coreml.ComputeUnits{
+	"all":         coreml.ComputeAll,
+	"cpu_only":    coreml.ComputeCPUOnly,
+	"cpu_and_gpu": coreml.ComputeCPUAndGPU,
+	"cpu_and_ane": coreml.ComputeCPUAndANE,
+}
+
+// ParseComputeUnits returns the CoreML compute units for a config name such
+// as "cpu_and_ane". An empty name selects coreml.ComputeAll.
+func ParseComputeUnits(name string) (coreml.ComputeUnits, error) {
+	if name == "" {
+		return coreml.ComputeAll, nil
+	}
+	units, ok := computeUnitNames[name]
+	if !ok {
+		return 0, fmt.Errorf("parakeet: unknown compute units %q", name)
+	}
+	return units, nil
 }
 
 // NewParakeetTranscriber loads the 4 CoreML models and vocabulary from modelDir.
-func NewParakeetTranscriber(modelDir string) (*ParakeetTranscriber, error) {
+func NewParakeetTranscriber(modelDir string, opts ParakeetOptions) (*ParakeetTranscriber, error) {
 	// Load vocabulary
 	vocabPath := modelDir + "/parakeet_vocab.json"
 	vocab, err := loadVocabulary(vocabPath)
 	if err != nil {
 		return nil, fmt.Errorf("parakeet: %w", err)
 	}
+	decode := parakeetDecodeParams(vocab, opts)
+	slog.Debug("parakeet decode settings", "blank_id", decode.blankID, "max_symbols", decode.maxSymsPerStep)
 
 	// Load CoreML models
 	// Preprocessor runs on CPU (mel spectrogram is faster on CPU)
@@ -43,8 +134,9 @@ func NewParakeetTranscriber(modelDir string) (*ParakeetTranscriber, error) {
 		return nil, fmt.Errorf("parakeet: load preprocessor: %w", err)
 	}
 
-	// Encoder, decoder, joint run on all units (ANE preferred)
-	coreml.SetComputeUnits(coreml.ComputeAll)
+	// Encoder, decoder, joint run on the configured units (default: all,
+	// ANE preferred)
+	coreml.SetComputeUnits(opts.ComputeUnits)
 	encoder, err := coreml.LoadModel(modelDir + "/Encoder.mlmodelc")
 	if err != nil {
 		preprocessor.Close()
@@ -67,11 +159,18 @@ func NewParakeetTranscriber(modelDir string) (*ParakeetTranscriber, error) {
 	}
 
 	p := &ParakeetTranscriber{
-		preprocessor: preprocessor,
-		encoder:      encoder,
-		decoder:      decoder,
-		joint:        joint,
-		vocab:        vocab,
+		preprocessor:   preprocessor,
+		encoder:        encoder,
+		decoder:        decoder,
+		joint:          joint,
+		vocab:          vocab,
+		decode:         decode,
+		predictTimeout: opts.PredictTimeout,
+	}
+	p.pipeline = p.runPipeline
+	p.encode = p.runEncode
+	p.tdt = func(output []float32, frames int, params tdtParams) ([]int32, error) {
+		return tdtDecode(output, frames, params, p, p)
 	}
 
 	// Cache sorted input names from model introspection
@@ -91,6 +190,22 @@ func NewParakeetTranscriber(modelDir string) (*ParakeetTranscriber, error) {
 
 // Close releases all CoreML model resources.
 func (p *ParakeetTranscriber) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stuck != nil {
+		select {
+		case <-p.stuck:
+		default:
+			// A timed-out prediction is still using the models; freeing
+			// them underneath it would crash. Leak them instead.
+			slog.Warn("parakeet: prediction still running at close, not releasing models")
+			p.closed = true
+			return nil
+		}
+	}
+	p.closed = true
+
 	if p.preprocessor != nil {
 		p.preprocessor.Close()
 	}
@@ -107,45 +222,144 @@ func (p *ParakeetTranscriber) Close() error {
 }
 
 // Process transcribes mono 16kHz float32 audio samples to text.
+// Concurrent calls are run one at a time. Empty audio yields empty text.
 func (p *ParakeetTranscriber) Process(samples []float32) (string, error) {
+	if len(samples) == 0 {
+		return "", nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", ErrModelNotLoaded
+	}
+	if len(samples) > parakeetMaxSamples {
+		slog.Warn("Audio exceeds parakeet window, transcribing the start only",
+			"audio_s", float64(len(samples))/16000,
+			"max_s", float64(parakeetMaxSamples)/16000)
+	}
+
 	// Pad or truncate to maxModelSamples
-	padded := padAudio(samples, parakeetMaxSamples)
+	return p.runPipelineTimed(padAudio(samples, parakeetMaxSamples), min(len(samples), parakeetMaxSamples))
+}
+
+// ProcessLong transcribes mono 16kHz float32 audio of any length by running
+// the model on overlapping 15s windows and joining the results.
+func (p *ParakeetTranscriber) ProcessLong(samples []float32) (string, error) {
+	if len(samples) == 0 {
+		return "", nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", ErrModelNotLoaded
+	}
+	text, err := transcribeWindows(samples, parakeetMaxSamples, longOverlapSamples, func(window []float32) (string, error) {
+		return p.runPipelineTimed(padAudio(window, parakeetMaxSamples), min(len(window), parakeetMaxSamples))
+	})
+	if err != nil {
+		return "", fmt.Errorf("parakeet: %w", err)
+	}
+	return text, nil
+}
+
+// runPipelineTimed runs p.pipeline, giving up after p.predictTimeout. A run
+// that times out is left to finish in the background and recorded in
+// p.stuck; later calls fail until it does, so two runs never use the models
+// at once. The caller must hold p.mu.
+func (p *ParakeetTranscriber) runPipelineTimed(padded []float32, valid int) (string, error) {
+	if p.stuck != nil {
+		select {
+		case <-p.stuck:
+			p.stuck = nil
+		default:
+			return "", fmt.Errorf("%w: an earlier prediction is still running", ErrPredictTimeout)
+		}
+	}
+	if p.predictTimeout <= 0 {
+		return p.pipeline(padded, valid)
+	}
 
+	var text string
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		text, err = p.pipeline(padded, valid)
+	}()
+
+	timer := time.NewTimer(p.predictTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return text, err
+	case <-timer.C:
+		p.stuck = done
+		return "", fmt.Errorf("%w after %s", ErrPredictTimeout, p.predictTimeout)
+	}
+}
+
+// runPipeline runs preprocessor, encoder and TDT decode on padded audio.
+// The caller must hold p.mu.
+func (p *ParakeetTranscriber) runPipeline(padded []float32, valid int) (string, error) {
+	encoderOutput, encoderLength, err := p.encode(padded, valid)
+	if err != nil {
+		return "", err
+	}
+	return p.decodeEncoded(encoderOutput, encoderLength)
+}
+
+// runEncode runs the preprocessor and encoder on padded audio and returns
+// the encoder output as [frames, hidden] flattened, plus the number of
+// frames covering the first valid samples; the rest are padding and must
+// not be decoded. The caller must hold p.mu.
+func (p *ParakeetTranscriber) runEncode(padded []float32, valid int) ([]float32, int, error) {
 	// Step 1: Preprocessor (audio → mel features)
-	prepResult, err := p.runPreprocessor(padded)
+	prepResult, err := p.runPreprocessor(padded, valid)
 	if err != nil {
-		return "", fmt.Errorf("parakeet: preprocessor: %w", err)
+		return nil, 0, fmt.Errorf("parakeet: preprocessor: %w", err)
 	}
 	defer prepResult.Close()
 
 	// Step 2: Encoder (mel features → encoder hidden states)
 	encResult, err := p.runEncoder(prepResult)
 	if err != nil {
-		return "", fmt.Errorf("parakeet: encoder: %w", err)
+		return nil, 0, fmt.Errorf("parakeet: encoder: %w", err)
 	}
 	defer encResult.Close()
 
 	// Extract encoder output and length
-	encoderOutput, encoderLength, err := p.extractEncoderOutput(encResult)
+	encoderOutput, allocated, err := p.extractEncoderOutput(encResult)
 	if err != nil {
-		return "", fmt.Errorf("parakeet: %w", err)
+		return nil, 0, fmt.Errorf("parakeet: %w", err)
 	}
+	encoderLength := validEncoderFrames(
+		tensorInt32(encResult, "encoder_length"), tensorInt32(prepResult, "mel_length"), valid, allocated)
 
-	slog.Debug("parakeet encoder", "frames", encoderLength, "totalFloats", len(encoderOutput))
+	slog.Debug("parakeet encoder", "frames", encoderLength, "allocated", allocated, "totalFloats", len(encoderOutput))
+	return encoderOutput, encoderLength, nil
+}
 
-	// Step 3+4: TDT decode loop (decoder + joint)
-	tokens, err := tdtDecode(encoderOutput, encoderLength, p, p)
+// decodeEncoded runs the TDT decode loop (decoder + joint) on encoder output
+// and converts the tokens to text. The caller must hold p.mu.
+func (p *ParakeetTranscriber) decodeEncoded(encoderOutput []float32, encoderLength int) (string, error) {
+	tokens, err := p.tdt(encoderOutput, encoderLength, p.decode)
 	if err != nil {
 		return "", fmt.Errorf("parakeet: decode: %w", err)
 	}
-
-	// Step 5: Convert tokens to text
-	text := decodeTokens(tokens, p.vocab)
-	return text, nil
+	return decodeTokens(tokens, p.vocab), nil
 }
 
-// runPreprocessor runs the preprocessor model on raw audio.
-func (p *ParakeetTranscriber) runPreprocessor(audio []float32) (*coreml.PredictAllocResult, error) {
+// runPreprocessor runs the preprocessor model on raw audio, of which the
+// first valid samples are real and the rest padding.
+func (p *ParakeetTranscriber) runPreprocessor(audio []float32, valid int) (*coreml.PredictAllocResult, error) {
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("empty audio")
+	}
+
 	// Create audio_signal tensor [1, N]
 	audioTensor, err := coreml.NewTensorWithData(
 		[]int64{1, int64(len(audio))},
@@ -157,8 +371,9 @@ func (p *ParakeetTranscriber) runPreprocessor(audio []float32) (*coreml.PredictA
 	}
 	defer audioTensor.Close()
 
-	// Create audio_length tensor [1] with value N
-	audioLen := []int32{int32(len(audio))}
+	// Create audio_length tensor [1] with the real sample count, so the
+	// model's mel and encoder lengths exclude the padding.
+	audioLen := []int32{int32(min(valid, len(audio)))}
 	audioLenTensor, err := coreml.NewTensorWithData(
 		[]int64{1},
 		coreml.DTypeInt32,
@@ -197,11 +412,12 @@ func (p *ParakeetTranscriber) runEncoder(prepResult *coreml.PredictAllocResult)
 	return p.encoder.PredictAlloc(p.encInputNames, inputs)
 }
 
-// extractEncoderOutput extracts the flattened encoder hidden states and length from encoder outputs.
+// extractEncoderOutput extracts the flattened encoder hidden states from
+// encoder outputs, with the number of frames allocated; see
+// validEncoderFrames for how many of them to decode.
 // The encoder output shape is [1, encoderHidden, T] (not [1, T, encoderHidden]).
 func (p *ParakeetTranscriber) extractEncoderOutput(encResult *coreml.PredictAllocResult) ([]float32, int, error) {
 	encoderTensor := encResult.Tensor("encoder")
-	lengthTensor := encResult.Tensor("encoder_length")
 
 	if encoderTensor == nil {
 		return nil, 0, fmt.Errorf("no 'encoder' output tensor found in result (got %v)", encResult.Names)
@@ -215,16 +431,7 @@ func (p *ParakeetTranscriber) extractEncoderOutput(encResult *coreml.PredictAllo
 	H := int(encoderTensor.Dim(1)) // encoder hidden size
 	T := int(encoderTensor.Dim(2)) // number of frames
 
-	// Extract encoder length
-	var encoderLength int
-	if lengthTensor != nil && lengthTensor.DType() == coreml.DTypeInt32 {
-		data := (*int32)(lengthTensor.DataPtr())
-		encoderLength = int(*data)
-	} else {
-		encoderLength = T
-	}
-
-	slog.Debug("parakeet encoder output", "shape", encoderTensor.Shape(), "H", H, "T", T, "encoderLength", encoderLength)
+	slog.Debug("parakeet encoder output", "shape", encoderTensor.Shape(), "H", H, "T", T)
 
 	// The decode loop expects encoderOutput as a flat array indexed by [t*H + h].
 	// CoreML stores the data in row-major order as [1, H, T] meaning memory layout is H×T.
@@ -250,7 +457,33 @@ func (p *ParakeetTranscriber) extractEncoderOutput(encResult *coreml.PredictAllo
 		}
 	}
 
-	return encoderData, encoderLength, nil
+	return encoderData, T, nil
+}
+
+// tensorInt32 returns the first value of the named int32 output in r, or
+// -1 if r has no such output.
+func tensorInt32(r *coreml.PredictAllocResult, name string) int {
+	t := r.Tensor(name)
+	if t == nil || t.DType() != coreml.DTypeInt32 {
+		return -1
+	}
+	return int(*(*int32)(t.DataPtr()))
+}
+
+// validEncoderFrames returns how many of the allocated encoder frames cover
+// real audio rather than padding. It trusts the encoder's reported length,
+// then the preprocessor's mel length, and otherwise derives the count from
+// the valid sample count; -1 marks a length the model didn't report.
+// Decoding the padding frames produces trailing garbage tokens.
+func validEncoderFrames(encoderLength, melLength, validSamples, allocated int) int {
+	frames := encoderLength
+	if frames < 0 {
+		if melLength < 0 {
+			melLength = validSamples/parakeetHopSamples + 1
+		}
+		frames = (melLength + parakeetSubsampling - 1) / parakeetSubsampling
+	}
+	return max(0, min(frames, allocated))
 }
 
 // Ensure ParakeetTranscriber implements decoderRunner and jointRunner.
@@ -259,6 +492,11 @@ var _ jointRunner = (*ParakeetTranscriber)(nil)
 
 // runDecoder runs the LSTM decoder for one step via CoreML.
 func (p *ParakeetTranscriber) runDecoder(targetID int32, hIn, cIn []float32) (decoderOut, hOut, cOut []float32, err error) {
+	lstmStateSize := parakeetLSTMLayers * 1 * parakeetDecoderHidden
+	if len(hIn) != lstmStateSize || len(cIn) != lstmStateSize {
+		return nil, nil, nil, fmt.Errorf("decoder state has %d/%d values, want %d", len(hIn), len(cIn), lstmStateSize)
+	}
+
 	// Create targets tensor [1, 1]
 	targets := []int32{targetID}
 	targetsTensor, err := coreml.NewTensorWithData(
@@ -334,7 +572,6 @@ func (p *ParakeetTranscriber) runDecoder(targetID int32, hIn, cIn []float32) (de
 
 	// Copy outputs to Go slices
 	decoderOut = copyFloat32FromTensor(decTensor, parakeetDecoderHidden)
-	lstmStateSize := parakeetLSTMLayers * 1 * parakeetDecoderHidden
 	hOut = copyFloat32FromTensor(hOutTensor, lstmStateSize)
 	cOut = copyFloat32FromTensor(cOutTensor, lstmStateSize)
 
@@ -411,6 +648,25 @@ func (p *ParakeetTranscriber) runJoint(encoderStep, decoderStep []float32) (toke
 	return tokenID, duration, nil
 }
 
+// parakeetDecodeParams resolves the decode settings for a model from opts,
+// detecting the blank ID from the vocabulary when it isn't set.
+func parakeetDecodeParams(vocab []string, opts ParakeetOptions) tdtParams {
+	params := tdtParams{
+		blankID:        defaultParakeetBlankID,
+		maxSymsPerStep: opts.MaxSymbolsPerStep,
+		maxRepeats:     opts.MaxRepeats,
+	}
+	if opts.BlankID != nil {
+		params.blankID = int32(*opts.BlankID)
+	} else if n := len(vocab); n > 0 && vocab[n-1] == "<blank>" {
+		params.blankID = int32(n - 1)
+	}
+	if params.maxSymsPerStep <= 0 {
+		params.maxSymsPerStep = defaultParakeetMaxSymsPerStep
+	}
+	return params
+}
+
 // orderInputs arranges tensors to match the sorted input name order.
 func orderInputs(names []string, tensorMap map[string]*coreml.Tensor) ([]*coreml.Tensor, error) {
 	result := make([]*coreml.Tensor, len(names))
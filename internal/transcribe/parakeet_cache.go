@@ -0,0 +1,73 @@
+package transcribe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EncoderCache is the parakeet encoder output for one input, kept so the TDT
+// decoder can be re-run with different settings without re-running the
+// (much more expensive) encoder. It is a developer tool for tuning decode
+// parameters; normal transcription doesn't use it.
+type EncoderCache struct {
+	// Output is the encoder hidden states, [Frames, hidden size] flattened.
+	Output []float32
+	// Frames is the number of valid frames in Output.
+	Frames int
+}
+
+// ProcessWithEncoderCache transcribes samples like Process and also returns
+// the encoder output, for use with DecodeFromCache. It ignores the predict
+// timeout. Empty audio yields empty text and a nil cache.
+func (p *ParakeetTranscriber) ProcessWithEncoderCache(samples []float32) (string, *EncoderCache, error) {
+	if len(samples) == 0 {
+		return "", nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", nil, ErrModelNotLoaded
+	}
+	output, frames, err := p.encode(padAudio(samples, parakeetMaxSamples), min(len(samples), parakeetMaxSamples))
+	if err != nil {
+		return "", nil, err
+	}
+	cache := &EncoderCache{Output: output, Frames: frames}
+	text, err := p.decodeEncoded(cache.Output, cache.Frames)
+	if err != nil {
+		return "", nil, err
+	}
+	return text, cache, nil
+}
+
+// DecodeFromCache re-runs the TDT decoder on cached encoder output with the
+// current decode settings (see SetDecodeOptions).
+func (p *ParakeetTranscriber) DecodeFromCache(cache *EncoderCache) (string, error) {
+	if cache == nil {
+		return "", errors.New("parakeet: nil encoder cache")
+	}
+	if cache.Frames < 0 || cache.Frames*parakeetEncoderHidden > len(cache.Output) {
+		return "", fmt.Errorf("parakeet: encoder cache has %d floats, too few for %d frames",
+			len(cache.Output), cache.Frames)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return "", ErrModelNotLoaded
+	}
+	return p.decodeEncoded(cache.Output, cache.Frames)
+}
+
+// SetDecodeOptions replaces the decode settings (opts.BlankID,
+// opts.MaxSymbolsPerStep and opts.MaxRepeats, with the same defaults as
+// NewParakeetTranscriber) used by later calls. Other fields of opts are
+// ignored.
+func (p *ParakeetTranscriber) SetDecodeOptions(opts ParakeetOptions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.decode = parakeetDecodeParams(p.vocab, opts)
+}
@@ -0,0 +1,68 @@
+package transcribe
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParakeetDecodeFromCache(t *testing.T) {
+	var encodes int
+	p := &ParakeetTranscriber{vocab: []string{"▁hi", "▁there", "▁you"}}
+	p.encode = func(padded []float32, _ int) ([]float32, int, error) {
+		encodes++
+		if len(padded) != parakeetMaxSamples {
+			t.Errorf("encode got %d samples, want %d", len(padded), parakeetMaxSamples)
+		}
+		return make([]float32, 3*parakeetEncoderHidden), 3, nil
+	}
+	// Every decode sees the same joint decisions, one per frame: tokens
+	// 0, 1, 2. Which of them is the blank decides the text.
+	p.tdt = func(output []float32, frames int, params tdtParams) ([]int32, error) {
+		joint := &mockJoint{results: []mockJointResult{
+			{tokenID: 0, duration: 1},
+			{tokenID: 1, duration: 1},
+			{tokenID: 2, duration: 1},
+		}}
+		return tdtDecode(output, frames, params, &mockDecoder{}, joint)
+	}
+	p.SetDecodeOptions(ParakeetOptions{BlankID: blankID(2)})
+
+	text, cache, err := p.ProcessWithEncoderCache(make([]float32, 16000))
+	if err != nil {
+		t.Fatalf("ProcessWithEncoderCache() error = %v", err)
+	}
+	if text != "hi there" {
+		t.Errorf("ProcessWithEncoderCache() = %q, want %q", text, "hi there")
+	}
+	if cache == nil || cache.Frames != 3 || len(cache.Output) != 3*parakeetEncoderHidden {
+		t.Fatalf("cache = %+v, want 3 frames of encoder output", cache)
+	}
+
+	p.SetDecodeOptions(ParakeetOptions{BlankID: blankID(1)})
+	text, err = p.DecodeFromCache(cache)
+	if err != nil {
+		t.Fatalf("DecodeFromCache() error = %v", err)
+	}
+	if text != "hi you" {
+		t.Errorf("DecodeFromCache() with blank ID 1 = %q, want %q", text, "hi you")
+	}
+	if encodes != 1 {
+		t.Errorf("encoder ran %d times, want 1", encodes)
+	}
+}
+
+func TestParakeetDecodeFromCacheErrors(t *testing.T) {
+	p := &ParakeetTranscriber{}
+	if _, err := p.DecodeFromCache(nil); err == nil {
+		t.Error("DecodeFromCache(nil) should fail")
+	}
+	if _, err := p.DecodeFromCache(&EncoderCache{Output: make([]float32, 10), Frames: 3}); err == nil {
+		t.Error("DecodeFromCache with too little output should fail")
+	}
+
+	encodeErr := errors.New("encoder failed")
+	p.encode = func([]float32, int) ([]float32, int, error) { return nil, 0, encodeErr }
+	if _, cache, err := p.ProcessWithEncoderCache([]float32{0.1}); !errors.Is(err, encodeErr) || cache != nil {
+		t.Errorf("ProcessWithEncoderCache() = (%v, %v), want encoder error and nil cache", cache, err)
+	}
+}
@@ -3,13 +3,20 @@ package transcribe
 import "fmt"
 
 const (
-	parakeetBlankID        = 1024 // blank token index for v2 CoreML model (FluidInference conversion)
-	parakeetMaxSymsPerStep = 10
-	parakeetEncoderHidden  = 1024
-	parakeetDecoderHidden  = 640
-	parakeetLSTMLayers     = 2
+	defaultParakeetBlankID        = 1024 // blank token index for v2 CoreML model (FluidInference conversion)
+	defaultParakeetMaxSymsPerStep = 10
+	parakeetEncoderHidden         = 1024
+	parakeetDecoderHidden         = 640
+	parakeetLSTMLayers            = 2
 )
 
+// tdtParams are the model-dependent settings of the TDT decode loop.
+type tdtParams struct {
+	blankID        int32 // token index of the blank symbol
+	maxSymsPerStep int   // max tokens emitted on one frame before forcing an advance
+	maxRepeats     int   // max consecutive emissions of one token before forcing an advance (0 = no limit)
+}
+
 var parakeetDurationBins = []int32{0, 1, 2, 3, 4}
 
 // decoderRunner runs the LSTM decoder for one step.
@@ -25,25 +32,41 @@ type jointRunner interface {
 // tdtDecode runs the TDT greedy decode algorithm over encoder output frames.
 // encoderOutput shape: [T, encoderHidden] flattened.
 // encoderLength: number of valid frames.
-// Returns decoded token IDs (excluding blank tokens).
+// Returns decoded token IDs (excluding blank tokens, as identified by
+// params.blankID).
+//
+// The decoder is run once for the initial blank and then lazily after each
+// emitted token, only when the joint network next needs its output. An empty
+// encoder or a token emitted on the final frame costs no decoder run.
+//
+// With params.maxRepeats set, a token emitted that many times in a row
+// (blanks in between don't break the run) is not emitted again; the frame
+// advances instead, so a decoder stuck on one token can't fill the output
+// with it.
 func tdtDecode(
 	encoderOutput []float32,
 	encoderLength int,
+	params tdtParams,
 	dec decoderRunner,
 	joint jointRunner,
 ) ([]int32, error) {
+	if encoderLength <= 0 {
+		return nil, nil
+	}
+
 	// Initialize LSTM state (zeros)
 	lstmStateSize := parakeetLSTMLayers * 1 * parakeetDecoderHidden
 	hState := make([]float32, lstmStateSize)
 	cState := make([]float32, lstmStateSize)
 
-	// Initial decoder run with blank token
-	decoderOut, hState, cState, err := dec.runDecoder(int32(parakeetBlankID), hState, cState)
-	if err != nil {
-		return nil, fmt.Errorf("initial decoder run: %w", err)
-	}
+	// Decoder state is stale until the decoder has seen lastToken, starting
+	// with the initial blank.
+	var decoderOut []float32
+	lastToken := params.blankID
+	stale := true
 
 	var tokens []int32
+	repeats := 0 // consecutive emissions of lastToken
 	t := 0
 
 	for t < encoderLength {
@@ -51,7 +74,19 @@ func tdtDecode(
 		encoderFrame := encoderOutput[frameStart : frameStart+parakeetEncoderHidden]
 
 		symCount := 0
-		for symCount < parakeetMaxSymsPerStep {
+		for symCount < params.maxSymsPerStep {
+			if stale {
+				var err error
+				decoderOut, hState, cState, err = dec.runDecoder(lastToken, hState, cState)
+				if err != nil {
+					if len(tokens) == 0 {
+						return nil, fmt.Errorf("initial decoder run: %w", err)
+					}
+					return nil, fmt.Errorf("decoder at frame %d: %w", t, err)
+				}
+				stale = false
+			}
+
 			tokenID, durIdx, err := joint.runJoint(encoderFrame, decoderOut)
 			if err != nil {
 				return nil, fmt.Errorf("joint at frame %d: %w", t, err)
@@ -59,7 +94,7 @@ func tdtDecode(
 
 			dur := parakeetDurationBins[durIdx]
 
-			if tokenID == parakeetBlankID {
+			if tokenID == params.blankID {
 				if dur == 0 {
 					dur = 1 // prevent infinite loop
 				}
@@ -67,13 +102,21 @@ func tdtDecode(
 				break
 			}
 
-			// Non-blank: emit token, update decoder state
-			tokens = append(tokens, tokenID)
-			decoderOut, hState, cState, err = dec.runDecoder(tokenID, hState, cState)
-			if err != nil {
-				return nil, fmt.Errorf("decoder at frame %d: %w", t, err)
+			if tokenID == lastToken {
+				if params.maxRepeats > 0 && repeats >= params.maxRepeats {
+					t++ // repetition loop: drop the token and move on
+					break
+				}
+				repeats++
+			} else {
+				repeats = 1
 			}
 
+			// Non-blank: emit token; the decoder catches up before the next joint run
+			tokens = append(tokens, tokenID)
+			lastToken = tokenID
+			stale = true
+
 			if dur > 0 {
 				t += int(dur)
 				break
@@ -82,7 +125,7 @@ func tdtDecode(
 			symCount++
 		}
 
-		if symCount >= parakeetMaxSymsPerStep {
+		if symCount >= params.maxSymsPerStep {
 			t++
 		}
 	}
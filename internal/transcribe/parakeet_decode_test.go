@@ -5,10 +5,18 @@ import (
 	"testing"
 )
 
-// mockDecoder returns predetermined decoder outputs for testing.
+// testTDT are the decode settings of the v2 CoreML model, used by the tests
+// that don't exercise the settings themselves.
+var testTDT = tdtParams{blankID: 1024, maxSymsPerStep: 10}
+
+// mockDecoder returns predetermined decoder outputs for testing. Every call
+// is counted in total and its target token recorded in targets.
 type mockDecoder struct {
 	calls   int
 	outputs []mockDecoderOutput
+
+	total   int
+	targets []int32
 }
 
 type mockDecoderOutput struct {
@@ -18,6 +26,8 @@ type mockDecoderOutput struct {
 }
 
 func (m *mockDecoder) runDecoder(targetID int32, hIn, cIn []float32) (decoderOut, hOut, cOut []float32, err error) {
+	m.total++
+	m.targets = append(m.targets, targetID)
 	if m.calls >= len(m.outputs) {
 		// Return zeros for any extra calls
 		size := parakeetDecoderHidden
@@ -28,10 +38,13 @@ func (m *mockDecoder) runDecoder(targetID int32, hIn, cIn []float32) (decoderOut
 	return out.decoderOut, out.hOut, out.cOut, nil
 }
 
-// mockJoint returns predetermined joint decisions for testing.
+// mockJoint returns predetermined joint decisions for testing. Every call is
+// counted in total.
 type mockJoint struct {
 	calls   int
 	results []mockJointResult
+
+	total int
 }
 
 type mockJointResult struct {
@@ -40,8 +53,9 @@ type mockJointResult struct {
 }
 
 func (m *mockJoint) runJoint(encoderStep, decoderStep []float32) (tokenID, duration int32, err error) {
+	m.total++
 	if m.calls >= len(m.results) {
-		return parakeetBlankID, 1, nil // default: blank, advance 1
+		return testTDT.blankID, 1, nil // default: blank, advance 1
 	}
 	r := m.results[m.calls]
 	m.calls++
@@ -56,7 +70,7 @@ func TestTDTDecodeBasic(t *testing.T) {
 	joint := &mockJoint{results: []mockJointResult{
 		{tokenID: 5, duration: 1},               // frame 0: emit 5, advance 1
 		{tokenID: 10, duration: 1},              // frame 1: emit 10, advance 1
-		{tokenID: parakeetBlankID, duration: 1}, // frame 2: blank, advance 1
+		{tokenID: testTDT.blankID, duration: 1}, // frame 2: blank, advance 1
 	}}
 
 	// Mock decoder: return dummy outputs for each call
@@ -66,7 +80,7 @@ func TestTDTDecodeBasic(t *testing.T) {
 		{decoderOut: make([]float32, parakeetDecoderHidden), hOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden), cOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden)},
 	}}
 
-	tokens, err := tdtDecode(encoderOutput, 3, dec, joint)
+	tokens, err := tdtDecode(encoderOutput, 3, testTDT, dec, joint)
 	if err != nil {
 		t.Fatalf("tdtDecode: %v", err)
 	}
@@ -87,9 +101,9 @@ func TestTDTDecodeBlankSkip(t *testing.T) {
 	// Frame 3: emit token 7 (dur 1), advance to frame 4
 	// Frame 4: blank (dur 1)
 	joint := &mockJoint{results: []mockJointResult{
-		{tokenID: parakeetBlankID, duration: 3}, // frame 0: skip 3 frames
+		{tokenID: testTDT.blankID, duration: 3}, // frame 0: skip 3 frames
 		{tokenID: 7, duration: 1},               // frame 3: emit 7
-		{tokenID: parakeetBlankID, duration: 1}, // frame 4: blank
+		{tokenID: testTDT.blankID, duration: 1}, // frame 4: blank
 	}}
 
 	dec := &mockDecoder{outputs: []mockDecoderOutput{
@@ -97,7 +111,7 @@ func TestTDTDecodeBlankSkip(t *testing.T) {
 		{decoderOut: make([]float32, parakeetDecoderHidden), hOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden), cOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden)},
 	}}
 
-	tokens, err := tdtDecode(encoderOutput, 5, dec, joint)
+	tokens, err := tdtDecode(encoderOutput, 5, testTDT, dec, joint)
 	if err != nil {
 		t.Fatalf("tdtDecode: %v", err)
 	}
@@ -111,7 +125,7 @@ func TestTDTDecodeMaxSymbolsGuard(t *testing.T) {
 	// 1 encoder frame, joint keeps emitting non-blank tokens with duration 0
 	encoderOutput := make([]float32, 1*parakeetEncoderHidden)
 
-	// Emit 15 tokens with duration 0 — should be capped at parakeetMaxSymsPerStep (10)
+	// Emit 15 tokens with duration 0 — should be capped at testTDT.maxSymsPerStep (10)
 	results := make([]mockJointResult, 15)
 	for i := range results {
 		results[i] = mockJointResult{tokenID: int32(i), duration: 0}
@@ -129,13 +143,13 @@ func TestTDTDecodeMaxSymbolsGuard(t *testing.T) {
 	}
 	dec := &mockDecoder{outputs: outputs}
 
-	tokens, err := tdtDecode(encoderOutput, 1, dec, joint)
+	tokens, err := tdtDecode(encoderOutput, 1, testTDT, dec, joint)
 	if err != nil {
 		t.Fatalf("tdtDecode: %v", err)
 	}
 
-	if len(tokens) > parakeetMaxSymsPerStep {
-		t.Errorf("got %d tokens, want at most %d (max symbols per step)", len(tokens), parakeetMaxSymsPerStep)
+	if len(tokens) > testTDT.maxSymsPerStep {
+		t.Errorf("got %d tokens, want at most %d (max symbols per step)", len(tokens), testTDT.maxSymsPerStep)
 	}
 }
 
@@ -144,15 +158,15 @@ func TestTDTDecodeBlankDurationZeroForceAdvance(t *testing.T) {
 	encoderOutput := make([]float32, 2*parakeetEncoderHidden)
 
 	joint := &mockJoint{results: []mockJointResult{
-		{tokenID: parakeetBlankID, duration: 0}, // frame 0: blank, dur 0 -> should force advance to 1
-		{tokenID: parakeetBlankID, duration: 1}, // frame 1: blank, advance 1
+		{tokenID: testTDT.blankID, duration: 0}, // frame 0: blank, dur 0 -> should force advance to 1
+		{tokenID: testTDT.blankID, duration: 1}, // frame 1: blank, advance 1
 	}}
 
 	dec := &mockDecoder{outputs: []mockDecoderOutput{
 		{decoderOut: make([]float32, parakeetDecoderHidden), hOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden), cOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden)},
 	}}
 
-	tokens, err := tdtDecode(encoderOutput, 2, dec, joint)
+	tokens, err := tdtDecode(encoderOutput, 2, testTDT, dec, joint)
 	if err != nil {
 		t.Fatalf("tdtDecode: %v", err)
 	}
@@ -164,7 +178,7 @@ func TestTDTDecodeBlankDurationZeroForceAdvance(t *testing.T) {
 }
 
 func TestTDTDecodeEmptyEncoder(t *testing.T) {
-	tokens, err := tdtDecode(nil, 0, &mockDecoder{outputs: []mockDecoderOutput{
+	tokens, err := tdtDecode(nil, 0, testTDT, &mockDecoder{outputs: []mockDecoderOutput{
 		{decoderOut: make([]float32, parakeetDecoderHidden), hOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden), cOut: make([]float32, parakeetLSTMLayers*1*parakeetDecoderHidden)},
 	}}, &mockJoint{})
 	if err != nil {
@@ -182,7 +196,7 @@ func TestTDTDecodeDecoderError(t *testing.T) {
 	dec := &errorDecoder{err: fmt.Errorf("decoder failed")}
 	joint := &mockJoint{}
 
-	_, err := tdtDecode(encoderOutput, 1, dec, joint)
+	_, err := tdtDecode(encoderOutput, 1, testTDT, dec, joint)
 	if err == nil {
 		t.Error("expected error from decoder failure")
 	}
@@ -196,3 +210,204 @@ type errorDecoder struct {
 func (e *errorDecoder) runDecoder(targetID int32, hIn, cIn []float32) ([]float32, []float32, []float32, error) {
 	return nil, nil, nil, e.err
 }
+
+func TestTDTDecodeCallCounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		frames      int
+		results     []mockJointResult
+		wantTokens  int
+		wantTargets []int32 // decoder inputs, in order
+		wantJoint   int
+	}{
+		{
+			name:        "empty encoder",
+			frames:      0,
+			wantTargets: nil,
+			wantJoint:   0,
+		},
+		{
+			name:   "all blank",
+			frames: 3,
+			results: []mockJointResult{
+				{tokenID: testTDT.blankID, duration: 1},
+				{tokenID: testTDT.blankID, duration: 2},
+			},
+			wantTargets: []int32{testTDT.blankID},
+			wantJoint:   2,
+		},
+		{
+			name:   "token per frame",
+			frames: 3,
+			results: []mockJointResult{
+				{tokenID: 5, duration: 1},
+				{tokenID: 6, duration: 1},
+				{tokenID: testTDT.blankID, duration: 1},
+			},
+			wantTokens:  2,
+			wantTargets: []int32{testTDT.blankID, 5, 6},
+			wantJoint:   3,
+		},
+		{
+			name:   "tokens within one frame",
+			frames: 2,
+			results: []mockJointResult{
+				{tokenID: 5, duration: 0},
+				{tokenID: 6, duration: 0},
+				{tokenID: testTDT.blankID, duration: 1},
+				{tokenID: testTDT.blankID, duration: 1},
+			},
+			wantTokens:  2,
+			wantTargets: []int32{testTDT.blankID, 5, 6},
+			wantJoint:   4,
+		},
+		{
+			// The last token ends decoding, so its decoder update is never needed.
+			name:   "token on final frame",
+			frames: 2,
+			results: []mockJointResult{
+				{tokenID: 5, duration: 1},
+				{tokenID: 6, duration: 1},
+			},
+			wantTokens:  2,
+			wantTargets: []int32{testTDT.blankID, 5},
+			wantJoint:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := &mockDecoder{}
+			joint := &mockJoint{results: tt.results}
+
+			tokens, err := tdtDecode(make([]float32, tt.frames*parakeetEncoderHidden), tt.frames, testTDT, dec, joint)
+			if err != nil {
+				t.Fatalf("tdtDecode: %v", err)
+			}
+			if len(tokens) != tt.wantTokens {
+				t.Errorf("got %d tokens, want %d", len(tokens), tt.wantTokens)
+			}
+			if dec.total != len(tt.wantTargets) {
+				t.Errorf("decoder calls = %d, want %d", dec.total, len(tt.wantTargets))
+			}
+			for i := 0; i < len(dec.targets) && i < len(tt.wantTargets); i++ {
+				if dec.targets[i] != tt.wantTargets[i] {
+					t.Errorf("decoder call %d target = %d, want %d", i, dec.targets[i], tt.wantTargets[i])
+				}
+			}
+			if joint.total != tt.wantJoint {
+				t.Errorf("joint calls = %d, want %d", joint.total, tt.wantJoint)
+			}
+		})
+	}
+}
+
+func TestTDTDecodeCustomParams(t *testing.T) {
+	// A conversion with a different vocabulary: blank is 8192, and token
+	// 1024 (blank in the v2 model) is an ordinary token.
+	params := tdtParams{blankID: 8192, maxSymsPerStep: 2}
+	joint := &mockJoint{results: []mockJointResult{
+		{tokenID: 1024, duration: 0}, // frame 0: token
+		{tokenID: 3, duration: 0},    // frame 0: token, hits the 2-symbol cap
+		{tokenID: 8192, duration: 1}, // frame 1: blank, advance 1
+		{tokenID: 8192, duration: 1}, // frame 2: blank, advance 1
+	}}
+	dec := &mockDecoder{}
+
+	tokens, err := tdtDecode(make([]float32, 3*parakeetEncoderHidden), 3, params, dec, joint)
+	if err != nil {
+		t.Fatalf("tdtDecode: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0] != 1024 || tokens[1] != 3 {
+		t.Errorf("tokens = %v, want [1024 3]", tokens)
+	}
+	if len(dec.targets) == 0 || dec.targets[0] != 8192 {
+		t.Errorf("decoder targets = %v, want initial blank 8192", dec.targets)
+	}
+	if joint.total != 4 {
+		t.Errorf("joint calls = %d, want 4", joint.total)
+	}
+}
+
+func TestTDTDecodeRepetitionGuard(t *testing.T) {
+	// A joint stuck on token 7: every call emits it without advancing.
+	stuck := func() *mockJoint {
+		results := make([]mockJointResult, 100)
+		for i := range results {
+			results[i] = mockJointResult{tokenID: 7, duration: 0}
+		}
+		return &mockJoint{results: results}
+	}
+	encoder := make([]float32, 5*parakeetEncoderHidden)
+
+	// Unguarded, only the per-frame symbol cap stops it: 10 per frame.
+	tokens, err := tdtDecode(encoder, 5, testTDT, &mockDecoder{}, stuck())
+	if err != nil {
+		t.Fatalf("tdtDecode: %v", err)
+	}
+	if len(tokens) != 50 {
+		t.Fatalf("unguarded tokens = %d, want 50", len(tokens))
+	}
+
+	// Guarded, the run stops at 3 and every later frame is skipped.
+	params := testTDT
+	params.maxRepeats = 3
+	joint := stuck()
+	tokens, err = tdtDecode(encoder, 5, params, &mockDecoder{}, joint)
+	if err != nil {
+		t.Fatalf("tdtDecode: %v", err)
+	}
+	if len(tokens) != 3 {
+		t.Errorf("guarded tokens = %v, want [7 7 7]", tokens)
+	}
+	// 3 emissions plus one refused call per frame.
+	if joint.total != 3+5 {
+		t.Errorf("joint calls = %d, want 8", joint.total)
+	}
+
+	// A different token resets the run.
+	joint = &mockJoint{results: []mockJointResult{
+		{tokenID: 7, duration: 0},
+		{tokenID: 7, duration: 0},
+		{tokenID: 8, duration: 1},
+		{tokenID: 7, duration: 0},
+		{tokenID: 7, duration: 1},
+	}}
+	params.maxRepeats = 2
+	tokens, err = tdtDecode(make([]float32, 3*parakeetEncoderHidden), 3, params, &mockDecoder{}, joint)
+	if err != nil {
+		t.Fatalf("tdtDecode: %v", err)
+	}
+	if want := []int32{7, 7, 8, 7, 7}; fmt.Sprint(tokens) != fmt.Sprint(want) {
+		t.Errorf("tokens = %v, want %v", tokens, want)
+	}
+}
+
+// blankID returns a pointer to id, for ParakeetOptions.BlankID.
+func blankID(id int) *int { return &id }
+
+func TestParakeetDecodeParams(t *testing.T) {
+	withBlank := []string{"▁a", "▁b", "<blank>"}
+	withoutBlank := []string{"▁a", "▁b", "c"}
+
+	tests := []struct {
+		name  string
+		vocab []string
+		opts  ParakeetOptions
+		want  tdtParams
+	}{
+		{name: "defaults", vocab: withoutBlank, want: tdtParams{blankID: 1024, maxSymsPerStep: 10}},
+		{name: "detect_from_vocab", vocab: withBlank, want: tdtParams{blankID: 2, maxSymsPerStep: 10}},
+		{name: "explicit_overrides_vocab", vocab: withBlank, opts: ParakeetOptions{BlankID: blankID(8192), MaxSymbolsPerStep: 4}, want: tdtParams{blankID: 8192, maxSymsPerStep: 4}},
+		{name: "explicit_zero", vocab: withBlank, opts: ParakeetOptions{BlankID: blankID(0)}, want: tdtParams{blankID: 0, maxSymsPerStep: 10}},
+		{name: "empty_vocab", want: tdtParams{blankID: 1024, maxSymsPerStep: 10}},
+		{name: "max_repeats", vocab: withBlank, opts: ParakeetOptions{MaxRepeats: 3}, want: tdtParams{blankID: 2, maxSymsPerStep: 10, maxRepeats: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parakeetDecodeParams(tt.vocab, tt.opts); got != tt.want {
+				t.Errorf("parakeetDecodeParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
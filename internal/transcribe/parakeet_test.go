@@ -1,10 +1,18 @@
 package transcribe
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/chaz8081/gostt-writer/internal/coreml"
 )
 
 // parakeetModelDir returns the path to the parakeet model directory, skipping if not found.
@@ -55,10 +63,35 @@ func TestPadAudioLonger(t *testing.T) {
 	}
 }
 
+func TestParseComputeUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    coreml.ComputeUnits
+		wantErr bool
+	}{
+		{"", coreml.ComputeAll, false},
+		{"all", coreml.ComputeAll, false},
+		{"cpu_only", coreml.ComputeCPUOnly, false},
+		{"cpu_and_gpu", coreml.ComputeCPUAndGPU, false},
+		{"cpu_and_ane", coreml.ComputeCPUAndANE, false},
+		{"ANE", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseComputeUnits(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseComputeUnits(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseComputeUnits(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestNewParakeetTranscriber(t *testing.T) {
 	dir := parakeetModelDir(t)
 
-	tr, err := NewParakeetTranscriber(dir)
+	tr, err := NewParakeetTranscriber(dir, ParakeetOptions{})
 	if err != nil {
 		t.Fatalf("NewParakeetTranscriber: %v", err)
 	}
@@ -71,7 +104,7 @@ func TestParakeetProcessJFK(t *testing.T) {
 
 	t.Logf("Input audio: %d samples (%.2fs)", len(samples), float64(len(samples))/16000.0)
 
-	tr, err := NewParakeetTranscriber(dir)
+	tr, err := NewParakeetTranscriber(dir, ParakeetOptions{})
 	if err != nil {
 		t.Fatalf("NewParakeetTranscriber: %v", err)
 	}
@@ -79,7 +112,7 @@ func TestParakeetProcessJFK(t *testing.T) {
 
 	// Debug: run preprocessor manually
 	padded := padAudio(samples, parakeetMaxSamples)
-	prepResult, err := tr.runPreprocessor(padded)
+	prepResult, err := tr.runPreprocessor(padded, len(samples))
 	if err != nil {
 		t.Fatalf("runPreprocessor: %v", err)
 	}
@@ -99,11 +132,12 @@ func TestParakeetProcessJFK(t *testing.T) {
 	}
 
 	// Debug: extract encoder output
-	encoderOutput, encoderLength, err := tr.extractEncoderOutput(encResult)
+	encoderOutput, allocated, err := tr.extractEncoderOutput(encResult)
 	if err != nil {
 		t.Fatalf("extractEncoderOutput: %v", err)
 	}
-	t.Logf("Encoder: %d frames × %d hidden, encoderLength=%d", len(encoderOutput)/parakeetEncoderHidden, parakeetEncoderHidden, encoderLength)
+	encoderLength := validEncoderFrames(tensorInt32(encResult, "encoder_length"), tensorInt32(prepResult, "mel_length"), len(samples), allocated)
+	t.Logf("Encoder: %d frames × %d hidden, encoderLength=%d", allocated, parakeetEncoderHidden, encoderLength)
 
 	// Check if encoder output is all zeros
 	nonZero := 0
@@ -130,3 +164,249 @@ func TestParakeetProcessJFK(t *testing.T) {
 		t.Errorf("expected transcript to contain 'ask not what your country', got: %q", text)
 	}
 }
+
+func TestParakeetProcessShortInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		samples   []float32
+		wantCalls int
+	}{
+		{name: "nil", samples: nil, wantCalls: 0},
+		{name: "empty", samples: []float32{}, wantCalls: 0},
+		{name: "single_sample", samples: []float32{0.5}, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			p := &ParakeetTranscriber{}
+			p.pipeline = func(padded []float32, _ int) (string, error) {
+				calls++
+				if len(padded) != parakeetMaxSamples {
+					t.Errorf("pipeline got %d samples, want %d", len(padded), parakeetMaxSamples)
+				}
+				return "", nil
+			}
+
+			for _, process := range []func([]float32) (string, error){p.Process, p.ProcessLong} {
+				text, err := process(tt.samples)
+				if err != nil {
+					t.Fatalf("error = %v", err)
+				}
+				if text != "" {
+					t.Errorf("text = %q, want empty", text)
+				}
+			}
+			if calls != 2*tt.wantCalls {
+				t.Errorf("pipeline calls = %d, want %d", calls, 2*tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestParakeetRunPreprocessorEmpty(t *testing.T) {
+	p := &ParakeetTranscriber{}
+	for _, audio := range [][]float32{nil, {}} {
+		if _, err := p.runPreprocessor(audio, len(audio)); err == nil {
+			t.Errorf("runPreprocessor(%v) error = nil, want error", audio)
+		}
+	}
+}
+
+func TestValidEncoderFrames(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		encoderLength, melLength, valid, got int
+		want                                 int
+	}{
+		{"reported length trusted", 40, 900, 160000, 188, 40},
+		{"reported length clamped", 500, -1, 240000, 188, 188},
+		{"from mel length", -1, 101, 16000, 188, 13},
+		{"from valid samples", -1, -1, 16000, 188, 13},
+		{"full window", -1, -1, parakeetMaxSamples, 188, 188},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validEncoderFrames(tt.encoderLength, tt.melLength, tt.valid, tt.got); got != tt.want {
+				t.Errorf("validEncoderFrames() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParakeetDecodeStopsAtValidFrames(t *testing.T) {
+	// The encoder output is allocated for the whole 15s window, but only 4
+	// frames hold the 0.3s of audio. Every frame would emit a token.
+	const allocated, valid = 188, 4
+	p := &ParakeetTranscriber{vocab: []string{"▁a", "▁b", "<blank>"}}
+	p.encode = func(padded []float32, n int) ([]float32, int, error) {
+		if n != 4800 {
+			t.Errorf("encode got %d valid samples, want 4800", n)
+		}
+		return make([]float32, allocated*parakeetEncoderHidden), valid, nil
+	}
+	var joint *mockJoint
+	p.tdt = func(output []float32, frames int, params tdtParams) ([]int32, error) {
+		results := make([]mockJointResult, allocated)
+		for i := range results {
+			results[i] = mockJointResult{tokenID: int32(i % 2), duration: 1}
+		}
+		joint = &mockJoint{results: results}
+		return tdtDecode(output, frames, params, &mockDecoder{}, joint)
+	}
+	p.SetDecodeOptions(ParakeetOptions{BlankID: blankID(2)})
+	p.pipeline = p.runPipeline
+
+	text, err := p.Process(make([]float32, 4800))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if want := "a b a b"; text != want {
+		t.Errorf("Process() = %q, want %q", text, want)
+	}
+	if joint.total != valid {
+		t.Errorf("joint ran %d times, want %d (one per valid frame)", joint.total, valid)
+	}
+}
+
+func TestParakeetRunDecoderBadState(t *testing.T) {
+	p := &ParakeetTranscriber{}
+	state := make([]float32, parakeetLSTMLayers*parakeetDecoderHidden)
+	tests := []struct {
+		name string
+		hIn  []float32
+		cIn  []float32
+	}{
+		{"nil_state", nil, nil},
+		{"empty_h", []float32{}, state},
+		{"single_value_c", state, []float32{0}},
+	}
+	for _, tt := range tests {
+		if _, _, _, err := p.runDecoder(0, tt.hIn, tt.cIn); err == nil {
+			t.Errorf("%s: runDecoder error = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestParakeetProcessSerialized(t *testing.T) {
+	// The mock pipeline mutates shared state the way the decode loop reuses
+	// model handles; overlapping calls would corrupt it.
+	var (
+		active  atomic.Int32
+		overlap atomic.Bool
+		shared  []float32
+	)
+	p := &ParakeetTranscriber{}
+	p.pipeline = func(samples []float32, _ int) (string, error) {
+		if active.Add(1) > 1 {
+			overlap.Store(true)
+		}
+		defer active.Add(-1)
+
+		shared = shared[:0]
+		for _, s := range samples[:4] {
+			shared = append(shared, s)
+			time.Sleep(time.Millisecond)
+		}
+		return fmt.Sprint(shared), nil
+	}
+
+	inputs := [][]float32{{1, 1, 1, 1}, {2, 2, 2, 2}}
+	results := make([]string, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i, in := range inputs {
+		wg.Add(1)
+		go func(i int, in []float32) {
+			defer wg.Done()
+			results[i], errs[i] = p.Process(in)
+		}(i, in)
+	}
+	wg.Wait()
+
+	if overlap.Load() {
+		t.Error("Process calls ran concurrently")
+	}
+	for i, want := range []string{"[1 1 1 1]", "[2 2 2 2]"} {
+		if errs[i] != nil {
+			t.Errorf("Process(%d) error = %v", i, errs[i])
+		}
+		if results[i] != want {
+			t.Errorf("Process(%d) = %q, want %q", i, results[i], want)
+		}
+	}
+}
+
+func TestParakeetPredictTimeout(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	p := &ParakeetTranscriber{predictTimeout: 20 * time.Millisecond}
+	p.pipeline = func([]float32, int) (string, error) {
+		if calls.Add(1) == 1 {
+			// Simulate a wedged CoreML call.
+			<-release
+		}
+		return "ok", nil
+	}
+
+	start := time.Now()
+	_, err := p.Process(make([]float32, 16000))
+	if !errors.Is(err, ErrPredictTimeout) {
+		t.Fatalf("Process() error = %v, want ErrPredictTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Process() took %s, want it to return at the timeout", elapsed)
+	}
+
+	// While the wedged run holds the models, calls fail fast.
+	if _, err := p.Process(make([]float32, 16000)); !errors.Is(err, ErrPredictTimeout) {
+		t.Errorf("Process() during stuck run error = %v, want ErrPredictTimeout", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("pipeline calls = %d, want 1 while the first run is stuck", n)
+	}
+
+	close(release)
+	<-p.stuck
+	got, err := p.Process(make([]float32, 16000))
+	if err != nil || got != "ok" {
+		t.Errorf("Process() after recovery = %q, %v; want \"ok\", nil", got, err)
+	}
+}
+
+func TestParakeetProcessLong(t *testing.T) {
+	// 40s of audio: windows start at 0s, 14s and 28s.
+	samples := make([]float32, 40*16000)
+	for i := range samples {
+		samples[i] = float32(i / 16000) // second index
+	}
+
+	var lens, valids []int
+	p := &ParakeetTranscriber{}
+	p.pipeline = func(padded []float32, valid int) (string, error) {
+		lens = append(lens, len(padded))
+		valids = append(valids, valid)
+		// Report the first and last second heard in the window.
+		first, last := int(padded[0]), int(padded[0])
+		for _, s := range padded {
+			last = max(last, int(s))
+		}
+		return fmt.Sprintf("s%d s%d", first, last), nil
+	}
+
+	got, err := p.ProcessLong(samples)
+	if err != nil {
+		t.Fatalf("ProcessLong() error = %v", err)
+	}
+	if want := "s0 s14 s28 s39"; got != want {
+		t.Errorf("ProcessLong() = %q, want %q", got, want)
+	}
+	for i, n := range lens {
+		if n != parakeetMaxSamples {
+			t.Errorf("window %d: pipeline got %d samples, want %d (padded)", i, n, parakeetMaxSamples)
+		}
+	}
+	// The last window holds 12s of audio; the rest of it is padding.
+	if want := []int{parakeetMaxSamples, parakeetMaxSamples, 12 * 16000}; !slices.Equal(valids, want) {
+		t.Errorf("valid samples per window = %v, want %v", valids, want)
+	}
+}
@@ -4,10 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
+var (
+	// spaceBeforeClosing matches spaces before punctuation that attaches to
+	// the preceding word. Vocabularies with punctuation often encode it as a
+	// word-initial piece ("▁."), which would otherwise decode as " .".
+	spaceBeforeClosing = regexp.MustCompile(` +([.,?!:;%)\]}])`)
+	// spaceAfterOpening matches spaces after punctuation that attaches to the
+	// following word.
+	spaceAfterOpening = regexp.MustCompile(`([(\[{]) +`)
+)
+
 // loadVocabulary reads parakeet_vocab.json and returns a token ID -> string mapping.
 // The JSON format is {"0": "▁the", "1": "▁a", ...} where keys are string token IDs.
 func loadVocabulary(path string) ([]string, error) {
@@ -42,7 +53,9 @@ func loadVocabulary(path string) ([]string, error) {
 }
 
 // decodeTokens converts a sequence of token IDs to text using the vocabulary.
-// SentencePiece "▁" markers are replaced with spaces, then the result is trimmed.
+// SentencePiece "▁" markers are replaced with spaces and runs of spaces are
+// collapsed. Punctuation is attached to its word: no space before ".", ",",
+// "?" and other closing marks, or after opening brackets.
 func decodeTokens(tokens []int32, vocab []string) string {
 	var b strings.Builder
 	for _, id := range tokens {
@@ -50,7 +63,7 @@ func decodeTokens(tokens []int32, vocab []string) string {
 			b.WriteString(vocab[id])
 		}
 	}
-	text := b.String()
-	text = strings.ReplaceAll(text, "▁", " ")
-	return strings.TrimSpace(text)
+	text := strings.Join(strings.Fields(strings.ReplaceAll(b.String(), "▁", " ")), " ")
+	text = spaceBeforeClosing.ReplaceAllString(text, "$1")
+	return spaceAfterOpening.ReplaceAllString(text, "$1")
 }
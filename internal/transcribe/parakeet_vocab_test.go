@@ -58,6 +58,39 @@ func TestDecodeTokens(t *testing.T) {
 	}
 }
 
+func TestDecodeTokensPunctuation(t *testing.T) {
+	// Punctuation appears both as bare pieces and as word-initial pieces.
+	vocab := []string{
+		0: "▁hello", 1: "▁world", 2: ".", 3: "▁.", 4: ",", 5: "▁,",
+		6: "▁?", 7: "!", 8: "▁(", 9: "▁)", 10: "▁", 11: "▁it", 12: "'s",
+		13: "▁50", 14: "▁%",
+	}
+	tests := []struct {
+		name   string
+		tokens []int32
+		want   string
+	}{
+		{name: "bare_period", tokens: []int32{0, 1, 2}, want: "hello world."},
+		{name: "word_initial_period", tokens: []int32{0, 1, 3}, want: "hello world."},
+		{name: "bare_comma", tokens: []int32{0, 4, 1}, want: "hello, world"},
+		{name: "word_initial_comma", tokens: []int32{0, 5, 1}, want: "hello, world"},
+		{name: "question", tokens: []int32{0, 6}, want: "hello?"},
+		{name: "exclamation_after_period", tokens: []int32{0, 3, 7}, want: "hello.!"},
+		{name: "parentheses", tokens: []int32{0, 8, 1, 9}, want: "hello (world)"},
+		{name: "lone_marker", tokens: []int32{0, 10, 1}, want: "hello world"},
+		{name: "leading_punctuation_piece", tokens: []int32{3, 0}, want: ". hello"},
+		{name: "apostrophe", tokens: []int32{11, 12}, want: "it's"},
+		{name: "percent", tokens: []int32{13, 14}, want: "50%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeTokens(tt.tokens, vocab); got != tt.want {
+				t.Errorf("decodeTokens(%v) = %q, want %q", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDecodeTokensEmpty(t *testing.T) {
 	vocab := []string{"▁hello"}
 	text := decodeTokens(nil, vocab)
@@ -0,0 +1,142 @@
+package transcribe
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// pipelineSteps maps transcribe.pipeline step names to their transforms.
+var pipelineSteps = map[string]func(text string, cfg *config.TranscribeConfig) string{
+	"trim":         textOnly(strings.TrimSpace),
+	"replacements": replacementsStep,
+	"numbers":      textOnly(NormalizeNumbers),
+	"controls":     textOnly(ExpandSpokenControls),
+	"punctuate":    textOnly(Punctuate),
+	"capitalize":   textOnly(Capitalize),
+}
+
+// textOnly adapts a transform that needs no settings to a pipeline step.
+func textOnly(f func(string) string) func(string, *config.TranscribeConfig) string {
+	return func(text string, _ *config.TranscribeConfig) string { return f(text) }
+}
+
+func replacementsStep(text string, cfg *config.TranscribeConfig) string {
+	return ApplyFuzzyReplacements(text, cfg.Replacements, cfg.ReplacementFuzziness)
+}
+
+// PipelineSteps returns the transforms to run on each transcript: the
+// configured transcribe.pipeline, or, if that is empty, the steps implied by
+// replacements, normalize_numbers and spoken_controls.
+func PipelineSteps(cfg *config.TranscribeConfig) []string {
+	if len(cfg.Pipeline) > 0 {
+		return cfg.Pipeline
+	}
+	var steps []string
+	if len(cfg.Replacements) > 0 {
+		steps = append(steps, "replacements")
+	}
+	if cfg.NormalizeNumbers {
+		steps = append(steps, "numbers")
+	}
+	if cfg.SpokenControls {
+		steps = append(steps, "controls")
+	}
+	return steps
+}
+
+// RunPipeline applies the named transforms to text in order. It returns an
+// error, and leaves the text alone, if any step name is unknown.
+func RunPipeline(text string, steps []string, cfg *config.TranscribeConfig) (string, error) {
+	for _, name := range steps {
+		if _, ok := pipelineSteps[name]; !ok {
+			return text, fmt.Errorf("transcribe: unknown pipeline step %q", name)
+		}
+	}
+	for _, name := range steps {
+		text = pipelineSteps[name](text, cfg)
+	}
+	return text, nil
+}
+
+// ApplyReplacements replaces each rule's From with its To, in order.
+// Matching is case-insensitive, and a From that starts or ends with a letter
+// or digit only matches at a word boundary, so "ai" doesn't change "said".
+func ApplyReplacements(text string, rules []config.Replacement) string {
+	for _, r := range rules {
+		if r.From == "" {
+			continue
+		}
+		pattern := regexp.QuoteMeta(r.From)
+		if isWordRune(firstRune(r.From)) {
+			pattern = `\b` + pattern
+		}
+		if isWordRune(lastRune(r.From)) {
+			pattern += `\b`
+		}
+		re := regexp.MustCompile(`(?i)` + pattern)
+		text = re.ReplaceAllLiteralString(text, r.To)
+	}
+	return text
+}
+
+// Punctuate ends text with a period unless it already ends with
+// punctuation. Trailing whitespace is kept after the period.
+func Punctuate(text string) string {
+	body := strings.TrimRight(text, " \t\n")
+	if body == "" || strings.ContainsRune(".!?,;:…", lastRune(body)) {
+		return text
+	}
+	return body + "." + text[len(body):]
+}
+
+// Capitalize upper-cases the first letter of text and of each sentence,
+// i.e. the first letter after ".", "!" or "?" and whitespace, or after a
+// newline.
+func Capitalize(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	start := true // at the start of a sentence
+	end := false  // just saw sentence-ending punctuation
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r):
+			if start {
+				r = unicode.ToUpper(r)
+			}
+			start, end = false, false
+		case r == '\n':
+			start = true
+		case unicode.IsSpace(r):
+			if end {
+				start = true
+			}
+		case r == '.' || r == '!' || r == '?':
+			start, end = false, true
+		case unicode.IsDigit(r):
+			start, end = false, false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isWordRune reports whether r is a letter, digit or underscore, matching
+// regexp's \b definition.
+func isWordRune(r rune) bool {
+	return r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
@@ -0,0 +1,149 @@
+package transcribe
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+func TestRunPipelineOrder(t *testing.T) {
+	cfg := &config.TranscribeConfig{
+		Replacements: []config.Replacement{{From: "one", To: "won"}},
+	}
+	tests := []struct {
+		name  string
+		steps []string
+		input string
+		want  string
+	}{
+		{name: "none", steps: nil, input: " one two three ", want: " one two three "},
+		{name: "trim", steps: []string{"trim"}, input: "  hello  ", want: "hello"},
+		// Replacing first hides "one" from the number step.
+		{name: "replace_then_numbers", steps: []string{"replacements", "numbers"}, input: "one two three", want: "won 23"},
+		{name: "numbers_then_replace", steps: []string{"numbers", "replacements"}, input: "one two three", want: "123"},
+		{name: "full", steps: []string{"trim", "numbers", "punctuate", "capitalize"}, input: " i have two cats ", want: "I have 2 cats."},
+		{name: "controls", steps: []string{"controls", "capitalize"}, input: "hi new line there", want: "Hi\nThere"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RunPipeline(tt.input, tt.steps, cfg)
+			if err != nil {
+				t.Fatalf("RunPipeline() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RunPipeline(%q, %v) = %q, want %q", tt.input, tt.steps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPipelineUnknownStep(t *testing.T) {
+	got, err := RunPipeline(" two ", []string{"trim", "shout"}, &config.TranscribeConfig{})
+	if err == nil {
+		t.Fatal("RunPipeline() should fail for an unknown step")
+	}
+	if !strings.Contains(err.Error(), "shout") {
+		t.Errorf("error %q should name the unknown step", err)
+	}
+	if got != " two " {
+		t.Errorf("RunPipeline() = %q, want the input unchanged", got)
+	}
+}
+
+func TestPipelineStepsMatchConfig(t *testing.T) {
+	for _, name := range config.PipelineStepNames {
+		if _, ok := pipelineSteps[name]; !ok {
+			t.Errorf("config step %q has no transform", name)
+		}
+	}
+	for name := range pipelineSteps {
+		if !slices.Contains(config.PipelineStepNames, name) {
+			t.Errorf("transform %q is not a valid config step", name)
+		}
+	}
+}
+
+func TestPipelineStepsLegacy(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.TranscribeConfig
+		want []string
+	}{
+		{name: "off", want: nil},
+		{name: "numbers", cfg: config.TranscribeConfig{NormalizeNumbers: true}, want: []string{"numbers"}},
+		{name: "both", cfg: config.TranscribeConfig{NormalizeNumbers: true, SpokenControls: true}, want: []string{"numbers", "controls"}},
+		{name: "explicit", cfg: config.TranscribeConfig{NormalizeNumbers: true, Pipeline: []string{"trim"}}, want: []string{"trim"}},
+		{
+			name: "replacements",
+			cfg:  config.TranscribeConfig{NormalizeNumbers: true, Replacements: []config.Replacement{{From: "go lang", To: "Go"}}},
+			want: []string{"replacements", "numbers"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PipelineSteps(&tt.cfg); !slices.Equal(got, tt.want) {
+				t.Errorf("PipelineSteps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyReplacements(t *testing.T) {
+	rules := []config.Replacement{
+		{From: "ai", To: "AI"},
+		{From: "go lang", To: "Go"},
+		{From: "c++", To: "C++"},
+	}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "ai said", want: "AI said"},
+		{input: "I like Go Lang.", want: "I like Go."},
+		{input: "write c++ code", want: "write C++ code"},
+		{input: "said", want: "said"},
+	}
+	for _, tt := range tests {
+		if got := ApplyReplacements(tt.input, rules); got != tt.want {
+			t.Errorf("ApplyReplacements(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPunctuate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "hello", want: "hello."},
+		{input: "hello?", want: "hello?"},
+		{input: "hello ", want: "hello. "},
+		{input: "", want: ""},
+		{input: "count 3", want: "count 3."},
+	}
+	for _, tt := range tests {
+		if got := Punctuate(tt.input); got != tt.want {
+			t.Errorf("Punctuate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "hello. how are you? fine", want: "Hello. How are you? Fine"},
+		{input: "  leading space", want: "  Leading space"},
+		{input: "pi is 3.14 roughly", want: "Pi is 3.14 roughly"},
+		{input: "line\nnext", want: "Line\nNext"},
+		{input: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Capitalize(tt.input); got != tt.want {
+			t.Errorf("Capitalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
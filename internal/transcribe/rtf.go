@@ -0,0 +1,75 @@
+package transcribe
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RealTimeFactor returns processing time divided by audio duration. Values
+// above 1 mean transcription is slower than real time.
+func RealTimeFactor(elapsed, audio time.Duration) float64 {
+	if audio <= 0 {
+		return 0
+	}
+	return elapsed.Seconds() / audio.Seconds()
+}
+
+// ProcessTimed transcribes mono samples recorded at sampleRate with t and
+// returns the text, the processing time, and the real-time factor. Audio is
+// converted to the 16kHz backends expect, by t.ProcessRaw if t is a
+// RawTranscriber. If rtfWarn is positive and the real-time factor exceeds
+// it, a warning is logged.
+func ProcessTimed(t Transcriber, samples []float32, sampleRate int, rtfWarn float64) (string, time.Duration, float64, error) {
+	start := time.Now()
+	text, err := processAt(t, samples, sampleRate)
+	elapsed := time.Since(start)
+	if err != nil {
+		return "", elapsed, 0, err
+	}
+	return text, elapsed, checkRTF(elapsed, len(samples), sampleRate, rtfWarn), nil
+}
+
+// ProcessSegmentsTimed is ProcessTimed for a SegmentTranscriber, returning
+// the segments (with their confidence) instead of the joined text.
+func ProcessSegmentsTimed(t SegmentTranscriber, samples []float32, sampleRate int, rtfWarn float64) ([]Segment, time.Duration, float64, error) {
+	start := time.Now()
+	mono, err := toModelFormat(samples, uint32(sampleRate), 1)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	segments, err := t.ProcessSegments(mono)
+	elapsed := time.Since(start)
+	if err != nil {
+		return nil, elapsed, 0, err
+	}
+	return segments, elapsed, checkRTF(elapsed, len(samples), sampleRate, rtfWarn), nil
+}
+
+// processAt transcribes mono samples recorded at sampleRate with t.
+func processAt(t Transcriber, samples []float32, sampleRate int) (string, error) {
+	if rt, ok := t.(RawTranscriber); ok {
+		return rt.ProcessRaw(samples, uint32(sampleRate), 1)
+	}
+	mono, err := toModelFormat(samples, uint32(sampleRate), 1)
+	if err != nil {
+		return "", err
+	}
+	return t.Process(mono)
+}
+
+// checkRTF returns the real-time factor for transcribing n samples at
+// sampleRate in elapsed, logging a warning if it exceeds a positive rtfWarn.
+func checkRTF(elapsed time.Duration, n, sampleRate int, rtfWarn float64) float64 {
+	audio := time.Duration(n) * time.Second / time.Duration(sampleRate)
+	rtf := RealTimeFactor(elapsed, audio)
+	if rtfWarn > 0 && rtf > rtfWarn {
+		slog.Warn("Transcription slower than expected",
+			"rtf", fmt.Sprintf("%.2f", rtf),
+			"threshold", rtfWarn,
+			"audio", audio.Round(time.Millisecond),
+			"elapsed", elapsed.Round(time.Millisecond),
+			"hint", "Try a smaller whisper model, or the parakeet backend on Apple Silicon")
+	}
+	return rtf
+}
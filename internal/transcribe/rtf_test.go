@@ -0,0 +1,136 @@
+package transcribe
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowTranscriber sleeps for a fixed time before returning.
+type slowTranscriber struct {
+	delay time.Duration
+}
+
+func (s *slowTranscriber) Process([]float32) (string, error) {
+	time.Sleep(s.delay)
+	return "hello", nil
+}
+
+func (s *slowTranscriber) Close() error { return nil }
+
+// captureLogs redirects the default slog logger to a buffer for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func TestRealTimeFactor(t *testing.T) {
+	tests := []struct {
+		elapsed, audio time.Duration
+		want           float64
+	}{
+		{time.Second, 2 * time.Second, 0.5},
+		{3 * time.Second, time.Second, 3},
+		{time.Second, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := RealTimeFactor(tt.elapsed, tt.audio); got != tt.want {
+			t.Errorf("RealTimeFactor(%v, %v) = %v, want %v", tt.elapsed, tt.audio, got, tt.want)
+		}
+	}
+}
+
+func TestProcessTimedWarnsWhenSlow(t *testing.T) {
+	logs := captureLogs(t)
+
+	// 10ms of audio at 16kHz takes at least 50ms: RTF >= 5.
+	tr := &slowTranscriber{delay: 50 * time.Millisecond}
+	text, _, rtf, err := ProcessTimed(tr, make([]float32, 160), 16000, 1.0)
+	if err != nil {
+		t.Fatalf("ProcessTimed() error = %v", err)
+	}
+	if text != "hello" {
+		t.Errorf("text = %q, want %q", text, "hello")
+	}
+	if rtf < 5 {
+		t.Errorf("rtf = %v, want >= 5", rtf)
+	}
+	if !strings.Contains(logs.String(), "Transcription slower than expected") {
+		t.Errorf("expected RTF warning, got logs:\n%s", logs.String())
+	}
+}
+
+func TestProcessTimedNoWarning(t *testing.T) {
+	tests := []struct {
+		name    string
+		rtfWarn float64
+	}{
+		{"under_threshold", 1000},
+		{"disabled", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			tr := &slowTranscriber{delay: 10 * time.Millisecond}
+			if _, _, _, err := ProcessTimed(tr, make([]float32, 160), 16000, tt.rtfWarn); err != nil {
+				t.Fatalf("ProcessTimed() error = %v", err)
+			}
+			if strings.Contains(logs.String(), "slower than expected") {
+				t.Errorf("unexpected RTF warning:\n%s", logs.String())
+			}
+		})
+	}
+}
+
+// lenTranscriber records how many samples Process and ProcessRaw received.
+type lenTranscriber struct {
+	processed int
+	raw       bool
+}
+
+func (l *lenTranscriber) Process(samples []float32) (string, error) {
+	l.processed = len(samples)
+	return "", nil
+}
+
+func (l *lenTranscriber) Close() error { return nil }
+
+// rawLenTranscriber is a lenTranscriber that converts raw audio itself.
+type rawLenTranscriber struct{ lenTranscriber }
+
+func (l *rawLenTranscriber) ProcessRaw(samples []float32, sampleRate, channels uint32) (string, error) {
+	l.raw = true
+	mono, err := toModelFormat(samples, sampleRate, channels)
+	if err != nil {
+		return "", err
+	}
+	return l.Process(mono)
+}
+
+func TestProcessTimedConvertsSampleRate(t *testing.T) {
+	// 10ms recorded at 48kHz reaches the backend as 10ms at 16kHz.
+	plain := &lenTranscriber{}
+	if _, _, _, err := ProcessTimed(plain, make([]float32, 480), 48000, 0); err != nil {
+		t.Fatalf("ProcessTimed() error = %v", err)
+	}
+	if plain.processed != 160 {
+		t.Errorf("Process got %d samples, want 160", plain.processed)
+	}
+
+	raw := &rawLenTranscriber{}
+	if _, _, _, err := ProcessTimed(raw, make([]float32, 480), 48000, 0); err != nil {
+		t.Fatalf("ProcessTimed() error = %v", err)
+	}
+	if !raw.raw {
+		t.Error("ProcessTimed() did not use ProcessRaw")
+	}
+	if raw.processed != 160 {
+		t.Errorf("Process got %d samples, want 160", raw.processed)
+	}
+}
@@ -0,0 +1,202 @@
+package transcribe
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Compile-time interface satisfaction checks.
+var (
+	_ Transcriber        = (*ModelSelector)(nil)
+	_ SegmentTranscriber = (*ModelSelector)(nil)
+	_ LanguageDetector   = (*ModelSelector)(nil)
+	_ RawTranscriber     = (*ModelSelector)(nil)
+)
+
+// selectorSampleRate is the rate ModelSelector assumes when measuring an
+// utterance; every backend takes 16kHz audio.
+const selectorSampleRate = 16000
+
+// selectorCacheSize is how many models a ModelSelector keeps loaded. The
+// least recently used idle model is closed to make room for another.
+const selectorCacheSize = 2
+
+// ModelRule routes utterances no longer than MaxDuration to the model at Path.
+type ModelRule struct {
+	MaxDuration time.Duration
+	Path        string
+}
+
+// ModelSelector transcribes each utterance with a model chosen by its
+// length: the first rule (by ascending MaxDuration) that covers it, or the
+// default model for anything longer. Models are loaded on first use and
+// cached, so short commands can use a small fast model while long dictation
+// gets an accurate one.
+type ModelSelector struct {
+	rules       []ModelRule
+	defaultPath string
+	load        func(path string) (Transcriber, error)
+	capacity    int
+
+	mu     sync.Mutex
+	models map[string]*selectedModel
+	clock  uint64 // bumped on every use, for LRU ordering
+}
+
+// selectedModel is a cached model with its LRU bookkeeping.
+type selectedModel struct {
+	t        Transcriber
+	lastUsed uint64
+	inUse    int // utterances currently using t; it is not evicted while > 0
+}
+
+// NewModelSelector creates a ModelSelector over rules, falling back to
+// defaultPath. load opens the model at a path; it is called at most once per
+// path while that model stays cached.
+func NewModelSelector(rules []ModelRule, defaultPath string, load func(path string) (Transcriber, error)) *ModelSelector {
+	rules = slices.Clone(rules)
+	slices.SortStableFunc(rules, func(a, b ModelRule) int { return cmp.Compare(a.MaxDuration, b.MaxDuration) })
+	return &ModelSelector{
+		rules:       rules,
+		defaultPath: defaultPath,
+		load:        load,
+		capacity:    selectorCacheSize,
+		models:      make(map[string]*selectedModel),
+	}
+}
+
+// PathFor returns the model path used for an utterance of the given length.
+func (s *ModelSelector) PathFor(d time.Duration) string {
+	for _, r := range s.rules {
+		if d <= r.MaxDuration {
+			return r.Path
+		}
+	}
+	return s.defaultPath
+}
+
+// acquire returns the model for samples, loading it if needed. The caller
+// must call release when done with it.
+func (s *ModelSelector) acquire(samples []float32) (Transcriber, func(), error) {
+	path := s.PathFor(time.Duration(len(samples)) * time.Second / selectorSampleRate)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock++
+	m, ok := s.models[path]
+	if !ok {
+		start := time.Now()
+		t, err := s.load(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transcribe: load model %q: %w", path, err)
+		}
+		slog.Info("Model loaded", "path", path, "elapsed", time.Since(start).Round(time.Millisecond))
+		m = &selectedModel{t: t}
+		s.models[path] = m
+	}
+	m.lastUsed = s.clock
+	m.inUse++
+	s.evict()
+
+	release := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		m.inUse--
+		s.evict()
+	}
+	return m.t, release, nil
+}
+
+// evict closes least recently used idle models until the cache is within
+// capacity. Models in use are never closed. The caller must hold mu.
+func (s *ModelSelector) evict() {
+	for len(s.models) > s.capacity {
+		var oldest string
+		for path, m := range s.models {
+			if m.inUse == 0 && (oldest == "" || m.lastUsed < s.models[oldest].lastUsed) {
+				oldest = path
+			}
+		}
+		if oldest == "" {
+			return // everything is busy; try again on release
+		}
+		if err := s.models[oldest].t.Close(); err != nil {
+			slog.Warn("Failed to close evicted model", "path", oldest, "error", err)
+		}
+		delete(s.models, oldest)
+		slog.Debug("Evicted model", "path", oldest)
+	}
+}
+
+// Process transcribes samples with the model selected for their length.
+func (s *ModelSelector) Process(samples []float32) (string, error) {
+	t, release, err := s.acquire(samples)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return t.Process(samples)
+}
+
+// ProcessRaw converts interleaved samples recorded at sampleRate with the
+// given number of channels to mono 16kHz, then transcribes them with the
+// model selected for their length.
+func (s *ModelSelector) ProcessRaw(samples []float32, sampleRate, channels uint32) (string, error) {
+	mono, err := toModelFormat(samples, sampleRate, channels)
+	if err != nil {
+		return "", err
+	}
+	return s.Process(mono)
+}
+
+// ProcessSegments transcribes samples into segments with the model selected
+// for their length. A model without segment support yields one segment.
+func (s *ModelSelector) ProcessSegments(samples []float32) ([]Segment, error) {
+	t, release, err := s.acquire(samples)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if st, ok := t.(SegmentTranscriber); ok {
+		return st.ProcessSegments(samples)
+	}
+	text, err := t.Process(samples)
+	if err != nil || text == "" {
+		return nil, err
+	}
+	return []Segment{{Text: text}}, nil
+}
+
+// DetectLanguage detects the spoken language with the model selected for
+// the samples' length, if that model supports detection.
+func (s *ModelSelector) DetectLanguage(samples []float32) (string, float64, error) {
+	t, release, err := s.acquire(samples)
+	if err != nil {
+		return "", 0, err
+	}
+	defer release()
+	ld, ok := t.(LanguageDetector)
+	if !ok {
+		return "", 0, errors.New("transcribe: detect language: model does not support it")
+	}
+	return ld.DetectLanguage(samples)
+}
+
+// Close closes every loaded model.
+func (s *ModelSelector) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for path, m := range s.models {
+		if err := m.t.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("transcribe: close model %q: %w", path, err))
+		}
+		delete(s.models, path)
+	}
+	return errors.Join(errs...)
+}
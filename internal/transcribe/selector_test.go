@@ -0,0 +1,169 @@
+package transcribe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// selectorModel is a fake model that reports its path and whether it was
+// closed.
+type selectorModel struct {
+	path   string
+	closed bool
+}
+
+func (m *selectorModel) Process([]float32) (string, error) { return m.path, nil }
+
+func (m *selectorModel) Close() error {
+	m.closed = true
+	return nil
+}
+
+// newTestSelector returns a selector over fake models and the log of paths
+// it loaded.
+func newTestSelector(rules []ModelRule) (*ModelSelector, *[]string, map[string]*selectorModel) {
+	var loads []string
+	models := make(map[string]*selectorModel)
+	s := NewModelSelector(rules, "large.bin", func(path string) (Transcriber, error) {
+		loads = append(loads, path)
+		m := &selectorModel{path: path}
+		models[path] = m
+		return m, nil
+	})
+	return s, &loads, models
+}
+
+// secs returns d seconds of silence.
+func secs(d float64) []float32 {
+	return make([]float32, int(d*selectorSampleRate))
+}
+
+func TestModelSelectorPathFor(t *testing.T) {
+	s, _, _ := newTestSelector([]ModelRule{
+		{MaxDuration: 10 * time.Second, Path: "small.bin"},
+		{MaxDuration: 3 * time.Second, Path: "tiny.bin"}, // rules are sorted
+	})
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: time.Second, want: "tiny.bin"},
+		{d: 3 * time.Second, want: "tiny.bin"},
+		{d: 3*time.Second + time.Millisecond, want: "small.bin"},
+		{d: 10 * time.Second, want: "small.bin"},
+		{d: time.Minute, want: "large.bin"},
+	}
+	for _, tt := range tests {
+		if got := s.PathFor(tt.d); got != tt.want {
+			t.Errorf("PathFor(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestModelSelectorLoadsLazilyAndReuses(t *testing.T) {
+	s, loads, _ := newTestSelector([]ModelRule{{MaxDuration: 5 * time.Second, Path: "tiny.bin"}})
+	defer func() { _ = s.Close() }()
+
+	if len(*loads) != 0 {
+		t.Fatalf("loaded %v before any Process, want none", *loads)
+	}
+	for _, tt := range []struct {
+		samples []float32
+		want    string
+	}{
+		{secs(2), "tiny.bin"},
+		{secs(30), "large.bin"},
+		{secs(1), "tiny.bin"},
+		{secs(40), "large.bin"},
+	} {
+		got, err := s.Process(tt.samples)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Process(%d samples) used %q, want %q", len(tt.samples), got, tt.want)
+		}
+	}
+	if want := []string{"tiny.bin", "large.bin"}; !reflect.DeepEqual(*loads, want) {
+		t.Errorf("loads = %v, want %v (each model loaded once)", *loads, want)
+	}
+}
+
+func TestModelSelectorEvictsLeastRecentlyUsed(t *testing.T) {
+	s, loads, models := newTestSelector([]ModelRule{
+		{MaxDuration: 2 * time.Second, Path: "tiny.bin"},
+		{MaxDuration: 10 * time.Second, Path: "small.bin"},
+	})
+
+	for _, d := range []float64{1, 30, 1, 5} { // tiny, large, tiny, small
+		if _, err := s.Process(secs(d)); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	if !models["large.bin"].closed {
+		t.Error("large.bin (least recently used) was not evicted")
+	}
+	if models["tiny.bin"].closed || models["small.bin"].closed {
+		t.Error("a recently used model was evicted")
+	}
+
+	// The evicted model is reloaded on demand.
+	if _, err := s.Process(secs(30)); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if want := []string{"tiny.bin", "large.bin", "small.bin", "large.bin"}; !reflect.DeepEqual(*loads, want) {
+		t.Errorf("loads = %v, want %v", *loads, want)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	for path, m := range models {
+		if !m.closed {
+			t.Errorf("%s not closed by Close", path)
+		}
+	}
+}
+
+func TestModelSelectorKeepsBusyModels(t *testing.T) {
+	s, _, models := newTestSelector([]ModelRule{
+		{MaxDuration: 2 * time.Second, Path: "tiny.bin"},
+		{MaxDuration: 10 * time.Second, Path: "small.bin"},
+	})
+	defer func() { _ = s.Close() }()
+
+	// Hold tiny.bin as an in-flight utterance would.
+	_, release, err := s.acquire(secs(1))
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	for _, d := range []float64{30, 5} {
+		if _, err := s.Process(secs(d)); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	if models["tiny.bin"].closed {
+		t.Error("tiny.bin was evicted while in use")
+	}
+	if !models["large.bin"].closed {
+		t.Error("large.bin was not evicted to make room")
+	}
+	release()
+}
+
+func TestModelSelectorProcessRaw(t *testing.T) {
+	s, _, _ := newTestSelector([]ModelRule{{MaxDuration: 3 * time.Second, Path: "tiny.bin"}})
+
+	// 2s of stereo at 48kHz is 2s of audio: the short-utterance model.
+	got, err := s.ProcessRaw(make([]float32, 2*2*48000), 48000, 2)
+	if err != nil {
+		t.Fatalf("ProcessRaw() error = %v", err)
+	}
+	if got != "tiny.bin" {
+		t.Errorf("ProcessRaw() used %q, want tiny.bin", got)
+	}
+	if _, err := s.ProcessRaw(secs(1), 0, 1); err == nil {
+		t.Error("ProcessRaw() should reject a zero sample rate")
+	}
+}
@@ -0,0 +1,30 @@
+package transcribe
+
+import (
+	"regexp"
+	"strings"
+)
+
+// spokenControls maps spoken commands to the control characters they insert.
+var spokenControls = map[string]string{
+	"new line":      "\n",
+	"newline":       "\n",
+	"new paragraph": "\n\n",
+	"tab":           "\t",
+}
+
+// spokenControlPattern matches a spoken command with the spaces around it
+// and any punctuation the recognizer attached to it ("New line.").
+var spokenControlPattern = regexp.MustCompile(`(?i)[ ]*\b(new ?line|new paragraph|tab)\b[.,;:!?]?[ ]*`)
+
+// ExpandSpokenControls replaces the spoken commands "new line", "new
+// paragraph" and "tab" with newline and tab characters, e.g. "if ok new line
+// tab return" → "if ok\n\treturn". Matching is case-insensitive and only
+// whole words are replaced, so "tablet" and "tabs" are left alone.
+func ExpandSpokenControls(text string) string {
+	return spokenControlPattern.ReplaceAllStringFunc(text, func(m string) string {
+		cmd := spokenControlPattern.FindStringSubmatch(m)[1]
+		cmd = strings.Join(strings.Fields(strings.ToLower(cmd)), " ")
+		return spokenControls[cmd]
+	})
+}
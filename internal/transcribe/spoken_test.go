@@ -0,0 +1,30 @@
+package transcribe
+
+import "testing"
+
+func TestExpandSpokenControls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "new_line", input: "first line new line second line", want: "first line\nsecond line"},
+		{name: "newline_one_word", input: "a newline b", want: "a\nb"},
+		{name: "punctuated", input: "Hello there. New line. How are you?", want: "Hello there.\nHow are you?"},
+		{name: "new_paragraph", input: "Thanks, new paragraph, regards", want: "Thanks,\n\nregards"},
+		{name: "code", input: "if ok { new line tab return new line }", want: "if ok {\n\treturn\n}"},
+		{name: "leading", input: "Tab indented", want: "\tindented"},
+		{name: "trailing", input: "end of line new line", want: "end of line\n"},
+		{name: "tablet_untouched", input: "my tablet has tabs", want: "my tablet has tabs"},
+		{name: "newer_untouched", input: "a newer line", want: "a newer line"},
+		{name: "no_commands", input: "plain text", want: "plain text"},
+		{name: "empty", input: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandSpokenControls(tt.input); got != tt.want {
+				t.Errorf("ExpandSpokenControls(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +27,8 @@ type StreamingTranscriber struct {
 	lengthMs int
 	keepMs   int
 
+	translate bool // translate speech to English (see SetTranslate)
+
 	mu       sync.Mutex
 	prevText string // accumulated text from previous windows
 	cancel   context.CancelFunc
@@ -37,8 +38,10 @@ type StreamingTranscriber struct {
 // AudioFunc returns the current audio buffer snapshot (mono 16kHz float32).
 type AudioFunc func() []float32
 
-// DeltaFunc is called with each incremental text update.
-type DeltaFunc func(backspaces int, newText string)
+// UpdateFunc is called whenever the transcript changes, with the text
+// reported last time and the revised text. Revisions aren't always appends:
+// a later window may correct words already reported.
+type UpdateFunc func(prev, curr string)
 
 // NewStreamingTranscriber creates a streaming transcriber that shares the
 // given whisper model. The model must remain open for the lifetime of this
@@ -52,12 +55,18 @@ func NewStreamingTranscriber(model whisper.Model, stepMs, lengthMs, keepMs int)
 	}
 }
 
+// SetTranslate makes each window translate speech to English instead of
+// transcribing it. Call before Start.
+func (s *StreamingTranscriber) SetTranslate(on bool) {
+	s.translate = on
+}
+
 // Start begins the streaming transcription loop. It calls audioFn every
 // stepMs milliseconds to get the current audio, transcribes a sliding window,
-// and calls deltaFn with incremental text updates. Blocks until Stop() is
+// and calls updateFn whenever the transcript changes. Blocks until Stop() is
 // called or ctx is cancelled. After stopping, performs one final transcription
 // of all audio.
-func (s *StreamingTranscriber) Start(audioFn AudioFunc, deltaFn DeltaFunc) {
+func (s *StreamingTranscriber) Start(audioFn AudioFunc, updateFn UpdateFunc) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	s.mu.Lock()
@@ -68,7 +77,7 @@ func (s *StreamingTranscriber) Start(audioFn AudioFunc, deltaFn DeltaFunc) {
 
 	go func() {
 		defer close(s.done)
-		s.run(ctx, audioFn, deltaFn)
+		s.run(ctx, audioFn, updateFn)
 	}()
 }
 
@@ -96,7 +105,7 @@ func (s *StreamingTranscriber) FinalText() string {
 	return s.prevText
 }
 
-func (s *StreamingTranscriber) run(ctx context.Context, audioFn AudioFunc, deltaFn DeltaFunc) {
+func (s *StreamingTranscriber) run(ctx context.Context, audioFn AudioFunc, updateFn UpdateFunc) {
 	ticker := time.NewTicker(time.Duration(s.stepMs) * time.Millisecond)
 	defer ticker.Stop()
 
@@ -110,7 +119,7 @@ func (s *StreamingTranscriber) run(ctx context.Context, audioFn AudioFunc, delta
 		select {
 		case <-ctx.Done():
 			// Final transcription of all audio
-			s.finalTranscribe(audioFn, deltaFn, prompt)
+			s.finalTranscribe(audioFn, updateFn, prompt)
 			return
 		case <-ticker.C:
 			samples := audioFn()
@@ -150,17 +159,13 @@ func (s *StreamingTranscriber) run(ctx context.Context, audioFn AudioFunc, delta
 			// Use last segment as prompt for next window
 			prompt = text
 
-			// Compute and emit delta
+			// Emit the update if the transcript changed
 			s.mu.Lock()
-			backspaces, appendText := computeDelta(s.prevText, text)
-			if backspaces > 0 || appendText != "" {
+			prev := s.prevText
+			if text != prev {
 				s.prevText = text
 				s.mu.Unlock()
-				deltaFn(backspaces, appendText)
-				slog.Debug("streaming: delta",
-					"backspaces", backspaces,
-					"append", appendText,
-					"elapsed", elapsed.Round(time.Millisecond))
+				updateFn(prev, text)
 			} else {
 				s.mu.Unlock()
 			}
@@ -168,7 +173,7 @@ func (s *StreamingTranscriber) run(ctx context.Context, audioFn AudioFunc, delta
 	}
 }
 
-func (s *StreamingTranscriber) finalTranscribe(audioFn AudioFunc, deltaFn DeltaFunc, prompt string) {
+func (s *StreamingTranscriber) finalTranscribe(audioFn AudioFunc, updateFn UpdateFunc, prompt string) {
 	samples := audioFn()
 	if len(samples) == 0 {
 		return
@@ -185,12 +190,12 @@ func (s *StreamingTranscriber) finalTranscribe(audioFn AudioFunc, deltaFn DeltaF
 	}
 
 	s.mu.Lock()
-	backspaces, appendText := computeDelta(s.prevText, text)
+	prev := s.prevText
 	s.prevText = text
 	s.mu.Unlock()
 
-	if backspaces > 0 || appendText != "" {
-		deltaFn(backspaces, appendText)
+	if prev != text {
+		updateFn(prev, text)
 	}
 
 	slog.Info("streaming: final transcription", "text", text)
@@ -202,6 +207,9 @@ func (s *StreamingTranscriber) transcribeWindow(samples []float32, prompt string
 		return "", fmt.Errorf("streaming: create context: %w", err)
 	}
 
+	if err := setTask(ctx, s.translate); err != nil {
+		return "", fmt.Errorf("streaming: %w", err)
+	}
 	if prompt != "" {
 		ctx.SetInitialPrompt(prompt)
 	}
@@ -222,5 +230,5 @@ func (s *StreamingTranscriber) transcribeWindow(samples []float32, prompt string
 		segments = append(segments, seg.Text)
 	}
 
-	return strings.TrimSpace(strings.Join(segments, " ")), nil
+	return joinSegments(segments), nil
 }
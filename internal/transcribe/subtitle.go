@@ -0,0 +1,66 @@
+package transcribe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatSRT renders segments as SubRip (.srt) subtitles. Segments with no
+// text are skipped and cues are numbered from 1.
+func FormatSRT(segments []Segment) string {
+	var b strings.Builder
+	n := 0
+	for _, seg := range segments {
+		text := cueText(seg.Text)
+		if text == "" {
+			continue
+		}
+		n++
+		if n > 1 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n",
+			n, formatTimestamp(seg.Start, ','), formatTimestamp(seg.End, ','), text)
+	}
+	return b.String()
+}
+
+// FormatVTT renders segments as WebVTT (.vtt) subtitles. Segments with no
+// text are skipped.
+func FormatVTT(segments []Segment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for _, seg := range segments {
+		text := cueText(seg.Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n",
+			formatTimestamp(seg.Start, '.'), formatTimestamp(seg.End, '.'), text)
+	}
+	return b.String()
+}
+
+// cueText trims each line of a segment's text and drops blank lines, since a
+// blank line terminates a cue in both SRT and VTT.
+func cueText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatTimestamp renders d as HH:MM:SS followed by sep and milliseconds,
+// e.g. "00:01:02,345" for SRT or "00:01:02.345" for VTT.
+func formatTimestamp(d time.Duration, sep byte) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d",
+		ms/3_600_000, ms/60_000%60, ms/1000%60, sep, ms%1000)
+}
@@ -0,0 +1,125 @@
+package transcribe
+
+import (
+	"testing"
+	"time"
+)
+
+var testSegments = []Segment{
+	{Start: 0, End: 2500 * time.Millisecond, Text: " And so my fellow Americans,"},
+	{Start: 2500 * time.Millisecond, End: 4 * time.Second, Text: "   "},
+	{Start: 4 * time.Second, End: 61*time.Second + 7*time.Millisecond, Text: " ask not\n\n what your country can do for you"},
+	{Start: time.Hour + 2*time.Minute + 3*time.Second + 450*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "ask what you can do."},
+}
+
+func TestFormatSRT(t *testing.T) {
+	want := `1
+00:00:00,000 --> 00:00:02,500
+And so my fellow Americans,
+
+2
+00:00:04,000 --> 00:01:01,007
+ask not
+what your country can do for you
+
+3
+01:02:03,450 --> 01:02:05,000
+ask what you can do.
+`
+	if got := FormatSRT(testSegments); got != want {
+		t.Errorf("FormatSRT() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatVTT(t *testing.T) {
+	want := `WEBVTT
+
+00:00:00.000 --> 00:00:02.500
+And so my fellow Americans,
+
+00:00:04.000 --> 00:01:01.007
+ask not
+what your country can do for you
+
+01:02:03.450 --> 01:02:05.000
+ask what you can do.
+`
+	if got := FormatVTT(testSegments); got != want {
+		t.Errorf("FormatVTT() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatSubtitlesShortSegments(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 0, Text: "click"},                                                              // zero-length
+		{Start: 120 * time.Millisecond, End: 870 * time.Millisecond, Text: "hi"},                       // sub-second
+		{Start: 999 * time.Millisecond, End: 1001 * time.Millisecond, Text: "there"},                   // spans a second boundary
+		{Start: 1500 * time.Millisecond, End: 1500*time.Millisecond + 400*time.Microsecond, Text: "x"}, // sub-millisecond
+	}
+
+	wantSRT := `1
+00:00:00,000 --> 00:00:00,000
+click
+
+2
+00:00:00,120 --> 00:00:00,870
+hi
+
+3
+00:00:00,999 --> 00:00:01,001
+there
+
+4
+00:00:01,500 --> 00:00:01,500
+x
+`
+	if got := FormatSRT(segments); got != wantSRT {
+		t.Errorf("FormatSRT() =\n%s\nwant:\n%s", got, wantSRT)
+	}
+
+	wantVTT := `WEBVTT
+
+00:00:00.000 --> 00:00:00.000
+click
+
+00:00:00.120 --> 00:00:00.870
+hi
+
+00:00:00.999 --> 00:00:01.001
+there
+
+00:00:01.500 --> 00:00:01.500
+x
+`
+	if got := FormatVTT(segments); got != wantVTT {
+		t.Errorf("FormatVTT() =\n%s\nwant:\n%s", got, wantVTT)
+	}
+}
+
+func TestFormatSubtitlesEmpty(t *testing.T) {
+	if got := FormatSRT(nil); got != "" {
+		t.Errorf("FormatSRT(nil) = %q, want empty", got)
+	}
+	if got := FormatVTT(nil); got != "WEBVTT\n" {
+		t.Errorf("FormatVTT(nil) = %q, want %q", got, "WEBVTT\n")
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		sep  byte
+		want string
+	}{
+		{0, ',', "00:00:00,000"},
+		{999 * time.Millisecond, ',', "00:00:00,999"},
+		{59*time.Minute + 59*time.Second, '.', "00:59:59.000"},
+		{10*time.Hour + 1500*time.Microsecond, '.', "10:00:00.001"},
+		{-time.Second, ',', "00:00:00,000"},
+	}
+	for _, tt := range tests {
+		if got := formatTimestamp(tt.d, tt.sep); got != tt.want {
+			t.Errorf("formatTimestamp(%v, %q) = %q, want %q", tt.d, tt.sep, got, tt.want)
+		}
+	}
+}
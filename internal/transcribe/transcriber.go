@@ -3,11 +3,19 @@
 // Supported backends:
 //   - whisper: whisper.cpp via Go bindings (default)
 //   - parakeet: Parakeet TDT 0.6B v2 via CoreML
+//   - mock: returns transcribe.mock_text, for testing without a model
 package transcribe
 
 import (
 	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/chaz8081/gostt-writer/internal/audio"
 	"github.com/chaz8081/gostt-writer/internal/config"
 )
 
@@ -19,14 +27,189 @@ type Transcriber interface {
 	Close() error
 }
 
-// New creates a Transcriber based on the config backend setting.
+// Segment is a span of transcribed text with its position in the audio.
+type Segment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+	// Confidence is the backend's confidence in Text, from 0 to 1 (whisper:
+	// mean token probability). It is 0 for backends that don't report one.
+	Confidence float64
+}
+
+// SegmentTranscriber is implemented by backends that can report segment
+// timestamps alongside the transcribed text.
+type SegmentTranscriber interface {
+	// ProcessSegments transcribes mono 16kHz float32 audio samples into
+	// timestamped segments.
+	ProcessSegments(samples []float32) ([]Segment, error)
+}
+
+// LanguageDetector is implemented by backends that can identify the spoken
+// language of a recording.
+type LanguageDetector interface {
+	// DetectLanguage returns the most likely language code (e.g. "es") of
+	// mono 16kHz float32 audio and the backend's probability for it.
+	DetectLanguage(samples []float32) (lang string, prob float64, err error)
+}
+
+// RawTranscriber is implemented by backends that accept audio in another
+// PCM layout and convert it to the mono 16kHz they need.
+type RawTranscriber interface {
+	// ProcessRaw transcribes interleaved float32 samples recorded at
+	// sampleRate with the given number of channels.
+	ProcessRaw(samples []float32, sampleRate, channels uint32) (string, error)
+}
+
+// modelSampleRate is the sample rate every backend expects.
+const modelSampleRate = 16000
+
+// toModelFormat downmixes interleaved samples to mono and resamples them to
+// modelSampleRate.
+func toModelFormat(samples []float32, sampleRate, channels uint32) ([]float32, error) {
+	if sampleRate == 0 || channels == 0 {
+		return nil, fmt.Errorf("transcribe: invalid input format: %d Hz, %d channels", sampleRate, channels)
+	}
+	return audio.Resample(audio.DownmixToMono(samples, channels), sampleRate, modelSampleRate), nil
+}
+
+// warmupSamples is the length of the silent buffer used by Warmup (1s at 16kHz).
+const warmupSamples = 16000
+
+// backendConstructors maps backend names to constructors. Replaced in tests.
+var backendConstructors = map[string]func(cfg *config.TranscribeConfig) (Transcriber, error){
+	"whisper": func(cfg *config.TranscribeConfig) (Transcriber, error) {
+		temp, inc := float32(cfg.Whisper.Temperature), float32(cfg.Whisper.TemperatureInc)
+		opts := WhisperOptions{
+			InitialPrompt:      cfg.Whisper.InitialPrompt,
+			HotWords:           cfg.Whisper.HotWords,
+			Translate:          cfg.Whisper.Task == "translate",
+			AnnotationPatterns: annotationPatterns(cfg),
+			Temperature:        &temp,
+			TemperatureInc:     &inc,
+			Deterministic:      cfg.Whisper.Deterministic,
+		}
+		if len(cfg.ModelSelection) > 0 {
+			return newWhisperSelector(cfg, opts)
+		}
+		return NewWhisperTranscriber(cfg.ModelPath, opts)
+	},
+	"mock": func(cfg *config.TranscribeConfig) (Transcriber, error) {
+		return NewMockTranscriber(cfg.MockText)
+	},
+	"parakeet": func(cfg *config.TranscribeConfig) (Transcriber, error) {
+		units, err := ParseComputeUnits(cfg.Parakeet.ComputeUnits)
+		if err != nil {
+			return nil, err
+		}
+		return NewParakeetTranscriber(cfg.ParakeetModelDir, ParakeetOptions{
+			BlankID:           cfg.ParakeetBlankID,
+			MaxSymbolsPerStep: cfg.ParakeetMaxSyms,
+			MaxRepeats:        cfg.Parakeet.MaxRepeats,
+			ComputeUnits:      units,
+			PredictTimeout:    time.Duration(cfg.Parakeet.PredictTimeoutMs) * time.Millisecond,
+		})
+	},
+}
+
+// newWhisperSelector creates a ModelSelector over cfg.ModelSelection's
+// whisper models, with cfg.ModelPath for longer utterances. Models load
+// lazily, but a missing model file is reported now.
+func newWhisperSelector(cfg *config.TranscribeConfig, opts WhisperOptions) (*ModelSelector, error) {
+	rules := make([]ModelRule, len(cfg.ModelSelection))
+	paths := []string{cfg.ModelPath}
+	for i, r := range cfg.ModelSelection {
+		rules[i] = ModelRule{
+			MaxDuration: time.Duration(r.MaxSecs * float64(time.Second)),
+			Path:        r.ModelPath,
+		}
+		paths = append(paths, r.ModelPath)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("transcribe: whisper model: %w", err)
+		}
+	}
+	return NewModelSelector(rules, cfg.ModelPath, func(path string) (Transcriber, error) {
+		return NewWhisperTranscriber(path, opts)
+	}), nil
+}
+
+// New creates a Transcriber based on the config backend setting. If the
+// backend fails to initialize and cfg.FallbackBackend is set, the fallback
+// is tried instead. If cfg.Warmup is set, the model is warmed up before New
+// returns.
 func New(cfg *config.TranscribeConfig) (Transcriber, error) {
-	switch cfg.Backend {
-	case "parakeet":
-		return NewParakeetTranscriber(cfg.ParakeetModelDir)
-	case "whisper", "":
-		return NewWhisperTranscriber(cfg.ModelPath)
+	backend := cfg.Backend
+	t, err := newBackend(backend, cfg)
+	if err != nil && cfg.FallbackBackend != "" && cfg.FallbackBackend != backend {
+		slog.Warn("Transcription backend failed to load, using fallback",
+			"backend", backend,
+			"fallback", cfg.FallbackBackend,
+			"error", err)
+		backend = cfg.FallbackBackend
+		var fbErr error
+		t, fbErr = newBackend(backend, cfg)
+		if fbErr != nil {
+			return nil, fmt.Errorf("%w; fallback %q: %w", err, backend, fbErr)
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, mock := t.(*MockTranscriber); cfg.Warmup && !mock {
+		start := time.Now()
+		if err := Warmup(t); err != nil {
+			// Warm-up is an optimization; a failure here will resurface on
+			// the first real dictation if the model is actually broken.
+			slog.Warn("Model warm-up failed", "backend", backend, "error", err)
+		} else {
+			slog.Info("Model warmed up", "backend", backend, "elapsed", time.Since(start).Round(time.Millisecond))
+		}
+	}
+	return t, nil
+}
+
+// newBackend constructs the named backend ("" means whisper).
+func newBackend(name string, cfg *config.TranscribeConfig) (Transcriber, error) {
+	if name == "" {
+		name = "whisper"
+	}
+	construct, ok := backendConstructors[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(backendConstructors))
+		return nil, &BackendError{Backend: name, Err: fmt.Errorf("transcribe: unknown backend %q (supported: %s)", name, strings.Join(names, ", "))}
+	}
+	t, err := construct(cfg)
+	if err != nil {
+		return nil, &BackendError{Backend: name, Err: err}
+	}
+	return t, nil
+}
+
+// BackendName returns the backend name of a Transcriber created by New,
+// which may differ from the configured backend after a fallback.
+func BackendName(t Transcriber) string {
+	switch t.(type) {
+	case *WhisperTranscriber, *ModelSelector:
+		return "whisper"
+	case *ParakeetTranscriber:
+		return "parakeet"
+	case *MockTranscriber:
+		return "mock"
 	default:
-		return nil, fmt.Errorf("transcribe: unknown backend %q (supported: whisper, parakeet)", cfg.Backend)
+		return "unknown"
+	}
+}
+
+// Warmup runs one transcription on a short buffer of silence so that lazy
+// backend initialization (CoreML compilation, whisper buffer allocation)
+// happens before the first real dictation.
+func Warmup(t Transcriber) error {
+	if _, err := t.Process(make([]float32, warmupSamples)); err != nil {
+		return fmt.Errorf("transcribe: warm-up: %w", err)
 	}
+	return nil
 }
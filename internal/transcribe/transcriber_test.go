@@ -0,0 +1,142 @@
+package transcribe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chaz8081/gostt-writer/internal/config"
+)
+
+// mockTranscriber records Process calls and returns a fixed result.
+type mockTranscriber struct {
+	calls [][]float32
+	text  string
+	err   error
+}
+
+func (m *mockTranscriber) Process(samples []float32) (string, error) {
+	m.calls = append(m.calls, samples)
+	return m.text, m.err
+}
+
+func (m *mockTranscriber) Close() error { return nil }
+
+func TestWarmup(t *testing.T) {
+	m := &mockTranscriber{}
+	if err := Warmup(m); err != nil {
+		t.Fatalf("Warmup() error = %v", err)
+	}
+	if len(m.calls) != 1 {
+		t.Fatalf("Process called %d times, want 1", len(m.calls))
+	}
+	if len(m.calls[0]) != warmupSamples {
+		t.Errorf("warm-up buffer has %d samples, want %d", len(m.calls[0]), warmupSamples)
+	}
+	for i, s := range m.calls[0] {
+		if s != 0 {
+			t.Fatalf("warm-up sample %d = %v, want silence", i, s)
+		}
+	}
+}
+
+func TestWarmupError(t *testing.T) {
+	want := errors.New("model not ready")
+	m := &mockTranscriber{err: want}
+	if err := Warmup(m); !errors.Is(err, want) {
+		t.Errorf("Warmup() error = %v, want %v", err, want)
+	}
+}
+
+// stubBackends replaces backendConstructors for the duration of a test.
+func stubBackends(t *testing.T, constructors map[string]func(*config.TranscribeConfig) (Transcriber, error)) {
+	t.Helper()
+	prev := backendConstructors
+	backendConstructors = constructors
+	t.Cleanup(func() { backendConstructors = prev })
+}
+
+func TestNewFallbackBackend(t *testing.T) {
+	primaryErr := errors.New("coreml unavailable")
+	fallback := &mockTranscriber{}
+	var used []string
+	stubBackends(t, map[string]func(*config.TranscribeConfig) (Transcriber, error){
+		"parakeet": func(*config.TranscribeConfig) (Transcriber, error) {
+			used = append(used, "parakeet")
+			return nil, primaryErr
+		},
+		"whisper": func(*config.TranscribeConfig) (Transcriber, error) {
+			used = append(used, "whisper")
+			return fallback, nil
+		},
+	})
+
+	tr, err := New(&config.TranscribeConfig{Backend: "parakeet", FallbackBackend: "whisper"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tr != fallback {
+		t.Errorf("New() = %v, want fallback transcriber", tr)
+	}
+	if len(used) != 2 || used[0] != "parakeet" || used[1] != "whisper" {
+		t.Errorf("constructors called = %v, want [parakeet whisper]", used)
+	}
+}
+
+func TestNewFallbackBothFail(t *testing.T) {
+	primaryErr := errors.New("coreml unavailable")
+	fallbackErr := errors.New("model missing")
+	stubBackends(t, map[string]func(*config.TranscribeConfig) (Transcriber, error){
+		"parakeet": func(*config.TranscribeConfig) (Transcriber, error) { return nil, primaryErr },
+		"whisper":  func(*config.TranscribeConfig) (Transcriber, error) { return nil, fallbackErr },
+	})
+
+	_, err := New(&config.TranscribeConfig{Backend: "parakeet", FallbackBackend: "whisper"})
+	if !errors.Is(err, primaryErr) || !errors.Is(err, fallbackErr) {
+		t.Errorf("New() error = %v, want both primary and fallback errors", err)
+	}
+}
+
+func TestNewNoFallbackOnSuccess(t *testing.T) {
+	primary := &mockTranscriber{}
+	stubBackends(t, map[string]func(*config.TranscribeConfig) (Transcriber, error){
+		"parakeet": func(*config.TranscribeConfig) (Transcriber, error) { return primary, nil },
+		"whisper": func(*config.TranscribeConfig) (Transcriber, error) {
+			t.Error("fallback constructor should not be called")
+			return nil, nil
+		},
+	})
+
+	tr, err := New(&config.TranscribeConfig{Backend: "parakeet", FallbackBackend: "whisper"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if tr != primary {
+		t.Errorf("New() = %v, want primary transcriber", tr)
+	}
+}
+
+func TestNewWithoutFallbackReturnsError(t *testing.T) {
+	primaryErr := errors.New("coreml unavailable")
+	stubBackends(t, map[string]func(*config.TranscribeConfig) (Transcriber, error){
+		"parakeet": func(*config.TranscribeConfig) (Transcriber, error) { return nil, primaryErr },
+		"whisper": func(*config.TranscribeConfig) (Transcriber, error) {
+			t.Error("whisper constructor should not be called without fallback")
+			return nil, nil
+		},
+	})
+
+	if _, err := New(&config.TranscribeConfig{Backend: "parakeet"}); !errors.Is(err, primaryErr) {
+		t.Errorf("New() error = %v, want %v", err, primaryErr)
+	}
+}
+
+func TestNewUnknownBackend(t *testing.T) {
+	_, err := New(&config.TranscribeConfig{Backend: "bogus", Warmup: true})
+	if err == nil {
+		t.Fatal("New() with unknown backend should return error")
+	}
+	if !strings.Contains(err.Error(), "supported: mock, parakeet, whisper") {
+		t.Errorf("New() error = %v, want it to list every backend", err)
+	}
+}
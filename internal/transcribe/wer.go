@@ -3,8 +3,40 @@ package transcribe
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// AlignOpType is the kind of edit in a word alignment.
+type AlignOpType int
+
+const (
+	OpMatch AlignOpType = iota // reference and hypothesis words agree
+	OpSub                      // reference word replaced by hypothesis word
+	OpIns                      // extra hypothesis word
+	OpDel                      // reference word missing from hypothesis
+)
+
+// String returns the single-letter marker used in rendered alignments.
+func (t AlignOpType) String() string {
+	switch t {
+	case OpSub:
+		return "S"
+	case OpIns:
+		return "I"
+	case OpDel:
+		return "D"
+	}
+	return ""
+}
+
+// AlignOp is one step of the word alignment between reference and hypothesis.
+// Ref is empty for insertions and Hyp is empty for deletions.
+type AlignOp struct {
+	Type AlignOpType
+	Ref  string
+	Hyp  string
+}
+
 // WERResult holds detailed word error rate results.
 type WERResult struct {
 	WER           float64 // Word Error Rate (0.0 = perfect, 1.0+ = very bad)
@@ -12,6 +44,8 @@ type WERResult struct {
 	Insertions    int     // Extra words in hypothesis
 	Deletions     int     // Words missing from hypothesis
 	RefWords      int     // Total words in reference
+
+	Alignment []AlignOp // Word-level alignment, in reference order
 }
 
 // ComputeWER calculates the word error rate between reference and hypothesis text.
@@ -53,35 +87,86 @@ func ComputeWER(reference, hypothesis string) WERResult {
 
 	// Backtrace to count substitutions, insertions, deletions.
 	var subs, ins, dels int
+	var align []AlignOp
 	i, j := n, m
 	for i > 0 || j > 0 {
 		if i > 0 && j > 0 && refWords[i-1] == hypWords[j-1] {
 			// Match
+			align = append(align, AlignOp{Type: OpMatch, Ref: refWords[i-1], Hyp: hypWords[j-1]})
 			i--
 			j--
 		} else if i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1 {
 			// Substitution
+			align = append(align, AlignOp{Type: OpSub, Ref: refWords[i-1], Hyp: hypWords[j-1]})
 			subs++
 			i--
 			j--
 		} else if i > 0 && d[i][j] == d[i-1][j]+1 {
 			// Deletion (ref word missing from hyp)
+			align = append(align, AlignOp{Type: OpDel, Ref: refWords[i-1]})
 			dels++
 			i--
 		} else {
 			// Insertion (extra word in hyp)
+			align = append(align, AlignOp{Type: OpIns, Hyp: hypWords[j-1]})
 			ins++
 			j--
 		}
 	}
 
+	// The backtrace walks from the end; put the alignment in reading order.
+	for l, r := 0, len(align)-1; l < r; l, r = l+1, r-1 {
+		align[l], align[r] = align[r], align[l]
+	}
+
 	return WERResult{
 		WER:           float64(subs+ins+dels) / float64(n),
 		Substitutions: subs,
 		Insertions:    ins,
 		Deletions:     dels,
 		RefWords:      n,
+		Alignment:     align,
+	}
+}
+
+// Render formats the alignment as an aligned three-line diff: reference words,
+// hypothesis words, and an S/I/D marker under each error. Missing words are
+// shown as asterisks.
+//
+//	REF: the quick brown fox jumps over the lazy dog
+//	HYP: a   quick brown cat jumps **** the lazy dog
+//	     S               S         D
+func (r WERResult) Render() string {
+	var ref, hyp, ops strings.Builder
+	for k, op := range r.Alignment {
+		rw, hw := op.Ref, op.Hyp
+		width := max(utf8.RuneCountInString(rw), utf8.RuneCountInString(hw))
+		if rw == "" {
+			rw = strings.Repeat("*", width)
+		}
+		if hw == "" {
+			hw = strings.Repeat("*", width)
+		}
+		if k > 0 {
+			ref.WriteByte(' ')
+			hyp.WriteByte(' ')
+			ops.WriteByte(' ')
+		}
+		ref.WriteString(padRight(rw, width))
+		hyp.WriteString(padRight(hw, width))
+		ops.WriteString(padRight(op.Type.String(), width))
+	}
+	return "REF: " + strings.TrimRight(ref.String(), " ") + "\n" +
+		"HYP: " + strings.TrimRight(hyp.String(), " ") + "\n" +
+		"     " + strings.TrimRight(ops.String(), " ") + "\n"
+}
+
+// padRight pads s with spaces to width runes.
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
 	}
+	return s
 }
 
 // normalizeWords lowercases text, strips punctuation, and splits into words.
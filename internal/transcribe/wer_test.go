@@ -1,6 +1,9 @@
 package transcribe
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestComputeWER(t *testing.T) {
 	tests := []struct {
@@ -12,6 +15,7 @@ func TestComputeWER(t *testing.T) {
 		wantIns    int
 		wantDels   int
 		wantRef    int
+		wantAlign  []AlignOp
 	}{
 		{
 			name:       "identical",
@@ -106,6 +110,17 @@ func TestComputeWER(t *testing.T) {
 			wantSubs: 2,
 			wantDels: 1,
 			wantRef:  9,
+			wantAlign: []AlignOp{
+				{Type: OpSub, Ref: "the", Hyp: "a"},
+				{Type: OpMatch, Ref: "quick", Hyp: "quick"},
+				{Type: OpMatch, Ref: "brown", Hyp: "brown"},
+				{Type: OpSub, Ref: "fox", Hyp: "cat"},
+				{Type: OpMatch, Ref: "jumps", Hyp: "jumps"},
+				{Type: OpDel, Ref: "over"},
+				{Type: OpMatch, Ref: "the", Hyp: "the"},
+				{Type: OpMatch, Ref: "lazy", Hyp: "lazy"},
+				{Type: OpMatch, Ref: "dog", Hyp: "dog"},
+			},
 		},
 	}
 	for _, tt := range tests {
@@ -127,6 +142,9 @@ func TestComputeWER(t *testing.T) {
 			if tt.wantDels != 0 && got.Deletions != tt.wantDels {
 				t.Errorf("Deletions = %d, want %d", got.Deletions, tt.wantDels)
 			}
+			if tt.wantAlign != nil && !reflect.DeepEqual(got.Alignment, tt.wantAlign) {
+				t.Errorf("Alignment = %+v, want %+v", got.Alignment, tt.wantAlign)
+			}
 		})
 	}
 }
@@ -149,3 +167,24 @@ func TestComputeWERResult(t *testing.T) {
 		t.Errorf("WER = %f, want %f", got.WER, wantWER)
 	}
 }
+
+func TestWERRender(t *testing.T) {
+	got := ComputeWER(
+		"the quick brown fox jumps over the lazy dog",
+		"a quick brown cat jumps the lazy dog",
+	).Render()
+	want := "REF: the quick brown fox jumps over the lazy dog\n" +
+		"HYP: a   quick brown cat jumps **** the lazy dog\n" +
+		"     S               S         D\n"
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	got = ComputeWER("the cat sat", "the big cat sat").Render()
+	want = "REF: the *** cat sat\n" +
+		"HYP: the big cat sat\n" +
+		"         I\n"
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
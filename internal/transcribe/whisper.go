@@ -1,26 +1,137 @@
 package transcribe
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"runtime"
 	"strings"
 
 	whisper "github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
 )
 
+// Compile-time interface satisfaction checks.
+var (
+	_ Transcriber        = (*WhisperTranscriber)(nil)
+	_ SegmentTranscriber = (*WhisperTranscriber)(nil)
+	_ LanguageDetector   = (*WhisperTranscriber)(nil)
+	_ RawTranscriber     = (*WhisperTranscriber)(nil)
+)
+
+// detectWindowSamples is how much audio DetectLanguage looks at: whisper
+// identifies the language from its first 30s window (30s at 16kHz).
+const detectWindowSamples = 30 * 16000
+
+// whisperMinSamples is the shortest input whisper.cpp transcribes (100ms at
+// 16kHz); it silently returns nothing for anything shorter.
+const whisperMinSamples = 1600
+
 // WhisperTranscriber wraps a whisper.cpp model for speech-to-text.
 type WhisperTranscriber struct {
-	model whisper.Model
+	model       whisper.Model
+	prompt      string             // initial prompt applied to every context ("" = none)
+	translate   bool               // translate speech to English instead of transcribing it
+	annotations *annotationMatcher // strips non-speech annotations from segments (nil = off)
+	temperature *float32           // decoding temperature (nil = whisper default)
+	tempInc     *float32           // temperature fallback step (nil = whisper default)
+	beamSize    int                // beam search width (0 = whisper default)
+}
+
+// WhisperOptions configures a WhisperTranscriber.
+type WhisperOptions struct {
+	// InitialPrompt is text whisper treats as preceding the audio, biasing
+	// it toward the prompt's vocabulary and style.
+	InitialPrompt string
+	// HotWords are domain terms (names, identifiers, jargon) appended to the
+	// initial prompt.
+	HotWords []string
+	// Translate makes whisper output English text whatever language is
+	// spoken. The source language is detected from the audio. Requires a
+	// multilingual model.
+	Translate bool
+	// AnnotationPatterns, if set, removes bracketed non-speech annotations
+	// matching these patterns (see StripAnnotations) from segment text, and
+	// drops segments left empty.
+	AnnotationPatterns []string
+	// Temperature, if set, is the decoding temperature; 0 is greedy and
+	// deterministic.
+	Temperature *float32
+	// TemperatureInc, if set, is how much whisper raises the temperature
+	// each time it retries a degenerate decode; 0 disables the retries.
+	TemperatureInc *float32
+	// Deterministic forces reproducible greedy decoding (temperature 0, no
+	// temperature retries, beam size 1), overriding Temperature and
+	// TemperatureInc. Used by the benchmarks so WER is comparable across
+	// runs.
+	Deterministic bool
 }
 
 // NewWhisperTranscriber loads a whisper model from the given path.
 // The caller must call Close() when done.
-func NewWhisperTranscriber(modelPath string) (*WhisperTranscriber, error) {
+func NewWhisperTranscriber(modelPath string, opts WhisperOptions) (*WhisperTranscriber, error) {
 	model, err := whisper.New(modelPath)
 	if err != nil {
 		return nil, fmt.Errorf("transcribe: load whisper model %q: %w", modelPath, err)
 	}
-	return &WhisperTranscriber{model: model}, nil
+	if opts.Translate && !model.IsMultilingual() {
+		_ = model.Close()
+		return nil, fmt.Errorf("transcribe: translate needs a multilingual whisper model, %q is English-only", modelPath)
+	}
+	return newWhisperTranscriber(model, opts), nil
+}
+
+// newWhisperTranscriber creates a WhisperTranscriber for a loaded model,
+// applying opts.
+func newWhisperTranscriber(model whisper.Model, opts WhisperOptions) *WhisperTranscriber {
+	t := &WhisperTranscriber{
+		model:       model,
+		prompt:      whisperPrompt(opts),
+		translate:   opts.Translate,
+		temperature: opts.Temperature,
+		tempInc:     opts.TemperatureInc,
+	}
+	if opts.Deterministic {
+		zero := float32(0)
+		t.temperature, t.tempInc, t.beamSize = &zero, &zero, 1
+	}
+	if len(opts.AnnotationPatterns) > 0 {
+		t.annotations = newAnnotationMatcher(opts.AnnotationPatterns)
+	}
+	return t
+}
+
+// setTask configures ctx to translate to English when translate is set.
+// Transcription is the context default, so nothing is changed otherwise.
+func setTask(ctx whisper.Context, translate bool) error {
+	if !translate {
+		return nil
+	}
+	if err := ctx.SetLanguage("auto"); err != nil {
+		return fmt.Errorf("set language: %w", err)
+	}
+	ctx.SetTranslate(true)
+	return nil
+}
+
+// whisperPrompt builds the initial prompt from opts: the prompt text followed
+// by the hot words as a comma-separated list.
+func whisperPrompt(opts WhisperOptions) string {
+	parts := []string{strings.TrimSpace(opts.InitialPrompt)}
+	var words []string
+	for _, w := range opts.HotWords {
+		if w = strings.TrimSpace(w); w != "" {
+			words = append(words, w)
+		}
+	}
+	if len(words) > 0 {
+		parts = append(parts, strings.Join(words, ", "))
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// Translate reports whether the transcriber translates speech to English.
+func (t *WhisperTranscriber) Translate() bool {
+	return t.translate
 }
 
 // Model returns the underlying whisper model. Used by StreamingTranscriber
@@ -32,33 +143,167 @@ func (t *WhisperTranscriber) Model() whisper.Model {
 // Close releases the whisper model resources.
 func (t *WhisperTranscriber) Close() error {
 	if t.model != nil {
-		return t.model.Close()
+		model := t.model
+		t.model = nil
+		return model.Close()
 	}
 	return nil
 }
 
 // Process transcribes mono 16kHz float32 audio samples to text.
 func (t *WhisperTranscriber) Process(samples []float32) (string, error) {
+	segments, err := t.ProcessSegments(samples)
+	if err != nil {
+		return "", err
+	}
+	return SegmentsText(segments), nil
+}
+
+// ProcessRaw transcribes interleaved float32 samples recorded at sampleRate
+// with the given number of channels, downmixing and resampling them to mono
+// 16kHz first. Whisper reads any other layout as garbled speech rather than
+// failing, so this guards callers whose audio format may be misconfigured.
+func (t *WhisperTranscriber) ProcessRaw(samples []float32, sampleRate, channels uint32) (string, error) {
+	mono, err := toModelFormat(samples, sampleRate, channels)
+	if err != nil {
+		return "", err
+	}
+	return t.Process(mono)
+}
+
+// ProcessSegments transcribes mono 16kHz float32 audio samples and returns
+// the timestamped segments reported by whisper. Empty audio yields no
+// segments; audio shorter than whisperMinSamples returns ErrAudioTooShort.
+func (t *WhisperTranscriber) ProcessSegments(samples []float32) ([]Segment, error) {
+	if len(samples) == 0 {
+		return nil, nil
+	}
+	if t.model == nil {
+		return nil, ErrModelNotLoaded
+	}
+	if len(samples) < whisperMinSamples {
+		return nil, fmt.Errorf("%w: %d ms, whisper needs %d ms",
+			ErrAudioTooShort, len(samples)*1000/16000, whisperMinSamples*1000/16000)
+	}
+
+	// A fresh context per call is deliberate. In the Go binding a Context is
+	// only a set of decode params and a segment cursor over the model's one
+	// whisper_context, so creating it allocates no inference state (see
+	// BenchmarkWhisperNewContext) and caching it would save nothing. Reuse
+	// would also be wrong: Process doesn't rewind the cursor, so NextSegment
+	// on a reused context skips the first segments of the next recording.
 	ctx, err := t.model.NewContext()
 	if err != nil {
-		return "", fmt.Errorf("transcribe: create context: %w", err)
+		return nil, fmt.Errorf("transcribe: create context: %w", err)
+	}
+
+	if err := setTask(ctx, t.translate); err != nil {
+		return nil, fmt.Errorf("transcribe: %w", err)
+	}
+	if t.prompt != "" {
+		ctx.SetInitialPrompt(t.prompt)
+	}
+	if t.temperature != nil {
+		ctx.SetTemperature(*t.temperature)
+	}
+	if t.tempInc != nil {
+		ctx.SetTemperatureFallback(*t.tempInc)
+	}
+	if t.beamSize > 0 {
+		ctx.SetBeamSize(t.beamSize)
 	}
 
 	if err := ctx.Process(samples, nil, nil, nil); err != nil {
-		return "", fmt.Errorf("transcribe: process: %w", err)
+		return nil, fmt.Errorf("transcribe: process: %w", err)
 	}
 
-	var segments []string
+	var segments []Segment
 	for {
 		seg, err := ctx.NextSegment()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("transcribe: next segment: %w", err)
+			return nil, fmt.Errorf("transcribe: next segment: %w", err)
+		}
+		text := seg.Text
+		if t.annotations != nil {
+			if text = t.annotations.strip(text); strings.TrimSpace(text) == "" {
+				continue
+			}
+		}
+		segments = append(segments, Segment{
+			Start:      seg.Start,
+			End:        seg.End,
+			Text:       text,
+			Confidence: tokenConfidence(seg.Tokens),
+		})
+	}
+	return segments, nil
+}
+
+// DetectLanguage identifies the spoken language of mono 16kHz float32 audio
+// samples and returns its code with whisper's probability for it. It runs a
+// separate whisper pass over the first 30s, so it roughly doubles the work
+// for a recording. Requires a multilingual model.
+func (t *WhisperTranscriber) DetectLanguage(samples []float32) (string, float64, error) {
+	if len(samples) == 0 {
+		return "", 0, errors.New("transcribe: detect language: no audio")
+	}
+	if !t.model.IsMultilingual() {
+		return "", 0, errors.New("transcribe: detect language needs a multilingual whisper model")
+	}
+	if len(samples) > detectWindowSamples {
+		samples = samples[:detectWindowSamples]
+	}
+
+	ctx, err := t.model.NewContext()
+	if err != nil {
+		return "", 0, fmt.Errorf("transcribe: create context: %w", err)
+	}
+	if err := ctx.SetLanguage("auto"); err != nil {
+		return "", 0, fmt.Errorf("transcribe: set language: %w", err)
+	}
+	// WhisperLangAutoDetect reads the context's mel spectrogram, which the
+	// bindings only compute inside Process. Process runs a full decode of
+	// the window, so this is the expensive part of detection.
+	if err := ctx.Process(samples, nil, nil, nil); err != nil {
+		return "", 0, fmt.Errorf("transcribe: process: %w", err)
+	}
+	probs, err := ctx.WhisperLangAutoDetect(0, min(4, runtime.NumCPU()))
+	if err != nil {
+		return "", 0, fmt.Errorf("transcribe: detect language: %w", err)
+	}
+
+	// probs is indexed by whisper language id, as is Languages().
+	langs := t.model.Languages()
+	best := -1
+	for i, p := range probs {
+		if i < len(langs) && (best < 0 || p > probs[best]) {
+			best = i
 		}
-		segments = append(segments, seg.Text)
 	}
+	if best < 0 {
+		return "", 0, errors.New("transcribe: detect language: no language probabilities")
+	}
+	return langs[best], float64(probs[best]), nil
+}
 
-	return strings.TrimSpace(strings.Join(segments, " ")), nil
+// tokenConfidence returns the mean probability of a segment's text tokens.
+// Special tokens such as timestamps ("[_TT_150]") and "<|endoftext|>" are
+// not part of the text and are skipped.
+func tokenConfidence(tokens []whisper.Token) float64 {
+	var sum float64
+	n := 0
+	for _, tok := range tokens {
+		if strings.HasPrefix(tok.Text, "[_") || strings.HasPrefix(tok.Text, "<|") {
+			continue
+		}
+		sum += float64(tok.P)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
 }
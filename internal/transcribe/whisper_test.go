@@ -1,12 +1,20 @@
 package transcribe
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/go-audio/wav"
+	whisper "github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
+
+	"github.com/chaz8081/gostt-writer/internal/audio"
 )
 
 // whisperModelPath resolves the path to the whisper model relative to the project root.
@@ -22,7 +30,7 @@ func whisperModelPath(t *testing.T) string {
 func TestNewWhisperTranscriber(t *testing.T) {
 	path := whisperModelPath(t)
 
-	tr, err := NewWhisperTranscriber(path)
+	tr, err := NewWhisperTranscriber(path, WhisperOptions{})
 	if err != nil {
 		t.Fatalf("NewWhisperTranscriber(%q) returned error: %v", path, err)
 	}
@@ -36,34 +44,314 @@ func TestNewWhisperTranscriber(t *testing.T) {
 	}
 }
 
+// fakeWhisperModel hands out fakeWhisperContexts. Unimplemented Model methods
+// panic via the nil embedded interface.
+type fakeWhisperModel struct {
+	whisper.Model
+	contexts []*fakeWhisperContext
+	segments []whisper.Segment // returned by each context's NextSegment
+
+	multilingual bool
+	languages    []string  // returned by Languages
+	langProbs    []float32 // returned by each context's WhisperLangAutoDetect
+}
+
+func (m *fakeWhisperModel) NewContext() (whisper.Context, error) {
+	ctx := &fakeWhisperContext{segments: m.segments, langProbs: m.langProbs}
+	m.contexts = append(m.contexts, ctx)
+	return ctx, nil
+}
+
+func (m *fakeWhisperModel) Close() error { return nil }
+
+func (m *fakeWhisperModel) IsMultilingual() bool { return m.multilingual }
+
+func (m *fakeWhisperModel) Languages() []string { return m.languages }
+
+// fakeWhisperContext records the calls made on it, in order.
+type fakeWhisperContext struct {
+	whisper.Context
+	calls    []string
+	prompt   string
+	segments []whisper.Segment
+
+	langProbs []float32
+	processed int // samples passed to Process
+}
+
+func (c *fakeWhisperContext) SetInitialPrompt(prompt string) {
+	c.calls = append(c.calls, "SetInitialPrompt")
+	c.prompt = prompt
+}
+
+func (c *fakeWhisperContext) SetLanguage(lang string) error {
+	c.calls = append(c.calls, "SetLanguage:"+lang)
+	return nil
+}
+
+func (c *fakeWhisperContext) SetTranslate(on bool) {
+	if on {
+		c.calls = append(c.calls, "SetTranslate")
+	}
+}
+
+func (c *fakeWhisperContext) SetTemperature(t float32) {
+	c.calls = append(c.calls, fmt.Sprintf("SetTemperature:%g", t))
+}
+
+func (c *fakeWhisperContext) SetTemperatureFallback(t float32) {
+	c.calls = append(c.calls, fmt.Sprintf("SetTemperatureFallback:%g", t))
+}
+
+func (c *fakeWhisperContext) SetBeamSize(n int) {
+	c.calls = append(c.calls, fmt.Sprintf("SetBeamSize:%d", n))
+}
+
+func (c *fakeWhisperContext) Process(samples []float32, _ whisper.EncoderBeginCallback, _ whisper.SegmentCallback, _ whisper.ProgressCallback) error {
+	c.calls = append(c.calls, "Process")
+	c.processed = len(samples)
+	return nil
+}
+
+func (c *fakeWhisperContext) WhisperLangAutoDetect(int, int) ([]float32, error) {
+	c.calls = append(c.calls, "WhisperLangAutoDetect")
+	return c.langProbs, nil
+}
+
+func (c *fakeWhisperContext) NextSegment() (whisper.Segment, error) {
+	if len(c.segments) == 0 {
+		return whisper.Segment{}, io.EOF
+	}
+	seg := c.segments[0]
+	c.segments = c.segments[1:]
+	return seg, nil
+}
+
+func TestWhisperProcessRawCoercesFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate, chs  uint32
+		frames     int
+		wantLength int
+	}{
+		{"mono 16kHz", 16000, 1, 16000, 16000},
+		{"stereo 16kHz", 16000, 2, 16000, 16000},
+		{"stereo 48kHz", 48000, 2, 48000, 16000},
+		{"mono 44.1kHz", 44100, 1, 22050, 8000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeWhisperModel{}
+			tr := &WhisperTranscriber{model: model}
+			if _, err := tr.ProcessRaw(make([]float32, tt.frames*int(tt.chs)), tt.rate, tt.chs); err != nil {
+				t.Fatalf("ProcessRaw() error = %v", err)
+			}
+			if len(model.contexts) != 1 {
+				t.Fatalf("contexts = %d, want 1", len(model.contexts))
+			}
+			if got := model.contexts[0].processed; got != tt.wantLength {
+				t.Errorf("whisper got %d samples, want %d (mono 16kHz)", got, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestWhisperProcessRawInvalidFormat(t *testing.T) {
+	tr := &WhisperTranscriber{model: &fakeWhisperModel{}}
+	for _, f := range [][2]uint32{{0, 1}, {16000, 0}} {
+		if _, err := tr.ProcessRaw(make([]float32, 16000), f[0], f[1]); err == nil {
+			t.Errorf("ProcessRaw(rate %d, channels %d) error = nil, want error", f[0], f[1])
+		}
+	}
+}
+
+func TestWhisperInitialPrompt(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       WhisperOptions
+		wantPrompt string
+		wantCalls  []string
+	}{
+		{
+			name:      "no_prompt",
+			wantCalls: []string{"Process"},
+		},
+		{
+			name:       "initial_prompt",
+			opts:       WhisperOptions{InitialPrompt: "Go code review."},
+			wantPrompt: "Go code review.",
+			wantCalls:  []string{"SetInitialPrompt", "Process"},
+		},
+		{
+			name:       "hot_words_only",
+			opts:       WhisperOptions{HotWords: []string{"goroutine", " ", "gofmt"}},
+			wantPrompt: "goroutine, gofmt",
+			wantCalls:  []string{"SetInitialPrompt", "Process"},
+		},
+		{
+			name:       "prompt_and_hot_words",
+			opts:       WhisperOptions{InitialPrompt: "Go code review.", HotWords: []string{"goroutine", "gofmt"}},
+			wantPrompt: "Go code review. goroutine, gofmt",
+			wantCalls:  []string{"SetInitialPrompt", "Process"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeWhisperModel{}
+			tr := &WhisperTranscriber{model: model, prompt: whisperPrompt(tt.opts)}
+
+			if _, err := tr.Process(make([]float32, 16000)); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if len(model.contexts) != 1 {
+				t.Fatalf("contexts created = %d, want 1", len(model.contexts))
+			}
+			ctx := model.contexts[0]
+			if !reflect.DeepEqual(ctx.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", ctx.calls, tt.wantCalls)
+			}
+			if ctx.prompt != tt.wantPrompt {
+				t.Errorf("prompt = %q, want %q", ctx.prompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestWhisperTranslate(t *testing.T) {
+	for _, translate := range []bool{false, true} {
+		model := &fakeWhisperModel{}
+		tr := &WhisperTranscriber{model: model, prompt: "Go.", translate: translate}
+		if _, err := tr.Process(make([]float32, 16000)); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		want := []string{"SetInitialPrompt", "Process"}
+		if translate {
+			want = append([]string{"SetLanguage:auto", "SetTranslate"}, want...)
+		}
+		if got := model.contexts[0].calls; !reflect.DeepEqual(got, want) {
+			t.Errorf("translate=%v: calls = %v, want %v", translate, got, want)
+		}
+	}
+}
+
+func TestWhisperTemperature(t *testing.T) {
+	temp, inc := float32(0.4), float32(0.2)
+	tests := []struct {
+		name      string
+		opts      WhisperOptions
+		wantCalls []string
+	}{
+		{name: "whisper_defaults", wantCalls: []string{"Process"}},
+		{
+			name:      "temperature_and_fallback",
+			opts:      WhisperOptions{Temperature: &temp, TemperatureInc: &inc},
+			wantCalls: []string{"SetTemperature:0.4", "SetTemperatureFallback:0.2", "Process"},
+		},
+		{
+			name:      "deterministic",
+			opts:      WhisperOptions{Deterministic: true},
+			wantCalls: []string{"SetTemperature:0", "SetTemperatureFallback:0", "SetBeamSize:1", "Process"},
+		},
+		{
+			name:      "deterministic_overrides_temperature",
+			opts:      WhisperOptions{Temperature: &temp, TemperatureInc: &inc, Deterministic: true},
+			wantCalls: []string{"SetTemperature:0", "SetTemperatureFallback:0", "SetBeamSize:1", "Process"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeWhisperModel{}
+			tr := newWhisperTranscriber(model, tt.opts)
+			if _, err := tr.Process(make([]float32, 16000)); err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if got := model.contexts[0].calls; !reflect.DeepEqual(got, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWhisperSegmentConfidence(t *testing.T) {
+	model := &fakeWhisperModel{segments: []whisper.Segment{
+		{
+			Start: 0, End: time.Second, Text: " Hello world.",
+			Tokens: []whisper.Token{
+				{Text: "[_BEG_]", P: 0.1},
+				{Text: " Hello", P: 0.9},
+				{Text: " world", P: 0.8},
+				{Text: ".", P: 0.7},
+				{Text: "[_TT_50]", P: 0.1},
+			},
+		},
+		{Start: time.Second, End: 2 * time.Second, Text: " ", Tokens: []whisper.Token{{Text: "<|endoftext|>", P: 1}}},
+	}}
+	tr := &WhisperTranscriber{model: model}
+
+	segments, err := tr.ProcessSegments(make([]float32, 32000))
+	if err != nil {
+		t.Fatalf("ProcessSegments() error = %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2", len(segments))
+	}
+	if got := segments[0].Confidence; math.Abs(got-0.8) > 1e-6 {
+		t.Errorf("segment 0 confidence = %v, want 0.8 (special tokens skipped)", got)
+	}
+	if got := segments[1].Confidence; got != 0 {
+		t.Errorf("segment 1 confidence = %v, want 0 (no text tokens)", got)
+	}
+}
+
+func TestWhisperProcessShortInput(t *testing.T) {
+	tests := []struct {
+		name         string
+		samples      []float32
+		wantErr      error
+		wantContexts int
+	}{
+		{name: "nil", samples: nil, wantContexts: 0},
+		{name: "empty", samples: []float32{}, wantContexts: 0},
+		{name: "single_sample", samples: []float32{0.5}, wantErr: ErrAudioTooShort, wantContexts: 0},
+		{name: "minimum", samples: make([]float32, whisperMinSamples), wantContexts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &fakeWhisperModel{}
+			tr := &WhisperTranscriber{model: model}
+
+			text, err := tr.Process(tt.samples)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+			if text != "" {
+				t.Errorf("Process() = %q, want empty", text)
+			}
+			if len(model.contexts) != tt.wantContexts {
+				t.Errorf("contexts created = %d, want %d", len(model.contexts), tt.wantContexts)
+			}
+		})
+	}
+}
+
 func TestNewWhisperTranscriberBadPath(t *testing.T) {
-	_, err := NewWhisperTranscriber("/nonexistent/model.bin")
+	_, err := NewWhisperTranscriber("/nonexistent/model.bin", WhisperOptions{})
 	if err == nil {
 		t.Fatal("NewWhisperTranscriber with bad path should return error")
 	}
 }
 
-// loadWAVSamples loads a 16-bit PCM WAV file and returns mono float32 samples
+// loadWAVSamples loads a PCM WAV file as 16kHz mono float32 samples
 // normalized to [-1.0, 1.0]. The test is skipped if the file does not exist.
 func loadWAVSamples(t *testing.T, wavPath string) []float32 {
 	t.Helper()
-	f, err := os.Open(wavPath)
-	if err != nil {
+	if _, err := os.Stat(wavPath); err != nil {
 		t.Skipf("WAV file not found at %s: %v", wavPath, err)
 	}
-	defer func() { _ = f.Close() }()
-
-	dec := wav.NewDecoder(f)
-	buf, err := dec.FullPCMBuffer()
+	samples, err := audio.LoadWAV(wavPath, 16000)
 	if err != nil {
 		t.Fatalf("decode WAV %s: %v", wavPath, err)
 	}
-
-	// Convert int samples to float32 normalized to [-1.0, 1.0]
-	samples := make([]float32, len(buf.Data))
-	for i, s := range buf.Data {
-		samples[i] = float32(s) / 32768.0
-	}
 	return samples
 }
 
@@ -95,7 +383,7 @@ func TestWhisperProcessJFK(t *testing.T) {
 	path := whisperModelPath(t)
 	samples := jfkSamples(t)
 
-	tr, err := NewWhisperTranscriber(path)
+	tr, err := NewWhisperTranscriber(path, WhisperOptions{})
 	if err != nil {
 		t.Fatalf("NewWhisperTranscriber: %v", err)
 	}
@@ -115,7 +403,7 @@ func TestWhisperProcessJFK(t *testing.T) {
 func TestWhisperProcessEmptyAudio(t *testing.T) {
 	path := whisperModelPath(t)
 
-	tr, err := NewWhisperTranscriber(path)
+	tr, err := NewWhisperTranscriber(path, WhisperOptions{})
 	if err != nil {
 		t.Fatalf("NewWhisperTranscriber: %v", err)
 	}
@@ -129,3 +417,52 @@ func TestWhisperProcessEmptyAudio(t *testing.T) {
 	}
 	_ = text
 }
+
+func TestWhisperDetectLanguage(t *testing.T) {
+	model := &fakeWhisperModel{
+		multilingual: true,
+		languages:    []string{"en", "de", "es"},
+		langProbs:    []float32{0.03, 0.01, 0.94, 0.5}, // ids beyond Languages() are ignored
+	}
+	tr := &WhisperTranscriber{model: model}
+
+	lang, prob, err := tr.DetectLanguage(make([]float32, 40*16000))
+	if err != nil {
+		t.Fatalf("DetectLanguage() error = %v", err)
+	}
+	if lang != "es" || math.Abs(prob-0.94) > 1e-6 {
+		t.Errorf("DetectLanguage() = %q, %v, want \"es\", 0.94", lang, prob)
+	}
+	ctx := model.contexts[0]
+	wantCalls := []string{"SetLanguage:auto", "Process", "WhisperLangAutoDetect"}
+	if !reflect.DeepEqual(ctx.calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", ctx.calls, wantCalls)
+	}
+	if ctx.processed != detectWindowSamples {
+		t.Errorf("processed %d samples, want the first %d", ctx.processed, detectWindowSamples)
+	}
+}
+
+func TestWhisperDetectLanguageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *fakeWhisperModel
+		samples []float32
+	}{
+		{name: "no_audio", model: &fakeWhisperModel{multilingual: true}, samples: nil},
+		{name: "english_only", model: &fakeWhisperModel{}, samples: make([]float32, 16000)},
+		{
+			name:    "no_probabilities",
+			model:   &fakeWhisperModel{multilingual: true, languages: []string{"en"}},
+			samples: make([]float32, 16000),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := &WhisperTranscriber{model: tt.model}
+			if _, _, err := tr.DetectLanguage(tt.samples); err == nil {
+				t.Error("DetectLanguage() error = nil, want error")
+			}
+		})
+	}
+}